@@ -20,6 +20,7 @@ import (
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	multiplatformconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/multiplatform"
 	xceleratconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 )
@@ -587,19 +588,22 @@ var authClearCmd = &cobra.Command{
 	},
 }
 
+// nolint:gochecknoglobals
+var tokenScope string
+
 // nolint:gochecknoglobals
 var authTokenCmd = &cobra.Command{
 	Use:           "token",
 	Short:         "Resolve and print the Bitrise Build Cache auth token to stdout",
-	Long:          "Resolves the auth token via the same precedence chain as the rest of the CLI (env vars → OS keychain → multiplatform analytics config) and prints it to stdout. Intended for build-time consumers (Gradle init script, future Bazel workspace_status_command) that need the resolved token without baking it into a config file. On failure exits non-zero with a short one-line message on stderr (no cobra Error: prefix) — callers framing the wrapper script own the wording.",
+	Long:          "Resolves the auth token via the same precedence chain as the rest of the CLI (env vars → OS keychain → multiplatform analytics config) and prints it to stdout. Intended for build-time consumers (Gradle init script, future Bazel workspace_status_command) that need the resolved token without baking it into a config file. With --scope (or BITRISE_BUILD_CACHE_TOKEN_SCOPE) a stored browser login prints a short-lived, workspace-scoped token instead: read for developer machines, read-write for trusted pipelines; env and CI credentials are refused with a scope set. On failure exits non-zero with a short one-line message on stderr (no cobra Error: prefix) — callers framing the wrapper script own the wording.",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
-		cfg, _, err := configcommon.ResolveAuthConfig(utils.AllEnvs())
+		cfg, err := credhelper.StoredLoginResolver(cmd.Context(), utils.AllEnvs(), tokenScope)()
 		if err != nil {
 			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
 
-			return fmt.Errorf("resolve auth config: %w", err)
+			return fmt.Errorf("resolve auth token: %w", err)
 		}
 
 		if _, err := fmt.Fprintln(cmd.OutOrStdout(), cfg.TokenInGradleFormat()); err != nil {
@@ -695,6 +699,8 @@ func init() {
 	_ = authSetCmd.MarkFlagRequired("token")
 	_ = authSetCmd.MarkFlagRequired("workspace-id")
 
+	authTokenCmd.Flags().StringVar(&tokenScope, "scope", "", fmt.Sprintf("Print a short-lived token of this scope minted from the stored login: read | read-write. Defaults to %s; empty prints the stored credential.", configcommon.EnvTokenScope))
+
 	authClearCmd.Flags().StringVar(&clearStorage, "storage", "", "Which backend to clear: keychain | file | auto (default auto clears both).")
 
 	authUsernameCmd.Flags().StringVar(&usernameSetValue, "set", "", "Persist this display name into the store holding your credentials (token/workspace untouched). Empty clears the stored override. Omit the flag to print the resolved name instead.")
@@ -30,7 +30,7 @@ This command will:
 - If the configuration block already exists, only update its content
 
 The command supports:
-- Remote caching with push/pull capabilities
+- Remote caching with push/pull capabilities, directly or through the local proxy (--cache-proxy)
 - Build Event Service (BES) integration
 - Remote Build Execution (RBE)`,
 	RunE:         activateBazel,
@@ -47,6 +47,8 @@ func init() {
 	flags.BoolVar(&activateBazelParams.Cache.Enabled, "cache", activateBazelParams.Cache.Enabled, "Enable remote cache")
 	flags.BoolVar(&activateBazelParams.Cache.PushEnabled, "cache-push", activateBazelParams.Cache.PushEnabled, "Enable pushing new cache entries")
 	flags.StringVar(&activateBazelParams.Cache.Endpoint, "cache-endpoint", activateBazelParams.Cache.Endpoint, "Remote cache endpoint URL")
+	flags.BoolVar(&activateBazelParams.Cache.UseProxy, "cache-proxy", activateBazelParams.Cache.UseProxy, "Route remote cache calls through the local Bazel proxy (`bazel start-proxy`)")
+	flags.StringVar(&activateBazelParams.Cache.ProxySocketPath, "cache-proxy-socket", activateBazelParams.Cache.ProxySocketPath, "Unix socket of the local Bazel proxy, defaults to $"+bazelconfig.EnvProxySocketPath+" or <temp dir>/bazel-proxy.sock")
 	flags.BoolVar(&activateBazelParams.BES.Enabled, "bes", activateBazelParams.BES.Enabled, "Enable Build Event Service (BES)")
 	flags.StringVar(&activateBazelParams.BES.Endpoint, "bes-endpoint", activateBazelParams.BES.Endpoint, "BES endpoint URL")
 	flags.BoolVar(&activateBazelParams.RBE.Enabled, "rbe", activateBazelParams.RBE.Enabled, "Enable Remote Build Execution (RBE)")
@@ -102,6 +104,9 @@ func activateBazel(_ *cobra.Command, _ []string) error {
 			BESEnabled:        activateBazelParams.BES.Enabled,
 			RBEEnabled:        activateBazelParams.RBE.Enabled,
 			TimestampsEnabled: activateBazelParams.Timestamps,
+
+			CacheEndpoint:        activateBazelParams.Cache.Endpoint,
+			CacheProxySocketPath: activateBazelParams.CacheProxySocketPath(utils.AllEnvs()),
 		}); mErr != nil {
 			logger.Debugf("bazel sidecar write failed (non-fatal): %s", mErr)
 		}
@@ -0,0 +1,206 @@
+package bazel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/bazelproxy"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	bazelconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/bazel"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/invocations"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/proxypid"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/kv_storage"
+)
+
+//nolint:gochecknoglobals
+var (
+	bazelCmd = &cobra.Command{
+		Use:          "bazel",
+		Short:        "Bazel related commands",
+		Long:         "Bazel related commands. To set up the remote cache for Bazel, use `activate bazel` first.",
+		SilenceUsage: true,
+	}
+
+	startProxyCmd = &cobra.Command{
+		Use:   "start-proxy",
+		Short: "Start the local Bazel remote cache proxy",
+		Long: `start-proxy serves the Bazel remote cache API (ActionCache, ContentAddressableStorage, Capabilities and ByteStream) ` +
+			`on a local unix socket and forwards the calls to the Bitrise Build Cache. ` +
+			"Activate it with `activate bazel --cache-proxy`; the daemon runs it as the bazel-proxy service.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode))
+			logger.TInfof("Bazel Proxy")
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("get user home dir: %w", err)
+			}
+
+			sidecar, found, err := bazelconfig.ReadSidecar(home)
+			if err != nil {
+				return fmt.Errorf("read bazel config: %w", err)
+			}
+			if !found || sidecar.CacheProxySocketPath == "" {
+				logger.TInfof("Bazel proxy not configured; run `bitrise-build-cache activate bazel --cache-proxy` to enable. Proxy idle.")
+
+				return nil
+			}
+
+			release, err := proxypid.Acquire(utils.DefaultOsProxy{}, bazelconfig.ProxyPidFilePath(home), nil)
+			if err != nil {
+				logger.Infof("Skipping proxy startup: %s", err)
+
+				return nil
+			}
+			defer func() {
+				if err := release(); err != nil {
+					logger.Warnf("Failed to release proxy pid lock: %s", err)
+				}
+			}()
+
+			if err := os.Remove(sidecar.CacheProxySocketPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove socket file, error: %w", err)
+			}
+
+			logger.TInfof("socketPath: %s", sidecar.CacheProxySocketPath)
+
+			signalCtx, stopSignals := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+			defer stopSignals()
+
+			listener, err := (&net.ListenConfig{}).Listen(signalCtx, "unix", sidecar.CacheProxySocketPath)
+			if err != nil {
+				return fmt.Errorf("failed to listen on unix socket: %w", err)
+			}
+			defer listener.Close()
+
+			return StartBazelProxy(
+				signalCtx,
+				sidecar,
+				utils.AllEnvs(),
+				func(name string, v ...string) (string, error) {
+					output, err := exec.Command(name, v...).Output()
+
+					return string(output), err
+				},
+				nil,
+				listener,
+				logger,
+			)
+		},
+	}
+)
+
+func init() {
+	common.RootCmd.AddCommand(bazelCmd)
+	bazelCmd.AddCommand(startProxyCmd)
+}
+
+// StartBazelProxy serves the Bazel proxy on listener until ctx is cancelled.
+func StartBazelProxy(
+	ctx context.Context,
+	sidecar bazelconfig.Sidecar,
+	envProvider map[string]string,
+	commandFunc configcommon.CommandFunc,
+	bitriseKVClient kv_storage.KVStorageClient,
+	listener net.Listener,
+	logger log.Logger,
+) error {
+	oauthCfg := oauth.NewConfigFromEnv(envProvider)
+	oauthCfg.Logger = logger
+	authProvider := credhelper.NewAuthSource(context.WithoutCancel(ctx), envProvider, oauthCfg, logger)
+
+	client, err := common.CreateKVClient(ctx, common.CreateKVClientParams{
+		CacheOperationID: uuid.NewString(),
+		ClientName:       common.ClientNameBazel,
+		AuthConfig:       authProvider.Get(),
+		AuthSource:       authProvider,
+		Envs:             envProvider,
+		CommandFunc:      commandFunc,
+		Logger:           logger,
+		BitriseKVClient:  bitriseKVClient,
+		EndpointURL:      sidecar.CacheEndpoint,
+		SkipCapabilities: true, // proxy checks capabilities once per Bazel invocation
+		CircuitBreaker:   kv.NewCircuitBreaker(kv.CircuitBreakerParams{Logger: logger}),
+	})
+	if err != nil {
+		return fmt.Errorf("create kv client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	metadata := configcommon.NewMetadata(envProvider, commandFunc, logger)
+
+	p := bazelproxy.NewProxy(client, sidecar.CachePushEnabled, logger, newLocalInvocationEmitter(metadata, logger))
+	p.AppSlug = metadata.BitriseAppID
+	p.BuildSlug = metadata.BitriseBuildID
+
+	go func() {
+		<-ctx.Done()
+		p.GracefulStop()
+	}()
+
+	serveErr := p.Serve(listener)
+
+	p.FlushCurrentSession(context.WithoutCancel(ctx))
+
+	//nolint:wrapcheck
+	return serveErr
+}
+
+// localInvocationEmitter records every proxied Bazel invocation in the local invocation log.
+type localInvocationEmitter struct {
+	writer   *invocations.Writer
+	metadata configcommon.CacheConfigMetadata
+	logger   log.Logger
+}
+
+func newLocalInvocationEmitter(metadata configcommon.CacheConfigMetadata, logger log.Logger) bazelproxy.InvocationEmitter {
+	p, err := paths.Default()
+	if err != nil {
+		logger.Warnf("Local invocation log disabled: %s", err)
+
+		return nil
+	}
+
+	writer := invocations.NewWriter(p)
+	writer.Logger = logger
+
+	return &localInvocationEmitter{writer: writer, metadata: metadata, logger: logger}
+}
+
+func (e *localInvocationEmitter) EmitSession(_ context.Context, meta bazelproxy.SessionMeta, stats bazelproxy.SessionStats) {
+	command := meta.ToolName
+	if command == "" {
+		command = "bazel"
+	}
+
+	if err := e.writer.Append(invocations.Record{
+		InvocationID: meta.InvocationID,
+		Command:      command,
+		Tool:         invocations.ToolBazel,
+		ToolVersion:  meta.ToolVersion,
+		CLIVersion:   e.metadata.CLIVersion,
+		StartedAt:    meta.StartTime,
+		FinishedAt:   meta.EndTime,
+		CIProvider:   e.metadata.CIProvider,
+		Username:     e.metadata.HostMetadata.Username,
+		HitRate:      stats.HitRate(),
+	}); err != nil {
+		e.logger.Warnf("Failed to append local invocation log: %s", err)
+	}
+}
@@ -0,0 +1,20 @@
+// Package cache exposes the `cache` cobra subcommand for looking at what is stored in the remote Build Cache.
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+)
+
+//nolint:gochecknoglobals
+var cacheCmd = &cobra.Command{
+	Use:          "cache",
+	Short:        "Work with entries stored in the remote Bitrise Build Cache",
+	SilenceUsage: true,
+}
+
+//nolint:gochecknoinits
+func init() {
+	common.RootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cacheinspect"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+//nolint:gochecknoglobals
+var inspectFlags struct {
+	key      string
+	json     bool
+	depth    int
+	maxBytes int
+}
+
+//nolint:gochecknoglobals
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Download the entry stored under a key and pretty-print it",
+	Long: `inspect downloads the entry stored under --key and prints it according to its kind: ` +
+		`DerivedData and Gradle configuration cache metadata as file trees with sizes, ` +
+		`xcelerate CAS blobs with their reference list, and anything else as a hexdump. ` +
+		`Metadata keys only store the checksum of the metadata, which is followed to the metadata itself.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// logs go to stderr so stdout only carries the entry, e.g. for --json
+		logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode), log.WithOutput(cmd.ErrOrStderr()))
+
+		envs := utils.AllEnvs()
+		authConfig, _, err := configcommon.ResolveAuthConfig(envs)
+		if err != nil {
+			return fmt.Errorf("resolve auth config: %w", err)
+		}
+
+		kvClient, err := common.CreateKVClient(cmd.Context(), common.CreateKVClientParams{
+			CacheOperationID: uuid.NewString(),
+			ClientName:       common.ClientNameInspect,
+			AuthConfig:       authConfig,
+			Envs:             envs,
+			CommandFunc: func(name string, v ...string) (string, error) {
+				output, err := exec.Command(name, v...).Output()
+
+				return string(output), err
+			},
+			Logger: logger,
+		})
+		if err != nil {
+			return fmt.Errorf("create kv client: %w", err)
+		}
+		defer func() { _ = kvClient.Close() }()
+
+		return inspectCmdFn(cmd.Context(), kvClient, inspectParams{
+			key:      inspectFlags.key,
+			json:     inspectFlags.json,
+			depth:    inspectFlags.depth,
+			maxBytes: inspectFlags.maxBytes,
+		}, cmd.OutOrStdout(), logger)
+	},
+}
+
+type inspectClient interface {
+	DownloadStream(ctx context.Context, destination io.Writer, key string) error
+}
+
+type inspectParams struct {
+	key      string
+	json     bool
+	depth    int
+	maxBytes int
+}
+
+func inspectCmdFn(ctx context.Context, client inspectClient, params inspectParams, out io.Writer, logger log.Logger) error {
+	var buf bytes.Buffer
+	if err := client.DownloadStream(ctx, &buf, params.key); err != nil {
+		return fmt.Errorf("download %s: %w", params.key, err)
+	}
+
+	// metadata keys only point at the metadata blob, show the blob instead
+	checksum, isPointer := cacheinspect.ChecksumPointer(buf.Bytes())
+	if isPointer {
+		logger.Debugf("Key %s points to metadata %s", params.key, checksum)
+		buf.Reset()
+		if err := client.DownloadStream(ctx, &buf, checksum); err != nil {
+			return fmt.Errorf("download metadata %s of %s: %w", checksum, params.key, err)
+		}
+	}
+
+	entry := cacheinspect.Inspect(params.key, buf.Bytes(), params.maxBytes)
+	if isPointer {
+		entry.MetadataChecksum = checksum
+	}
+	if params.json {
+		return cacheinspect.WriteJSON(out, entry) //nolint:wrapcheck
+	}
+
+	return cacheinspect.WriteText(out, entry, params.depth) //nolint:wrapcheck
+}
+
+//nolint:gochecknoinits
+func init() {
+	inspectCmd.Flags().StringVar(&inspectFlags.key, "key", "", "The cache key of the entry to inspect (required)")
+	inspectCmd.Flags().BoolVar(&inspectFlags.json, "json", false, "Emit the entry as JSON instead of text.")
+	inspectCmd.Flags().IntVar(&inspectFlags.depth, "depth", 3, "Directory levels of file trees to expand, 0 expands them fully.")
+	inspectCmd.Flags().IntVar(&inspectFlags.maxBytes, "max-bytes", cacheinspect.DefaultMaxBytes, "Maximum number of bytes of opaque content to dump.")
+	_ = inspectCmd.MarkFlagRequired("key")
+
+	cacheCmd.AddCommand(inspectCmd)
+}
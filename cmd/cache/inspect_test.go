@@ -0,0 +1,97 @@
+//go:build unit
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/deriveddata"
+)
+
+type fakeInspectClient struct {
+	entries    map[string][]byte
+	downloaded []string
+}
+
+func (f *fakeInspectClient) DownloadStream(_ context.Context, destination io.Writer, key string) error {
+	f.downloaded = append(f.downloaded, key)
+	data, ok := f.entries[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	_, err := destination.Write(data)
+
+	return err
+}
+
+func TestInspect_followsMetadataChecksum(t *testing.T) {
+	metadata, err := json.Marshal(deriveddata.Metadata{
+		CacheKey:  "xcode-cache-metadata-app-main",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GitBranch: "main",
+		DerivedData: filegroup.Info{Files: []*filegroup.FileInfo{
+			{Path: "/dd/Build/Products/App.app/App", Size: 3000},
+		}},
+		MetadataVersion: 2,
+	})
+	require.NoError(t, err)
+	sum := sha256.Sum256(metadata)
+	checksum := hex.EncodeToString(sum[:])
+
+	client := &fakeInspectClient{entries: map[string][]byte{
+		"xcode-cache-metadata-app-main": []byte(checksum),
+		checksum:                        metadata,
+	}}
+
+	var out bytes.Buffer
+	err = inspectCmdFn(context.Background(), client, inspectParams{key: "xcode-cache-metadata-app-main", maxBytes: 16}, &out, log.NewLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"xcode-cache-metadata-app-main", checksum}, client.downloaded)
+	assert.Contains(t, out.String(), "Key: xcode-cache-metadata-app-main\n")
+	assert.Contains(t, out.String(), "Metadata checksum: "+checksum+"\n")
+	assert.Contains(t, out.String(), "Kind: deriveddata-metadata\n")
+	assert.Contains(t, out.String(), "Git branch: main")
+
+	out.Reset()
+	err = inspectCmdFn(context.Background(), client, inspectParams{key: "xcode-cache-metadata-app-main", json: true}, &out, log.NewLogger())
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, checksum, entry["metadataChecksum"])
+	assert.Equal(t, "deriveddata-metadata", entry["kind"])
+}
+
+func TestInspect_missingMetadataBlob(t *testing.T) {
+	checksum := hex.EncodeToString(make([]byte, sha256.Size))
+	client := &fakeInspectClient{entries: map[string][]byte{"gradle-config-cache-metadata-app": []byte(checksum)}}
+
+	err := inspectCmdFn(context.Background(), client, inspectParams{key: "gradle-config-cache-metadata-app"}, &bytes.Buffer{}, log.NewLogger())
+	require.ErrorContains(t, err, "download metadata "+checksum)
+}
+
+func TestInspect_rawEntryIsNotFollowed(t *testing.T) {
+	client := &fakeInspectClient{entries: map[string][]byte{"key": []byte("just bytes")}}
+
+	var out bytes.Buffer
+	err := inspectCmdFn(context.Background(), client, inspectParams{key: "key", maxBytes: 4}, &out, log.NewLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"key"}, client.downloaded)
+	assert.Contains(t, out.String(), "Kind: raw\n")
+	assert.NotContains(t, out.String(), "Metadata checksum")
+}
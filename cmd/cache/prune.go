@@ -0,0 +1,328 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/deriveddata"
+)
+
+var errPruneAborted = errors.New("prune aborted")
+
+//nolint:gochecknoglobals
+var pruneFlags struct {
+	prefixes    []string
+	apps        []string
+	branches    []string
+	oses        []string
+	templates   []string
+	keys        []string
+	keysFrom    string
+	dryRun      bool
+	yes         bool
+	concurrency int
+}
+
+//nolint:gochecknoglobals
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete whole families of cache keys by prefix",
+	Long: `prune deletes every key starting with --prefix after confirmation, ` +
+		`e.g. all ` + "`xcode-cache-metadata-<appSlug>-`" + ` keys of a renamed app or the Gradle configuration cache of a feature branch. ` +
+		`The remote cache cannot list its keys, so the family is derived from the key templates (--template, ` +
+		`by default the DerivedData and Gradle configuration cache ones) expanded for every --app, --branch and --os; ` +
+		`the keys starting with a prefix are deleted. --app defaults to the app of the CI build, ` +
+		`--branch to the branches of the git repository in the working directory. ` +
+		`Keys can also be listed with --key or --keys-from. ` +
+		`Use --dry-run to only list the keys and --yes to skip the confirmation prompt.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode), log.WithOutput(cmd.ErrOrStderr()))
+
+		envs := utils.AllEnvs()
+		authConfig, _, err := configcommon.ResolveAuthConfig(envs)
+		if err != nil {
+			return fmt.Errorf("resolve auth config: %w", err)
+		}
+
+		kvClient, err := common.CreateKVClient(cmd.Context(), common.CreateKVClientParams{
+			CacheOperationID: uuid.NewString(),
+			ClientName:       common.ClientNamePrune,
+			AuthConfig:       authConfig,
+			Envs:             envs,
+			CommandFunc: func(name string, v ...string) (string, error) {
+				output, err := exec.Command(name, v...).Output()
+
+				return string(output), err
+			},
+			Logger: logger,
+		})
+		if err != nil {
+			return fmt.Errorf("create kv client: %w", err)
+		}
+		defer func() { _ = kvClient.Close() }()
+
+		apps := pruneFlags.apps
+		branches := pruneFlags.branches
+		if len(pruneFlags.prefixes) > 0 {
+			if len(apps) == 0 {
+				app, err := cachekey.NewVars(envs, nil).App()
+				if err != nil {
+					return fmt.Errorf("no app to expand --prefix with, set --app: %w", err)
+				}
+				apps = []string{app}
+			}
+			if len(branches) == 0 {
+				branches, err = gitBranches(func(name string, v ...string) (string, error) {
+					output, err := exec.Command(name, v...).Output()
+
+					return string(output), err
+				})
+				if err != nil {
+					return fmt.Errorf("no branches to expand --prefix with, set --branch: %w", err)
+				}
+			}
+		}
+
+		return pruneCmdFn(cmd.Context(), kvClient, pruneParams{
+			prefixes:    pruneFlags.prefixes,
+			apps:        apps,
+			branches:    branches,
+			oses:        pruneFlags.oses,
+			templates:   pruneFlags.templates,
+			keys:        pruneFlags.keys,
+			keysFrom:    pruneFlags.keysFrom,
+			dryRun:      pruneFlags.dryRun,
+			yes:         pruneFlags.yes,
+			concurrency: pruneFlags.concurrency,
+		}, cmd.InOrStdin(), cmd.OutOrStdout(), logger)
+	},
+}
+
+type pruneClient interface {
+	DeleteKeys(ctx context.Context, keys []string, concurrency int) (kv.DeleteKeysStats, error)
+}
+
+type pruneParams struct {
+	prefixes    []string
+	apps        []string
+	branches    []string
+	oses        []string
+	templates   []string
+	keys        []string
+	keysFrom    string
+	dryRun      bool
+	yes         bool
+	concurrency int
+}
+
+func pruneCmdFn(ctx context.Context, client pruneClient, params pruneParams, in io.Reader, out io.Writer, logger log.Logger) error {
+	keys, err := expandPrefixes(params)
+	if err != nil {
+		return err
+	}
+	keys = append(keys, params.keys...)
+	if params.keysFrom != "" {
+		if params.keysFrom == "-" && !params.dryRun && !params.yes {
+			return errors.New("--keys-from - reads the keys from stdin, so it needs --yes or --dry-run")
+		}
+
+		fromFile, err := readKeys(params.keysFrom, in)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, fromFile...)
+	}
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("empty key")
+		}
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	if len(keys) == 0 {
+		logger.Infof("(i) Nothing to prune")
+
+		return nil
+	}
+
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(out, key); err != nil {
+			return fmt.Errorf("write key: %w", err)
+		}
+	}
+
+	if params.dryRun {
+		logger.Infof("(i) Dry run: %d keys would be deleted", len(keys))
+
+		return nil
+	}
+
+	if !params.yes && !confirm(in, out, fmt.Sprintf("Delete these %d keys from the Bitrise Build Cache?", len(keys))) {
+		return errPruneAborted
+	}
+
+	stats, err := client.DeleteKeys(ctx, keys, params.concurrency)
+	logger.TInfof("(i) Deleted %d keys, %d were already gone, %d failed", stats.KeysDeleted, stats.KeysMissing, stats.KeysFailed)
+	if err != nil {
+		return fmt.Errorf("delete keys: %w", err)
+	}
+
+	return nil
+}
+
+// defaultPruneTemplates are the key templates the DerivedData and Gradle
+// configuration cache commands save their metadata under by default.
+//
+//nolint:gochecknoglobals
+var defaultPruneTemplates = []string{
+	deriveddata.DefaultCacheKeyTemplate,
+	deriveddata.DefaultFallbackCacheKeyTemplate,
+	gradle.DefaultCacheKeyTemplate,
+	gradle.DefaultFallbackCacheKeyTemplate,
+}
+
+// expandPrefixes derives the keys starting with one of params.prefixes from the
+// key templates expanded for every app, branch and OS.
+func expandPrefixes(params pruneParams) ([]string, error) {
+	if len(params.prefixes) == 0 {
+		return nil, nil
+	}
+	for _, prefix := range params.prefixes {
+		if strings.TrimSpace(prefix) == "" {
+			return nil, errors.New("empty prefix")
+		}
+	}
+	if len(params.apps) == 0 || len(params.branches) == 0 || len(params.oses) == 0 {
+		return nil, errors.New("--prefix needs at least one app, branch and OS to expand the key templates with")
+	}
+
+	templates := params.templates
+	if len(templates) == 0 {
+		templates = defaultPruneTemplates
+	}
+
+	var keys []string
+	for _, template := range templates {
+		family, err := cachekey.Family(template, params.apps, params.branches, params.oses)
+		if err != nil {
+			return nil, fmt.Errorf("expand key template: %w", err)
+		}
+
+		for _, key := range family {
+			if slices.ContainsFunc(params.prefixes, func(prefix string) bool { return strings.HasPrefix(key, prefix) }) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// gitBranches lists the local and remote-tracking branches of the git
+// repository in the working directory, without the remote names.
+func gitBranches(commandFunc configcommon.CommandFunc) ([]string, error) {
+	output, err := commandFunc("git", "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("list git branches: %w", err)
+	}
+
+	var branches []string
+	for _, ref := range strings.Split(strings.TrimSpace(output), "\n") {
+		var branch string
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			branch = name
+		} else if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+			_, branch, _ = strings.Cut(name, "/")
+		}
+		if branch == "" || branch == "HEAD" || slices.Contains(branches, branch) {
+			continue
+		}
+		branches = append(branches, branch)
+	}
+	if len(branches) == 0 {
+		return nil, errors.New("no git branches found")
+	}
+
+	return branches, nil
+}
+
+// readKeys reads one key per line from path, or from in when path is "-".
+// Blank lines and lines starting with # are skipped.
+func readKeys(path string, in io.Reader) ([]string, error) {
+	source := in
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open keys file: %w", err)
+		}
+		defer file.Close()
+		source = file
+	}
+
+	var keys []string
+	scanner := bufio.NewScanner(source)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// confirm asks a yes/no question on out and reads the answer from in. Anything
+// but an explicit yes, including a closed input, declines.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	if _, err := fmt.Fprintf(out, "%s [y/N]: ", question); err != nil {
+		return false
+	}
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+//nolint:gochecknoinits
+func init() {
+	pruneCmd.Flags().StringArrayVar(&pruneFlags.prefixes, "prefix", nil, "Delete the keys starting with this prefix, e.g. xcode-cache-metadata-<appSlug>-; can be repeated")
+	pruneCmd.Flags().StringArrayVar(&pruneFlags.apps, "app", nil, "App slug (or repository) to expand the key templates with; can be repeated. Defaults to the app of the CI build.")
+	pruneCmd.Flags().StringArrayVar(&pruneFlags.branches, "branch", nil, "Branch to expand the key templates with; can be repeated. Defaults to the branches of the git repository in the working directory.")
+	pruneCmd.Flags().StringSliceVar(&pruneFlags.oses, "os", []string{"darwin", "linux"}, "Operating systems to expand the key templates with.")
+	pruneCmd.Flags().StringArrayVar(&pruneFlags.templates, "template", nil, "Key template to expand, as given to --key-template when saving; can be repeated. Defaults to the DerivedData and Gradle configuration cache templates.")
+	pruneCmd.Flags().StringArrayVar(&pruneFlags.keys, "key", nil, "Delete this key; can be repeated")
+	pruneCmd.Flags().StringVar(&pruneFlags.keysFrom, "keys-from", "", "Read the keys to delete from this file, one per line; - reads stdin")
+	pruneCmd.Flags().BoolVar(&pruneFlags.dryRun, "dry-run", false, "Only list the matching keys, do not delete anything.")
+	pruneCmd.Flags().BoolVarP(&pruneFlags.yes, "yes", "y", false, "Do not ask for confirmation before deleting.")
+	pruneCmd.Flags().IntVar(&pruneFlags.concurrency, "concurrency", 10, "Maximum number of deletes in flight.")
+	pruneCmd.MarkFlagsOneRequired("prefix", "key", "keys-from")
+
+	cacheCmd.AddCommand(pruneCmd)
+}
@@ -0,0 +1,167 @@
+//go:build unit
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+)
+
+type fakePruneClient struct {
+	deleted []string
+}
+
+func (f *fakePruneClient) DeleteKeys(_ context.Context, keys []string, _ int) (kv.DeleteKeysStats, error) {
+	f.deleted = append(f.deleted, keys...)
+
+	return kv.DeleteKeysStats{KeysDeleted: len(keys)}, nil
+}
+
+func TestPrune_dryRunOnlyLists(t *testing.T) {
+	client := &fakePruneClient{}
+	keysFile := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(keysFile, []byte("# renamed app\nxcode-cache-metadata-app-main\n\nxcode-cache-metadata-other-main\n"), 0o600))
+	var out bytes.Buffer
+
+	err := pruneCmdFn(context.Background(), client, pruneParams{
+		keys:     []string{"xcode-cache-metadata-app-feature", "xcode-cache-metadata-app-main"},
+		keysFrom: keysFile,
+		dryRun:   true,
+	}, strings.NewReader(""), &out, log.NewLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, "xcode-cache-metadata-app-feature\nxcode-cache-metadata-app-main\nxcode-cache-metadata-other-main\n", out.String())
+	assert.Empty(t, client.deleted)
+}
+
+func TestPrune_confirmation(t *testing.T) {
+	params := pruneParams{keys: []string{"xcode-cache-metadata-app-main", "xcode-cache-metadata-app-feature"}}
+
+	client := &fakePruneClient{}
+	var out bytes.Buffer
+	err := pruneCmdFn(context.Background(), client, params, strings.NewReader("n\n"), &out, log.NewLogger())
+	require.ErrorIs(t, err, errPruneAborted)
+	assert.Contains(t, out.String(), "Delete these 2 keys from the Bitrise Build Cache? [y/N]")
+	assert.Empty(t, client.deleted)
+
+	// closed input declines too
+	err = pruneCmdFn(context.Background(), client, params, strings.NewReader(""), &out, log.NewLogger())
+	require.ErrorIs(t, err, errPruneAborted)
+
+	err = pruneCmdFn(context.Background(), client, params, strings.NewReader("yes\n"), &out, log.NewLogger())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"xcode-cache-metadata-app-main", "xcode-cache-metadata-app-feature"}, client.deleted)
+}
+
+func TestPrune_keysFromStdin(t *testing.T) {
+	client := &fakePruneClient{}
+
+	// stdin cannot carry both the keys and the confirmation
+	err := pruneCmdFn(context.Background(), client, pruneParams{keysFrom: "-"},
+		strings.NewReader("a\nb\n"), &bytes.Buffer{}, log.NewLogger())
+	require.Error(t, err)
+	assert.Empty(t, client.deleted)
+
+	err = pruneCmdFn(context.Background(), client, pruneParams{keysFrom: "-", yes: true},
+		strings.NewReader("a\nb\n"), &bytes.Buffer{}, log.NewLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, client.deleted)
+}
+
+func TestPrune_skipConfirmationAndEmptyKey(t *testing.T) {
+	client := &fakePruneClient{}
+
+	err := pruneCmdFn(context.Background(), client, pruneParams{keys: []string{"a", "b"}, yes: true},
+		strings.NewReader(""), &bytes.Buffer{}, log.NewLogger())
+	require.NoError(t, err)
+	assert.Len(t, client.deleted, 2)
+
+	err = pruneCmdFn(context.Background(), client, pruneParams{keys: []string{" "}, yes: true},
+		strings.NewReader(""), &bytes.Buffer{}, log.NewLogger())
+	require.Error(t, err)
+	assert.Len(t, client.deleted, 2)
+}
+
+func TestPrune_prefixExpandsKeyTemplates(t *testing.T) {
+	client := &fakePruneClient{}
+	var out bytes.Buffer
+
+	err := pruneCmdFn(context.Background(), client, pruneParams{
+		prefixes: []string{"xcode-cache-metadata-old-app-"},
+		apps:     []string{"old-app"},
+		branches: []string{"main", "feature/x"},
+		oses:     []string{"darwin", "linux"},
+		dryRun:   true,
+	}, strings.NewReader(""), &out, log.NewLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.Join([]string{
+		"xcode-cache-metadata-old-app-darwin",
+		"xcode-cache-metadata-old-app-feature_x-darwin",
+		"xcode-cache-metadata-old-app-feature_x-linux",
+		"xcode-cache-metadata-old-app-linux",
+		"xcode-cache-metadata-old-app-main-darwin",
+		"xcode-cache-metadata-old-app-main-linux",
+	}, "\n")+"\n", out.String())
+	assert.Empty(t, client.deleted)
+}
+
+func TestPrune_prefixOfFeatureBranch(t *testing.T) {
+	client := &fakePruneClient{}
+
+	err := pruneCmdFn(context.Background(), client, pruneParams{
+		prefixes: []string{"gradle-config-cache-metadata-app-feature_x-"},
+		apps:     []string{"app"},
+		branches: []string{"main", "feature/x"},
+		oses:     []string{"linux"},
+		yes:      true,
+	}, strings.NewReader(""), &bytes.Buffer{}, log.NewLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"gradle-config-cache-metadata-app-feature_x-linux"}, client.deleted)
+}
+
+func TestPrune_prefixErrors(t *testing.T) {
+	client := &fakePruneClient{}
+	base := pruneParams{apps: []string{"app"}, branches: []string{"main"}, oses: []string{"linux"}, yes: true}
+
+	params := base
+	params.prefixes = []string{" "}
+	require.ErrorContains(t, pruneCmdFn(context.Background(), client, params, strings.NewReader(""), &bytes.Buffer{}, log.NewLogger()), "empty prefix")
+
+	params = base
+	params.prefixes = []string{"xcode-"}
+	params.branches = nil
+	require.Error(t, pruneCmdFn(context.Background(), client, params, strings.NewReader(""), &bytes.Buffer{}, log.NewLogger()))
+
+	// a template that cannot be expanded offline must not silently match nothing
+	params = base
+	params.prefixes = []string{"xcode-"}
+	params.templates = []string{`xcode-{{ checksum "Podfile.lock" }}`}
+	require.ErrorContains(t, pruneCmdFn(context.Background(), client, params, strings.NewReader(""), &bytes.Buffer{}, log.NewLogger()), "expand key template")
+
+	assert.Empty(t, client.deleted)
+}
+
+func TestGitBranches(t *testing.T) {
+	branches, err := gitBranches(func(name string, _ ...string) (string, error) {
+		assert.Equal(t, "git", name)
+
+		return "refs/heads/main\nrefs/heads/feature/x\nrefs/remotes/origin/HEAD\nrefs/remotes/origin/main\nrefs/remotes/origin/release/1.0\n", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main", "feature/x", "release/1.0"}, branches)
+
+	_, err = gitBranches(func(string, ...string) (string, error) { return "", nil })
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
@@ -13,7 +14,10 @@ import (
 )
 
 //nolint:gochecknoglobals
-var activateCppParams = ccacheconfig.DefaultParams()
+var (
+	activateCppParams         = ccacheconfig.DefaultParams()
+	activateCppLocalCacheSize string
+)
 
 //nolint:gochecknoglobals
 var activateCppCmd = &cobra.Command{
@@ -31,12 +35,22 @@ This command will:
 		logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode))
 		logger.EnableDebugLog(common.IsDebugLogMode)
 
+		if activateCppLocalCacheSize != "" {
+			size, err := humanize.ParseBytes(activateCppLocalCacheSize)
+			if err != nil {
+				return fmt.Errorf("invalid --local-cache-size %q: %w", activateCppLocalCacheSize, err)
+			}
+			activateCppParams.LocalCacheMaxBytes = int64(size) //nolint:gosec
+		}
+
 		activator := ccachepkg.NewActivator(ccachepkg.ActivatorParams{
 			BuildCacheEndpoint:    activateCppParams.BuildCacheEndpoint,
 			PushEnabled:           activateCppParams.PushEnabled,
 			IPCSocketPathOverride: activateCppParams.IPCSocketPathOverride,
 			BaseDirOverride:       activateCppParams.BaseDirOverride,
 			DebugLogging:          common.IsDebugLogMode,
+			LocalCacheDir:         activateCppParams.LocalCacheDir,
+			LocalCacheMaxBytes:    activateCppParams.LocalCacheMaxBytes,
 		})
 
 		if err := activator.Activate(cmd.Context()); err != nil {
@@ -75,4 +89,16 @@ func init() {
 		activateCppParams.BaseDirOverride,
 		"Override the base directory for ccache (CCACHE_BASEDIR). Defaults to the current working directory.",
 	)
+	activateCppCmd.Flags().StringVar(
+		&activateCppLocalCacheSize,
+		"local-cache-size",
+		"",
+		"Enable the storage helper's local on-disk cache tier with the given size limit (e.g. 5GB).",
+	)
+	activateCppCmd.Flags().StringVar(
+		&activateCppParams.LocalCacheDir,
+		"local-cache-dir",
+		activateCppParams.LocalCacheDir,
+		"Directory of the local cache tier. Defaults to ~/.bitrise/cache/ccache/local-blobs.",
+	)
 }
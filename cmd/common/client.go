@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bitrise-io/go-utils/v2/log"
@@ -14,10 +15,14 @@ import (
 )
 
 const (
-	ClientNameXcode             = "xcode"
-	ClientNameGradleConfigCache = "gradle-config"
-	ClientNameGradle            = "gradle"
-	ClientNameCcache            = "ccache"
+	ClientNameXcode              = "xcode"
+	ClientNameGradleConfigCache  = "gradle-config"
+	ClientNameGradle             = "gradle"
+	ClientNameCcache             = "ccache"
+	ClientNameInspect            = "inspect"
+	ClientNamePrune              = "prune"
+	ClientNameBazel              = "bazel"
+	ClientNameGradleDependencies = "gradle-dependencies"
 )
 
 type CreateKVClientParams struct {
@@ -30,27 +35,29 @@ type CreateKVClientParams struct {
 	CommandFunc        common.CommandFunc
 	Logger             log.Logger
 	EndpointURL        string
+	Endpoints          []string                           // ordered; overrides EndpointURL when set
 	BitriseKVClient    kv_storage.KVStorageClient         // nullable, if not provided, a new client will be created
 	CapabilitiesClient remoteexecution.CapabilitiesClient // nullable, if not provided, a new client will be created
 	SkipCapabilities   bool                               // if true, GetCapabilities will not be called
+	CircuitBreaker     *kv.CircuitBreaker                 // nullable, if not provided, calls are never short-circuited
+	Transfer           kv.TransferOptions                 // parallelism and bandwidth of file group transfers
 }
 
 func CreateKVClient(ctx context.Context, params CreateKVClientParams) (*kv.Client, error) {
-	endpointURL := common.SelectCacheEndpointURL(params.EndpointURL, params.Envs)
-	params.Logger.Infof("(i) Build Cache Endpoint URL: %s", endpointURL)
+	endpointURLs := params.Endpoints
+	if len(endpointURLs) == 0 {
+		endpointURLs = common.SelectCacheEndpointURLs(params.EndpointURL, params.Envs)
+	}
+	params.Logger.Infof("(i) Build Cache Endpoint URL: %s", strings.Join(endpointURLs, ", "))
 
-	buildCacheHost, insecureGRPC, err := kv.ParseURLGRPC(endpointURL)
+	endpoints, err := kv.ParseEndpoints(endpointURLs)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"the url grpc[s]://host:port format, %q is invalid: %w",
-			endpointURL, err,
-		)
+		return nil, fmt.Errorf("the url grpc[s]://host:port format, %w", err)
 	}
-	params.Logger.Debugf("Build Cache host: %s", buildCacheHost)
+	params.Logger.Debugf("Build Cache endpoints: %+v", endpoints)
 
 	kvClient, err := kv.NewClient(kv.NewClientParams{
-		UseInsecure:         insecureGRPC,
-		Host:                buildCacheHost,
+		Endpoints:           endpoints,
 		DialTimeout:         5 * time.Second,
 		ClientName:          params.ClientName,
 		AuthConfig:          params.AuthConfig,
@@ -61,6 +68,10 @@ func CreateKVClient(ctx context.Context, params CreateKVClientParams) (*kv.Clien
 		BitriseKVClient:     params.BitriseKVClient,
 		CapabilitiesClient:  params.CapabilitiesClient,
 		InvocationID:        params.InvocationID,
+		CircuitBreaker:      params.CircuitBreaker,
+		CompressUploads:     common.CompressionEnabled(params.Envs),
+		DeleteCorrupted:     common.DeleteCorruptedEnabled(params.Envs),
+		Transfer:            params.Transfer,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("new kv client: %w", err)
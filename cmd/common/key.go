@@ -1,5 +1,11 @@
 package common
 
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
+)
+
 const (
 	CacheKeyTypeDefault  = "default"
 	CacheKeyTypeFallback = "fallback"
@@ -7,3 +13,24 @@ const (
 )
 
 type CacheKeyType string
+
+// AddCacheKeyTemplateFlags registers the --key-template and
+// --fallback-key-template flags used when no explicit --key is given.
+func AddCacheKeyTemplateFlags(cmd *cobra.Command, defaultTemplate, defaultFallbackTemplate string) {
+	cmd.Flags().String("key-template", "",
+		"Template of the cache key, used when --key is not set. Variables: .App, .Branch, .Commit, .Build, .CI, .OS, .Arch, .XcodeVersion; "+
+			`functions: checksum "file" ... (globs allowed), env "NAME". Defaults to "`+defaultTemplate+`"`)
+	cmd.Flags().StringArray("fallback-key-template", nil,
+		`Template of a fallback cache key, tried in order when the primary key is not found. Can be repeated. Defaults to "`+defaultFallbackTemplate+`"`)
+}
+
+// CacheKeyTemplatesFromFlags reads the flags registered by AddCacheKeyTemplateFlags.
+func CacheKeyTemplatesFromFlags(cmd *cobra.Command) cachekey.Templates {
+	primary, _ := cmd.Flags().GetString("key-template")
+	fallbacks, _ := cmd.Flags().GetStringArray("fallback-key-template")
+
+	return cachekey.Templates{
+		Primary:   primary,
+		Fallbacks: fallbacks,
+	}
+}
@@ -61,6 +61,9 @@ var LogoutCmd = &cobra.Command{ //nolint:gochecknoglobals
 		if err := oauth.Clear(); err != nil {
 			return fmt.Errorf("clear stored login: %w", err)
 		}
+		if err := oauth.NewConfigFromEnv(utils.AllEnvs()).ClearScopedTokens(); err != nil {
+			logger.Warnf("Scoped tokens minted from the login were not removed: %s", err)
+		}
 		logger.Infof("Signed out.")
 
 		return nil
@@ -26,7 +26,7 @@ var (
 var statusCmd = &cobra.Command{
 	Use:           "status",
 	Short:         "Show which Bitrise Build Cache features are enabled on this machine",
-	Long:          "Reports gradle / xcode / cpp / react-native activation status. Intended for step integrations that need to decide whether to engage cache wrapping.",
+	Long:          "Reports gradle / xcode / cpp / react-native / bazel activation status and the installed Gradle repository mirrors. Intended for step integrations that need to decide whether to engage cache wrapping. --json adds per-tool details (config files and version, endpoint, push, benchmark phase, helper reachability) under a versioned schema.",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
@@ -36,7 +36,7 @@ var statusCmd = &cobra.Command{
 
 func init() {
 	statusCmd.Flags().BoolVar(&statusJSONOutput, "json", false, "Emit machine-readable JSON instead of a text table")
-	statusCmd.Flags().StringVar(&statusFeature, "feature", "", "Query a single feature: gradle, xcode, cpp, react-native")
+	statusCmd.Flags().StringVar(&statusFeature, "feature", "", "Query a single feature: gradle, xcode, cpp, react-native, bazel")
 	statusCmd.Flags().BoolVar(&statusQuiet, "quiet", false, "Suppress stdout; only meaningful with --feature (exit 0=enabled, 1=disabled, 2=unknown feature or misuse). Takes precedence over --json.")
 
 	RootCmd.AddCommand(statusCmd)
@@ -71,14 +71,21 @@ func runStatus(out, errOut io.Writer, checker *status.Checker) error {
 	}
 
 	s := checker.Status()
+	gradleMirrors := checker.GradleMirrors()
 	auth := currentAuthStatus()
 	if statusJSONOutput {
+		details := checker.Details()
+
 		return writeJSON(out, statusOutput{
-			Gradle:      s.Gradle,
-			Xcode:       s.Xcode,
-			Cpp:         s.Cpp,
-			ReactNative: s.ReactNative,
-			Auth:        auth,
+			SchemaVersion: details.SchemaVersion,
+			Gradle:        s.Gradle,
+			Xcode:         s.Xcode,
+			Cpp:           s.Cpp,
+			ReactNative:   s.ReactNative,
+			Bazel:         s.Bazel,
+			Tools:         details.Tools,
+			GradleMirrors: gradleMirrors,
+			Auth:          auth,
 		})
 	}
 
@@ -86,6 +93,10 @@ func runStatus(out, errOut io.Writer, checker *status.Checker) error {
 		return err
 	}
 
+	if err := writeGradleMirrors(out, gradleMirrors); err != nil {
+		return err
+	}
+
 	return writeAuthLine(out, auth)
 }
 
@@ -95,7 +106,7 @@ func runStatusFeature(out, errOut io.Writer, checker *status.Checker) error {
 	enabled, err := checker.IsEnabled(statusFeature)
 	if err != nil {
 		if errors.Is(err, status.ErrUnknownFeature) {
-			fmt.Fprintf(errOut, "error: unknown feature %q (expected: gradle, xcode, cpp, react-native)\n", statusFeature)
+			fmt.Fprintf(errOut, "error: unknown feature %q (expected: gradle, xcode, cpp, react-native, bazel)\n", statusFeature)
 
 			return &statusExitError{code: 2}
 		}
@@ -137,13 +148,20 @@ type authStatusInfo struct {
 	Error       string `json:"error,omitempty"`
 }
 
-// statusOutput is the --json shape: feature flags plus auth.
+// statusOutput is the --json shape: feature flags, per-tool details,
+// installed Gradle mirrors plus auth. SchemaVersion follows
+// status.DetailsSchemaVersion; the top-level flags predate it and stay for
+// existing scripts.
 type statusOutput struct {
-	Gradle      bool           `json:"gradle"`
-	Xcode       bool           `json:"xcode"`
-	Cpp         bool           `json:"cpp"`
-	ReactNative bool           `json:"reactNative"`
-	Auth        authStatusInfo `json:"auth"`
+	SchemaVersion int                   `json:"schemaVersion"`
+	Gradle        bool                  `json:"gradle"`
+	Xcode         bool                  `json:"xcode"`
+	Cpp           bool                  `json:"cpp"`
+	ReactNative   bool                  `json:"reactNative"`
+	Bazel         bool                  `json:"bazel"`
+	Tools         status.ToolDetails    `json:"tools"`
+	GradleMirrors []status.GradleMirror `json:"gradleMirrors,omitempty"`
+	Auth          authStatusInfo        `json:"auth"`
 }
 
 // currentAuthStatus reports the credential commands would use, via config
@@ -203,6 +221,34 @@ func writeAuthLine(out io.Writer, a authStatusInfo) error {
 	return nil
 }
 
+// writeGradleMirrors lists the installed Gradle mirrors below the status table.
+func writeGradleMirrors(out io.Writer, gradleMirrors []status.GradleMirror) error {
+	if len(gradleMirrors) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(out, "\nGradle mirrors:"); err != nil {
+		return fmt.Errorf("write gradle mirrors: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, m := range gradleMirrors {
+		kind := "known"
+		if m.Custom {
+			kind = "custom"
+		}
+		if _, err := fmt.Fprintf(tw, "  %s\t%s\t%s\n", m.Name, kind, m.URL); err != nil {
+			return fmt.Errorf("write gradle mirror row: %w", err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flush gradle mirrors table: %w", err)
+	}
+
+	return nil
+}
+
 func writeJSON(out io.Writer, v any) error {
 	enc := json.NewEncoder(out)
 	enc.SetIndent("", "  ")
@@ -223,6 +269,7 @@ func writeTable(out io.Writer, s status.Status) error {
 		{"xcode", s.Xcode},
 		{"cpp", s.Cpp},
 		{"react-native", s.ReactNative},
+		{"bazel", s.Bazel},
 	} {
 		if _, err := fmt.Fprintf(tw, "%s\t%s\n", row.name, statusLabel(row.enabled)); err != nil {
 			return fmt.Errorf("write status row: %w", err)
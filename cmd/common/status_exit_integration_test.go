@@ -97,7 +97,7 @@ func TestExecute_StatusExitCodes_EndToEnd(t *testing.T) {
 	t.Run("unknown feature → exit 2", func(t *testing.T) {
 		home := t.TempDir()
 
-		_, stderr, code := spawnStatus(t, home, "--feature=bazel", "--quiet")
+		_, stderr, code := spawnStatus(t, home, "--feature=nonsense", "--quiet")
 		assert.Equal(t, 2, code)
 		assert.Contains(t, strings.ToLower(stderr), "unknown feature")
 	})
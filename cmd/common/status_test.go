@@ -95,7 +95,6 @@ func TestStatus_TextTable(t *testing.T) {
 	stdout, stderr, err := runStatusCmd(t, home)
 	require.NoError(t, err)
 	assert.Empty(t, stderr)
-	assert.NotContains(t, stdout, "bazel")
 
 	// Per-row assertions: match `<label><whitespace><state>\n` so we catch
 	// cross-row contamination (e.g. xcode row claiming "disabled").
@@ -107,6 +106,7 @@ func TestStatus_TextTable(t *testing.T) {
 		{"xcode", "enabled"},
 		{"cpp", "enabled"},
 		{"react-native", "disabled"},
+		{"bazel", "disabled"},
 	} {
 		re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(row.label) + `\s+` + row.state + `$`)
 		assert.Regexp(t, re, stdout, "row %q should be %s", row.label, row.state)
@@ -123,12 +123,17 @@ func TestStatus_JSON_Shape(t *testing.T) {
 	require.NoError(t, err)
 
 	var got struct {
-		Gradle      bool  `json:"gradle"`
-		Xcode       bool  `json:"xcode"`
-		Cpp         bool  `json:"cpp"`
-		ReactNative bool  `json:"reactNative"`
-		Bazel       *bool `json:"bazel"`
-		Auth        struct {
+		SchemaVersion int   `json:"schemaVersion"`
+		Gradle        bool  `json:"gradle"`
+		Xcode         bool  `json:"xcode"`
+		Cpp           bool  `json:"cpp"`
+		ReactNative   bool  `json:"reactNative"`
+		Bazel         *bool `json:"bazel"`
+		Tools         map[string]struct {
+			Enabled     bool     `json:"enabled"`
+			ConfigFiles []string `json:"configFiles"`
+		} `json:"tools"`
+		Auth struct {
 			Configured bool   `json:"configured"`
 			Source     string `json:"source"`
 		} `json:"auth"`
@@ -139,20 +144,66 @@ func TestStatus_JSON_Shape(t *testing.T) {
 	assert.False(t, got.Xcode)
 	assert.False(t, got.Cpp)
 	assert.True(t, got.ReactNative)
-	assert.Nil(t, got.Bazel)
+	assert.Equal(t, 1, got.SchemaVersion)
+	require.NotNil(t, got.Bazel)
+	assert.False(t, *got.Bazel)
+	assert.ElementsMatch(t, []string{"gradle", "xcode", "cpp", "reactNative", "bazel"}, keys(got.Tools))
+	assert.True(t, got.Tools["reactNative"].Enabled)
+	assert.Equal(t, []string{filepath.Join(home, ".bitrise", "cache", "reactnative", "config.json")}, got.Tools["reactNative"].ConfigFiles)
 	assert.False(t, got.Auth.Configured)
 	assert.Equal(t, "none", got.Auth.Source)
 }
 
-func TestStatus_FeatureBazel_ExitTwo(t *testing.T) {
+func TestStatus_GradleMirrors(t *testing.T) {
 	home := t.TempDir()
+	t.Setenv("GRADLE_USER_HOME", "")
+	dir := filepath.Join(home, ".gradle", "init.d")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bitrise-gradle-mirrors.init.gradle.kts"), []byte(
+		"// mirror: mavencentral https://repository-manager.services.bitrise.io:8090/maven/central\n"+
+			"// mirror: jitpack https://artifactory.example.com/jitpack custom\n"+
+			"apply<InternalRepositoryPlugin>()\n"), 0o600))
+
+	stdout, _, err := runStatusCmd(t, home)
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Gradle mirrors:")
+	assert.Regexp(t, `(?m)^  jitpack\s+custom\s+https://artifactory.example.com/jitpack$`, stdout)
+	assert.Regexp(t, `(?m)^  mavencentral\s+known\s+https://repository-manager.services.bitrise.io:8090/maven/central$`, stdout)
+
+	stdout, _, err = runStatusCmd(t, home, "--json")
+	require.NoError(t, err)
+
+	var got struct {
+		GradleMirrors []struct {
+			Name   string `json:"name"`
+			URL    string `json:"url"`
+			Custom bool   `json:"custom"`
+		} `json:"gradleMirrors"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+	require.Len(t, got.GradleMirrors, 2)
+	assert.Equal(t, "jitpack", got.GradleMirrors[1].Name)
+	assert.True(t, got.GradleMirrors[1].Custom)
+	assert.False(t, got.GradleMirrors[0].Custom)
+}
 
-	_, stderr, err := runStatusCmd(t, home, "--feature=bazel", "--quiet")
+func TestStatus_FeatureBazel_Disabled_ExitOne(t *testing.T) {
+	home := t.TempDir()
+
+	_, _, err := runStatusCmd(t, home, "--feature=bazel", "--quiet")
 	require.Error(t, err)
 	code, ok := common.HandleStatusExit(err)
 	require.True(t, ok)
-	assert.Equal(t, 2, code)
-	assert.Contains(t, strings.ToLower(stderr), "unknown feature")
+	assert.Equal(t, 1, code)
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+
+	return result
 }
 
 func TestStatus_Feature_Enabled(t *testing.T) {
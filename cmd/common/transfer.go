@@ -0,0 +1,73 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+)
+
+// AddTransferFlags registers the --concurrency, bandwidth limit and
+// --adaptive-concurrency flags of commands transferring file groups.
+func AddTransferFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("concurrency", kv.DefaultTransferConcurrency, "Maximum number of files transferred in parallel")
+	cmd.Flags().String("max-bandwidth", "",
+		`Maximum transfer bandwidth per second shared by all parallel transfers, e.g. "20MB" or "500KiB". `+
+			`Sets both --max-upload-bandwidth and --max-download-bandwidth. Unlimited by default`)
+	cmd.Flags().String("max-upload-bandwidth", "",
+		"Maximum upload bandwidth per second, overrides --max-bandwidth for uploads")
+	cmd.Flags().String("max-download-bandwidth", "",
+		"Maximum download bandwidth per second, overrides --max-bandwidth for downloads")
+	cmd.Flags().Bool("adaptive-concurrency", true,
+		"Halve the number of parallel transfers when the cache reports it is overloaded (ResourceExhausted or Unavailable), then slowly grow it back")
+}
+
+// TransferOptionsFromFlags reads the flags registered by AddTransferFlags. The
+// concurrency applies to uploads and downloads, each direction has its own
+// bandwidth budget.
+func TransferOptionsFromFlags(cmd *cobra.Command) (kv.TransferOptions, error) {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	adaptive, _ := cmd.Flags().GetBool("adaptive-concurrency")
+
+	if concurrency < 1 {
+		return kv.TransferOptions{}, fmt.Errorf("invalid --concurrency %d: must be at least 1", concurrency)
+	}
+
+	bandwidth, err := bandwidthFlag(cmd, "max-bandwidth", 0)
+	if err != nil {
+		return kv.TransferOptions{}, err
+	}
+	uploadBandwidth, err := bandwidthFlag(cmd, "max-upload-bandwidth", bandwidth)
+	if err != nil {
+		return kv.TransferOptions{}, err
+	}
+	downloadBandwidth, err := bandwidthFlag(cmd, "max-download-bandwidth", bandwidth)
+	if err != nil {
+		return kv.TransferOptions{}, err
+	}
+
+	return kv.TransferOptions{
+		UploadConcurrency:   concurrency,
+		DownloadConcurrency: concurrency,
+		UploadBandwidth:     uploadBandwidth,
+		DownloadBandwidth:   downloadBandwidth,
+		AdaptiveConcurrency: adaptive,
+	}, nil
+}
+
+// bandwidthFlag parses a bytes per second flag, fallback when it is not set.
+func bandwidthFlag(cmd *cobra.Command, name string, fallback int64) (int64, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	bytesPerSecond, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --%s %q: %w", name, value, err)
+	}
+
+	return int64(bytesPerSecond), nil //nolint:gosec
+}
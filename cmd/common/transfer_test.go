@@ -0,0 +1,44 @@
+//go:build unit
+
+package common
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferOptionsFromFlags_bandwidth(t *testing.T) {
+	cases := []struct {
+		name         string
+		args         []string
+		wantUpload   int64
+		wantDownload int64
+		wantErr      bool
+	}{
+		{name: "unlimited by default"},
+		{name: "shorthand sets both", args: []string{"--max-bandwidth", "2MB"}, wantUpload: 2_000_000, wantDownload: 2_000_000},
+		{name: "per direction", args: []string{"--max-upload-bandwidth", "1MB", "--max-download-bandwidth", "3MB"}, wantUpload: 1_000_000, wantDownload: 3_000_000},
+		{name: "direction overrides shorthand", args: []string{"--max-bandwidth", "2MB", "--max-upload-bandwidth", "1MB"}, wantUpload: 1_000_000, wantDownload: 2_000_000},
+		{name: "invalid", args: []string{"--max-download-bandwidth", "fast"}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			AddTransferFlags(cmd)
+			require.NoError(t, cmd.ParseFlags(tc.args))
+
+			opts, err := TransferOptionsFromFlags(cmd)
+			if tc.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantUpload, opts.UploadBandwidth)
+			assert.Equal(t, tc.wantDownload, opts.DownloadBandwidth)
+		})
+	}
+}
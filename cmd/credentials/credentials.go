@@ -0,0 +1,130 @@
+package credentials
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/bazelcredhelper"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+//nolint:gochecknoglobals
+var credentialsScope string
+
+//nolint:gochecknoglobals
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Serve Bitrise Build Cache credentials to build tools on demand",
+	Long: fmt.Sprintf(`Serve the Bitrise Build Cache credentials to build tools when they need them,
+so no long-lived token has to be written into .bazelrc, gradle.properties or init scripts.
+
+Credentials are resolved the same way as everywhere else in the CLI (%s / %s,
+%s on Bitrise CI, then the stored login). A stored browser login is refreshed
+first, so the token served is never expired.
+
+With --scope (or %s) a stored browser login serves a short-lived token
+limited to its workspace instead: "read" for developer machines, "read-write" for
+trusted pipelines. Env and CI credentials cannot be narrowed, so with a scope set they
+are refused rather than served in full. CI builds, pull requests included, therefore
+cannot get a read-only token yet.
+
+The git and docker helpers only answer for the build cache and RBE endpoint hosts
+and the hosts listed in %s; other hosts get no credentials.
+
+Examples:
+  bazel:  the .bazelrc written by activate already uses this CLI as its credential helper;
+          "credentials bazel" speaks the same protocol for hand-written setups
+  git:    git config credential.https://<cache host>.helper "!bitrise-build-cache credentials git"
+  docker: a docker-credential-bitrise script running "bitrise-build-cache credentials docker \"$@\""
+  shell:  eval "$(bitrise-build-cache credentials env)"`,
+		configcommon.EnvAuthToken, configcommon.EnvWorkspaceID, configcommon.EnvJWT, configcommon.EnvTokenScope, credhelper.EnvCredentialHosts),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	// The helpers are spawned per request by the build tools and their stdout
+	// is parsed, so skip the root PersistentPreRun (version check, logging
+	// stored-auth hydration) — resolve refreshes the login quietly instead.
+	PersistentPreRun: func(*cobra.Command, []string) {},
+}
+
+//nolint:gochecknoglobals
+var credentialsBazelCmd = &cobra.Command{
+	Use:           "bazel",
+	Short:         "Bazel (EngFlow) credential helper: reads {\"uri\"} JSON, writes {\"headers\"} JSON",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return reportError(cmd, bazelcredhelper.RunWithResolver(cmd.InOrStdin(), cmd.OutOrStdout(), resolver(cmd)))
+	},
+}
+
+//nolint:gochecknoglobals
+var credentialsGitCmd = &cobra.Command{
+	Use:           "git <get|store|erase>",
+	Short:         "Git credential helper: answers get with the workspace and token",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportError(cmd, credhelper.RunGit(args[0], cmd.InOrStdin(), cmd.OutOrStdout(), credhelper.AllowedHosts(utils.AllEnvs()), resolver(cmd)))
+	},
+}
+
+//nolint:gochecknoglobals
+var credentialsDockerCmd = &cobra.Command{
+	Use:           "docker <get|store|erase|list>",
+	Short:         "Docker credential helper: answers get with the workspace and token",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportError(cmd, credhelper.RunDocker(args[0], cmd.InOrStdin(), cmd.OutOrStdout(), credhelper.AllowedHosts(utils.AllEnvs()), resolver(cmd)))
+	},
+}
+
+//nolint:gochecknoglobals
+var credentialsEnvCmd = &cobra.Command{
+	Use:           "env",
+	Short:         "Print the credentials as shell export lines",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := resolver(cmd)()
+		if err == nil {
+			err = credhelper.WriteEnv(cmd.OutOrStdout(), cfg)
+		}
+
+		return reportError(cmd, err)
+	},
+}
+
+func resolver(cmd *cobra.Command) func() (configcommon.CacheAuthConfig, error) {
+	return credhelper.StoredLoginResolver(cmd.Context(), utils.AllEnvs(), credentialsScope)
+}
+
+// reportError prints err as a single stderr line: the callers are build tools
+// that surface the helper's stderr verbatim.
+func reportError(cmd *cobra.Command, err error) error {
+	if err == nil {
+		return nil
+	}
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+
+	return fmt.Errorf("credentials %s: %w", cmd.Name(), err)
+}
+
+func init() {
+	credentialsCmd.PersistentFlags().StringVar(&credentialsScope, "scope", "", fmt.Sprintf("Serve a short-lived token of this scope minted from the stored login: read | read-write. Defaults to %s; empty serves the stored credential.", configcommon.EnvTokenScope))
+
+	credentialsCmd.AddCommand(credentialsBazelCmd)
+	credentialsCmd.AddCommand(credentialsGitCmd)
+	credentialsCmd.AddCommand(credentialsDockerCmd)
+	credentialsCmd.AddCommand(credentialsEnvCmd)
+
+	common.RootCmd.AddCommand(credentialsCmd)
+}
@@ -1,22 +1,23 @@
 package daemon
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"net"
 	"os"
-	"time"
+	"strconv"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
-	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/ccache"
+	bazelconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/bazel"
 	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
+	gradleconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle"
 	xcelerateconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/probe"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 )
 
@@ -33,7 +34,6 @@ const (
 	statusStopped       = "stopped"
 	statusStuck         = "stuck (socket present, not responding — run `bitrise-build-cache doctor --fix` or `bitrise-build-cache daemon restart`)"
 	statusNotConfigured = "not configured"
-	probeTimeout        = 500 * time.Millisecond
 )
 
 //nolint:gochecknoglobals
@@ -53,18 +53,28 @@ var infoCmd = &cobra.Command{
 
 		proxy := readXcelerateInfo(osProxy, decoder)
 		ccache := readCcacheInfo(osProxy, decoder)
+		bazel := readBazelInfo()
+		gradle := readGradleInfo()
 
 		if infoJSON {
 			payload := struct {
-				XcelerateProxy       string `json:"xcelerateProxy"`
-				XcelerateProxyStatus string `json:"xcelerateProxyStatus"`
-				CcacheHelper         string `json:"ccacheHelper"`
-				CcacheHelperStatus   string `json:"ccacheHelperStatus"`
+				XcelerateProxy        string `json:"xcelerateProxy"`
+				XcelerateProxyStatus  string `json:"xcelerateProxyStatus"`
+				CcacheHelper          string `json:"ccacheHelper"`
+				CcacheHelperStatus    string `json:"ccacheHelperStatus"`
+				BazelProxy            string `json:"bazelProxy"`
+				BazelProxyStatus      string `json:"bazelProxyStatus"`
+				GradleConnector       string `json:"gradleConnector"`
+				GradleConnectorStatus string `json:"gradleConnectorStatus"`
 			}{
-				XcelerateProxy:       proxy.Socket,
-				XcelerateProxyStatus: proxy.Status,
-				CcacheHelper:         ccache.Socket,
-				CcacheHelperStatus:   ccache.Status,
+				XcelerateProxy:        proxy.Socket,
+				XcelerateProxyStatus:  proxy.Status,
+				CcacheHelper:          ccache.Socket,
+				CcacheHelperStatus:    ccache.Status,
+				BazelProxy:            bazel.Socket,
+				BazelProxyStatus:      bazel.Status,
+				GradleConnector:       gradle.Socket,
+				GradleConnectorStatus: gradle.Status,
 			}
 
 			if err := json.NewEncoder(out).Encode(payload); err != nil {
@@ -74,11 +84,15 @@ var infoCmd = &cobra.Command{
 			return nil
 		}
 
-		fmt.Fprintf(out, "xcelerate-proxy: %s\n", proxy.Socket)
-		fmt.Fprintf(out, "ccache-helper:   %s\n", ccache.Socket)
+		fmt.Fprintf(out, "xcelerate-proxy:        %s\n", proxy.Socket)
+		fmt.Fprintf(out, "ccache-helper:          %s\n", ccache.Socket)
+		fmt.Fprintf(out, "bazel-proxy:            %s\n", bazel.Socket)
+		fmt.Fprintf(out, "gradle-cache-connector: %s\n", gradle.Socket)
 		fmt.Fprintln(out)
-		fmt.Fprintf(out, "xcelerate-proxy status: %s\n", proxy.Status)
-		fmt.Fprintf(out, "ccache-helper status:   %s\n", ccache.Status)
+		fmt.Fprintf(out, "xcelerate-proxy status:        %s\n", proxy.Status)
+		fmt.Fprintf(out, "ccache-helper status:          %s\n", ccache.Status)
+		fmt.Fprintf(out, "bazel-proxy status:            %s\n", bazel.Status)
+		fmt.Fprintf(out, "gradle-cache-connector status: %s\n", gradle.Status)
 
 		return nil
 	},
@@ -108,47 +122,64 @@ func readCcacheInfo(osProxy utils.OsProxy, decoder utils.DecoderFactory) service
 	}
 }
 
-func probeSocket(path string) string {
-	if _, err := os.Stat(path); err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			debugLogger().Debugf("probeSocket: stat %s failed: %v", path, err)
-		}
-
-		return statusStopped
+func readBazelInfo() serviceInfo {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return serviceInfo{Socket: "<not configured>", Status: statusNotConfigured}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
-	defer cancel()
+	sidecar, _, err := bazelconfig.ReadSidecar(home)
+	switch {
+	case err == nil && sidecar.CacheProxySocketPath != "":
+		return serviceInfo{Socket: sidecar.CacheProxySocketPath, Status: probeSocket(sidecar.CacheProxySocketPath)}
+	case err == nil:
+		return serviceInfo{Socket: "<not configured — run `bitrise-build-cache activate bazel --cache-proxy`>", Status: statusNotConfigured}
+	default:
+		return serviceInfo{Socket: "<not configured>", Status: statusNotConfigured}
+	}
+}
 
-	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path)
+func readGradleInfo() serviceInfo {
+	home, err := os.UserHomeDir()
 	if err != nil {
-		return statusStuck
+		return serviceInfo{Socket: "<not configured>", Status: statusNotConfigured}
 	}
-	_ = conn.Close()
 
-	return statusRunning
+	sidecar, _, err := gradleconfig.ReadSidecar(home)
+	switch {
+	case err == nil && sidecar.CacheLocalHTTPPort != 0:
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(sidecar.CacheLocalHTTPPort))
+
+		return serviceInfo{Socket: gradleconfig.LocalHTTPCacheURL(sidecar.CacheLocalHTTPPort), Status: probeTCP(address)}
+	case err == nil:
+		return serviceInfo{Socket: "<not configured — run `bitrise-build-cache activate gradle --cache --connector=local-http`>", Status: statusNotConfigured}
+	default:
+		return serviceInfo{Socket: "<not configured>", Status: statusNotConfigured}
+	}
 }
 
-// probeCcacheSocket uses the ccache protocol's health-check exchange so the
-// storage helper sees a clean handshake — a raw dial+close would surface as
-// "Capabilities check failed" in the helper's log, and CI asserts on those.
-func probeCcacheSocket(path string) string {
-	if _, err := os.Stat(path); err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			debugLogger().Debugf("probeCcacheSocket: stat %s failed: %v", path, err)
-		}
+func probeTCP(address string) string {
+	return serviceStatus(probe.TCP(address))
+}
 
-		return statusStopped
-	}
+func probeSocket(path string) string {
+	return serviceStatus(probe.Socket(debugLogger(), path))
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
-	defer cancel()
+func probeCcacheSocket(path string) string {
+	return serviceStatus(probe.CcacheSocket(debugLogger(), path))
+}
 
-	if err := ccache.SendHealthCheck(ctx, path); err != nil {
+// serviceStatus maps a probe state to the human-readable info status.
+func serviceStatus(state probe.State) string {
+	switch state {
+	case probe.Running:
+		return statusRunning
+	case probe.Stuck:
 		return statusStuck
+	default:
+		return statusStopped
 	}
-
-	return statusRunning
 }
 
 func debugLogger() log.Logger {
@@ -156,6 +187,6 @@ func debugLogger() log.Logger {
 }
 
 func init() {
-	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Emit `{xcelerateProxy, xcelerateProxyStatus, ccacheHelper, ccacheHelperStatus}` as JSON instead of human-readable text.")
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Emit `{xcelerateProxy, xcelerateProxyStatus, ccacheHelper, ccacheHelperStatus, bazelProxy, bazelProxyStatus, gradleConnector, gradleConnectorStatus}` as JSON instead of human-readable text.")
 	daemonCmd.AddCommand(infoCmd)
 }
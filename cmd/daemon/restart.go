@@ -40,6 +40,9 @@ var restartCmd = &cobra.Command{
 		for _, st := range result.Statuses {
 			logger.Donef("%s — restarted (%s)", st.Service.Name, result.BackendName)
 		}
+		for _, st := range result.NotInstalled {
+			logger.Infof("%s — not installed, skipped; rerun `bitrise-build-cache daemon install` to add it", st.Service.Name)
+		}
 
 		return nil
 	},
@@ -18,7 +18,8 @@ var upCmd = &cobra.Command{
 	Short: "Start the Bitrise Build Cache background services",
 	Long: `up starts the daemon services that were registered by ` + "`daemon install`" + `. ` +
 		`Safe to rerun, but not a true no-op against an already-running daemon: on macOS the underlying ` + "`launchctl bootstrap`" + ` is preceded by a ` + "`launchctl bootout`" + `, which briefly stops + restarts each service (so a CLI binary upgrade is picked up). On Linux ` + "`systemctl --user enable --now`" + ` is a real no-op on an already-running unit. ` +
-		`Errors with a "run install first" hint if the supervisor config files are missing from disk.`,
+		`Services whose supervisor config is missing from disk, e.g. ones added by a newer CLI version, are skipped; ` +
+		`errors with a "run install first" hint if none of them is installed.`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode))
@@ -42,6 +43,9 @@ var upCmd = &cobra.Command{
 		for _, st := range result.Statuses {
 			logger.Donef("%s — started (%s)", st.Service.Name, result.BackendName)
 		}
+		for _, st := range result.NotInstalled {
+			logger.Infof("%s — not installed, skipped; rerun `bitrise-build-cache daemon install` to add it", st.Service.Name)
+		}
 
 		return nil
 	},
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/bazelcredhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 )
 
@@ -24,10 +25,12 @@ var getCmd = &cobra.Command{
 	// timeout — override the root PersistentPreRun (version check, stored-auth
 	// hydration) with a no-op so the helper fast-paths straight to Run. The
 	// helper resolves credentials via configcommon.ResolveAuthConfig(envs),
-	// which walks env → keychain → file without needing hydration to have run.
+	// which walks env → keychain → file without needing hydration to have run,
+	// and refreshes a stored OAuth login itself (same as `credentials bazel`).
 	PersistentPreRun: func(*cobra.Command, []string) {},
 	RunE: func(cmd *cobra.Command, _ []string) error {
-		if err := bazelcredhelper.Run(cmd.InOrStdin(), cmd.OutOrStdout(), utils.AllEnvs()); err != nil {
+		resolve := credhelper.StoredLoginResolver(cmd.Context(), utils.AllEnvs(), "")
+		if err := bazelcredhelper.RunWithResolver(cmd.InOrStdin(), cmd.OutOrStdout(), resolve); err != nil {
 			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
 
 			return fmt.Errorf("run bazel credential helper: %w", err)
@@ -84,12 +84,19 @@ If the "# [start/end] generated-by-bitrise-build-cache" block is already present
 		// Best-effort: sidecar write failure must not fail the activate.
 		if home, homeErr := os.UserHomeDir(); homeErr == nil {
 			initFile := filepath.Join(gradleHome, "init.d", "bitrise-build-cache.init.gradle.kts")
-			if err := gradleconfig.WriteSidecar(home, gradleconfig.Sidecar{
+			sidecar := gradleconfig.Sidecar{
 				InitScriptPath:   initFile,
 				CacheEnabled:     activateGradleParams.Cache.Enabled,
 				CachePushEnabled: activateGradleParams.Cache.PushEnabled,
 				AnalyticsEnabled: activateGradleParams.Analytics.Enabled,
-			}); err != nil {
+				TaskAnalytics:    activateGradleParams.Analytics.TaskReport,
+				CacheConnector:   activateGradleParams.Cache.Connector,
+				CacheEndpoint:    activateGradleParams.Cache.Endpoint,
+			}
+			if gradleconfig.CacheConnector(activateGradleParams.Cache.Connector) == gradleconfig.CacheConnectorLocalHTTP {
+				sidecar.CacheLocalHTTPPort = activateGradleParams.Cache.LocalHTTPPort
+			}
+			if err := gradleconfig.WriteSidecar(home, sidecar); err != nil {
 				logger.Debugf("gradle sidecar write failed (non-fatal): %s", err)
 			}
 		}
@@ -110,9 +117,12 @@ func init() {
 	ActivateGradleCmd.Flags().BoolVar(&activateGradleParams.Cache.PushEnabled, "cache-push", activateGradleParams.Cache.PushEnabled, "Push enabled/disabled. Enabled means the build can also write new entries to the remote cache. Disabled means the build can only read from the remote cache.")
 	ActivateGradleCmd.Flags().StringVar(&activateGradleParams.Cache.ValidationLevel, "cache-validation", activateGradleParams.Cache.ValidationLevel, "Level of cache entry validation for both uploads and downloads. Possible values: none, warning, error")
 	ActivateGradleCmd.Flags().StringVar(&activateGradleParams.Cache.Endpoint, "cache-endpoint", activateGradleParams.Cache.Endpoint, "The endpoint can be manually provided here for caching operations.")
+	ActivateGradleCmd.Flags().StringVar(&activateGradleParams.Cache.Connector, "connector", activateGradleParams.Cache.Connector, "How Gradle reaches the remote cache. Possible values: plugin (Bitrise Gradle plugin), local-http (Gradle's built-in HTTP build cache pointed at the CLI's local connector, run with `gradle start-cache-connector`)")
+	ActivateGradleCmd.Flags().IntVar(&activateGradleParams.Cache.LocalHTTPPort, "connector-port", activateGradleParams.Cache.LocalHTTPPort, "Loopback port of the local HTTP connector. Only used with --connector=local-http.")
 
 	ActivateGradleCmd.Flags().BoolVar(&activateGradleParams.Analytics.Enabled, "analytics", activateGradleParams.Analytics.Enabled, "Activate analytics plugin. Will override analytics-dep.")
 	ActivateGradleCmd.Flags().BoolVar(&activateGradleParams.Analytics.JustDependency, "analytics-dep", activateGradleParams.Analytics.JustDependency, "Add analytics plugin as a dependency only.")
+	ActivateGradleCmd.Flags().BoolVar(&activateGradleParams.Analytics.TaskReport, "task-analytics", activateGradleParams.Analytics.TaskReport, "Record every task's outcome (FROM-CACHE, UP-TO-DATE, EXECUTED, cacheability, execution reasons) with a listener in the init script and upload it to Bitrise analytics. Needs no Gradle plugin.")
 
 	ActivateGradleCmd.Flags().BoolVar(&activateGradleParams.TestDistro.Enabled, "test-distribution", activateGradleParams.TestDistro.Enabled, "Activate test distribution plugin for the provided app slug. Will override test-distribution-dep.")
 	ActivateGradleCmd.Flags().BoolVar(&activateGradleParams.TestDistro.JustDependency, "test-distribution-dep", activateGradleParams.TestDistro.JustDependency, "Add test distribution plugin as a dependency only.")
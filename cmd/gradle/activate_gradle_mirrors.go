@@ -1,6 +1,8 @@
 package gradle
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
@@ -8,6 +10,13 @@ import (
 	mirrorspkg "github.com/bitrise-io/bitrise-build-cache-cli/v3/pkg/gradle/mirrors"
 )
 
+//nolint:gochecknoglobals
+var (
+	mirrorsLocalProxyPort int
+	mirrorsCustomSpecs    []string
+	mirrorsConfigFile     string
+)
+
 var activateGradleMirrorsCmd = &cobra.Command{ //nolint:gochecknoglobals
 	Use:   "gradle-mirrors",
 	Short: "Activate Bitrise repository mirrors for Gradle",
@@ -22,14 +31,38 @@ The command checks the BITRISE_MAVENCENTRAL_PROXY_ENABLED environment variable
 and only installs the init script when it is set to "true".
 The mirror URL is determined by the BITRISE_DEN_VM_DATACENTER environment variable.
 
+Additional repositories, e.g. JitPack or an internal Artifactory, can be
+mirrored by declaring custom mirrors in ~/.bitrise/gradle-mirrors.json
+(or the file given by --mirrors-config):
+
+  {"mirrors": [{"name": "jitpack", "url": "https://artifactory.example.com/jitpack",
+                "urlPrefixes": ["https://jitpack.io"], "repoNames": [], "pluginManagement": false}]}
+
+or with --mirror, which can be repeated:
+
+  --mirror jitpack=https://artifactory.example.com/jitpack,match=url:https://jitpack.io
+
+A repository is mirrored when its URL starts with any urlPrefixes entry
+(match=url:) or its name is any repoNames entry (match=name:). Add ",plugins"
+to also apply the mirror to pluginManagement repositories. Custom mirrors are
+always enabled and are validated before the init script is written.
+
+With --local-proxy-port the mirrors point at the local dependency proxy
+started by "gradle start-dependency-proxy" instead, which serves artifacts from
+a local disk cache and works outside Bitrise datacenters too. The environment
+variables above are not required then.
+
 The installed init script re-reads BITRISE_MAVENCENTRAL_PROXY_ENABLED at Gradle
 build time: setting it to "false" (via Secrets, step inputs, or app config)
 disables the mirrors per workflow / per workspace without removing the file.`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		activator := mirrorspkg.NewActivator(mirrorspkg.ActivatorParams{
-			SelectedFlags: selectedMirrorFlags(cmd),
-			DebugLogging:  common.IsDebugLogMode,
+			SelectedFlags:     selectedMirrorFlags(cmd),
+			CustomMirrorSpecs: mirrorsCustomSpecs,
+			MirrorsConfigFile: mirrorsConfigFile,
+			LocalProxyPort:    mirrorsLocalProxyPort,
+			DebugLogging:      common.IsDebugLogMode,
 		})
 
 		return activator.Activate(cmd.Context())
@@ -41,6 +74,14 @@ func init() {
 		activateGradleMirrorsCmd.Flags().Bool(m.FlagName, false, "Enable mirror for "+m.FlagName)
 	}
 
+	activateGradleMirrorsCmd.Flags().IntVar(&mirrorsLocalProxyPort, "local-proxy-port", 0,
+		fmt.Sprintf("Point the mirrors at the local dependency proxy on this port (e.g. %d) instead of the Bitrise datacenter mirrors", mirrorsconfig.DefaultLocalProxyPort))
+
+	activateGradleMirrorsCmd.Flags().StringArrayVar(&mirrorsCustomSpecs, "mirror", nil,
+		"Custom mirror: <name>=<url>,match=url:<prefix>,match=name:<repo name>[,plugins]. Can be repeated.")
+	activateGradleMirrorsCmd.Flags().StringVar(&mirrorsConfigFile, "mirrors-config", "",
+		`Custom mirrors config file. Defaults to ~/.bitrise/gradle-mirrors.json, "-" disables it.`)
+
 	common.ActivateCmd.AddCommand(activateGradleMirrorsCmd)
 }
 
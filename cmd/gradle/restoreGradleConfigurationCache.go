@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/google/uuid"
@@ -16,8 +17,11 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/envexport"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle"
+	gradleanalytics "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle/analytics"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 )
 
@@ -42,6 +46,12 @@ var restoreGradleConfigCacheCmd = &cobra.Command{
 
 		logger.Infof("(i) Checking parameters")
 		cacheKey, _ := cmd.Flags().GetString("key")
+		projectDir, _ := cmd.Flags().GetString("project-dir")
+		keyTemplates := common.CacheKeyTemplatesFromFlags(cmd)
+		transfer, err := common.TransferOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		logger.Infof("(i) Check Auth Config")
 		allEnvs := utils.AllEnvs()
@@ -50,18 +60,37 @@ var restoreGradleConfigCacheCmd = &cobra.Command{
 			return fmt.Errorf("resolve auth config: %w", err)
 		}
 
-		err = restoreGradleConfigCacheCmdFn(cmd.Context(),
+		commandFunc := func(name string, v ...string) (string, error) {
+			output, err := exec.Command(name, v...).Output()
+
+			return string(output), err
+		}
+
+		operationID := uuid.NewString()
+		startT := time.Now()
+
+		stats, cmdError := restoreGradleConfigCacheCmdFn(cmd.Context(),
 			authConfig,
+			operationID,
 			cacheKey,
+			gradle.CacheKeyParams{Templates: keyTemplates, CommandFunc: commandFunc},
+			transfer,
+			projectDir,
 			logger,
 			allEnvs,
-			func(name string, v ...string) (string, error) {
-				output, err := exec.Command(name, v...).Output()
+			commandFunc)
+		if stats != nil {
+			reportConfigCacheRestore(operationID, startT, *stats, cmdError, authConfig, allEnvs, commandFunc, logger)
+		}
+		envexport.New(allEnvs, logger).PublishCacheReport(configCacheRestoreReport(stats, time.Since(startT), cmdError))
+		if cmdError != nil {
+			return fmt.Errorf("restore Gradle config cache from Bitrise Build Cache: %w", cmdError)
+		}
 
-				return string(output), err
-			})
-		if err != nil {
-			return fmt.Errorf("restore Gradle config cache from Bitrise Build Cache: %w", err)
+		if stats != nil && !stats.Restored {
+			logger.TInfof("Configuration cache not restored, the cached entry is incompatible with this environment")
+
+			return nil
 		}
 
 		logger.TInfof("✅ Configuration cache restored from Bitrise Build Cache ")
@@ -74,47 +103,72 @@ func init() {
 	common.RootCmd.AddCommand(restoreGradleConfigCacheCmd)
 
 	restoreGradleConfigCacheCmd.Flags().String("key", "", "The cache key used for the saved cache item (set to the Bitrise app's slug and current git branch by default)")
+	common.AddCacheKeyTemplateFlags(restoreGradleConfigCacheCmd, gradle.DefaultCacheKeyTemplate, gradle.DefaultFallbackCacheKeyTemplate)
+	restoreGradleConfigCacheCmd.Flags().String("project-dir", ".", "Path to the Gradle project root. Entries saved with a different Gradle version, JDK, init scripts or gradle.properties are skipped")
+	common.AddTransferFlags(restoreGradleConfigCacheCmd)
 }
 
+// restoreGradleConfigCacheCmdFn returns the restore stats once the metadata of
+// the entry is known. An incompatible entry is not an error: it is skipped and
+// reported through the stats.
 func restoreGradleConfigCacheCmdFn(ctx context.Context,
 	authConfig configcommon.CacheAuthConfig,
+	operationID,
 	providedCacheKey string,
+	keyParams gradle.CacheKeyParams,
+	transfer kv.TransferOptions,
+	projectDir string,
 	logger log.Logger,
 	envProvider map[string]string,
 	commandFunc func(string, ...string) (string, error),
-) error {
+) (*gradleanalytics.ConfigCacheRestoreStats, error) {
 	kvClient, err := common.CreateKVClient(ctx,
 		common.CreateKVClientParams{
-			CacheOperationID: uuid.NewString(),
+			CacheOperationID: operationID,
 			ClientName:       common.ClientNameGradleConfigCache,
 			AuthConfig:       authConfig,
 			Envs:             envProvider,
 			CommandFunc:      commandFunc,
 			Logger:           logger,
+			Transfer:         transfer,
 		})
 	if err != nil {
-		return fmt.Errorf("create kv client: %w", err)
+		return nil, fmt.Errorf("create kv client: %w", err)
 	}
 
 	g := gradle.NewCache(logger, envProvider, kvClient)
 
 	logger.TInfof("(i) Restoring Gradle configuration cache")
 
-	_, _, err = downloadGradleConfigCacheMetadata(ctx, GradleConfigCacheMetadataPath, providedCacheKey, g, kvClient, logger)
+	cacheKeyType, cacheKey, err := downloadGradleConfigCacheMetadata(ctx, GradleConfigCacheMetadataPath, providedCacheKey, keyParams, g, kvClient, logger)
+	stats := &gradleanalytics.ConfigCacheRestoreStats{
+		CacheKey:     cacheKey,
+		CacheKeyType: string(cacheKeyType),
+	}
 	if err != nil {
-		return fmt.Errorf("download cache metadata: %w", err)
+		return stats, fmt.Errorf("download cache metadata: %w", err)
 	}
 
 	logger.TInfof("Loading metadata from %s", GradleConfigCacheMetadataPath)
 	var metadata *gradle.Metadata
 	if metadata, _, err = g.LoadMetadata(GradleConfigCacheMetadataPath); err != nil {
-		return fmt.Errorf("load metadata: %w", err)
+		return stats, fmt.Errorf("load metadata: %w", err)
 	}
 
 	metadata.Print(logger)
 
 	if metadata.OS != runtime.GOOS {
-		return errors.New(ErrFmtMetadataWrongOS)
+		return stats, errors.New(ErrFmtMetadataWrongOS)
+	}
+
+	if issues := metadata.CompatibilityIssues(g.CollectEnvironment(projectDir)); len(issues) > 0 {
+		logger.Warnf("Skipping configuration cache restore, the cached entry was produced in an incompatible environment:")
+		for _, issue := range issues {
+			logger.Warnf("  - %s", issue)
+		}
+		stats.IncompatibilityReasons = issues
+
+		return stats, nil
 	}
 
 	logger.TInfof("Downloading configuration cache files")
@@ -128,7 +182,7 @@ func restoreGradleConfigCacheCmdFn(ctx context.Context,
 			}
 		}
 
-		return fmt.Errorf("download config cache files: %w", err)
+		return stats, fmt.Errorf("download config cache files: %w", err)
 	}
 
 	updated := 0
@@ -143,27 +197,78 @@ func restoreGradleConfigCacheCmdFn(ctx context.Context,
 
 	logger.Infof("(i) %d files' modification time restored", updated)
 
-	return nil
+	stats.Restored = true
+
+	return stats, nil
+}
+
+func configCacheRestoreReport(stats *gradleanalytics.ConfigCacheRestoreStats, duration time.Duration, cmdError error) envexport.CacheReport {
+	report := envexport.CacheReport{
+		Title:        "Gradle configuration cache restore",
+		OutputPrefix: "BITRISE_GRADLE_CONFIG_CACHE_RESTORE",
+		IsRestore:    true,
+		HitRate:      -1,
+		Duration:     duration,
+		Err:          cmdError,
+	}
+
+	if stats != nil {
+		report.CacheKey = stats.CacheKey
+		report.CacheKeyType = stats.CacheKeyType
+		report.CacheHit = stats.Restored
+		if len(stats.IncompatibilityReasons) > 0 {
+			report.SkipReason = "incompatible environment: " + strings.Join(stats.IncompatibilityReasons, "; ")
+		}
+	}
+
+	return report
+}
+
+func reportConfigCacheRestore(
+	operationID string,
+	startT time.Time,
+	stats gradleanalytics.ConfigCacheRestoreStats,
+	cmdError error,
+	authConfig configcommon.CacheAuthConfig,
+	envs map[string]string,
+	commandFunc func(string, ...string) (string, error),
+	logger log.Logger,
+) {
+	client, err := gradleanalytics.NewClient(consts.MultiplatformAnalyticsServiceEndpoint, authConfig.TokenInGradleFormat(), logger)
+	if err != nil {
+		logger.Warnf("Failed to create analytics client: %s", err)
+
+		return
+	}
+
+	metadata := configcommon.NewMetadata(envs, commandFunc, logger)
+	inv := gradleanalytics.NewConfigCacheRestoreInvocation(operationID, startT, stats, cmdError, authConfig, metadata)
+	if err := client.PutConfigCacheRestoreInvocation(*inv); err != nil {
+		logger.Warnf("Failed to send configuration cache restore analytics: %s", err)
+	}
 }
 
 func downloadGradleConfigCacheMetadata(ctx context.Context, cacheMetadataPath, providedCacheKey string,
+	keyParams gradle.CacheKeyParams,
 	gradleCache *gradle.Cache,
 	kvClient *kv.Client,
 	logger log.Logger,
 ) (common.CacheKeyType, string, error) {
 	var cacheKeyType common.CacheKeyType = common.CacheKeyTypeDefault
-	var cacheKey string
-	var err error
+	// Fallback keys are tried even for a provided key, so the error is only
+	// fatal when the primary key is needed.
+	keys, err := gradleCache.GetCacheKeys(keyParams)
 	if providedCacheKey == "" {
-		if cacheKey, err = gradleCache.GetCacheKey(gradle.CacheKeyParams{}); err != nil {
+		if err != nil {
 			return "", "", fmt.Errorf("get cache key: %w", err)
 		}
-		logger.TInfof("Downloading cache metadata checksum for key %s", cacheKey)
+		logger.TInfof("Downloading cache metadata checksum for key %s", keys.Primary)
 	} else {
 		cacheKeyType = common.CacheKeyTypeProvided
-		cacheKey = providedCacheKey
-		logger.TInfof("Downloading cache metadata checksum for provided key %s", cacheKey)
+		keys.Primary = providedCacheKey
+		logger.TInfof("Downloading cache metadata checksum for provided key %s", keys.Primary)
 	}
+	cacheKey := keys.Primary
 
 	var mdChecksum strings.Builder
 	err = kvClient.DownloadStreamFromBuildCache(ctx, &mdChecksum, cacheKey)
@@ -173,22 +278,29 @@ func downloadGradleConfigCacheMetadata(ctx context.Context, cacheMetadataPath, p
 
 	if errors.Is(err, kv.ErrCacheNotFound) {
 		cacheKeyType = common.CacheKeyTypeFallback
-		fallbackCacheKey, fallbackErr := gradleCache.GetCacheKey(gradle.CacheKeyParams{IsFallback: true})
-		if fallbackErr != nil {
-			return cacheKeyType, fallbackCacheKey, errors.New("cache metadata not found in cache")
-		}
+		found := false
+		for _, fallbackCacheKey := range keys.Fallbacks {
+			cacheKey = fallbackCacheKey
+			logger.Infof("Cache metadata not found, trying fallback key %s", cacheKey)
+
+			mdChecksum.Reset()
+			err = kvClient.DownloadStreamFromBuildCache(ctx, &mdChecksum, cacheKey)
+			if errors.Is(err, kv.ErrCacheNotFound) {
+				continue
+			}
+			if err != nil {
+				return cacheKeyType, cacheKey, fmt.Errorf("download cache metadata checksum: %w", err)
+			}
 
-		cacheKey = fallbackCacheKey
-		logger.Infof("Cache metadata not found for original key, trying fallback key %s", cacheKey)
+			logger.Infof("Cache metadata found for fallback key %s", cacheKey)
+			found = true
 
-		err = kvClient.DownloadStreamFromBuildCache(ctx, &mdChecksum, cacheKey)
-		if errors.Is(err, kv.ErrCacheNotFound) {
-			return cacheKeyType, cacheKey, errors.New("cache metadata not found in cache")
+			break
 		}
-		if err != nil {
-			return cacheKeyType, cacheKey, fmt.Errorf("download cache metadata checksum: %w", err)
+
+		if !found {
+			return cacheKeyType, cacheKey, errors.New("cache metadata not found in cache")
 		}
-		logger.Infof("Cache metadata found for fallback key %s", cacheKey)
 	}
 
 	logger.TInfof("Downloading cache metadata content to %s for key %s", cacheMetadataPath, mdChecksum.String())
@@ -6,13 +6,17 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/envexport"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
@@ -37,6 +41,12 @@ var saveGradleConfigCacheCmd = &cobra.Command{
 
 		logger.Infof("(i) Checking parameters")
 		configCacheDir, _ := cmd.Flags().GetString("config-cache-dir")
+		projectDir, _ := cmd.Flags().GetString("project-dir")
+		keyTemplates := common.CacheKeyTemplatesFromFlags(cmd)
+		transfer, err := common.TransferOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 		cacheKey, _ := cmd.Flags().GetString("key")
 
 		logger.Infof("(i) Check Auth Config")
@@ -46,17 +56,31 @@ var saveGradleConfigCacheCmd = &cobra.Command{
 			return fmt.Errorf("resolve auth config: %w", err)
 		}
 
-		err = saveGradleConfigCacheCmdFn(cmd.Context(),
+		commandFunc := func(name string, v ...string) (string, error) {
+			output, err := exec.Command(name, v...).Output()
+
+			return string(output), err
+		}
+
+		startT := time.Now()
+		savedKey, err := saveGradleConfigCacheCmdFn(cmd.Context(),
 			authConfig,
 			configCacheDir,
+			projectDir,
 			cacheKey,
+			gradle.CacheKeyParams{Templates: keyTemplates, CommandFunc: commandFunc},
+			transfer,
 			logger,
 			allEnvs,
-			func(name string, v ...string) (string, error) {
-				output, err := exec.Command(name, v...).Output()
-
-				return string(output), err
-			})
+			commandFunc)
+		envexport.New(allEnvs, logger).PublishCacheReport(envexport.CacheReport{
+			Title:        "Gradle configuration cache save",
+			OutputPrefix: "BITRISE_GRADLE_CONFIG_CACHE_SAVE",
+			CacheKey:     savedKey,
+			HitRate:      -1,
+			Duration:     time.Since(startT),
+			Err:          err,
+		})
 		if err != nil {
 			return fmt.Errorf("save Gradle config cache into Bitrise Build Cache: %w", err)
 		}
@@ -71,17 +95,23 @@ func init() {
 	common.RootCmd.AddCommand(saveGradleConfigCacheCmd)
 
 	saveGradleConfigCacheCmd.Flags().String("key", "", "The cache key to use for the saved cache item (set to the Bitrise app's slug and current git branch by default)")
+	common.AddCacheKeyTemplateFlags(saveGradleConfigCacheCmd, gradle.DefaultCacheKeyTemplate, gradle.DefaultFallbackCacheKeyTemplate)
 	saveGradleConfigCacheCmd.Flags().String("config-cache-dir", "./.gradle/configuration-cache", "Path to the Gradle configuration cache folder. It's usually the $PROJECT_ROOT/.gradle/configuration-cache")
+	saveGradleConfigCacheCmd.Flags().String("project-dir", ".", "Path to the Gradle project root. Its Gradle wrapper version, JDK and gradle.properties are recorded so restore can skip incompatible entries")
+	common.AddTransferFlags(saveGradleConfigCacheCmd)
 }
 
 func saveGradleConfigCacheCmdFn(ctx context.Context,
 	authConfig configcommon.CacheAuthConfig,
 	configCacheDir,
+	projectDir,
 	providedCacheKey string,
+	keyParams gradle.CacheKeyParams,
+	transfer kv.TransferOptions,
 	logger log.Logger,
 	envProvider map[string]string,
 	commandFunc func(string, ...string) (string, error),
-) error {
+) (string, error) {
 	var err error
 
 	kvClient, err := common.CreateKVClient(ctx,
@@ -92,77 +122,73 @@ func saveGradleConfigCacheCmdFn(ctx context.Context,
 			Envs:             envProvider,
 			CommandFunc:      commandFunc,
 			Logger:           logger,
+			Transfer:         transfer,
 		})
 	if err != nil {
-		return fmt.Errorf("create kv client: %w", err)
+		return "", fmt.Errorf("create kv client: %w", err)
 	}
 
 	cache := gradle.NewCache(logger, envProvider, kvClient)
 
-	var cacheKey string
+	var keys cachekey.Keys
 	if providedCacheKey == "" {
-		logger.Infof("(i) Cache key is not explicitly specified, setting it based on the current Bitrise app's slug and git branch...")
-		if cacheKey, err = cache.GetCacheKey(gradle.CacheKeyParams{}); err != nil {
-			return fmt.Errorf("get cache key: %w", err)
+		logger.Infof("(i) Cache key is not explicitly specified, rendering it from the key template...")
+		if keys, err = cache.GetCacheKeys(keyParams); err != nil {
+			return "", fmt.Errorf("get cache key: %w", err)
 		}
 	} else {
-		cacheKey = providedCacheKey
+		keys.Primary = providedCacheKey
 	}
+	cacheKey := keys.Primary
 	logger.Infof("(i) Cache key: %s", cacheKey)
 
 	absDir, err := filepath.Abs(configCacheDir)
 	if err != nil {
-		return fmt.Errorf("get absolute path of config cache dir: %w", err)
+		return "", fmt.Errorf("get absolute path of config cache dir: %w", err)
 	}
 
 	logger.TInfof(fmt.Sprintf("Gathering metadata for cache files in %s", absDir))
-	metadata, err := cache.CreateMetadata(cacheKey, absDir)
+	metadata, err := cache.CreateMetadata(cacheKey, absDir, projectDir)
 	if err != nil {
-		return fmt.Errorf("create metadata: %w", err)
+		return "", fmt.Errorf("create metadata: %w", err)
 	}
 
 	logger.TInfof("Saving metadata file %s", GradleConfigCacheMetadataPath)
 	_, err = cache.SaveMetadata(metadata, GradleConfigCacheMetadataPath)
 	if err != nil {
-		return fmt.Errorf("save metadata: %w", err)
+		return "", fmt.Errorf("save metadata: %w", err)
 	}
 
 	mdChecksum, err := hash.ChecksumOfFile(GradleConfigCacheMetadataPath)
 	mdChecksumReader := strings.NewReader(mdChecksum)
 	if err != nil {
-		return fmt.Errorf("checksum of metadata file: %w", err)
+		return "", fmt.Errorf("checksum of metadata file: %w", err)
 	}
 
 	logger.TInfof("Uploading cache files")
 
 	_, err = kvClient.UploadFileGroupToBuildCache(ctx, metadata.ConfigCacheFiles)
 	if err != nil {
-		return fmt.Errorf("upload cache files to build cache: %w", err)
+		return "", fmt.Errorf("upload cache files to build cache: %w", err)
 	}
 
 	logger.TInfof("Uploading metadata checksum of %s (%s) for key %s", GradleConfigCacheMetadataPath, mdChecksum, cacheKey)
 	if err := kvClient.UploadStreamToBuildCache(ctx, mdChecksumReader, cacheKey, mdChecksumReader.Size()); err != nil {
-		return fmt.Errorf("upload metadata checksum to build cache: %w", err)
+		return "", fmt.Errorf("upload metadata checksum to build cache: %w", err)
 	}
 
 	logger.TInfof("Uploading metadata content of %s for key %s", GradleConfigCacheMetadataPath, mdChecksum)
 	if err := kvClient.UploadFileToBuildCache(ctx, GradleConfigCacheMetadataPath, mdChecksum); err != nil {
-		return fmt.Errorf("upload metadata content to build cache: %w", err)
+		return "", fmt.Errorf("upload metadata content to build cache: %w", err)
 	}
 
-	if providedCacheKey == "" {
-		fallbackCacheKey, err := cache.GetCacheKey(gradle.CacheKeyParams{IsFallback: true})
-		if err != nil {
-			logger.Warnf("Failed to get fallback cache key: %s", err)
-		} else if fallbackCacheKey != "" && cacheKey != fallbackCacheKey {
-			cacheKey = fallbackCacheKey
-			mdChecksumReader = strings.NewReader(mdChecksum) // reset reader
-			logger.TInfof("Uploading metadata checksum of %s (%s) for fallback key %s", GradleConfigCacheMetadataPath, mdChecksum, cacheKey)
-			if err := kvClient.UploadStreamToBuildCache(ctx, mdChecksumReader, cacheKey, mdChecksumReader.Size()); err != nil {
-				return fmt.Errorf("upload metadata checksum to build cache: %w", err)
-			}
+	for _, fallbackCacheKey := range keys.Fallbacks {
+		mdChecksumReader = strings.NewReader(mdChecksum) // reset reader
+		logger.TInfof("Uploading metadata checksum of %s (%s) for fallback key %s", GradleConfigCacheMetadataPath, mdChecksum, fallbackCacheKey)
+		if err := kvClient.UploadStreamToBuildCache(ctx, mdChecksumReader, fallbackCacheKey, mdChecksumReader.Size()); err != nil {
+			return "", fmt.Errorf("upload metadata checksum to build cache: %w", err)
 		}
 	}
 
-	return nil
+	return cacheKey, nil
 }
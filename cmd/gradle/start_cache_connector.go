@@ -0,0 +1,207 @@
+package gradle
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	gradleconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradlehttpcache"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/invocations"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/proxypid"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/kv_storage"
+)
+
+const connectorShutdownTimeout = 30 * time.Second
+
+//nolint:gochecknoglobals
+var (
+	gradleCmd = &cobra.Command{
+		Use:          "gradle",
+		Short:        "Gradle related commands",
+		Long:         "Gradle related commands. To set up the remote cache for Gradle, use `activate gradle` first.",
+		SilenceUsage: true,
+	}
+
+	startCacheConnectorCmd = &cobra.Command{
+		Use:   "start-cache-connector",
+		Short: "Start the local Gradle HTTP build cache connector",
+		Long: `start-cache-connector serves Gradle's HTTP build cache protocol (GET and PUT /cache/<key>) on a loopback port ` +
+			`and forwards the calls to the Bitrise Build Cache, so projects that cannot apply the Bitrise Gradle plugin still get remote caching. ` +
+			"Activate it with `activate gradle --cache --connector=local-http`; the daemon runs it as the gradle-cache-connector service.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode))
+			logger.TInfof("Gradle HTTP cache connector")
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("get user home dir: %w", err)
+			}
+
+			sidecar, found, err := gradleconfig.ReadSidecar(home)
+			if err != nil {
+				return fmt.Errorf("read gradle config: %w", err)
+			}
+			if !found || !sidecar.CacheEnabled || sidecar.CacheLocalHTTPPort == 0 {
+				logger.TInfof("Gradle cache connector not configured; run `bitrise-build-cache activate gradle --cache --connector=local-http` to enable. Connector idle.")
+
+				return nil
+			}
+
+			release, err := proxypid.Acquire(utils.DefaultOsProxy{}, gradleconfig.ConnectorPidFilePath(home), nil)
+			if err != nil {
+				logger.Infof("Skipping connector startup: %s", err)
+
+				return nil
+			}
+			defer func() {
+				if err := release(); err != nil {
+					logger.Warnf("Failed to release connector pid lock: %s", err)
+				}
+			}()
+
+			signalCtx, stopSignals := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+			defer stopSignals()
+
+			address := net.JoinHostPort("127.0.0.1", strconv.Itoa(sidecar.CacheLocalHTTPPort))
+			listener, err := (&net.ListenConfig{}).Listen(signalCtx, "tcp", address)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", address, err)
+			}
+			defer listener.Close()
+
+			logger.TInfof("Listening on %s", gradleconfig.LocalHTTPCacheURL(sidecar.CacheLocalHTTPPort))
+
+			return StartGradleCacheConnector(
+				signalCtx,
+				sidecar,
+				utils.AllEnvs(),
+				func(name string, v ...string) (string, error) {
+					output, err := exec.Command(name, v...).Output()
+
+					return string(output), err
+				},
+				nil,
+				listener,
+				logger,
+			)
+		},
+	}
+)
+
+func init() {
+	common.RootCmd.AddCommand(gradleCmd)
+	gradleCmd.AddCommand(startCacheConnectorCmd)
+}
+
+// StartGradleCacheConnector serves the Gradle HTTP build cache on listener until ctx is cancelled.
+func StartGradleCacheConnector(
+	ctx context.Context,
+	sidecar gradleconfig.Sidecar,
+	envProvider map[string]string,
+	commandFunc configcommon.CommandFunc,
+	bitriseKVClient kv_storage.KVStorageClient,
+	listener net.Listener,
+	logger log.Logger,
+) error {
+	oauthCfg := oauth.NewConfigFromEnv(envProvider)
+	oauthCfg.Logger = logger
+	authProvider := credhelper.NewAuthSource(context.WithoutCancel(ctx), envProvider, oauthCfg, logger)
+
+	client, err := common.CreateKVClient(ctx, common.CreateKVClientParams{
+		CacheOperationID: uuid.NewString(),
+		ClientName:       common.ClientNameGradle,
+		AuthConfig:       authProvider.Get(),
+		AuthSource:       authProvider,
+		Envs:             envProvider,
+		CommandFunc:      commandFunc,
+		Logger:           logger,
+		BitriseKVClient:  bitriseKVClient,
+		EndpointURL:      sidecar.CacheEndpoint,
+		CircuitBreaker:   kv.NewCircuitBreaker(kv.CircuitBreakerParams{Logger: logger}),
+	})
+	if err != nil {
+		return fmt.Errorf("create kv client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	metadata := configcommon.NewMetadata(envProvider, commandFunc, logger)
+
+	server := gradlehttpcache.NewServer(client, sidecar.CachePushEnabled, logger, newConnectorInvocationEmitter(metadata, logger))
+	server.AppSlug = metadata.BitriseAppID
+	server.BuildSlug = metadata.BitriseBuildID
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), connectorShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Warnf("Failed to shut down the connector gracefully: %s", err)
+		}
+	}()
+
+	serveErr := server.Serve(listener)
+
+	server.FlushCurrentSession(context.WithoutCancel(ctx))
+
+	//nolint:wrapcheck
+	return serveErr
+}
+
+// connectorInvocationEmitter records every Gradle session served by the connector in the local invocation log.
+type connectorInvocationEmitter struct {
+	writer   *invocations.Writer
+	metadata configcommon.CacheConfigMetadata
+	logger   log.Logger
+}
+
+func newConnectorInvocationEmitter(metadata configcommon.CacheConfigMetadata, logger log.Logger) gradlehttpcache.InvocationEmitter {
+	p, err := paths.Default()
+	if err != nil {
+		logger.Warnf("Local invocation log disabled: %s", err)
+
+		return nil
+	}
+
+	writer := invocations.NewWriter(p)
+	writer.Logger = logger
+
+	return &connectorInvocationEmitter{writer: writer, metadata: metadata, logger: logger}
+}
+
+func (e *connectorInvocationEmitter) EmitSession(_ context.Context, meta gradlehttpcache.SessionMeta, stats gradlehttpcache.SessionStats) {
+	if err := e.writer.Append(invocations.Record{
+		InvocationID: meta.InvocationID,
+		Command:      "gradle",
+		Tool:         invocations.ToolGradle,
+		ToolVersion:  meta.GradleVersion,
+		CLIVersion:   e.metadata.CLIVersion,
+		StartedAt:    meta.StartTime,
+		FinishedAt:   meta.EndTime,
+		CIProvider:   e.metadata.CIProvider,
+		Username:     e.metadata.HostMetadata.Username,
+		HitRate:      stats.HitRate(),
+	}); err != nil {
+		e.logger.Warnf("Failed to append local invocation log: %s", err)
+	}
+}
@@ -0,0 +1,219 @@
+package gradle
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle/mirrors"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/mavenproxy"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+const (
+	dependencyProxyCacheTool       = "maven"
+	defaultDependencyProxyMaxBytes = 10 * 1024 * 1024 * 1024
+)
+
+//nolint:gochecknoglobals
+var dependencyProxyFlags struct {
+	port     int
+	cacheDir string
+	maxBytes int64
+	share    bool
+	push     bool
+	mirrors  []string
+	config   string
+}
+
+//nolint:gochecknoglobals
+var startDependencyProxyCmd = &cobra.Command{
+	Use:   "start-dependency-proxy",
+	Short: "Start the local Maven repository proxy for Gradle dependencies",
+	Long: `start-dependency-proxy serves the repositories of the known Gradle mirrors on a loopback port from a local disk cache, ` +
+		`fetching misses from the upstream repository. Custom mirrors (--mirror, --mirrors-config, same format as ` +
+		"`activate gradle-mirrors`) are served too, fetching from their URL. " +
+		`With --share, artifacts are also looked up in and (with --push) stored to the ` +
+		`Bitrise Build Cache so other machines can reuse them. Maven metadata and snapshots are always fetched from upstream. ` +
+		"Point Gradle at it with `activate gradle-mirrors --local-proxy-port <port>`.",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode))
+		logger.TInfof("Gradle dependency proxy")
+
+		p, err := paths.Default()
+		if err != nil {
+			return fmt.Errorf("resolve paths: %w", err)
+		}
+
+		cacheDir := dependencyProxyFlags.cacheDir
+		if cacheDir == "" {
+			cacheDir = p.LocalBlobCacheDir(dependencyProxyCacheTool)
+		}
+
+		configFile := dependencyProxyFlags.config
+		switch configFile {
+		case "-":
+			configFile = ""
+		case "":
+			configFile = p.GradleMirrorsConfigFile()
+		}
+		customMirrors, err := mirrors.LoadCustomMirrors(utils.DefaultOsProxy{}, configFile, dependencyProxyFlags.mirrors)
+		if err != nil {
+			return fmt.Errorf("load custom mirrors: %w", err)
+		}
+
+		signalCtx, stopSignals := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+		defer stopSignals()
+
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(dependencyProxyFlags.port))
+		listener, err := (&net.ListenConfig{}).Listen(signalCtx, "tcp", address)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", address, err)
+		}
+		defer listener.Close()
+
+		logger.TInfof("Listening on http://%s%s", address, mavenproxy.RepositoryPath)
+
+		return StartDependencyProxy(signalCtx, DependencyProxyParams{
+			CacheDir:      cacheDir,
+			MaxBytes:      dependencyProxyFlags.maxBytes,
+			Share:         dependencyProxyFlags.share,
+			PushEnabled:   dependencyProxyFlags.push,
+			CustomMirrors: customMirrors,
+			Envs:          utils.AllEnvs(),
+			CommandFunc: func(name string, v ...string) (string, error) {
+				output, err := exec.Command(name, v...).Output()
+
+				return string(output), err
+			},
+		}, listener, logger)
+	},
+}
+
+func init() {
+	startDependencyProxyCmd.Flags().IntVar(&dependencyProxyFlags.port, "port", mirrors.DefaultLocalProxyPort, "Loopback port to listen on.")
+	startDependencyProxyCmd.Flags().StringVar(&dependencyProxyFlags.cacheDir, "cache-dir", "", "Local artifact cache dir. Defaults to ~/.bitrise/cache/maven/local-blobs.")
+	startDependencyProxyCmd.Flags().Int64Var(&dependencyProxyFlags.maxBytes, "max-bytes", defaultDependencyProxyMaxBytes, "Size limit of the local artifact cache, least recently used artifacts are evicted.")
+	startDependencyProxyCmd.Flags().BoolVar(&dependencyProxyFlags.share, "share", false, "Look up artifacts missing locally in the Bitrise Build Cache.")
+	startDependencyProxyCmd.Flags().BoolVar(&dependencyProxyFlags.push, "push", false, "Store artifacts fetched from upstream in the Bitrise Build Cache. Requires --share.")
+
+	startDependencyProxyCmd.Flags().StringArrayVar(&dependencyProxyFlags.mirrors, "mirror", nil, "Custom mirror to serve, same format as for `activate gradle-mirrors`. Can be repeated.")
+	startDependencyProxyCmd.Flags().StringVar(&dependencyProxyFlags.config, "mirrors-config", "", `Custom mirrors config file. Defaults to ~/.bitrise/gradle-mirrors.json, "-" disables it.`)
+
+	gradleCmd.AddCommand(startDependencyProxyCmd)
+}
+
+// DependencyProxyParams configures StartDependencyProxy.
+type DependencyProxyParams struct {
+	CacheDir    string
+	MaxBytes    int64
+	Share       bool
+	PushEnabled bool
+	// CustomMirrors are served in addition to the known mirrors.
+	CustomMirrors []mirrors.CustomMirror
+	Envs          map[string]string
+	CommandFunc   configcommon.CommandFunc
+}
+
+// StartDependencyProxy serves the known and custom mirror repositories on listener until ctx is cancelled.
+func StartDependencyProxy(ctx context.Context, params DependencyProxyParams, listener net.Listener, logger log.Logger) error {
+	store, err := localcache.Open(params.CacheDir, params.MaxBytes)
+	if err != nil {
+		return fmt.Errorf("open local artifact cache: %w", err)
+	}
+	logger.TInfof("Local artifact cache: %s (%s used of %s)",
+		store.Dir(),
+		humanize.Bytes(uint64(store.Size())),    //nolint:gosec
+		humanize.Bytes(uint64(params.MaxBytes)), //nolint:gosec
+	)
+
+	served, err := mirrors.WithCustomMirrors(mirrors.KnownMirrors, params.CustomMirrors)
+	if err != nil {
+		return fmt.Errorf("load custom mirrors: %w", err)
+	}
+
+	repositories := make([]mavenproxy.Repository, 0, len(served))
+	for _, m := range served {
+		repositories = append(repositories, mavenproxy.Repository{Segment: m.URLSegment, UpstreamURL: m.UpstreamURL})
+	}
+
+	serverParams := mavenproxy.ServerParams{
+		Repositories: repositories,
+		Store:        store,
+		PushEnabled:  params.PushEnabled,
+		Logger:       logger,
+	}
+
+	if params.Share {
+		client, err := createDependencyProxyKVClient(ctx, params, logger)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = client.Close() }()
+
+		serverParams.KVClient = client
+	} else if params.PushEnabled {
+		logger.Warnf("--push has no effect without --share, artifacts stay local")
+	}
+
+	server := mavenproxy.NewServer(serverParams)
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), connectorShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Warnf("Failed to shut down the dependency proxy gracefully: %s", err)
+		}
+	}()
+
+	serveErr := server.Serve(listener)
+
+	stats := server.Stats()
+	logger.TInfof("Dependency proxy stopped: hit rate %.02f%% (%d local hits, %d build cache hits, %d upstream fetches), %d uploads, %d skipped while degraded, %d checksum mismatches, %d errors",
+		stats.HitRate()*100, stats.LocalHits, stats.RemoteHits, stats.UpstreamFetches, stats.Uploads, stats.SkippedUploads, stats.ChecksumMismatches, stats.Errors)
+
+	//nolint:wrapcheck
+	return serveErr
+}
+
+func createDependencyProxyKVClient(ctx context.Context, params DependencyProxyParams, logger log.Logger) (*kv.Client, error) {
+	oauthCfg := oauth.NewConfigFromEnv(params.Envs)
+	oauthCfg.Logger = logger
+	authProvider := credhelper.NewAuthSource(context.WithoutCancel(ctx), params.Envs, oauthCfg, logger)
+
+	client, err := common.CreateKVClient(ctx, common.CreateKVClientParams{
+		CacheOperationID: uuid.NewString(),
+		ClientName:       common.ClientNameGradleDependencies,
+		AuthConfig:       authProvider.Get(),
+		AuthSource:       authProvider,
+		Envs:             params.Envs,
+		CommandFunc:      params.CommandFunc,
+		Logger:           logger,
+		CircuitBreaker:   kv.NewCircuitBreaker(kv.CircuitBreakerParams{Logger: logger}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create kv client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,171 @@
+package gradle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/analytics/multiplatform"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
+	gradleanalytics "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle/analytics"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+//nolint:gochecknoglobals
+var (
+	uploadTaskReportFile string
+
+	uploadTaskReportCmd = &cobra.Command{
+		Use:   "upload-task-report",
+		Short: "Upload Gradle task analytics reports",
+		Long: `upload-task-report sends the NDJSON task reports written by the init script of ` +
+			"`activate gradle --task-analytics` to Bitrise analytics. " +
+			"The init script runs it after every build with --file; without --file every pending report is uploaded. " +
+			"Uploaded reports are deleted, failed ones are kept for the next run.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger := log.NewLogger(log.WithDebugLog(common.IsDebugLogMode))
+			logger.TInfof("Upload Gradle task reports")
+
+			reports, err := pendingTaskReports(uploadTaskReportFile)
+			if err != nil {
+				return err
+			}
+			if len(reports) == 0 {
+				logger.Infof("No task reports to upload")
+
+				return nil
+			}
+
+			envs := utils.AllEnvs()
+			authConfig, _, err := configcommon.ResolveAuthConfig(envs)
+			if err != nil {
+				return fmt.Errorf("resolve auth config: %w", err)
+			}
+
+			metadata := configcommon.NewMetadata(envs, func(name string, v ...string) (string, error) {
+				output, err := exec.CommandContext(cmd.Context(), name, v...).Output()
+
+				return string(output), err
+			}, logger)
+
+			client, err := gradleanalytics.NewClient(consts.MultiplatformAnalyticsServiceEndpoint, authConfig.TokenInGradleFormat(), logger)
+			if err != nil {
+				return fmt.Errorf("create analytics client: %w", err)
+			}
+
+			return UploadTaskReports(reports, envs["BITRISE_INVOCATION_ID"], client, authConfig, metadata, logger)
+		},
+	}
+)
+
+func init() {
+	uploadTaskReportCmd.Flags().StringVar(&uploadTaskReportFile, "file", "", "Task report to upload. Defaults to every pending report.")
+	gradleCmd.AddCommand(uploadTaskReportCmd)
+}
+
+type taskReportAPI interface {
+	PutGradleInvocation(inv gradleanalytics.GradleInvocation) error
+	PutInvocationRelation(rel multiplatform.InvocationRelation) error
+}
+
+// UploadTaskReports sends each report as a gradle invocation, related to
+// parentInvocationID when set. Uploaded and unreadable reports are deleted;
+// reports that failed to upload stay for the next run.
+func UploadTaskReports(
+	reports []string,
+	parentInvocationID string,
+	api taskReportAPI,
+	authConfig configcommon.CacheAuthConfig,
+	metadata configcommon.CacheConfigMetadata,
+	logger log.Logger,
+) error {
+	var errs []error
+
+	for _, report := range reports {
+		if err := uploadTaskReport(report, parentInvocationID, api, authConfig, metadata, logger); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if err := os.Remove(report); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("Failed to remove uploaded task report %s: %s", report, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func uploadTaskReport(
+	report string,
+	parentInvocationID string,
+	api taskReportAPI,
+	authConfig configcommon.CacheAuthConfig,
+	metadata configcommon.CacheConfigMetadata,
+	logger log.Logger,
+) error {
+	f, err := os.Open(report) //nolint:gosec // path comes from the flag or the reports dir
+	if err != nil {
+		return fmt.Errorf("open task report %s: %w", report, err)
+	}
+	records, err := gradleanalytics.ReadTaskReport(f)
+	_ = f.Close()
+
+	if err != nil || records[0].InvocationID == "" {
+		// Retrying cannot fix a broken report — drop it instead of failing every later run.
+		logger.Warnf("Skipping unreadable task report %s: %v", report, err)
+
+		return nil
+	}
+
+	inv := gradleanalytics.NewGradleInvocation(records, parentInvocationID, authConfig, metadata)
+	if err := api.PutGradleInvocation(*inv); err != nil {
+		return fmt.Errorf("upload task report %s: %w", report, err)
+	}
+
+	logger.Infof("Uploaded task report of invocation %s: %d tasks, cache hit rate %.02f%%",
+		inv.InvocationID, inv.BuildToolStats.TotalTasks, inv.BuildToolStats.CacheHitRate*100)
+
+	if parentInvocationID == "" {
+		return nil
+	}
+
+	if err := api.PutInvocationRelation(multiplatform.InvocationRelation{
+		ParentInvocationID: parentInvocationID,
+		ChildInvocationID:  inv.InvocationID,
+		InvocationDate:     time.Now(),
+		BuildTool:          "gradle",
+	}); err != nil {
+		logger.Warnf("Failed to relate invocation %s to %s: %s", inv.InvocationID, parentInvocationID, err)
+	}
+
+	return nil
+}
+
+func pendingTaskReports(file string) ([]string, error) {
+	if file != "" {
+		return []string{file}, nil
+	}
+
+	p, err := paths.Default()
+	if err != nil {
+		return nil, fmt.Errorf("resolve home dir: %w", err)
+	}
+
+	reports, err := filepath.Glob(filepath.Join(p.GradleTaskReportsDir(), "*.ndjson"))
+	if err != nil {
+		return nil, fmt.Errorf("list task reports: %w", err)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,83 @@
+package gradle_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/gradle"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/analytics/multiplatform"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	gradleanalytics "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle/analytics"
+)
+
+type recordingTaskReportAPI struct {
+	invocations []gradleanalytics.GradleInvocation
+	relations   []multiplatform.InvocationRelation
+	putErr      error
+}
+
+func (a *recordingTaskReportAPI) PutGradleInvocation(inv gradleanalytics.GradleInvocation) error {
+	if a.putErr != nil {
+		return a.putErr
+	}
+	a.invocations = append(a.invocations, inv)
+
+	return nil
+}
+
+func (a *recordingTaskReportAPI) PutInvocationRelation(rel multiplatform.InvocationRelation) error {
+	a.relations = append(a.relations, rel)
+
+	return nil
+}
+
+func writeTaskReport(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestUploadTaskReports(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeTaskReport(t, dir, "inv-1.ndjson",
+		`{"invocationId":"inv-1","path":":app:compileJava","outcome":"FROM-CACHE","startTime":1000,"endTime":1200,"cacheable":true}`+"\n")
+	broken := writeTaskReport(t, dir, "broken.ndjson", "{not json\n")
+
+	api := &recordingTaskReportAPI{}
+	err := gradle.UploadTaskReports([]string{valid, broken}, "parent-1", api,
+		configcommon.CacheAuthConfig{}, configcommon.CacheConfigMetadata{}, log.NewLogger(log.WithOutput(io.Discard)))
+	require.NoError(t, err)
+
+	require.Len(t, api.invocations, 1)
+	assert.Equal(t, "inv-1", api.invocations[0].InvocationID)
+	assert.Equal(t, "parent-1", api.invocations[0].ParentInvocationID)
+	require.Len(t, api.relations, 1)
+	assert.Equal(t, "inv-1", api.relations[0].ChildInvocationID)
+
+	assert.NoFileExists(t, valid)
+	assert.NoFileExists(t, broken)
+}
+
+func TestUploadTaskReports_keepsReportWhenUploadFails(t *testing.T) {
+	dir := t.TempDir()
+	report := writeTaskReport(t, dir, "inv-1.ndjson",
+		`{"invocationId":"inv-1","path":":app:compileJava","outcome":"EXECUTED","startTime":1000,"endTime":1200}`+"\n")
+
+	api := &recordingTaskReportAPI{putErr: errors.New("503")}
+	err := gradle.UploadTaskReports([]string{report}, "", api,
+		configcommon.CacheAuthConfig{}, configcommon.CacheConfigMetadata{}, log.NewLogger(log.WithOutput(io.Discard)))
+
+	require.ErrorContains(t, err, "503")
+	assert.FileExists(t, report)
+	assert.Empty(t, api.relations)
+}
@@ -2,8 +2,10 @@ package xcode
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
@@ -47,6 +49,13 @@ This command will:
 		logger.TInfof(activateXcode)
 
 		activateXcodeParams.DebugLogging = common.DebugEnabled(activateXcodeParams.DebugLogging)
+		if activateXcodeLocalCacheSize != "" {
+			size, err := humanize.ParseBytes(activateXcodeLocalCacheSize)
+			if err != nil {
+				return fmt.Errorf("invalid --local-cache-size %q: %w", activateXcodeLocalCacheSize, err)
+			}
+			activateXcodeParams.LocalCacheMaxBytes = int64(size) //nolint:gosec
+		}
 		logger.Infof("Activate Xcode params: %+v", activateXcodeParams)
 
 		if err := xcelerate.Activate(
@@ -71,7 +80,10 @@ This command will:
 }
 
 //nolint:gochecknoglobals
-var activateXcodeParams = xcelerate.DefaultParams()
+var (
+	activateXcodeParams         = xcelerate.DefaultParams()
+	activateXcodeLocalCacheSize string
+)
 
 func init() {
 	common.ActivateCmd.AddCommand(activateXcodeCmd)
@@ -125,6 +137,15 @@ Cache will have to be enabled manually in the Xcode project settings.`)
 		"disable-prefix-mapping",
 		activateXcodeParams.DisablePrefixMapping,
 		`Disable injecting Clang prefix-mapping flags into xcodebuild. Prefix mapping canonicalizes rotating source/DerivedData paths so compilation cache keys stay stable; disable only if it causes issues.`)
+	activateXcodeCmd.Flags().StringVar(&activateXcodeLocalCacheSize,
+		"local-cache-size",
+		"",
+		`Enable the proxy's local on-disk cache tier with the given size limit (e.g. 10GB).
+Hits are served from disk before reaching the remote cache; least recently used entries are evicted first.`)
+	activateXcodeCmd.Flags().StringVar(&activateXcodeParams.LocalCacheDir,
+		"local-cache-dir",
+		activateXcodeParams.LocalCacheDir,
+		"Directory of the local cache tier. Defaults to ~/.bitrise/cache/xcelerate/local-blobs.")
 }
 
 // ActivateXcodeCommandFn is a backward-compatible wrapper around xcelerate.Activate.
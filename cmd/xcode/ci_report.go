@@ -0,0 +1,41 @@
+package xcode
+
+import (
+	"time"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/envexport"
+	xa "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/analytics"
+)
+
+// cacheOperationReport builds the CI report of a DerivedData save or restore.
+// op may be nil when the command failed before the operation started.
+func cacheOperationReport(title, outputPrefix string, isRestore bool, op *xa.CacheOperation, cmdError error) envexport.CacheReport {
+	report := envexport.CacheReport{
+		Title:        title,
+		OutputPrefix: outputPrefix,
+		IsRestore:    isRestore,
+		HitRate:      -1,
+		Err:          cmdError,
+	}
+
+	if op == nil {
+		return report
+	}
+
+	report.CacheKey = op.CacheKey
+	if op.CacheKeyType != nil {
+		report.CacheKeyType = *op.CacheKeyType
+	}
+	report.TransferBytes = op.TransferSize
+	report.Duration = time.Duration(op.DurationMilliseconds) * time.Millisecond
+
+	if isRestore {
+		report.CacheHit = cmdError == nil
+		if total := op.FileStats.TotalFiles; total > 0 {
+			served := total - op.FileStats.FilesMissing - op.FileStats.FilesFailed
+			report.HitRate = float64(max(served, 0)) / float64(total)
+		}
+	}
+
+	return report
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
@@ -38,8 +39,9 @@ var deleteXcodeDerivedDataCmd = &cobra.Command{
 		logger.Infof("(i) Checking parameters")
 		cacheKey, _ := cmd.Flags().GetString("key")
 		empty, _ := cmd.Flags().GetBool("empty")
+		keyTemplates := common.CacheKeyTemplatesFromFlags(cmd)
 
-		if err := deleteXcodeDerivedDataCmdFn(cmd.Context(), cacheKey, empty, logger,
+		if err := deleteXcodeDerivedDataCmdFn(cmd.Context(), cacheKey, empty, keyTemplates, logger,
 			utils.AllEnvs(),
 			func(name string, v ...string) (string, error) {
 				output, err := exec.Command(name, v...).Output()
@@ -60,11 +62,13 @@ func init() {
 
 	deleteXcodeDerivedDataCmd.Flags().String("key", "", "The cache key to be delete (set to the Bitrise app's slug and current git branch by default)")
 	deleteXcodeDerivedDataCmd.Flags().Bool("empty", false, "If true, upload an empty metadata")
+	common.AddCacheKeyTemplateFlags(deleteXcodeDerivedDataCmd, deriveddata.DefaultCacheKeyTemplate, deriveddata.DefaultFallbackCacheKeyTemplate)
 }
 
 func deleteXcodeDerivedDataCmdFn(ctx context.Context,
 	providedCacheKey string,
 	uploadEmpty bool,
+	keyTemplates cachekey.Templates,
 	logger log.Logger,
 	envProvider map[string]string,
 	commandFunc func(string, ...string) (string, error),
@@ -75,16 +79,16 @@ func deleteXcodeDerivedDataCmdFn(ctx context.Context,
 		return fmt.Errorf("resolve auth config: %w", err)
 	}
 
-	var cacheKey string
+	var keys cachekey.Keys
 	if providedCacheKey == "" {
-		logger.Infof("(i) Cache key is not explicitly specified, setting it based on the current Bitrise app's slug and git branch...")
-		if cacheKey, err = deriveddata.GetCacheKey(envProvider, deriveddata.CacheKeyParams{}); err != nil {
+		logger.Infof("(i) Cache key is not explicitly specified, rendering it from the key template...")
+		if keys, err = deriveddata.GetCacheKeys(envProvider, deriveddata.CacheKeyParams{Templates: keyTemplates, CommandFunc: commandFunc}); err != nil {
 			return fmt.Errorf("get cache key: %w", err)
 		}
 	} else {
-		cacheKey = providedCacheKey
+		keys.Primary = providedCacheKey
 	}
-	logger.Infof("(i) Cache key: %s", cacheKey)
+	logger.Infof("(i) Cache key: %s", keys.Primary)
 
 	kvClient, err := common.CreateKVClient(ctx,
 		common.CreateKVClientParams{
@@ -100,13 +104,15 @@ func deleteXcodeDerivedDataCmdFn(ctx context.Context,
 	}
 
 	if !uploadEmpty {
-		return deleteCacheKey(ctx, providedCacheKey, cacheKey, envProvider, kvClient, logger)
+		return deleteCacheKeys(ctx, keys, kvClient, logger)
 	}
 
-	return uploadEmptyMetadata(ctx, providedCacheKey, cacheKey, envProvider, kvClient, logger)
+	return uploadEmptyMetadata(ctx, keys, envProvider, kvClient, logger)
 }
 
-func uploadEmptyMetadata(ctx context.Context, providedCacheKey, cacheKey string, envProvider map[string]string, client *kv.Client, logger log.Logger) error {
+func uploadEmptyMetadata(ctx context.Context, keys cachekey.Keys, envProvider map[string]string, client *kv.Client, logger log.Logger) error {
+	cacheKey := keys.Primary
+
 	logger.TInfof("Saving empty metadata file %s", XCodeCacheMetadataPath)
 	_, err := deriveddata.SaveMetadata(&deriveddata.Metadata{
 		ProjectFiles:         filegroup.Info{},
@@ -141,50 +147,34 @@ func uploadEmptyMetadata(ctx context.Context, providedCacheKey, cacheKey string,
 		return fmt.Errorf("upload metadata content to build cache: %w", err)
 	}
 
-	if providedCacheKey == "" {
-		fallbackCacheKey, err := deriveddata.GetCacheKey(envProvider, deriveddata.CacheKeyParams{IsFallback: true})
-		if err != nil {
-			logger.Warnf("Failed to get fallback cache key: %s", err)
-		} else if fallbackCacheKey != "" && cacheKey != fallbackCacheKey {
-			cacheKey = fallbackCacheKey
-			mdChecksumReader = strings.NewReader(mdChecksum) // reset reader
-			logger.TInfof("Uploading metadata checksum of %s (%s) for fallback key %s", XCodeCacheMetadataPath, mdChecksum, cacheKey)
-			if err := client.UploadStreamToBuildCache(ctx, mdChecksumReader, cacheKey, mdChecksumReader.Size()); err != nil {
-				return fmt.Errorf("upload metadata checksum to build cache: %w", err)
-			}
+	for _, fallbackCacheKey := range keys.Fallbacks {
+		mdChecksumReader = strings.NewReader(mdChecksum) // reset reader
+		logger.TInfof("Uploading metadata checksum of %s (%s) for fallback key %s", XCodeCacheMetadataPath, mdChecksum, fallbackCacheKey)
+		if err := client.UploadStreamToBuildCache(ctx, mdChecksumReader, fallbackCacheKey, mdChecksumReader.Size()); err != nil {
+			return fmt.Errorf("upload metadata checksum to build cache: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func deleteCacheKey(ctx context.Context, providedCacheKey, cacheKey string, envProvider map[string]string, client *kv.Client, logger log.Logger) error {
-	logger.TInfof("Deleting cache key %s", cacheKey)
-	err := client.Delete(ctx, cacheKey)
-	if err != nil {
-		st, ok := status.FromError(err)
-		if !ok || st.Code() != codes.NotFound {
-			return fmt.Errorf("delete cache key: %w", err)
-		}
-	}
-
-	if providedCacheKey != "" {
-		return nil
+func deleteCacheKeys(ctx context.Context, keys cachekey.Keys, client *kv.Client, logger log.Logger) error {
+	logger.TInfof("Deleting cache key %s", keys.Primary)
+	if err := deleteCacheKey(ctx, keys.Primary, client); err != nil {
+		return err
 	}
 
-	fallbackCacheKey, err := deriveddata.GetCacheKey(envProvider, deriveddata.CacheKeyParams{IsFallback: true})
-	if err != nil {
-		logger.Warnf("Failed to get fallback cache key: %s", err)
-
-		return nil
+	for _, fallbackCacheKey := range keys.Fallbacks {
+		logger.TInfof("Deleting fallback cache key %s", fallbackCacheKey)
+		if err := deleteCacheKey(ctx, fallbackCacheKey, client); err != nil {
+			return err
+		}
 	}
 
-	if fallbackCacheKey == "" || cacheKey == fallbackCacheKey {
-		return nil
-	}
+	return nil
+}
 
-	cacheKey = fallbackCacheKey
-	logger.TInfof("Deleting fallback cache key %s", cacheKey)
+func deleteCacheKey(ctx context.Context, cacheKey string, client *kv.Client) error {
 	if err := client.Delete(ctx, cacheKey); err != nil {
 		st, ok := status.FromError(err)
 		if !ok || st.Code() != codes.NotFound {
@@ -13,8 +13,10 @@ import (
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/envexport"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 	xa "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/analytics"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/deriveddata"
@@ -40,10 +42,17 @@ var restoreXcodeDerivedDataFilesCmd = &cobra.Command{
 		cacheKey, _ := cmd.Flags().GetString("key")
 		forceOverwrite, _ := cmd.Flags().GetBool("force-overwrite-files")
 		skipExisting, _ := cmd.Flags().GetBool("skip-existing-files")
+		incremental, _ := cmd.Flags().GetBool("incremental")
 		maxLoggedErrors, _ := cmd.Flags().GetInt("max-logged-errors")
+		keyTemplates := common.CacheKeyTemplatesFromFlags(cmd)
+		transfer, err := common.TransferOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		logger.Infof("(i) Skip existing files: %t", skipExisting)
 		logger.Infof("(i) Force overwrite existing files: %t", forceOverwrite)
+		logger.Infof("(i) Incremental restore: %t", incremental)
 
 		allEnvs := utils.AllEnvs()
 		tracker := deriveddata.NewDefaultStepTracker("restore-xcode-build-cache", allEnvs, logger)
@@ -61,6 +70,8 @@ var restoreXcodeDerivedDataFilesCmd = &cobra.Command{
 			XCodeCacheMetadataPath,
 			projectRoot,
 			cacheKey,
+			keyTemplates,
+			transfer,
 			logger,
 			tracker,
 			startT,
@@ -73,11 +84,11 @@ var restoreXcodeDerivedDataFilesCmd = &cobra.Command{
 			common.IsDebugLogMode,
 			skipExisting,
 			forceOverwrite,
+			incremental,
 			maxLoggedErrors)
 		if op != nil {
 			if cmdError != nil {
-				errStr := cmdError.Error()
-				op.Error = &errStr
+				op.FillWithError(cmdError)
 			}
 
 			op.DurationMilliseconds = int(time.Since(op.StartedAt).Milliseconds())
@@ -92,6 +103,9 @@ var restoreXcodeDerivedDataFilesCmd = &cobra.Command{
 			}
 		}
 
+		envexport.New(allEnvs, logger).PublishCacheReport(
+			cacheOperationReport("Xcode DerivedData restore", "BITRISE_DERIVEDDATA_RESTORE", true, op, cmdError))
+
 		tracker.LogRestoreFinished(time.Since(startT), cmdError)
 		if cmdError != nil {
 			return fmt.Errorf("restore Xcode DerivedData from Bitrise Build Cache: %w", cmdError)
@@ -107,24 +121,29 @@ func init() {
 	common.RootCmd.AddCommand(restoreXcodeDerivedDataFilesCmd)
 
 	restoreXcodeDerivedDataFilesCmd.Flags().String("key", "", "The cache key to use for the saved cache item (set to the Bitrise app's slug and current git branch by default)")
+	common.AddCacheKeyTemplateFlags(restoreXcodeDerivedDataFilesCmd, deriveddata.DefaultCacheKeyTemplate, deriveddata.DefaultFallbackCacheKeyTemplate)
 	restoreXcodeDerivedDataFilesCmd.Flags().String("project-root", "", "Path to the iOS project folder to be built (this is used when restoring the modification time of the source files)")
 	if err := restoreXcodeDerivedDataFilesCmd.MarkFlagRequired("project-root"); err != nil {
 		panic(err)
 	}
 	restoreXcodeDerivedDataFilesCmd.Flags().Bool("force-overwrite-files", false, "If set, the command will try to overwrite existing files during restoring the cache even if the permissions do not allow it")
 	restoreXcodeDerivedDataFilesCmd.Flags().Bool("skip-existing-files", false, "If set, existing files will be skipped and not be overwritten during restoring the cache")
+	restoreXcodeDerivedDataFilesCmd.Flags().Bool("incremental", false, "If set, only DerivedData files that differ from the ones on disk (size, modification time, digest) are downloaded and files not in the cache are removed. Overrides skip-existing-files for DerivedData")
+	common.AddTransferFlags(restoreXcodeDerivedDataFilesCmd)
 	restoreXcodeDerivedDataFilesCmd.Flags().Int("max-logged-errors", 150, "The maximum number of errors logged to the console during restoring the cache.")
 }
 
 func restoreXcodeDerivedDataFilesCmdFn(ctx context.Context,
 	authConfig configcommon.CacheAuthConfig,
 	cacheMetadataPath, projectRoot, providedCacheKey string,
+	keyTemplates cachekey.Templates,
+	transfer kv.TransferOptions,
 	logger log.Logger,
 	tracker deriveddata.StepAnalyticsTracker,
 	startT time.Time,
 	envs map[string]string,
 	commandFunc func(string, ...string) (string, error),
-	isDebugLogMode, skipExisting, forceOverwrite bool,
+	isDebugLogMode, skipExisting, forceOverwrite, incremental bool,
 	maxLoggedDownloadErrors int,
 ) (*xa.CacheOperation, error) {
 	commonMetadata := configcommon.NewMetadata(envs, commandFunc, logger)
@@ -138,13 +157,15 @@ func restoreXcodeDerivedDataFilesCmdFn(ctx context.Context,
 			Envs:             envs,
 			CommandFunc:      commandFunc,
 			Logger:           logger,
+			Transfer:         transfer,
 		})
 	if err != nil {
 		return nil, fmt.Errorf("create kv client: %w", err)
 	}
 	logger.Infof("(i) Cache operation ID: %s", op.OperationID)
 
-	cacheKeyType, cacheKey, err := downloadXcodeMetadata(ctx, cacheMetadataPath, providedCacheKey, kvClient, logger, envs)
+	cacheKeyType, cacheKey, err := downloadXcodeMetadata(ctx, cacheMetadataPath, providedCacheKey,
+		deriveddata.CacheKeyParams{Templates: keyTemplates, CommandFunc: commandFunc}, kvClient, logger, envs)
 	op.CacheKey = cacheKey
 	if err != nil {
 		return op, fmt.Errorf("download cache metadata: %w", err)
@@ -176,8 +197,18 @@ func restoreXcodeDerivedDataFilesCmdFn(ctx context.Context,
 	metadataRestoredT := time.Now()
 	tracker.LogMetadataLoaded(metadataRestoredT.Sub(startT), string(cacheKeyType), len(metadata.ProjectFiles.Files)+len(metadata.ProjectFiles.Directories), filesUpdated, metadataSize)
 
+	derivedData := metadata.DerivedData
+	ddSkipExisting := skipExisting
+	var incrementalStats deriveddata.IncrementalRestoreStats
+	if incremental {
+		logger.TInfof("Comparing DerivedData files with the ones on disk")
+		derivedData, incrementalStats.Skipped = deriveddata.PlanIncrementalRestore(metadata.DerivedData, logger)
+		// Files left to download differ from the cached ones, so they have to be overwritten
+		ddSkipExisting = false
+	}
+
 	logger.TInfof("Downloading DerivedData files")
-	stats, err := kvClient.DownloadFileGroupFromBuildCache(ctx, metadata.DerivedData, isDebugLogMode, skipExisting, forceOverwrite, maxLoggedDownloadErrors)
+	stats, err := kvClient.DownloadFileGroupFromBuildCache(ctx, derivedData, isDebugLogMode, ddSkipExisting, forceOverwrite, maxLoggedDownloadErrors)
 	ddDownloadedT := time.Now()
 	tracker.LogDerivedDataDownloaded(ddDownloadedT.Sub(metadataRestoredT), stats)
 	op.FillWithDownloadStats(stats)
@@ -189,6 +220,15 @@ func restoreXcodeDerivedDataFilesCmdFn(ctx context.Context,
 		return op, fmt.Errorf("download DerivedData files: %w", err)
 	}
 
+	if incremental {
+		logger.TInfof("Removing stale DerivedData files")
+		incrementalStats.Restored = stats.FilesDownloaded
+		incrementalStats.Removed = deriveddata.RemoveStaleFiles(metadata.DerivedData, logger)
+		logger.Infof("(i) Incremental restore: %d files restored, %d skipped, %d removed",
+			incrementalStats.Restored, incrementalStats.Skipped, incrementalStats.Removed)
+		tracker.LogIncrementalRestore(time.Since(metadataRestoredT), incrementalStats)
+	}
+
 	logger.TInfof("Restoring DerivedData directory metadata")
 	if err := deriveddata.RestoreDirectoryInfos(metadata.DerivedData.Directories, "", logger); err != nil {
 		return op, fmt.Errorf("restore DerivedData directories: %w", err)
@@ -231,23 +271,26 @@ func restoreXcodeDerivedDataFilesCmdFn(ctx context.Context,
 }
 
 func downloadXcodeMetadata(ctx context.Context, cacheMetadataPath, providedCacheKey string,
+	keyParams deriveddata.CacheKeyParams,
 	kvClient *kv.Client,
 	logger log.Logger,
 	envs map[string]string,
 ) (common.CacheKeyType, string, error) {
 	var cacheKeyType common.CacheKeyType = common.CacheKeyTypeDefault
-	var cacheKey string
-	var err error
+	// Fallback keys are tried even for a provided key, so the error is only
+	// fatal when the primary key is needed.
+	keys, err := deriveddata.GetCacheKeys(envs, keyParams)
 	if providedCacheKey == "" {
-		if cacheKey, err = deriveddata.GetCacheKey(envs, deriveddata.CacheKeyParams{}); err != nil {
+		if err != nil {
 			return "", "", fmt.Errorf("get cache key: %w", err)
 		}
-		logger.TInfof("Downloading cache metadata checksum for key %s", cacheKey)
+		logger.TInfof("Downloading cache metadata checksum for key %s", keys.Primary)
 	} else {
 		cacheKeyType = common.CacheKeyTypeProvided
-		cacheKey = providedCacheKey
-		logger.TInfof("Downloading cache metadata checksum for provided key %s", cacheKey)
+		keys.Primary = providedCacheKey
+		logger.TInfof("Downloading cache metadata checksum for provided key %s", keys.Primary)
 	}
+	cacheKey := keys.Primary
 
 	var mdChecksum strings.Builder
 	err = kvClient.DownloadStreamFromBuildCache(ctx, &mdChecksum, cacheKey)
@@ -257,22 +300,29 @@ func downloadXcodeMetadata(ctx context.Context, cacheMetadataPath, providedCache
 
 	if errors.Is(err, kv.ErrCacheNotFound) {
 		cacheKeyType = common.CacheKeyTypeFallback
-		fallbackCacheKey, fallbackErr := deriveddata.GetCacheKey(envs, deriveddata.CacheKeyParams{IsFallback: true})
-		if fallbackErr != nil {
-			return cacheKeyType, fallbackCacheKey, errors.New("cache metadata not found in cache")
-		}
+		found := false
+		for _, fallbackCacheKey := range keys.Fallbacks {
+			cacheKey = fallbackCacheKey
+			logger.Infof("Cache metadata not found, trying fallback key %s", cacheKey)
+
+			mdChecksum.Reset()
+			err = kvClient.DownloadStreamFromBuildCache(ctx, &mdChecksum, cacheKey)
+			if errors.Is(err, kv.ErrCacheNotFound) {
+				continue
+			}
+			if err != nil {
+				return cacheKeyType, cacheKey, fmt.Errorf("download cache metadata checksum: %w", err)
+			}
 
-		cacheKey = fallbackCacheKey
-		logger.Infof("Cache metadata not found for original key, trying fallback key %s", cacheKey)
+			logger.Infof("Cache metadata found for fallback key %s", cacheKey)
+			found = true
 
-		err = kvClient.DownloadStreamFromBuildCache(ctx, &mdChecksum, cacheKey)
-		if errors.Is(err, kv.ErrCacheNotFound) {
-			return cacheKeyType, cacheKey, errors.New("cache metadata not found in cache")
+			break
 		}
-		if err != nil {
-			return cacheKeyType, cacheKey, fmt.Errorf("download cache metadata checksum: %w", err)
+
+		if !found {
+			return cacheKeyType, cacheKey, errors.New("cache metadata not found in cache")
 		}
-		logger.Infof("Cache metadata found for fallback key %s", cacheKey)
 	}
 
 	logger.TInfof("Downloading cache metadata content to %s for key %s", cacheMetadataPath, mdChecksum.String())
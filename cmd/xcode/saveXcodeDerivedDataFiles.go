@@ -12,8 +12,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/envexport"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 	xa "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/analytics"
@@ -44,6 +47,12 @@ var saveXcodeDerivedDataFilesCmd = &cobra.Command{
 		xcodeCachePath, _ := cmd.Flags().GetString("xcodecache-path")
 		followSymlinks, _ := cmd.Flags().GetBool("follow-symlinks")
 		skipSPM, _ := cmd.Flags().GetBool("skip-spm")
+		chunkLargeFiles, _ := cmd.Flags().GetBool("chunk-large-files")
+		keyTemplates := common.CacheKeyTemplatesFromFlags(cmd)
+		transfer, err := common.TransferOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		tracker := deriveddata.NewDefaultStepTracker("save-xcode-build-cache", utils.AllEnvs(), logger)
 		defer tracker.Wait()
@@ -65,6 +74,9 @@ var saveXcodeDerivedDataFilesCmd = &cobra.Command{
 			xcodeCachePath,
 			followSymlinks,
 			skipSPM,
+			chunkLargeFiles,
+			keyTemplates,
+			transfer,
 			logger,
 			tracker,
 			startT,
@@ -76,8 +88,7 @@ var saveXcodeDerivedDataFilesCmd = &cobra.Command{
 			})
 		if op != nil {
 			if cmdError != nil {
-				errStr := cmdError.Error()
-				op.Error = &errStr
+				op.FillWithError(cmdError)
 			}
 
 			op.DurationMilliseconds = int(time.Since(op.StartedAt).Milliseconds())
@@ -92,6 +103,9 @@ var saveXcodeDerivedDataFilesCmd = &cobra.Command{
 			}
 		}
 
+		envexport.New(allEnvs, logger).PublishCacheReport(
+			cacheOperationReport("Xcode DerivedData save", "BITRISE_DERIVEDDATA_SAVE", false, op, cmdError))
+
 		tracker.LogSaveFinished(time.Since(startT), cmdError)
 		if cmdError != nil {
 			return fmt.Errorf("save Xcode cache into Bitrise Build Cache: %w", cmdError)
@@ -107,6 +121,7 @@ func init() {
 	common.RootCmd.AddCommand(saveXcodeDerivedDataFilesCmd)
 
 	saveXcodeDerivedDataFilesCmd.Flags().String("key", "", "The cache key to use for the saved cache item (set to the Bitrise app's slug and current git branch by default)")
+	common.AddCacheKeyTemplateFlags(saveXcodeDerivedDataFilesCmd, deriveddata.DefaultCacheKeyTemplate, deriveddata.DefaultFallbackCacheKeyTemplate)
 	saveXcodeDerivedDataFilesCmd.Flags().String("project-root", "", "Path to the iOS project folder to be built (this is used when saving the modification time of the source files)")
 	if err := saveXcodeDerivedDataFilesCmd.MarkFlagRequired("project-root"); err != nil {
 		panic(err)
@@ -119,6 +134,8 @@ func init() {
 	saveXcodeDerivedDataFilesCmd.Flags().String("xcodecache-path", "", "Path to the Xcode cache directory folder to be saved. If not set, it will not be uploaded.")
 	saveXcodeDerivedDataFilesCmd.Flags().Bool("follow-symlinks", false, "Follow symlinks when calculating metadata and save referenced files to the cache (default: false)")
 	saveXcodeDerivedDataFilesCmd.Flags().Bool("skip-spm", false, "Skip saving files under \"DerivedData/*/SourcePackages\", i.e. skip SPM dependencies. Consider enabling this flag if using SPM cache steps. Default: false")
+	common.AddTransferFlags(saveXcodeDerivedDataFilesCmd)
+	saveXcodeDerivedDataFilesCmd.Flags().Bool("chunk-large-files", false, "Store large files as content-defined chunks, so only the changed parts of a file are uploaded. Caches saved this way can't be restored by older CLI versions. Default: false")
 }
 
 func SaveXcodeDerivedDataFilesCmdFn(ctx context.Context,
@@ -130,6 +147,9 @@ func SaveXcodeDerivedDataFilesCmdFn(ctx context.Context,
 	xcodeCachePath string,
 	followSymlinks bool,
 	skipSPM bool,
+	chunkLargeFiles bool,
+	keyTemplates cachekey.Templates,
+	transfer kv.TransferOptions,
 	logger log.Logger,
 	tracker deriveddata.StepAnalyticsTracker,
 	startT time.Time,
@@ -137,15 +157,16 @@ func SaveXcodeDerivedDataFilesCmdFn(ctx context.Context,
 	commandFunc func(string, ...string) (string, error),
 ) (*xa.CacheOperation, error) {
 	var err error
-	var cacheKey string
+	var keys cachekey.Keys
 	if providedCacheKey == "" {
-		logger.Infof("(i) Cache key is not explicitly specified, setting it based on the current Bitrise app's slug and git branch...")
-		if cacheKey, err = deriveddata.GetCacheKey(envs, deriveddata.CacheKeyParams{}); err != nil {
+		logger.Infof("(i) Cache key is not explicitly specified, rendering it from the key template...")
+		if keys, err = deriveddata.GetCacheKeys(envs, deriveddata.CacheKeyParams{Templates: keyTemplates, CommandFunc: commandFunc}); err != nil {
 			return nil, fmt.Errorf("get cache key: %w", err)
 		}
 	} else {
-		cacheKey = providedCacheKey
+		keys.Primary = providedCacheKey
 	}
+	cacheKey := keys.Primary
 	logger.Infof("(i) Cache key: %s", cacheKey)
 
 	commonMetadata := configcommon.NewMetadata(envs, commandFunc, logger)
@@ -162,6 +183,7 @@ func SaveXcodeDerivedDataFilesCmdFn(ctx context.Context,
 			Envs:             envs,
 			CommandFunc:      commandFunc,
 			Logger:           logger,
+			Transfer:         transfer,
 		})
 	if err != nil {
 		return op, fmt.Errorf("create kv client: %w", err)
@@ -184,6 +206,7 @@ func SaveXcodeDerivedDataFilesCmdFn(ctx context.Context,
 		CacheKey:           cacheKey,
 		FollowSymlinks:     followSymlinks,
 		SkipSPM:            skipSPM,
+		ChunkLargeFiles:    chunkLargeFiles,
 	}, envs, logger)
 	if err != nil {
 		return op, fmt.Errorf("create metadata: %w", err)
@@ -231,17 +254,11 @@ func SaveXcodeDerivedDataFilesCmdFn(ctx context.Context,
 		return op, fmt.Errorf("upload metadata content to build cache: %w", err)
 	}
 
-	if providedCacheKey == "" {
-		fallbackCacheKey, err := deriveddata.GetCacheKey(envs, deriveddata.CacheKeyParams{IsFallback: true})
-		if err != nil {
-			logger.Warnf("Failed to get fallback cache key: %s", err)
-		} else if fallbackCacheKey != "" && cacheKey != fallbackCacheKey {
-			cacheKey = fallbackCacheKey
-			mdChecksumReader = strings.NewReader(mdChecksum) // reset reader
-			logger.TInfof("Uploading metadata checksum of %s (%s) for fallback key %s", cacheMetadataPath, mdChecksum, cacheKey)
-			if err := kvClient.UploadStreamToBuildCache(ctx, mdChecksumReader, cacheKey, mdChecksumReader.Size()); err != nil {
-				return op, fmt.Errorf("upload metadata checksum to build cache: %w", err)
-			}
+	for _, fallbackCacheKey := range keys.Fallbacks {
+		mdChecksumReader = strings.NewReader(mdChecksum) // reset reader
+		logger.TInfof("Uploading metadata checksum of %s (%s) for fallback key %s", cacheMetadataPath, mdChecksum, fallbackCacheKey)
+		if err := kvClient.UploadStreamToBuildCache(ctx, mdChecksumReader, fallbackCacheKey, mdChecksumReader.Size()); err != nil {
+			return op, fmt.Errorf("upload metadata checksum to build cache: %w", err)
 		}
 	}
 
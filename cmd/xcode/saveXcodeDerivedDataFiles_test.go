@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/xcode"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	xcodeMocks "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/deriveddata/mocks"
 )
@@ -33,6 +35,9 @@ func Test_saveXcodeDerivedDataFilesCmdFn(t *testing.T) {
 			"",
 			false,
 			false,
+			false,
+			cachekey.Templates{},
+			kv.TransferOptions{},
 			mockLogger,
 			mockTracker,
 			time.Now(),
@@ -41,6 +46,6 @@ func Test_saveXcodeDerivedDataFilesCmdFn(t *testing.T) {
 		)
 
 		// then
-		require.EqualError(t, err, "get cache key: cache key is required if BITRISE_GIT_BRANCH env var is not set")
+		require.EqualError(t, err, "get cache key: cache key is required if BITRISE_APP_SLUG env var is not set")
 	})
 }
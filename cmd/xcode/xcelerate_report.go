@@ -0,0 +1,131 @@
+package xcode
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/invocations"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/journal"
+)
+
+// previousInvocationLookback is the number of local invocation log records
+// searched for the previous invocation of the same scheme.
+const previousInvocationLookback = 500
+
+//nolint:gochecknoglobals
+var reportFlags struct {
+	invocationID string
+	top          int
+	json         bool
+}
+
+//nolint:gochecknoglobals
+var xcelerateReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Explain the cache hits and misses of an xcelerate invocation",
+	Long: `report reads the key-level journal the proxy recorded for --invocation and lists ` +
+		`the most missed keys, the largest downloads and the slowest calls. When the local ` +
+		`invocation log has an earlier invocation of the same command and scheme with a ` +
+		`journal, the report also lists the keys that hit then but missed now.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if reportFlags.top < 0 {
+			return fmt.Errorf("invalid --top %d: must not be negative", reportFlags.top)
+		}
+
+		p, err := paths.Default()
+		if err != nil {
+			return fmt.Errorf("resolve paths: %w", err)
+		}
+
+		report, err := buildInvocationReport(p, reportFlags.invocationID, reportFlags.top)
+		if err != nil {
+			return err
+		}
+
+		if reportFlags.json {
+			return journal.WriteJSON(cmd.OutOrStdout(), report) //nolint:wrapcheck
+		}
+
+		return journal.WriteText(cmd.OutOrStdout(), report) //nolint:wrapcheck
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	xcelerateReportCmd.Flags().StringVar(&reportFlags.invocationID, "invocation", "", "The invocation ID to report on (required)")
+	xcelerateReportCmd.Flags().IntVar(&reportFlags.top, "top", 10, "Number of entries listed per ranking.")
+	xcelerateReportCmd.Flags().BoolVar(&reportFlags.json, "json", false, "Emit the report as JSON instead of text.")
+	_ = xcelerateReportCmd.MarkFlagRequired("invocation")
+
+	xcelerateCommand.AddCommand(xcelerateReportCmd)
+}
+
+func buildInvocationReport(p paths.Paths, invocationID string, top int) (journal.Report, error) {
+	dir := p.XcelerateJournalDir()
+
+	events, err := journal.Read(journal.Path(dir, invocationID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return journal.Report{}, fmt.Errorf("no journal found for invocation %s in %s", invocationID, dir)
+	}
+	if err != nil {
+		return journal.Report{}, fmt.Errorf("read journal: %w", err)
+	}
+
+	report := journal.NewReport(invocationID, events, top)
+
+	records, err := invocations.NewReader(p).Recent(previousInvocationLookback)
+	if err != nil {
+		return report, fmt.Errorf("read invocations: %w", err)
+	}
+
+	previousID := previousInvocationOfScheme(records, invocationID, func(id string) bool {
+		_, err := os.Stat(journal.Path(dir, id))
+
+		return err == nil
+	})
+	if previousID == "" {
+		return report, nil
+	}
+
+	previous, err := journal.Read(journal.Path(dir, previousID))
+	if err != nil {
+		return report, fmt.Errorf("read journal of previous invocation: %w", err)
+	}
+	comparison := journal.Compare(events, previousID, previous, top)
+	report.Previous = &comparison
+
+	return report, nil
+}
+
+// previousInvocationOfScheme returns the latest xcode invocation before
+// invocationID that ran the same short command, which carries the scheme, and
+// has a journal. records are oldest first. It returns "" when there is none.
+func previousInvocationOfScheme(records []invocations.Record, invocationID string, hasJournal func(string) bool) string {
+	current := -1
+	for i, rec := range records {
+		if rec.InvocationID == invocationID {
+			current = i
+		}
+	}
+	if current < 0 {
+		return ""
+	}
+
+	for i := current - 1; i >= 0; i-- {
+		rec := records[i]
+		if rec.Tool != invocations.ToolXcode || rec.Command != records[current].Command {
+			continue
+		}
+		if hasJournal(rec.InvocationID) {
+			return rec.InvocationID
+		}
+	}
+
+	return ""
+}
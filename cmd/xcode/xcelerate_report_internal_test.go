@@ -0,0 +1,28 @@
+//go:build unit
+
+package xcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/invocations"
+)
+
+func Test_previousInvocationOfScheme(t *testing.T) {
+	records := []invocations.Record{
+		{InvocationID: "a", Tool: invocations.ToolXcode, Command: "build [App]"},
+		{InvocationID: "b", Tool: invocations.ToolXcode, Command: "build [App]"},
+		{InvocationID: "c", Tool: invocations.ToolXcode, Command: "test [AppTests]"},
+		{InvocationID: "d", Tool: invocations.ToolGradle, Command: "build [App]"},
+		{InvocationID: "e", Tool: invocations.ToolXcode, Command: "build [App]"},
+	}
+	all := func(string) bool { return true }
+
+	assert.Equal(t, "b", previousInvocationOfScheme(records, "e", all))
+	assert.Equal(t, "a", previousInvocationOfScheme(records, "e", func(id string) bool { return id != "b" }))
+	assert.Empty(t, previousInvocationOfScheme(records, "c", all))
+	assert.Empty(t, previousInvocationOfScheme(records, "a", all))
+	assert.Empty(t, previousInvocationOfScheme(records, "unknown", all))
+}
@@ -13,13 +13,17 @@ import (
 	"time"
 
 	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/dustin/go-humanize"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/proxypid"
@@ -165,15 +169,7 @@ func StartXcodeCacheProxy(
 ) error {
 	oauthCfg := oauth.NewConfigFromEnv(envProvider)
 	oauthCfg.Logger = initialLogger
-	refreshFn := func(ctx context.Context) (string, string, error) {
-		creds, err := oauthCfg.EnsureFresh(ctx)
-		if err != nil {
-			return "", "", fmt.Errorf("ensure fresh oauth credentials: %w", err)
-		}
-
-		return creds.PAT, creds.WorkspaceID, nil
-	}
-	authProvider := configcommon.NewExpiryAwareResolver(context.WithoutCancel(ctx), envProvider, refreshFn, initialLogger)
+	authProvider := credhelper.NewAuthSource(context.WithoutCancel(ctx), envProvider, oauthCfg, initialLogger)
 
 	client, err := common.CreateKVClient(ctx, common.CreateKVClientParams{
 		CacheOperationID:   uuid.New().String(),
@@ -185,9 +181,11 @@ func StartXcodeCacheProxy(
 		Logger:             initialLogger,
 		BitriseKVClient:    bitriseKVClient,
 		EndpointURL:        config.BuildCacheEndpoint,
+		Endpoints:          config.BuildCacheEndpoints,
 		CapabilitiesClient: capabilitiesClient,
 		InvocationID:       initialInvocationID,
 		SkipCapabilities:   true, // proxy handles capabilities calls internally
+		CircuitBreaker:     kv.NewCircuitBreaker(kv.CircuitBreakerParams{Logger: initialLogger}),
 	})
 	if err != nil {
 		return fmt.Errorf("create kv client: %w", err)
@@ -203,8 +201,28 @@ func StartXcodeCacheProxy(
 
 	emitter := bundle.emitter()
 
-	p := proxy.NewProxy(client, config.PushEnabled, initialLogger, loggerFactory, emitter)
+	var proxyClient proxy.Client = client
+	if config.LocalCacheMaxBytes > 0 {
+		store, err := localcache.Open(config.LocalCacheDir, config.LocalCacheMaxBytes)
+		if err != nil {
+			initialLogger.Warnf("Local cache tier disabled: %s", err)
+		} else {
+			initialLogger.TInfof("Local cache tier: %s (%s used of %s)",
+				store.Dir(),
+				humanize.Bytes(uint64(store.Size())),              //nolint:gosec
+				humanize.Bytes(uint64(config.LocalCacheMaxBytes)), //nolint:gosec
+			)
+			proxyClient = proxy.NewLocalCacheClient(client, store, initialLogger)
+		}
+	}
+
+	p := proxy.NewProxy(proxyClient, config.PushEnabled, initialLogger, loggerFactory, emitter)
 	p.InactivityTimeout = resolveInactivityTimeout(envProvider, initialLogger)
+	if pathResolver, err := paths.Default(); err == nil {
+		p.JournalDir = pathResolver.XcelerateJournalDir()
+	} else {
+		initialLogger.Debugf("Key journal disabled, cannot resolve paths: %v", err)
+	}
 
 	if bundle.enrichmentEnabled() {
 		go bundle.watcher(initialLogger).Run(ctx)
@@ -226,7 +244,7 @@ func StartXcodeCacheProxy(
 
 type analyticsBundle struct {
 	client           *analytics.Client
-	authProvider     *configcommon.ExpiryAwareResolver
+	authProvider     kv.AuthSource
 	metadata         configcommon.CacheConfigMetadata
 	pending          *enrichment.Store
 	handledManifests *enrichment.HandledManifestStore
@@ -246,7 +264,7 @@ func newAnalyticsBundle(
 	envProvider map[string]string,
 	commandFunc configcommon.CommandFunc,
 	logger log.Logger,
-	authProvider *configcommon.ExpiryAwareResolver,
+	authProvider kv.AuthSource,
 ) *analyticsBundle {
 	tokenSupplier := func() string { return authProvider.Get().TokenInGradleFormat() }
 	client, err := analytics.NewClient(consts.XcodeAnalyticsServiceEndpoint, tokenSupplier, logger)
@@ -388,6 +406,8 @@ func (e *slimInvocationEmitter) EmitSlim(ctx context.Context, meta proxy.Session
 			InvocationDate: meta.StartTime,
 			InvocationID:   meta.InvocationID,
 			HitRate:        hitRate,
+			Degraded:       stats.Degraded,
+			CorruptedBlobs: stats.CorruptedBlobs,
 		}, b.authProvider.Get(), b.metadata)
 
 		if err := putter.PutInvocation(*inv); err != nil {
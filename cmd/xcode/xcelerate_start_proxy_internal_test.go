@@ -15,6 +15,7 @@ import (
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/analytics"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/enrichment"
@@ -59,7 +60,7 @@ func newBundleForTest(t *testing.T, home string, xcodebuildPath string) *analyti
 		OriginalXcodebuildPath: xcodebuildPath,
 	}
 
-	ap := common.NewExpiryAwareResolver(context.Background(), map[string]string{}, nil, bundleTestLogger)
+	ap := credhelper.NewAuthSource(context.Background(), map[string]string{}, nil, bundleTestLogger)
 
 	return newAnalyticsBundle(context.Background(), cfg, map[string]string{}, noopCommandFunc, bundleTestLogger, ap)
 }
@@ -100,7 +101,7 @@ func Test_newAnalyticsBundle_xcodeResolveError_leavesVersionEmptyWithoutPanic(t
 		return "", assert.AnError
 	}
 
-	ap := common.NewExpiryAwareResolver(context.Background(), map[string]string{}, nil, bundleTestLogger)
+	ap := credhelper.NewAuthSource(context.Background(), map[string]string{}, nil, bundleTestLogger)
 	b := newAnalyticsBundle(context.Background(), cfg, map[string]string{}, errCmd, bundleTestLogger, ap)
 
 	require.NotNil(t, b)
@@ -383,7 +383,7 @@ func (c *XcodebuildRunner) Run(ctx context.Context) xcodeargs.RunStats {
 	}
 	c.Logger.Debugf("Run stats: %+v", runStats)
 
-	hitRate := getHitRateFromSessionAndRunStats(ctx, c.ProxySessionClient, runStats, c.Logger)
+	hitRate, degraded := getHitRateFromSessionAndRunStats(ctx, c.ProxySessionClient, runStats, c.Logger)
 
 	c.Metadata.BenchmarkPhase = resolveBenchmarkPhase(c.Logger)
 
@@ -398,6 +398,7 @@ func (c *XcodebuildRunner) Run(ctx context.Context) xcodeargs.RunStats {
 		Error:            runStats.Error,
 		XcodeVersion:     runStats.XcodeVersion,
 		XcodeBuildNumber: runStats.XcodeBuildNumber,
+		Degraded:         degraded,
 	}, c.Config.AuthConfig, c.Metadata)
 
 	c.appendLocalInvocationLog(*inv, runStats)
@@ -578,8 +579,9 @@ func getHitRateFromSessionAndRunStats(ctx context.Context,
 	proxySessionClient session.SessionClient,
 	runStats xcodeargs.RunStats,
 	logger log.Logger,
-) float32 {
+) (float32, bool) {
 	var hitRate float32
+	var degraded bool
 	// If build cache is not enabled, session client is nil
 	if proxySessionClient != nil {
 		proxyStats, err := proxySessionClient.GetSessionStats(ctx, &empty.Empty{})
@@ -600,6 +602,17 @@ func getHitRateFromSessionAndRunStats(ctx context.Context,
 				proxyStats.GetUploads(),
 				humanize.Bytes(uint64(proxyStats.GetUploadedBytes())), // nolint: gosec
 			)
+			if proxyStats.GetDegraded() {
+				degraded = true
+				logger.Warnf("Remote cache was unreachable during this build, the proxy ran in degraded mode")
+			}
+			if proxyStats.GetLocalHits() > 0 {
+				logger.Infof(
+					"Proxy tier stats: local hits: %d, remote hits: %d",
+					proxyStats.GetLocalHits(),
+					proxyStats.GetRemoteHits(),
+				)
+			}
 
 			// If we have KV stats, use that instead of blob stats.
 			if proxyStats.GetKvHits()+proxyStats.GetKvMisses() > 0 {
@@ -627,7 +640,7 @@ func getHitRateFromSessionAndRunStats(ctx context.Context,
 		)
 	}
 
-	return hitRate
+	return hitRate, degraded
 }
 
 // resolveBenchmarkPhase reads the benchmark phase from:
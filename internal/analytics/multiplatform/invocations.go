@@ -42,6 +42,7 @@ type Invocation struct {
 	ExternalWorkflowName string            `json:"externalWorkflowName,omitempty"`
 	BuildTool            string            `json:"buildTool"`
 	Wrapper              string            `json:"wrapper,omitempty"`
+	Degraded             bool              `json:"degraded,omitempty"`
 }
 
 // InvocationRelation records a parent→child relationship between two invocations.
@@ -64,6 +65,7 @@ type InvocationRunStats struct {
 	Error          error
 	BuildTool      string
 	Wrapper        string
+	Degraded       bool
 }
 
 // NewInvocation assembles an Invocation from run stats, auth config, and system metadata.
@@ -107,6 +109,7 @@ func NewInvocation(runStats InvocationRunStats, authMetadata common.CacheAuthCon
 		ExternalWorkflowName: commonMetadata.ExternalWorkflowName,
 		BuildTool:            runStats.BuildTool,
 		Wrapper:              runStats.Wrapper,
+		Degraded:             runStats.Degraded,
 	}
 }
 
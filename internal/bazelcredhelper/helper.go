@@ -36,6 +36,19 @@ type GetCredentialsResponse struct {
 // writes a JSON response with an `authorization: Bearer <token>` header to
 // `out`. Returns an error on unparseable input or missing credentials.
 func Run(in io.Reader, out io.Writer, envs map[string]string) error {
+	return RunWithResolver(in, out, func() (configcommon.CacheAuthConfig, error) {
+		cfg, _, err := configcommon.ResolveAuthConfig(envs)
+		if err != nil {
+			return configcommon.CacheAuthConfig{}, fmt.Errorf("resolve auth config: %w", err)
+		}
+
+		return cfg, nil
+	})
+}
+
+// RunWithResolver is Run with the credential lookup supplied by the caller.
+// resolve is only called once the request decoded successfully.
+func RunWithResolver(in io.Reader, out io.Writer, resolve func() (configcommon.CacheAuthConfig, error)) error {
 	// The request body is optional in practice but we accept and discard it
 	// so a malformed payload surfaces as an error rather than silent success.
 	var req GetCredentialsRequest
@@ -44,9 +57,9 @@ func Run(in io.Reader, out io.Writer, envs map[string]string) error {
 		return fmt.Errorf("decode credential-helper request: %w", err)
 	}
 
-	cfg, _, err := configcommon.ResolveAuthConfig(envs)
+	cfg, err := resolve()
 	if err != nil {
-		return fmt.Errorf("resolve auth config: %w", err)
+		return err
 	}
 
 	resp := GetCredentialsResponse{
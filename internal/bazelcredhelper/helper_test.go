@@ -77,3 +77,15 @@ func TestRun_UsesRawToken_NotGradleFormat(t *testing.T) {
 	assert.Equal(t, []string{"Bearer raw-token"}, resp.Headers["authorization"])
 	assert.NotContains(t, resp.Headers["authorization"][0], "ws-1:", "workspace ID must not appear in the token")
 }
+
+func TestRunWithResolver_MalformedRequest_DoesNotResolve(t *testing.T) {
+	resolved := false
+	err := RunWithResolver(strings.NewReader("not-json"), &bytes.Buffer{}, func() (configcommon.CacheAuthConfig, error) {
+		resolved = true
+
+		return configcommon.CacheAuthConfig{}, nil
+	})
+
+	require.Error(t, err)
+	assert.False(t, resolved)
+}
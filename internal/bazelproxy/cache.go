@@ -0,0 +1,424 @@
+package bazelproxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+)
+
+// readChunkSize is the payload size of a single ByteStream Read response.
+const readChunkSize = 1024 * 1024
+
+// actionCacheKey is the kv key of an action result. CAS blobs are stored under
+// their bare hash, the same way DerivedData blobs are, so FindMissing works on them.
+func actionCacheKey(digest *remoteexecution.Digest) string {
+	return "bazel-ac-" + digest.GetHash()
+}
+
+func casKey(digest *remoteexecution.Digest) string {
+	return digest.GetHash()
+}
+
+func (p *Proxy) acquire() func() {
+	p.ccSemaphore <- struct{}{}
+
+	return func() { <-p.ccSemaphore }
+}
+
+func (p *Proxy) GetActionResult(ctx context.Context, request *remoteexecution.GetActionResultRequest) (*remoteexecution.ActionResult, error) {
+	state := p.observe(ctx)
+	defer p.acquire()()
+
+	key := actionCacheKey(request.GetActionDigest())
+	p.logger.TDebugf("GetActionResult: %s", key)
+
+	var data bytes.Buffer
+	if err := p.kvClient.DownloadStream(ctx, &data, key); err != nil {
+		if errors.Is(err, kv.ErrCacheNotFound) {
+			state.acMisses.Add(1)
+		} else {
+			p.logger.TErrorf("GetActionResult %s: %s", key, err)
+		}
+
+		return nil, remoteError(err, "action result %s", key)
+	}
+
+	result := &remoteexecution.ActionResult{}
+	if err := proto.Unmarshal(data.Bytes(), result); err != nil {
+		p.logger.TErrorf("GetActionResult %s: corrupt entry treated as a miss: %s", key, err)
+		state.acMisses.Add(1)
+
+		return nil, status.Errorf(codes.NotFound, "action result %s", key)
+	}
+
+	state.saveKeyOnce(key)
+	state.acHits.Add(1)
+	state.downloadBytes.Add(int64(data.Len()))
+
+	return result, nil
+}
+
+func (p *Proxy) UpdateActionResult(ctx context.Context, request *remoteexecution.UpdateActionResultRequest) (*remoteexecution.ActionResult, error) {
+	state := p.observe(ctx)
+	defer p.acquire()()
+
+	key := actionCacheKey(request.GetActionDigest())
+	p.logger.TDebugf("UpdateActionResult: %s", key)
+
+	if !p.pushEnabled {
+		p.logger.TDebugf("UpdateActionResult: push disabled, not uploading %s", key)
+
+		return request.GetActionResult(), nil
+	}
+
+	data, err := proto.Marshal(request.GetActionResult())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "marshal action result: %s", err)
+	}
+
+	if err := p.upload(ctx, state, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	return request.GetActionResult(), nil
+}
+
+func (p *Proxy) FindMissingBlobs(ctx context.Context, request *remoteexecution.FindMissingBlobsRequest) (*remoteexecution.FindMissingBlobsResponse, error) {
+	state := p.observe(ctx)
+	defer p.acquire()()
+
+	// Blobs uploaded or downloaded earlier in the invocation are known to be present.
+	var unknown []*kv.FileDigest
+	for _, digest := range request.GetBlobDigests() {
+		if digest.GetSizeBytes() == 0 || state.isKeySaved(casKey(digest)) {
+			continue
+		}
+
+		unknown = append(unknown, &kv.FileDigest{Sha256Sum: digest.GetHash(), SizeInBytes: digest.GetSizeBytes()})
+	}
+
+	p.logger.TDebugf("FindMissingBlobs: %d digests, %d not seen in this invocation", len(request.GetBlobDigests()), len(unknown))
+
+	if len(unknown) == 0 {
+		return &remoteexecution.FindMissingBlobsResponse{}, nil
+	}
+
+	missing, err := p.kvClient.FindMissing(ctx, unknown)
+	if err != nil {
+		p.logger.TErrorf("FindMissingBlobs: %s", err)
+
+		return nil, remoteError(err, "find missing blobs")
+	}
+
+	response := &remoteexecution.FindMissingBlobsResponse{}
+	for _, digest := range missing {
+		response.MissingBlobDigests = append(response.MissingBlobDigests, &remoteexecution.Digest{
+			Hash:      digest.Sha256Sum,
+			SizeBytes: digest.SizeInBytes,
+		})
+	}
+
+	return response, nil
+}
+
+func (p *Proxy) BatchUpdateBlobs(ctx context.Context, request *remoteexecution.BatchUpdateBlobsRequest) (*remoteexecution.BatchUpdateBlobsResponse, error) {
+	state := p.observe(ctx)
+
+	responses := make([]*remoteexecution.BatchUpdateBlobsResponse_Response, len(request.GetRequests()))
+	var wg sync.WaitGroup
+	for i, blob := range request.GetRequests() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer p.acquire()()
+
+			var err error
+			if verifyErr := verifyDigest(blob.GetDigest(), blob.GetData()); verifyErr != nil {
+				err = verifyErr
+			} else if p.pushEnabled {
+				err = p.upload(ctx, state, casKey(blob.GetDigest()), bytes.NewReader(blob.GetData()), int64(len(blob.GetData())))
+			}
+
+			responses[i] = &remoteexecution.BatchUpdateBlobsResponse_Response{
+				Digest: blob.GetDigest(),
+				Status: status.Convert(err).Proto(),
+			}
+		}()
+	}
+	wg.Wait()
+
+	return &remoteexecution.BatchUpdateBlobsResponse{Responses: responses}, nil
+}
+
+func (p *Proxy) BatchReadBlobs(ctx context.Context, request *remoteexecution.BatchReadBlobsRequest) (*remoteexecution.BatchReadBlobsResponse, error) {
+	state := p.observe(ctx)
+
+	responses := make([]*remoteexecution.BatchReadBlobsResponse_Response, len(request.GetDigests()))
+	var wg sync.WaitGroup
+	for i, digest := range request.GetDigests() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer p.acquire()()
+
+			var data bytes.Buffer
+			err := p.download(ctx, state, digest, &data)
+
+			//nolint:exhaustruct
+			responses[i] = &remoteexecution.BatchReadBlobsResponse_Response{
+				Digest: digest,
+				Data:   data.Bytes(),
+				Status: status.Convert(err).Proto(),
+			}
+		}()
+	}
+	wg.Wait()
+
+	return &remoteexecution.BatchReadBlobsResponse{Responses: responses}, nil
+}
+
+func (p *Proxy) Read(request *bytestream.ReadRequest, stream bytestream.ByteStream_ReadServer) error {
+	ctx := stream.Context()
+	state := p.observe(ctx)
+	defer p.acquire()()
+
+	digest, err := parseResourceName(request.GetResourceName(), false)
+	if err != nil {
+		return err
+	}
+
+	writer := &readStreamWriter{
+		stream: stream,
+		skip:   request.GetReadOffset(),
+		limit:  request.GetReadLimit(),
+	}
+
+	return p.download(ctx, state, digest, writer)
+}
+
+func (p *Proxy) Write(stream bytestream.ByteStream_WriteServer) error {
+	ctx := stream.Context()
+	state := p.observe(ctx)
+	defer p.acquire()()
+
+	request, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("receive first write request: %w", err)
+	}
+
+	digest, err := parseResourceName(request.GetResourceName(), true)
+	if err != nil {
+		return err
+	}
+	key := casKey(digest)
+
+	// Respond early: the client stops sending once the full size is committed.
+	if !p.pushEnabled || state.isKeySaved(key) {
+		if p.pushEnabled {
+			state.dedupedUploads.Add(1)
+		}
+
+		return stream.SendAndClose(&bytestream.WriteResponse{CommittedSize: digest.GetSizeBytes()})
+	}
+
+	tmp, err := os.CreateTemp("", "bazel-proxy-upload-*")
+	if err != nil {
+		return status.Errorf(codes.Internal, "create temp file: %s", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hasher := sha256.New()
+	destination := io.MultiWriter(tmp, hasher)
+	for {
+		if _, err := destination.Write(request.GetData()); err != nil {
+			return status.Errorf(codes.Internal, "buffer upload: %s", err)
+		}
+
+		if request.GetFinishWrite() {
+			break
+		}
+
+		request, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("receive write request: %w", err)
+		}
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return status.Errorf(codes.Internal, "buffer upload: %s", err)
+	}
+	if size != digest.GetSizeBytes() || hex.EncodeToString(hasher.Sum(nil)) != digest.GetHash() {
+		return status.Errorf(codes.InvalidArgument, "uploaded data does not match digest %s/%d", digest.GetHash(), digest.GetSizeBytes())
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return status.Errorf(codes.Internal, "buffer upload: %s", err)
+	}
+
+	if err := p.upload(ctx, state, key, tmp, size); err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&bytestream.WriteResponse{CommittedSize: size})
+}
+
+// upload stores data under key unless it is already known to be present in
+// this invocation.
+func (p *Proxy) upload(ctx context.Context, state *sessionState, key string, data io.ReadSeeker, size int64) error {
+	if state.saveKeyOnce(key) {
+		p.logger.TDebugf("Upload: %s already saved in this invocation", key)
+		state.dedupedUploads.Add(1)
+
+		return nil
+	}
+
+	if err := p.kvClient.UploadStreamToBuildCache(ctx, data, key, size); errors.Is(err, kv.ErrDegraded) {
+		// the remote is down: Bazel keeps its output, nothing is stored
+		state.markKeyUnsaved(key)
+		state.skippedUploads.Add(1)
+
+		return nil
+	} else if err != nil {
+		state.markKeyUnsaved(key)
+		p.logger.TErrorf("Upload %s: %s", key, err)
+
+		return status.Errorf(codes.Unavailable, "upload %s: %s", key, err)
+	}
+
+	state.uploads.Add(1)
+	state.uploadBytes.Add(size)
+
+	return nil
+}
+
+func (p *Proxy) download(ctx context.Context, state *sessionState, digest *remoteexecution.Digest, destination io.Writer) error {
+	if digest.GetSizeBytes() == 0 {
+		return nil
+	}
+
+	key := casKey(digest)
+	p.logger.TDebugf("Download: %s", key)
+
+	counter := &countingWriter{writer: destination}
+	if err := p.kvClient.DownloadStream(ctx, counter, key); err != nil {
+		if errors.Is(err, kv.ErrCacheNotFound) {
+			state.casMisses.Add(1)
+		} else {
+			p.logger.TErrorf("Download %s: %s", key, err)
+		}
+
+		return remoteError(err, "blob %s", key)
+	}
+
+	state.saveKeyOnce(key)
+	state.casHits.Add(1)
+	state.downloadBytes.Add(counter.written)
+
+	return nil
+}
+
+// parseResourceName extracts the digest from a ByteStream resource name:
+// `[{instance_name}/]blobs/{hash}/{size}` for reads and
+// `[{instance_name}/]uploads/{uuid}/blobs/{hash}/{size}[/{metadata}]` for writes.
+func parseResourceName(name string, upload bool) (*remoteexecution.Digest, error) {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		if part != "blobs" || i+2 >= len(parts) {
+			continue
+		}
+		if upload && (i < 2 || parts[i-2] != "uploads") {
+			continue
+		}
+
+		size, err := strconv.ParseInt(parts[i+2], 10, 64)
+		if err != nil || size < 0 {
+			break
+		}
+
+		return &remoteexecution.Digest{Hash: parts[i+1], SizeBytes: size}, nil
+	}
+
+	return nil, status.Errorf(codes.InvalidArgument, "invalid resource name: %s", name)
+}
+
+func verifyDigest(digest *remoteexecution.Digest, data []byte) error {
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != digest.GetSizeBytes() || hex.EncodeToString(sum[:]) != digest.GetHash() {
+		return status.Errorf(codes.InvalidArgument, "data does not match digest %s/%d", digest.GetHash(), digest.GetSizeBytes())
+	}
+
+	return nil
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	w.written += int64(n)
+
+	//nolint:wrapcheck
+	return n, err
+}
+
+// readStreamWriter sends downloaded data as ByteStream Read responses, honouring
+// the requested offset and limit.
+type readStreamWriter struct {
+	stream bytestream.ByteStream_ReadServer
+	skip   int64
+	limit  int64
+	sent   int64
+}
+
+func (w *readStreamWriter) Write(data []byte) (int, error) {
+	n := len(data)
+
+	if w.skip > 0 {
+		skipped := min(w.skip, int64(len(data)))
+		w.skip -= skipped
+		data = data[skipped:]
+	}
+
+	if w.limit > 0 {
+		data = data[:min(int64(len(data)), max(w.limit-w.sent, 0))]
+	}
+
+	for len(data) > 0 {
+		chunk := data[:min(len(data), readChunkSize)]
+		if err := w.stream.Send(&bytestream.ReadResponse{Data: chunk}); err != nil {
+			return 0, fmt.Errorf("send read response: %w", err)
+		}
+
+		w.sent += int64(len(chunk))
+		data = data[len(chunk):]
+	}
+
+	return n, nil
+}
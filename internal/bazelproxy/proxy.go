@@ -0,0 +1,303 @@
+package bazelproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/bazelbuild/remote-apis/build/bazel/semver"
+	"github.com/bitrise-io/go-utils/v2/log"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+)
+
+var (
+	_ remoteexecution.ActionCacheServer               = (*Proxy)(nil)
+	_ remoteexecution.ContentAddressableStorageServer = (*Proxy)(nil)
+	_ remoteexecution.CapabilitiesServer              = (*Proxy)(nil)
+	_ bytestream.ByteStreamServer                     = (*Proxy)(nil)
+)
+
+// Client is the part of kv.Client the proxy forwards to.
+type Client interface {
+	ChangeSession(invocationID string, appSlug string, buildSlug string, stepSlug string)
+	DownloadStream(ctx context.Context, writer io.Writer, key string) error
+	UploadStreamToBuildCache(ctx context.Context, reader io.ReadSeeker, key string, size int64) error
+	FindMissing(ctx context.Context, digests []*kv.FileDigest) ([]*kv.FileDigest, error)
+	GetCapabilitiesWithRetry(ctx context.Context) error
+	Degraded() bool
+}
+
+const (
+	// requestMetadataKey is the header Bazel attaches to every remote call.
+	requestMetadataKey = "build.bazel.remote.execution.v2.requestmetadata-bin"
+
+	defaultInactivityTimeout = 5 * time.Minute
+
+	// maxBatchTotalSizeBytes keeps batch calls below the default 4 MiB gRPC
+	// message limit; Bazel falls back to ByteStream for larger blobs.
+	maxBatchTotalSizeBytes = 4*1024*1024 - 64*1024
+)
+
+// Proxy is a local REAPI remote cache (ActionCache, ContentAddressableStorage,
+// Capabilities and ByteStream) forwarding to the Bitrise Build Cache.
+type Proxy struct {
+	remoteexecution.UnimplementedActionCacheServer
+	remoteexecution.UnimplementedContentAddressableStorageServer
+	remoteexecution.UnimplementedCapabilitiesServer
+	bytestream.UnimplementedByteStreamServer
+
+	kvClient    Client
+	pushEnabled bool
+	logger      log.Logger
+	emitter     InvocationEmitter
+	ccSemaphore chan struct{}
+	grpcServer  *grpc.Server
+
+	sessionMutex        sync.Mutex
+	sessionState        *sessionState
+	currentSession      *SessionMeta
+	capabilitiesChecked bool
+	lastActivity        time.Time
+	inactivityTimer     *time.Timer
+
+	// AppSlug and BuildSlug are forwarded to the cache with every invocation.
+	AppSlug   string
+	BuildSlug string
+	// InactivityTimeout is the idle window after which the current invocation
+	// is emitted. Zero falls back to defaultInactivityTimeout.
+	InactivityTimeout time.Duration
+}
+
+func NewProxy(kvClient Client, pushEnabled bool, logger log.Logger, emitter InvocationEmitter) *Proxy {
+	// Bazel defaults to --remote_max_connections=100 with many calls per connection.
+	ccLimit := 4 * runtime.NumCPU()
+	logger.Infof("Setting up Bazel proxy with concurrency limit: %d", ccLimit)
+
+	//nolint:exhaustruct
+	p := &Proxy{
+		kvClient:     kvClient,
+		pushEnabled:  pushEnabled,
+		logger:       logger,
+		emitter:      emitter,
+		ccSemaphore:  make(chan struct{}, ccLimit),
+		sessionState: newSessionState(),
+	}
+
+	grpcServer := grpc.NewServer()
+	remoteexecution.RegisterActionCacheServer(grpcServer, p)
+	remoteexecution.RegisterContentAddressableStorageServer(grpcServer, p)
+	remoteexecution.RegisterCapabilitiesServer(grpcServer, p)
+	bytestream.RegisterByteStreamServer(grpcServer, p)
+
+	p.grpcServer = grpcServer
+
+	return p
+}
+
+// Serve delegates to the underlying gRPC server.
+func (p *Proxy) Serve(l net.Listener) error {
+	//nolint:wrapcheck
+	return p.grpcServer.Serve(l)
+}
+
+// GracefulStop stops the underlying gRPC server after in-flight RPCs finish.
+func (p *Proxy) GracefulStop() {
+	p.grpcServer.GracefulStop()
+}
+
+// FlushCurrentSession emits the currently-open invocation (if any). Caller is
+// responsible for invoking this on shutdown.
+func (p *Proxy) FlushCurrentSession(ctx context.Context) {
+	p.sessionMutex.Lock()
+	defer p.sessionMutex.Unlock()
+
+	p.emitCurrentSessionLocked(ctx)
+}
+
+// observe attributes the call to the Bazel invocation named in its request
+// metadata, starting a new session when the invocation changes, and returns
+// the state the call should be counted in.
+func (p *Proxy) observe(ctx context.Context) *sessionState {
+	requestMetadata := requestMetadataFromContext(ctx)
+
+	p.sessionMutex.Lock()
+	defer p.sessionMutex.Unlock()
+
+	if id := requestMetadata.GetToolInvocationId(); id != "" && (p.currentSession == nil || p.currentSession.InvocationID != id) {
+		p.emitCurrentSessionLocked(context.WithoutCancel(ctx))
+		p.startSessionLocked(requestMetadata)
+	}
+
+	if p.currentSession != nil {
+		p.touchSessionLocked()
+	}
+
+	return p.sessionState
+}
+
+func (p *Proxy) startSessionLocked(requestMetadata *remoteexecution.RequestMetadata) {
+	p.sessionState = newSessionState()
+	p.capabilitiesChecked = false
+	p.currentSession = &SessionMeta{
+		InvocationID: requestMetadata.GetToolInvocationId(),
+		ToolName:     requestMetadata.GetToolDetails().GetToolName(),
+		ToolVersion:  requestMetadata.GetToolDetails().GetToolVersion(),
+		StartTime:    time.Now(),
+	}
+	p.lastActivity = time.Time{}
+	p.inactivityTimer = nil
+
+	p.kvClient.ChangeSession(p.currentSession.InvocationID, p.AppSlug, p.BuildSlug, "")
+
+	p.logger.TInfof("New Bazel invocation: %s (%s %s)",
+		p.currentSession.InvocationID,
+		p.currentSession.ToolName,
+		p.currentSession.ToolVersion,
+	)
+}
+
+// touchSessionLocked records activity on the current session and arms the
+// inactivity timer on the first touch.
+func (p *Proxy) touchSessionLocked() {
+	p.lastActivity = time.Now()
+
+	if p.inactivityTimer == nil {
+		target := p.currentSession
+		p.inactivityTimer = time.AfterFunc(p.inactivityDuration(), func() {
+			p.onInactivity(target)
+		})
+	}
+}
+
+// onInactivity fires when the timer elapses. Re-schedules itself if the
+// session saw activity in the meantime; emits otherwise.
+func (p *Proxy) onInactivity(target *SessionMeta) {
+	p.sessionMutex.Lock()
+	defer p.sessionMutex.Unlock()
+
+	if p.currentSession != target {
+		return
+	}
+
+	if remaining := p.inactivityDuration() - time.Since(p.lastActivity); remaining > 0 {
+		p.inactivityTimer = time.AfterFunc(remaining, func() {
+			p.onInactivity(target)
+		})
+
+		return
+	}
+
+	p.emitCurrentSessionLocked(context.Background())
+}
+
+func (p *Proxy) inactivityDuration() time.Duration {
+	if p.InactivityTimeout > 0 {
+		return p.InactivityTimeout
+	}
+
+	return defaultInactivityTimeout
+}
+
+func (p *Proxy) emitCurrentSessionLocked(ctx context.Context) {
+	if p.currentSession == nil {
+		return
+	}
+
+	if p.inactivityTimer != nil {
+		p.inactivityTimer.Stop()
+		p.inactivityTimer = nil
+	}
+
+	meta := *p.currentSession
+	meta.EndTime = p.lastActivity
+	stats := p.sessionState.getStats()
+	stats.Degraded = p.kvClient.Degraded()
+
+	p.logger.TInfof("Bazel invocation %s finished: action cache hit rate %.02f%% (%d hits, %d misses), %d uploads, %d deduplicated, %d skipped while degraded",
+		meta.InvocationID, stats.HitRate()*100, stats.ActionCacheHits, stats.ActionCacheMisses, stats.Uploads, stats.DedupedUploads, stats.SkippedUploads)
+
+	if p.emitter != nil {
+		p.emitter.EmitSession(ctx, meta, stats)
+	}
+
+	p.currentSession = nil
+}
+
+// checkRemoteCapabilities calls the remote's GetCapabilities once per session
+// so authentication problems surface in the proxy log right away.
+func (p *Proxy) checkRemoteCapabilities(ctx context.Context) {
+	p.sessionMutex.Lock()
+	checked := p.capabilitiesChecked
+	p.capabilitiesChecked = true
+	p.sessionMutex.Unlock()
+
+	if checked {
+		return
+	}
+
+	if err := p.kvClient.GetCapabilitiesWithRetry(ctx); err != nil {
+		p.logger.Warnf("Bitrise Build Cache capabilities check failed, cache calls will likely fail: %s", err)
+	}
+}
+
+func (p *Proxy) GetCapabilities(ctx context.Context, _ *remoteexecution.GetCapabilitiesRequest) (*remoteexecution.ServerCapabilities, error) {
+	p.observe(ctx)
+	p.checkRemoteCapabilities(ctx)
+
+	//nolint:exhaustruct
+	return &remoteexecution.ServerCapabilities{
+		CacheCapabilities: &remoteexecution.CacheCapabilities{
+			DigestFunctions: []remoteexecution.DigestFunction_Value{remoteexecution.DigestFunction_SHA256},
+			ActionCacheUpdateCapabilities: &remoteexecution.ActionCacheUpdateCapabilities{
+				UpdateEnabled: p.pushEnabled,
+			},
+			MaxBatchTotalSizeBytes:      maxBatchTotalSizeBytes,
+			SymlinkAbsolutePathStrategy: remoteexecution.SymlinkAbsolutePathStrategy_DISALLOWED,
+		},
+		LowApiVersion:  &semver.SemVer{Major: 2},
+		HighApiVersion: &semver.SemVer{Major: 2, Minor: 3},
+	}, nil
+}
+
+func requestMetadataFromContext(ctx context.Context) *remoteexecution.RequestMetadata {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	values := md.Get(requestMetadataKey)
+	if len(values) == 0 {
+		return nil
+	}
+
+	requestMetadata := &remoteexecution.RequestMetadata{}
+	if err := proto.Unmarshal([]byte(values[0]), requestMetadata); err != nil {
+		return nil
+	}
+
+	return requestMetadata
+}
+
+// remoteError converts a kv error into the status Bazel expects: NotFound for
+// cache misses, Unavailable for everything else so Bazel treats it as a cache
+// failure and builds locally.
+func remoteError(err error, format string, args ...any) error {
+	if errors.Is(err, kv.ErrCacheNotFound) {
+		return status.Errorf(codes.NotFound, format, args...)
+	}
+
+	return status.Errorf(codes.Unavailable, "%s: %s", fmt.Sprintf(format, args...), err)
+}
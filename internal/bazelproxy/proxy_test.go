@@ -0,0 +1,287 @@
+package bazelproxy_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/bazelproxy"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+)
+
+type fakeClient struct {
+	mutex    sync.Mutex
+	entries  map[string][]byte
+	uploads  []string
+	sessions []string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{entries: map[string][]byte{}}
+}
+
+func (f *fakeClient) ChangeSession(invocationID string, _ string, _ string, _ string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.sessions = append(f.sessions, invocationID)
+}
+
+func (f *fakeClient) DownloadStream(_ context.Context, writer io.Writer, key string) error {
+	f.mutex.Lock()
+	data, ok := f.entries[key]
+	f.mutex.Unlock()
+
+	if !ok {
+		return kv.ErrCacheNotFound
+	}
+
+	_, err := writer.Write(data)
+
+	return err
+}
+
+func (f *fakeClient) UploadStreamToBuildCache(_ context.Context, reader io.ReadSeeker, key string, _ int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.entries[key] = data
+	f.uploads = append(f.uploads, key)
+
+	return nil
+}
+
+func (f *fakeClient) FindMissing(_ context.Context, digests []*kv.FileDigest) ([]*kv.FileDigest, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var missing []*kv.FileDigest
+	for _, digest := range digests {
+		if _, ok := f.entries[digest.Sha256Sum]; !ok {
+			missing = append(missing, digest)
+		}
+	}
+
+	return missing, nil
+}
+
+func (f *fakeClient) GetCapabilitiesWithRetry(context.Context) error { return nil }
+
+func (f *fakeClient) Degraded() bool { return false }
+
+type recordingEmitter struct {
+	mutex sync.Mutex
+	metas []bazelproxy.SessionMeta
+	stats []bazelproxy.SessionStats
+}
+
+func (e *recordingEmitter) EmitSession(_ context.Context, meta bazelproxy.SessionMeta, stats bazelproxy.SessionStats) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.metas = append(e.metas, meta)
+	e.stats = append(e.stats, stats)
+}
+
+func startProxy(t *testing.T, client bazelproxy.Client, pushEnabled bool, emitter bazelproxy.InvocationEmitter) (*bazelproxy.Proxy, *grpc.ClientConn) {
+	t.Helper()
+
+	listener := bufconn.Listen(8 * 1024 * 1024)
+	p := bazelproxy.NewProxy(client, pushEnabled, log.NewLogger(log.WithOutput(io.Discard)), emitter)
+	go func() { _ = p.Serve(listener) }()
+	t.Cleanup(p.GracefulStop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return p, conn
+}
+
+func invocationContext(t *testing.T, invocationID string) context.Context {
+	t.Helper()
+
+	data, err := proto.Marshal(&remoteexecution.RequestMetadata{
+		ToolDetails:      &remoteexecution.ToolDetails{ToolName: "bazel", ToolVersion: "8.0.0"},
+		ToolInvocationId: invocationID,
+	})
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "build.bazel.remote.execution.v2.requestmetadata-bin", string(data))
+}
+
+func digestOf(data []byte) *remoteexecution.Digest {
+	sum := sha256.Sum256(data)
+
+	return &remoteexecution.Digest{Hash: hex.EncodeToString(sum[:]), SizeBytes: int64(len(data))}
+}
+
+func TestProxy_ActionCache(t *testing.T) {
+	client := newFakeClient()
+	emitter := &recordingEmitter{}
+	p, conn := startProxy(t, client, true, emitter)
+	actionCache := remoteexecution.NewActionCacheClient(conn)
+	ctx := invocationContext(t, "inv-1")
+
+	actionDigest := digestOf([]byte("action"))
+	_, err := actionCache.GetActionResult(ctx, &remoteexecution.GetActionResultRequest{ActionDigest: actionDigest})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	result := &remoteexecution.ActionResult{ExitCode: 0, StdoutRaw: []byte("ok")}
+	_, err = actionCache.UpdateActionResult(ctx, &remoteexecution.UpdateActionResultRequest{ActionDigest: actionDigest, ActionResult: result})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bazel-ac-" + actionDigest.GetHash()}, client.uploads)
+
+	got, err := actionCache.GetActionResult(ctx, &remoteexecution.GetActionResultRequest{ActionDigest: actionDigest})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ok"), got.GetStdoutRaw())
+
+	p.FlushCurrentSession(context.Background())
+	require.Len(t, emitter.metas, 1)
+	assert.Equal(t, "inv-1", emitter.metas[0].InvocationID)
+	assert.Equal(t, "8.0.0", emitter.metas[0].ToolVersion)
+	assert.Equal(t, int64(1), emitter.stats[0].ActionCacheHits)
+	assert.Equal(t, int64(1), emitter.stats[0].ActionCacheMisses)
+	assert.InDelta(t, 0.5, emitter.stats[0].HitRate(), 0.001)
+	assert.Equal(t, []string{"inv-1"}, client.sessions)
+}
+
+func TestProxy_CASAndByteStream(t *testing.T) {
+	client := newFakeClient()
+	_, conn := startProxy(t, client, true, nil)
+	cas := remoteexecution.NewContentAddressableStorageClient(conn)
+	byteStream := bytestream.NewByteStreamClient(conn)
+	ctx := invocationContext(t, "inv-1")
+
+	small := []byte("small blob")
+	large := bytes.Repeat([]byte("large blob "), 200_000)
+
+	missing, err := cas.FindMissingBlobs(ctx, &remoteexecution.FindMissingBlobsRequest{
+		BlobDigests: []*remoteexecution.Digest{digestOf(small), digestOf(large), digestOf(nil)},
+	})
+	require.NoError(t, err)
+	assert.Len(t, missing.GetMissingBlobDigests(), 2)
+
+	batch, err := cas.BatchUpdateBlobs(ctx, &remoteexecution.BatchUpdateBlobsRequest{Requests: []*remoteexecution.BatchUpdateBlobsRequest_Request{
+		{Digest: digestOf(small), Data: small},
+		{Digest: digestOf([]byte("other")), Data: small},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.OK), batch.GetResponses()[0].GetStatus().GetCode())
+	assert.Equal(t, int32(codes.InvalidArgument), batch.GetResponses()[1].GetStatus().GetCode())
+
+	largeDigest := digestOf(large)
+	writer, err := byteStream.Write(ctx)
+	require.NoError(t, err)
+	resourceName := "instance/uploads/some-uuid/blobs/" + largeDigest.GetHash() + "/" + "2200000"
+	require.NoError(t, writer.Send(&bytestream.WriteRequest{ResourceName: resourceName, Data: large[:1000]}))
+	require.NoError(t, writer.Send(&bytestream.WriteRequest{WriteOffset: 1000, Data: large[1000:], FinishWrite: true}))
+	written, err := writer.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(large)), written.GetCommittedSize())
+	assert.Equal(t, []string{digestOf(small).GetHash(), largeDigest.GetHash()}, client.uploads)
+
+	// A second upload of the same blob in the invocation is answered without forwarding
+	writer, err = byteStream.Write(ctx)
+	require.NoError(t, err)
+	require.NoError(t, writer.Send(&bytestream.WriteRequest{ResourceName: resourceName, Data: large[:1000]}))
+	written, err = writer.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(large)), written.GetCommittedSize())
+	assert.Len(t, client.uploads, 2)
+
+	reader, err := byteStream.Read(ctx, &bytestream.ReadRequest{ResourceName: "instance/blobs/" + largeDigest.GetHash() + "/2200000", ReadOffset: 10})
+	require.NoError(t, err)
+	var read []byte
+	for {
+		chunk, err := reader.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		read = append(read, chunk.GetData()...)
+	}
+	assert.Equal(t, large[10:], read)
+
+	readBatch, err := cas.BatchReadBlobs(ctx, &remoteexecution.BatchReadBlobsRequest{
+		Digests: []*remoteexecution.Digest{digestOf(small), digestOf([]byte("unknown"))},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, small, readBatch.GetResponses()[0].GetData())
+	assert.Equal(t, int32(codes.NotFound), readBatch.GetResponses()[1].GetStatus().GetCode())
+
+	reader, err = byteStream.Read(ctx, &bytestream.ReadRequest{ResourceName: "blobs/not-a-digest"})
+	require.NoError(t, err)
+	_, err = reader.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProxy_PushDisabled(t *testing.T) {
+	client := newFakeClient()
+	_, conn := startProxy(t, client, false, nil)
+	ctx := invocationContext(t, "inv-1")
+
+	capabilities, err := remoteexecution.NewCapabilitiesClient(conn).GetCapabilities(ctx, &remoteexecution.GetCapabilitiesRequest{})
+	require.NoError(t, err)
+	assert.False(t, capabilities.GetCacheCapabilities().GetActionCacheUpdateCapabilities().GetUpdateEnabled())
+	assert.Equal(t, []remoteexecution.DigestFunction_Value{remoteexecution.DigestFunction_SHA256}, capabilities.GetCacheCapabilities().GetDigestFunctions())
+
+	_, err = remoteexecution.NewActionCacheClient(conn).UpdateActionResult(ctx, &remoteexecution.UpdateActionResultRequest{
+		ActionDigest: digestOf([]byte("action")),
+		ActionResult: &remoteexecution.ActionResult{},
+	})
+	require.NoError(t, err)
+
+	_, err = remoteexecution.NewContentAddressableStorageClient(conn).BatchUpdateBlobs(ctx, &remoteexecution.BatchUpdateBlobsRequest{
+		Requests: []*remoteexecution.BatchUpdateBlobsRequest_Request{{Digest: digestOf([]byte("blob")), Data: []byte("blob")}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, client.uploads)
+}
+
+func TestProxy_NewInvocationEmitsPrevious(t *testing.T) {
+	client := newFakeClient()
+	emitter := &recordingEmitter{}
+	p, conn := startProxy(t, client, true, emitter)
+	actionCache := remoteexecution.NewActionCacheClient(conn)
+
+	for _, invocationID := range []string{"inv-1", "inv-1", "inv-2"} {
+		_, err := actionCache.GetActionResult(invocationContext(t, invocationID), &remoteexecution.GetActionResultRequest{ActionDigest: digestOf([]byte("action"))})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	require.Len(t, emitter.metas, 1)
+	assert.Equal(t, "inv-1", emitter.metas[0].InvocationID)
+	assert.Equal(t, int64(2), emitter.stats[0].ActionCacheMisses)
+
+	p.FlushCurrentSession(context.Background())
+	require.Len(t, emitter.metas, 2)
+	assert.Equal(t, "inv-2", emitter.metas[1].InvocationID)
+	assert.Equal(t, int64(1), emitter.stats[1].ActionCacheMisses)
+	assert.Equal(t, []string{"inv-1", "inv-2"}, client.sessions)
+}
@@ -0,0 +1,102 @@
+package bazelproxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SessionMeta is the identity of the Bazel invocation being emitted.
+type SessionMeta struct {
+	// InvocationID is Bazel's tool_invocation_id from the request metadata.
+	InvocationID string
+	ToolName     string
+	ToolVersion  string
+	StartTime    time.Time
+	// EndTime is the wall-clock of the last RPC of the invocation.
+	EndTime time.Time
+}
+
+// SessionStats is the counters snapshot at emit time. Action cache lookups
+// drive the hit rate, CAS reads are only reported as transferred bytes.
+type SessionStats struct {
+	ActionCacheHits   int64
+	ActionCacheMisses int64
+	CASHits           int64
+	CASMisses         int64
+	Uploads           int64
+	// DedupedUploads counts uploads skipped because the key was already
+	// uploaded or downloaded earlier in the same invocation.
+	DedupedUploads int64
+	// SkippedUploads counts uploads dropped because the remote was degraded.
+	SkippedUploads int64
+	UploadBytes    int64
+	DownloadBytes  int64
+	// Degraded is set when the remote was short-circuited during the session.
+	Degraded bool
+}
+
+// InvocationEmitter receives the stats of a finished Bazel invocation.
+type InvocationEmitter interface {
+	EmitSession(ctx context.Context, meta SessionMeta, stats SessionStats)
+}
+
+// HitRate is the action cache hit rate — that is what decides whether Bazel
+// re-runs an action, CAS reads only follow action cache hits.
+func (s SessionStats) HitRate() float32 {
+	if s.ActionCacheHits+s.ActionCacheMisses == 0 {
+		return 0
+	}
+
+	return float32(s.ActionCacheHits) / float32(s.ActionCacheHits+s.ActionCacheMisses)
+}
+
+type sessionState struct {
+	acHits         atomic.Int64
+	acMisses       atomic.Int64
+	casHits        atomic.Int64
+	casMisses      atomic.Int64
+	uploads        atomic.Int64
+	dedupedUploads atomic.Int64
+	skippedUploads atomic.Int64
+	uploadBytes    atomic.Int64
+	downloadBytes  atomic.Int64
+	savedKeys      sync.Map
+}
+
+func newSessionState() *sessionState {
+	return &sessionState{}
+}
+
+func (s *sessionState) getStats() SessionStats {
+	return SessionStats{
+		ActionCacheHits:   s.acHits.Load(),
+		ActionCacheMisses: s.acMisses.Load(),
+		CASHits:           s.casHits.Load(),
+		CASMisses:         s.casMisses.Load(),
+		Uploads:           s.uploads.Load(),
+		DedupedUploads:    s.dedupedUploads.Load(),
+		SkippedUploads:    s.skippedUploads.Load(),
+		UploadBytes:       s.uploadBytes.Load(),
+		DownloadBytes:     s.downloadBytes.Load(),
+	}
+}
+
+// saveKeyOnce marks key as present in the remote for this session and reports
+// whether it already was.
+func (s *sessionState) saveKeyOnce(key string) bool {
+	_, loaded := s.savedKeys.LoadOrStore(key, struct{}{})
+
+	return loaded
+}
+
+func (s *sessionState) isKeySaved(key string) bool {
+	_, ok := s.savedKeys.Load(key)
+
+	return ok
+}
+
+func (s *sessionState) markKeyUnsaved(key string) {
+	s.savedKeys.Delete(key)
+}
@@ -0,0 +1,213 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultCircuitBreakerThreshold = 5
+	DefaultCircuitBreakerCooldown  = 30 * time.Second
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreaker stops a Client from calling an unreachable remote. After
+// Threshold consecutive transport failures it opens: reads become fast misses
+// and writes become no-ops until Cooldown elapses. A single probe call is then
+// let through, which either closes the breaker or re-opens it.
+//
+// A nil *CircuitBreaker is valid and never trips.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mutex             sync.Mutex
+	logger            log.Logger
+	state             breakerState
+	failures          int
+	openUntil         time.Time
+	probing           bool
+	degradedInSession bool
+}
+
+type CircuitBreakerParams struct {
+	Threshold int
+	Cooldown  time.Duration
+	Logger    log.Logger
+}
+
+func NewCircuitBreaker(p CircuitBreakerParams) *CircuitBreaker {
+	if p.Threshold <= 0 {
+		p.Threshold = DefaultCircuitBreakerThreshold
+	}
+	if p.Cooldown <= 0 {
+		p.Cooldown = DefaultCircuitBreakerCooldown
+	}
+
+	//nolint:exhaustruct
+	return &CircuitBreaker{
+		threshold: p.Threshold,
+		cooldown:  p.Cooldown,
+		logger:    p.Logger,
+	}
+}
+
+func (b *CircuitBreaker) SetLogger(logger log.Logger) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.logger = logger
+}
+
+// Allow reports whether a remote call may be made. Once the cool-down has
+// elapsed only the first caller is allowed through as a probe.
+func (b *CircuitBreaker) Allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return false
+		}
+
+		b.state = breakerHalfOpen
+		b.probing = true
+
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+
+		return true
+	default:
+		return true
+	}
+}
+
+// Record updates the breaker with the outcome of a remote call. Only transport
+// failures count towards tripping; any other outcome proves the remote is
+// reachable. Cancellation by the caller is ignored.
+func (b *CircuitBreaker) Record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		b.probing = false
+
+		return
+	}
+
+	if !isTransportFailure(err) {
+		if b.state != breakerClosed && b.logger != nil {
+			b.logger.TInfof("Remote cache reachable again, leaving degraded mode")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		b.probing = false
+
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+		b.trip()
+	}
+}
+
+// Open reports whether calls are currently short-circuited.
+func (b *CircuitBreaker) Open() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state != breakerClosed
+}
+
+// Degraded reports whether the breaker is open or has tripped at any point
+// since the last ResetSession.
+func (b *CircuitBreaker) Degraded() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.degradedInSession || b.state != breakerClosed
+}
+
+// ResetSession starts a new session: it is only degraded if the breaker is
+// still open.
+func (b *CircuitBreaker) ResetSession() {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.degradedInSession = b.state != breakerClosed
+}
+
+func (b *CircuitBreaker) trip() {
+	if b.logger != nil {
+		b.logger.TWarnf("Remote cache unreachable after %d consecutive failures, switching to degraded mode for %s", b.failures, b.cooldown)
+	}
+
+	b.state = breakerOpen
+	b.openUntil = time.Now().Add(b.cooldown)
+	b.probing = false
+	b.degradedInSession = true
+}
+
+func isTransportFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
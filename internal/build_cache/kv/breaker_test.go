@@ -0,0 +1,127 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv/mocks"
+)
+
+func TestCircuitBreaker_TripsAfterConsecutiveTransportFailures(t *testing.T) {
+	breaker := kv.NewCircuitBreaker(kv.CircuitBreakerParams{Threshold: 3, Cooldown: time.Hour, Logger: mockLogger})
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	breaker.Record(unavailable)
+	breaker.Record(unavailable)
+	// a non-transport outcome proves the remote is reachable and resets the count
+	breaker.Record(kv.ErrCacheNotFound)
+	breaker.Record(unavailable)
+	breaker.Record(unavailable)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Degraded())
+
+	breaker.Record(unavailable)
+	assert.False(t, breaker.Allow())
+	assert.True(t, breaker.Open())
+	assert.True(t, breaker.Degraded())
+}
+
+func TestCircuitBreaker_ProbeAfterCooldown(t *testing.T) {
+	breaker := kv.NewCircuitBreaker(kv.CircuitBreakerParams{Threshold: 1, Cooldown: 10 * time.Millisecond, Logger: mockLogger})
+
+	breaker.Record(status.Error(codes.DeadlineExceeded, "timeout"))
+	require.False(t, breaker.Allow())
+
+	time.Sleep(20 * time.Millisecond)
+
+	// only a single probe is let through
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	// a failed probe re-opens the breaker
+	breaker.Record(status.Error(codes.Unavailable, "still down"))
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(20 * time.Millisecond)
+
+	require.True(t, breaker.Allow())
+	breaker.Record(nil)
+	assert.False(t, breaker.Open())
+	assert.True(t, breaker.Allow())
+
+	// the session that saw the outage stays degraded until the next one starts
+	assert.True(t, breaker.Degraded())
+	breaker.ResetSession()
+	assert.False(t, breaker.Degraded())
+}
+
+func TestCircuitBreaker_IgnoresCancellation(t *testing.T) {
+	breaker := kv.NewCircuitBreaker(kv.CircuitBreakerParams{Threshold: 1, Logger: mockLogger})
+
+	breaker.Record(context.Canceled)
+	breaker.Record(errors.New("some other error"))
+
+	assert.False(t, breaker.Open())
+}
+
+func TestCircuitBreaker_Nil(t *testing.T) {
+	var breaker *kv.CircuitBreaker
+
+	breaker.Record(status.Error(codes.Unavailable, "down"))
+	breaker.ResetSession()
+
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Degraded())
+}
+
+func TestClient_DegradedModeShortCircuitsCalls(t *testing.T) {
+	kvMock := &mocks.KVStorageClientMock{
+		GetFunc: func(ctx context.Context, in *bytestream.ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bytestream.ReadResponse], error) {
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		},
+		PutFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bytestream.WriteRequest, bytestream.WriteResponse], error) {
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		},
+	}
+
+	client, err := kv.NewClient(kv.NewClientParams{
+		Logger:            mockLogger,
+		BitriseKVClient:   kvMock,
+		DownloadRetry:     1,
+		DownloadRetryWait: 1,
+		UploadRetry:       1,
+		UploadRetryWait:   1,
+		CircuitBreaker:    kv.NewCircuitBreaker(kv.CircuitBreakerParams{Threshold: 2, Cooldown: time.Hour, Logger: mockLogger}),
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		err := client.DownloadStream(context.Background(), &bytes.Buffer{}, "key")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, kv.ErrCacheNotFound)
+	}
+	require.Len(t, kvMock.GetCalls(), 2)
+	assert.True(t, client.Degraded())
+
+	// reads are fast misses and writes are skipped, reported as ErrDegraded
+	err = client.DownloadStream(context.Background(), &bytes.Buffer{}, "key")
+	require.ErrorIs(t, err, kv.ErrCacheNotFound)
+	require.ErrorIs(t, client.UploadStreamToBuildCache(context.Background(), bytes.NewReader([]byte("data")), "key", 4), kv.ErrDegraded)
+	assert.Len(t, kvMock.GetCalls(), 2)
+	assert.Empty(t, kvMock.PutCalls())
+
+	// a new session is still degraded while the breaker is open
+	client.ChangeSession("inv", "app", "build", "step")
+	assert.True(t, client.Degraded())
+}
@@ -0,0 +1,178 @@
+//go:build unit
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv/mocks"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+)
+
+// memoryStore is an in-memory remote: it serves the kv bytestream and FindMissingBlobs.
+type memoryStore struct {
+	remoteexecution.ContentAddressableStorageClient
+
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *memoryStore) kvClient() *mocks.KVStorageClientMock {
+	return &mocks.KVStorageClientMock{
+		PutFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bytestream.WriteRequest, bytestream.WriteResponse], error) {
+			return &memoryWriteStream{store: s}, nil
+		},
+		GetFunc: func(ctx context.Context, in *bytestream.ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bytestream.ReadResponse], error) {
+			s.mu.Lock()
+			data, ok := s.blobs[strings.TrimPrefix(in.GetResourceName(), "kv/")]
+			s.mu.Unlock()
+			if !ok {
+				return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+					{Error: status.Error(codes.NotFound, "not found")},
+				}), nil
+			}
+
+			return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+				{Response: &bytestream.ReadResponse{Data: data[in.GetReadOffset():]}},
+				{Error: io.EOF},
+			}), nil
+		},
+	}
+}
+
+func (s *memoryStore) FindMissingBlobs(_ context.Context, in *remoteexecution.FindMissingBlobsRequest, _ ...grpc.CallOption) (*remoteexecution.FindMissingBlobsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &remoteexecution.FindMissingBlobsResponse{}
+	for _, d := range in.GetBlobDigests() {
+		if _, ok := s.blobs[d.GetHash()]; !ok {
+			resp.MissingBlobDigests = append(resp.MissingBlobDigests, d)
+		}
+	}
+
+	return resp, nil
+}
+
+type memoryWriteStream struct {
+	grpc.ClientStream
+
+	store *memoryStore
+	name  string
+	data  []byte
+}
+
+func (w *memoryWriteStream) Send(req *bytestream.WriteRequest) error {
+	w.name = strings.TrimPrefix(req.GetResourceName(), "kv/")
+	w.data = append(w.data, req.GetData()...)
+
+	return nil
+}
+
+func (w *memoryWriteStream) CloseAndRecv() (*bytestream.WriteResponse, error) {
+	w.store.mu.Lock()
+	w.store.blobs[w.name] = w.data
+	w.store.mu.Unlock()
+
+	return &bytestream.WriteResponse{CommittedSize: int64(len(w.data))}, nil
+}
+
+func collectFile(t *testing.T, path string) *filegroup.FileInfo {
+	t.Helper()
+
+	chunks, err := filegroup.ChunkFile(path)
+	require.NoError(t, err)
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+
+	checksum, err := hash.ChecksumOfFile(path)
+	require.NoError(t, err)
+
+	return &filegroup.FileInfo{Path: path, Size: stat.Size(), Hash: checksum, Mode: 0o644, Chunks: chunks}
+}
+
+func Test_fileGroup_chunkedUploadAndRestore(t *testing.T) {
+	store := &memoryStore{blobs: make(map[string][]byte)}
+	client, err := NewClient(NewClientParams{
+		Logger:            log.NewLogger(),
+		BitriseKVClient:   store.kvClient(),
+		DownloadRetryWait: 1,
+		UploadRetryWait:   1,
+	})
+	require.NoError(t, err)
+	client.endpoints.all[0].casClient = store
+
+	data := make([]byte, 6*1024*1024)
+	//nolint:gosec
+	_, _ = rand.New(rand.NewSource(1)).Read(data)
+
+	path := filepath.Join(t.TempDir(), "libLarge.a")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	original := collectFile(t, path)
+	require.Greater(t, len(original.Chunks), 2)
+
+	stats, err := client.UploadFileGroupToBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{original}})
+	require.NoError(t, err)
+	assert.Equal(t, len(original.Chunks), stats.ChunksUploaded)
+	assert.Equal(t, original.Size, stats.UploadSize)
+	assert.NotContains(t, store.blobs, original.Hash)
+
+	// a small edit in the middle only uploads the chunks around it
+	edited := append(bytes.Clone(data[:3*1024*1024]), append([]byte("patched"), data[3*1024*1024:]...)...)
+	require.NoError(t, os.WriteFile(path, edited, 0o600))
+	changed := collectFile(t, path)
+
+	stats, err = client.UploadFileGroupToBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{changed}})
+	require.NoError(t, err)
+	assert.Positive(t, stats.ChunksUploaded)
+	assert.LessOrEqual(t, stats.ChunksUploaded, 3)
+	assert.Less(t, stats.UploadSize, changed.Size/2)
+
+	restored := *changed
+	restored.Path = filepath.Join(t.TempDir(), "restored.a")
+	_, err = client.DownloadFileGroupFromBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{&restored}}, false, false, false, 10)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(restored.Path)
+	require.NoError(t, err)
+	assert.Equal(t, edited, content)
+}
+
+func Test_downloadChunkedFile_detectsCorruptReassembly(t *testing.T) {
+	hello, world := sha256Hex("hello "), sha256Hex("world")
+	store := &memoryStore{blobs: map[string][]byte{hello: []byte("hello "), world: []byte("world")}}
+	client, err := NewClient(NewClientParams{
+		Logger:            log.NewLogger(),
+		BitriseKVClient:   store.kvClient(),
+		DownloadRetryWait: 1,
+	})
+	require.NoError(t, err)
+
+	fileInfo := &filegroup.FileInfo{
+		Path:   filepath.Join(t.TempDir(), "file"),
+		Hash:   "not-the-hash",
+		Chunks: []filegroup.ChunkInfo{{Hash: hello, Size: 6}, {Hash: world, Size: 5}},
+	}
+
+	_, err = client.downloadChunkedFile(context.Background(), fileInfo, false, false, false)
+	require.ErrorIs(t, err, errHashMismatch)
+}
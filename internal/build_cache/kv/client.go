@@ -2,7 +2,6 @@ package kv
 
 import (
 	"bytes"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -11,9 +10,6 @@ import (
 
 	"github.com/bitrise-io/go-utils/v2/log"
 	"google.golang.org/genproto/googleapis/bytestream"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
@@ -35,10 +31,7 @@ type staticAuthSource struct {
 func (s staticAuthSource) Get() common.CacheAuthConfig { return s.cfg }
 
 type Client struct {
-	conn                *grpc.ClientConn // nil when test-injected via BitriseKVClient / CapabilitiesClient.
-	bitriseKVClient     kv_storage.KVStorageClient
-	capabilitiesClient  remoteexecution.CapabilitiesClient
-	casClient           remoteexecution.ContentAddressableStorageClient
+	endpoints           *endpointSet
 	clientName          string
 	authSource          AuthSource
 	cacheConfigMetadata common.CacheConfigMetadata
@@ -50,11 +43,19 @@ type Client struct {
 	downloadRetryWait   time.Duration
 	uploadRetry         uint
 	uploadRetryWait     time.Duration
+	breaker             *CircuitBreaker
+	compressUploads     bool
+	transfer            TransferOptions
+	uploadBandwidth     *BandwidthLimiter
+	downloadBandwidth   *BandwidthLimiter
+	quarantine          quarantine
+	deleteCorrupted     bool
 }
 
 type NewClientParams struct {
 	UseInsecure         bool
 	Host                string
+	Endpoints           []Endpoint // ordered; overrides Host/UseInsecure when set
 	DialTimeout         time.Duration
 	ClientName          string
 	AuthConfig          common.CacheAuthConfig
@@ -69,26 +70,35 @@ type NewClientParams struct {
 	DownloadRetryWait   time.Duration
 	UploadRetry         uint
 	UploadRetryWait     time.Duration
+	CircuitBreaker      *CircuitBreaker // optional; nil never short-circuits calls
+	CompressUploads     bool            // zstd-compress uploads; downloads are always decompressed transparently
+	Transfer            TransferOptions // parallelism and bandwidth of file group transfers
+	DeleteCorrupted     bool            // delete blobs failing digest verification from the cache, not only quarantine them
 }
 
 func NewClient(p NewClientParams) (*Client, error) {
-	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
-	if p.UseInsecure {
-		creds = insecure.NewCredentials()
+	endpoints := p.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []Endpoint{{Host: p.Host, UseInsecure: p.UseInsecure}}
 	}
 
-	conn, err := grpc.NewClient(p.Host, grpc.WithTransportCredentials(creds))
-	if err != nil {
-		return nil, fmt.Errorf("dial %s: %w", p.Host, err)
+	conns := make([]*endpointConn, 0, len(endpoints))
+	for _, e := range endpoints {
+		conn, err := dialEndpoint(e)
+		if err != nil {
+			closeEndpoints(conns)
+
+			return nil, err
+		}
+		conns = append(conns, conn)
 	}
 
-	bitriseKVClient := p.BitriseKVClient
-	if bitriseKVClient == nil {
-		bitriseKVClient = kv_storage.NewKVStorageClient(conn)
+	// injected stubs replace the ones of the primary endpoint
+	if p.BitriseKVClient != nil {
+		conns[0].kvClient = p.BitriseKVClient
 	}
-	capabilitiesClient := p.CapabilitiesClient
-	if capabilitiesClient == nil {
-		capabilitiesClient = remoteexecution.NewCapabilitiesClient(conn)
+	if p.CapabilitiesClient != nil {
+		conns[0].capabilitiesClient = p.CapabilitiesClient
 	}
 
 	if p.DownloadRetry == 0 {
@@ -110,10 +120,7 @@ func NewClient(p NewClientParams) (*Client, error) {
 	}
 
 	return &Client{
-		conn:                conn,
-		bitriseKVClient:     bitriseKVClient,
-		capabilitiesClient:  capabilitiesClient,
-		casClient:           remoteexecution.NewContentAddressableStorageClient(conn),
+		endpoints:           &endpointSet{all: conns},
 		clientName:          p.ClientName,
 		authSource:          authSource,
 		logger:              p.Logger,
@@ -124,25 +131,29 @@ func NewClient(p NewClientParams) (*Client, error) {
 		downloadRetryWait:   p.DownloadRetryWait,
 		uploadRetry:         p.UploadRetry,
 		uploadRetryWait:     p.UploadRetryWait,
+		breaker:             p.CircuitBreaker,
+		compressUploads:     p.CompressUploads,
+		transfer:            p.Transfer,
+		uploadBandwidth:     NewBandwidthLimiter(p.Transfer.UploadBandwidth),
+		downloadBandwidth:   NewBandwidthLimiter(p.Transfer.DownloadBandwidth),
+		deleteCorrupted:     p.DeleteCorrupted,
 	}, nil
 }
 
 func (c *Client) SetLogger(logger log.Logger) {
 	c.logger = logger
+	c.breaker.SetLogger(logger)
 }
 
-// Close releases the gRPC connection. Safe to call when the client was built
-// with injected stubs (no conn) — returns nil in that case.
-func (c *Client) Close() error {
-	if c.conn == nil {
-		return nil
-	}
-
-	if err := c.conn.Close(); err != nil {
-		return fmt.Errorf("close kv grpc conn: %w", err)
-	}
+// Degraded reports whether the circuit breaker has short-circuited calls to
+// the remote during the current session.
+func (c *Client) Degraded() bool {
+	return c.breaker.Degraded()
+}
 
-	return nil
+// Close releases the gRPC connections of all endpoints.
+func (c *Client) Close() error {
+	return closeEndpoints(c.endpoints.all)
 }
 
 type writer struct {
@@ -0,0 +1,306 @@
+package kv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+)
+
+const (
+	// Blobs smaller than this are never worth compressing.
+	minCompressSize = 1024
+	// Blobs up to this raw size are compressed in memory, larger ones are spooled to a temp file.
+	maxInMemoryCompressSize = 8 * 1024 * 1024
+)
+
+// compressedBlobMarker is a zstd skippable frame written in front of every
+// compressed blob. It marks the blob in-band, so readers do not depend on the
+// server echoing metadata, and any zstd decoder can still read the blob.
+//
+//nolint:gochecknoglobals
+var compressedBlobMarker = func() []byte {
+	payload := []byte("BRKVZSTD")
+	marker := binary.LittleEndian.AppendUint32(nil, 0x184D2A5B)
+	//nolint:gosec
+	marker = binary.LittleEndian.AppendUint32(marker, uint32(len(payload)))
+
+	return append(marker, payload...)
+}()
+
+// compressionAllowed reports whether uploads may be compressed: compression
+// is enabled and the active endpoint advertised zstd in its capabilities.
+// Compressed blobs share the key of their raw bytes and are told apart by
+// compressedBlobMarker, so a reader that predates compression would get the
+// compressed bytes. The backend only advertises zstd once its readers can
+// decompress them.
+func (c *Client) compressionAllowed() bool {
+	return c.compressUploads && c.endpoints.current().acceptsZstd.Load()
+}
+
+// uploadBody is what actually goes over the wire for an upload.
+type uploadBody struct {
+	reader     io.ReadSeeker
+	size       int64
+	checksum   string
+	compressed bool
+	cleanup    func()
+}
+
+// prepareUpload compresses source when compression is enabled and it pays off.
+// rawChecksum may be empty, it is only computed when the raw bytes are uploaded.
+func (c *Client) prepareUpload(source io.ReadSeeker, rawSize int64, rawChecksum string) (uploadBody, error) {
+	raw := func() (uploadBody, error) {
+		if _, err := source.Seek(0, io.SeekStart); err != nil {
+			return uploadBody{}, fmt.Errorf("seek to start: %w", err)
+		}
+		if rawChecksum == "" {
+			checksum, err := hash.Checksum(source)
+			if err != nil {
+				return uploadBody{}, fmt.Errorf("checksum: %w", err)
+			}
+			rawChecksum = checksum
+
+			if _, err := source.Seek(0, io.SeekStart); err != nil {
+				return uploadBody{}, fmt.Errorf("seek to start: %w", err)
+			}
+		}
+
+		return uploadBody{reader: source, size: rawSize, checksum: rawChecksum, compressed: false, cleanup: func() {}}, nil
+	}
+
+	if !c.compressionAllowed() || rawSize < minCompressSize {
+		return raw()
+	}
+
+	if _, err := source.Seek(0, io.SeekStart); err != nil {
+		return uploadBody{}, fmt.Errorf("seek to start: %w", err)
+	}
+
+	body, err := compress(source, rawSize)
+	if err != nil {
+		return uploadBody{}, err
+	}
+	if body.size >= rawSize {
+		body.cleanup()
+		c.logger.Debugf("Compression does not reduce size (%d >= %d), uploading raw bytes", body.size, rawSize)
+
+		return raw()
+	}
+
+	return body, nil
+}
+
+func compress(source io.Reader, rawSize int64) (uploadBody, error) {
+	var buf bytes.Buffer
+	var file *os.File
+	var sink io.Writer = &buf
+	cleanup := func() {}
+
+	if rawSize > maxInMemoryCompressSize {
+		var err error
+		file, err = os.CreateTemp("", "bitrise-build-cache-zstd-*")
+		if err != nil {
+			return uploadBody{}, fmt.Errorf("create temp file: %w", err)
+		}
+		sink = file
+		cleanup = func() {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}
+
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(hasher, sink)}
+
+	if _, err := counter.Write(compressedBlobMarker); err != nil {
+		cleanup()
+
+		return uploadBody{}, fmt.Errorf("write compression marker: %w", err)
+	}
+
+	encoder, err := zstd.NewWriter(counter)
+	if err != nil {
+		cleanup()
+
+		return uploadBody{}, fmt.Errorf("create zstd encoder: %w", err)
+	}
+	if _, err := io.Copy(encoder, source); err != nil {
+		_ = encoder.Close()
+		cleanup()
+
+		return uploadBody{}, fmt.Errorf("compress: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		cleanup()
+
+		return uploadBody{}, fmt.Errorf("compress: %w", err)
+	}
+
+	var reader io.ReadSeeker = bytes.NewReader(buf.Bytes())
+	if file != nil {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			cleanup()
+
+			return uploadBody{}, fmt.Errorf("seek compressed data: %w", err)
+		}
+		reader = file
+	}
+
+	return uploadBody{
+		reader:     reader,
+		size:       counter.n,
+		checksum:   hex.EncodeToString(hasher.Sum(nil)),
+		compressed: true,
+		cleanup:    cleanup,
+	}, nil
+}
+
+// decompressingWriter passes raw blobs through to dest and decompresses
+// blobs that start with compressedBlobMarker.
+type decompressingWriter struct {
+	dest      io.Writer
+	head      []byte
+	decided   bool
+	pw        *io.PipeWriter
+	done      chan struct{}
+	decodeErr error
+	wireBytes int64
+}
+
+func newDecompressingWriter(dest io.Writer) *decompressingWriter {
+	//nolint:exhaustruct
+	return &decompressingWriter{dest: dest}
+}
+
+func (w *decompressingWriter) Write(p []byte) (int, error) {
+	w.wireBytes += int64(len(p))
+
+	if w.decided {
+		return w.forward(p, len(p))
+	}
+
+	w.head = append(w.head, p...)
+	if len(w.head) < len(compressedBlobMarker) && bytes.HasPrefix(compressedBlobMarker, w.head) {
+		return len(p), nil
+	}
+
+	if err := w.decide(); err != nil {
+		return 0, err
+	}
+
+	head := w.head
+	w.head = nil
+
+	return w.forward(head, len(p))
+}
+
+// Close flushes a blob shorter than the marker and waits for decompression to finish.
+func (w *decompressingWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return err
+		}
+		if _, err := w.forward(w.head, 0); err != nil {
+			return err
+		}
+	}
+
+	if w.pw == nil {
+		return nil
+	}
+
+	_ = w.pw.Close()
+	<-w.done
+	if w.decodeErr != nil {
+		return fmt.Errorf("decompress: %w", w.decodeErr)
+	}
+
+	return nil
+}
+
+// Abort stops decompression after a failed download.
+func (w *decompressingWriter) Abort(err error) {
+	if w.pw == nil {
+		return
+	}
+
+	w.pw.CloseWithError(err)
+	<-w.done
+}
+
+func (w *decompressingWriter) decide() error {
+	w.decided = true
+	if !bytes.HasPrefix(w.head, compressedBlobMarker) {
+		return nil
+	}
+
+	pr, pw := io.Pipe()
+	decoder, err := zstd.NewReader(pr, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return fmt.Errorf("create zstd decoder: %w", err)
+	}
+
+	w.pw = pw
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+
+		_, err := io.Copy(w.dest, decoder)
+		decoder.Close()
+		w.decodeErr = err
+		pr.CloseWithError(err)
+	}()
+
+	return nil
+}
+
+// raw reports whether the blob turned out to be stored uncompressed, in which
+// case everything received so far was written to dest as is.
+func (w *decompressingWriter) raw() bool {
+	return w.decided && w.pw == nil
+}
+
+// forward writes p to the current sink and reports n bytes as consumed.
+func (w *decompressingWriter) forward(p []byte, n int) (int, error) {
+	if w.pw == nil {
+		if _, err := w.dest.Write(p); err != nil {
+			return 0, err //nolint:wrapcheck
+		}
+
+		return n, nil
+	}
+
+	if _, err := w.pw.Write(p); err != nil {
+		if errors.Is(err, io.ErrClosedPipe) {
+			<-w.done
+			if w.decodeErr != nil {
+				err = w.decodeErr
+			}
+		}
+
+		return 0, fmt.Errorf("decompress: %w", err)
+	}
+
+	return n, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err //nolint:wrapcheck
+}
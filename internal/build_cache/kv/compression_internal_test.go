@@ -0,0 +1,125 @@
+//go:build unit
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+)
+
+func Test_decompressingWriter_markerSplitAcrossWrites(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefgh"), 1024)
+	body, err := compress(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	defer body.cleanup()
+
+	var compressed bytes.Buffer
+	_, err = compressed.ReadFrom(body.reader)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(compressed.Bytes(), compressedBlobMarker))
+
+	var out bytes.Buffer
+	w := newDecompressingWriter(&out)
+	for _, b := range compressed.Bytes() {
+		_, err := w.Write([]byte{b})
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, data, out.Bytes())
+	assert.Equal(t, int64(compressed.Len()), w.wireBytes)
+}
+
+func Test_decompressingWriter_rawBlobs(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "shorter than the marker", data: []byte("abc")},
+		{name: "partial marker prefix", data: compressedBlobMarker[:6]},
+		{name: "diverges from the marker", data: append(append([]byte{}, compressedBlobMarker[:6]...), []byte("raw data")...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := newDecompressingWriter(&out)
+			_, err := w.Write(tt.data)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			assert.Equal(t, string(tt.data), out.String())
+		})
+	}
+}
+
+func Test_decompressingWriter_corruptStream(t *testing.T) {
+	var out bytes.Buffer
+	w := newDecompressingWriter(&out)
+	_, err := w.Write(append(append([]byte{}, compressedBlobMarker...), []byte("not zstd at all")...))
+	if err == nil {
+		err = w.Close()
+	}
+
+	require.Error(t, err)
+}
+
+func Test_fileGroup_compressedUploadAndRestore(t *testing.T) {
+	store := &memoryStore{blobs: make(map[string][]byte)}
+	client, err := NewClient(NewClientParams{
+		Logger:            log.NewLogger(),
+		BitriseKVClient:   store.kvClient(),
+		DownloadRetryWait: 1,
+		UploadRetryWait:   1,
+		CompressUploads:   true,
+	})
+	require.NoError(t, err)
+	client.endpoints.all[0].casClient = store
+	client.endpoints.all[0].acceptsZstd.Store(true)
+
+	dir := t.TempDir()
+	compressible := bytes.Repeat([]byte("compressible build output "), 4096)
+	fresh := filepath.Join(dir, "fresh.o")
+	require.NoError(t, os.WriteFile(fresh, compressible, 0o600))
+	// stored raw by a client without compression
+	legacy := filepath.Join(dir, "legacy.o")
+	require.NoError(t, os.WriteFile(legacy, []byte("uploaded without compression"), 0o600))
+	legacyInfo := collectFile(t, legacy)
+	store.blobs[legacyInfo.Hash] = []byte("uploaded without compression")
+
+	freshInfo := collectFile(t, fresh)
+	stats, err := client.UploadFileGroupToBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{freshInfo, legacyInfo}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.FilesUploaded, "the blob already stored raw is not uploaded again")
+	require.Contains(t, store.blobs, freshInfo.Hash, "compressed blobs are stored under the key of their raw bytes")
+	assert.True(t, bytes.HasPrefix(store.blobs[freshInfo.Hash], compressedBlobMarker))
+
+	// a second save finds everything present
+	stats, err = client.UploadFileGroupToBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{freshInfo, legacyInfo}})
+	require.NoError(t, err)
+	assert.Zero(t, stats.FilesUploaded)
+
+	restoreDir := t.TempDir()
+	restoredFresh, restoredLegacy := *freshInfo, *legacyInfo
+	restoredFresh.Path = filepath.Join(restoreDir, "fresh.o")
+	restoredLegacy.Path = filepath.Join(restoreDir, "legacy.o")
+	_, err = client.DownloadFileGroupFromBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{&restoredFresh, &restoredLegacy}}, false, false, false, 10)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(restoredFresh.Path)
+	require.NoError(t, err)
+	assert.Equal(t, compressible, content)
+	content, err = os.ReadFile(restoredLegacy.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "uploaded without compression", string(content))
+}
@@ -0,0 +1,176 @@
+package kv_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv/mocks"
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+)
+
+// committingStream acknowledges every byte it is sent.
+type committingStream struct {
+	*mocks.ClientStreamClientMock[bytestream.WriteRequest, bytestream.WriteResponse]
+
+	response *bytestream.WriteResponse
+}
+
+func (s *committingStream) Send(req *bytestream.WriteRequest) error {
+	s.response.CommittedSize += int64(len(req.GetData()))
+
+	return s.ClientStreamClientMock.Send(req)
+}
+
+// capabilities answers GetCapabilities, advertising zstd when zstd is set.
+type capabilities struct {
+	zstd bool
+}
+
+func (c capabilities) GetCapabilities(context.Context, *remoteexecution.GetCapabilitiesRequest, ...grpc.CallOption) (*remoteexecution.ServerCapabilities, error) {
+	caps := &remoteexecution.CacheCapabilities{}
+	if c.zstd {
+		caps.SupportedCompressors = []remoteexecution.Compressor_Value{remoteexecution.Compressor_ZSTD}
+	}
+
+	return &remoteexecution.ServerCapabilities{CacheCapabilities: caps}, nil
+}
+
+// uploadThenDownload uploads data through a client and serves the bytes that
+// went over the wire back to DownloadStream.
+func uploadThenDownload(t *testing.T, compress bool, data []byte) ([]byte, []byte) {
+	t.Helper()
+
+	response := &bytestream.WriteResponse{}
+	putStream := &committingStream{
+		ClientStreamClientMock: mocks.NewClientStreamClientMock[bytestream.WriteRequest, bytestream.WriteResponse](response, make([]error, 1024)),
+		response:               response,
+	}
+
+	var wire []byte
+	client, err := kv.NewClient(kv.NewClientParams{
+		Logger: mockLogger,
+		BitriseKVClient: &mocks.KVStorageClientMock{
+			PutFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bytestream.WriteRequest, bytestream.WriteResponse], error) {
+				return putStream, nil
+			},
+			GetFunc: func(ctx context.Context, in *bytestream.ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bytestream.ReadResponse], error) {
+				return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+					{Response: &bytestream.ReadResponse{Data: wire}},
+					{Error: io.EOF},
+				}), nil
+			},
+		},
+		CapabilitiesClient: capabilities{zstd: true},
+		UploadRetryWait:    1,
+		DownloadRetryWait:  1,
+		CompressUploads:    compress,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.GetCapabilities(context.Background()))
+
+	require.NoError(t, client.UploadStreamToBuildCache(context.Background(), bytes.NewReader(data), "key", int64(len(data))))
+	for _, req := range putStream.Requests() {
+		wire = append(wire, req.GetData()...)
+	}
+
+	destination := &bytes.Buffer{}
+	require.NoError(t, client.DownloadStream(context.Background(), destination, "key"))
+
+	return wire, destination.Bytes()
+}
+
+func TestClient_Compression_RoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible build output "), 4096)
+
+	wire, downloaded := uploadThenDownload(t, true, data)
+
+	assert.Less(t, len(wire), len(data))
+	assert.Equal(t, data, downloaded)
+}
+
+func TestClient_Compression_Disabled(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible build output "), 4096)
+
+	wire, downloaded := uploadThenDownload(t, false, data)
+
+	assert.Equal(t, data, wire)
+	assert.Equal(t, data, downloaded)
+}
+
+func TestClient_Compression_SmallBlobsStayRaw(t *testing.T) {
+	data := []byte("tiny")
+
+	wire, downloaded := uploadThenDownload(t, true, data)
+
+	assert.Equal(t, data, wire)
+	assert.Equal(t, data, downloaded)
+}
+
+func TestClient_Compression_IncompressibleBlobsStayRaw(t *testing.T) {
+	// xorshift noise, which zstd cannot shrink
+	data := make([]byte, 4096)
+	state := uint32(2463534242)
+	for i := range data {
+		state ^= state << 13
+		state ^= state >> 17
+		state ^= state << 5
+		data[i] = byte(state)
+	}
+
+	wire, downloaded := uploadThenDownload(t, true, data)
+
+	assert.Equal(t, data, wire)
+	assert.Equal(t, data, downloaded)
+}
+
+func TestClient_Compression_RequiresServerCapability(t *testing.T) {
+	tests := []struct {
+		name           string
+		advertisesZstd bool
+		wantCompressed bool
+	}{
+		{name: "zstd advertised", advertisesZstd: true, wantCompressed: true},
+		{name: "zstd not advertised", advertisesZstd: false, wantCompressed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &bytestream.WriteResponse{}
+			putStream := &committingStream{
+				ClientStreamClientMock: mocks.NewClientStreamClientMock[bytestream.WriteRequest, bytestream.WriteResponse](response, make([]error, 1024)),
+				response:               response,
+			}
+
+			client, err := kv.NewClient(kv.NewClientParams{
+				Logger: mockLogger,
+				BitriseKVClient: &mocks.KVStorageClientMock{
+					PutFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bytestream.WriteRequest, bytestream.WriteResponse], error) {
+						return putStream, nil
+					},
+				},
+				CapabilitiesClient: capabilities{zstd: tt.advertisesZstd},
+				UploadRetryWait:    1,
+				CompressUploads:    true,
+			})
+			require.NoError(t, err)
+			require.NoError(t, client.GetCapabilities(context.Background()))
+
+			data := bytes.Repeat([]byte("compressible build output "), 4096)
+			require.NoError(t, client.UploadStreamToBuildCache(context.Background(), bytes.NewReader(data), "key", int64(len(data))))
+
+			var wire []byte
+			for _, req := range putStream.Requests() {
+				wire = append(wire, req.GetData()...)
+			}
+			assert.Equal(t, "kv/key", putStream.Requests()[0].GetResourceName(), "compressed blobs keep the plain key")
+			assert.Equal(t, tt.wantCompressed, len(wire) < len(data))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
@@ -16,17 +17,36 @@ import (
 	"github.com/bitrise-io/go-utils/v2/retry"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
 )
 
 var (
 	// ErrCacheNotFound ...
 	ErrCacheNotFound        = errors.New("no cache archive found for the provided keys")
 	ErrCacheUnauthenticated = errors.New("unauthenticated")
+	// ErrDegraded is returned by uploads skipped because the circuit breaker
+	// is open. Nothing was stored: callers treat it as a no-op, not an upload.
+	ErrDegraded = errors.New("remote cache is degraded, upload skipped")
 )
 
 // ErrFileExistsAndNotWritable ...
 var ErrFileExistsAndNotWritable = errors.New("file already exists and is not writable")
 
+// errHashMismatch means the downloaded data is corrupt, so it must not be resumed.
+var errHashMismatch = errors.New("downloaded file hash mismatch")
+
+type downloadResult struct {
+	transferStats
+
+	skipped bool
+	// wireBytes is the number of bytes received over the wire.
+	wireBytes int64
+	// resumable is set when a failed download left a partial raw file behind
+	// that a later attempt can continue.
+	resumable bool
+}
+
 func (c *Client) DownloadFileFromBuildCache(ctx context.Context, fileName, key string) error {
 	c.logger.Debugf("Downloading %s", fileName)
 
@@ -41,11 +61,65 @@ func (c *Client) DownloadStreamFromBuildCache(ctx context.Context, destination i
 	return c.DownloadStream(ctx, destination, key)
 }
 
-// nolint: nestif
 func (c *Client) DownloadFile(ctx context.Context, filePath, key string, fileMode os.FileMode, isDebugLogMode, skipExisting, forceOverwrite bool) (bool, error) {
+	result, err := c.downloadFile(ctx, filePath, key, "", fileMode, isDebugLogMode, skipExisting, forceOverwrite, false)
+
+	return result.skipped, err
+}
+
+// downloadFile downloads key to filePath. With resume set, it continues the
+// partial file a previous resumable attempt left behind instead. A non-empty
+// digest is the SHA-256 the content must match, see verifyContent.
+func (c *Client) downloadFile(ctx context.Context, filePath, key, digest string, fileMode os.FileMode, isDebugLogMode, skipExisting, forceOverwrite, resume bool) (downloadResult, error) {
+	if resume {
+		return c.resumeDownloadFile(ctx, filePath, key, digest)
+	}
+
+	file, skipped, err := c.openDestination(filePath, fileMode, isDebugLogMode, skipExisting, forceOverwrite)
+	if err != nil || skipped {
+		return downloadResult{skipped: skipped}, err
+	}
+	defer file.Close()
+
+	return c.downloadDecompressed(ctx, file, key, digest)
+}
+
+// downloadChunkedFile reassembles a file from its content-defined chunks and
+// validates the result against the whole-file hash.
+func (c *Client) downloadChunkedFile(ctx context.Context, fileInfo *filegroup.FileInfo, isDebugLogMode, skipExisting, forceOverwrite bool) (downloadResult, error) {
+	file, skipped, err := c.openDestination(fileInfo.Path, fileInfo.Mode, isDebugLogMode, skipExisting, forceOverwrite)
+	if err != nil || skipped {
+		return downloadResult{skipped: skipped}, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	destination := io.MultiWriter(file, hasher)
+
+	var result downloadResult
+	for i, chunk := range fileInfo.Chunks {
+		chunkResult, err := c.downloadDecompressed(ctx, destination, chunk.Hash, chunk.Hash)
+		result.add(chunkResult.transferStats)
+		result.wireBytes += chunkResult.wireBytes
+		if err != nil {
+			return result, fmt.Errorf("chunk %d of %d: %w", i+1, len(fileInfo.Chunks), err)
+		}
+	}
+
+	if fileHash := hex.EncodeToString(hasher.Sum(nil)); fileHash != fileInfo.Hash {
+		return result, fmt.Errorf("%w for reassembled file %q: expected %s, got %s", errHashMismatch, fileInfo.Path, fileInfo.Hash, fileHash)
+	}
+
+	return result, nil
+}
+
+// openDestination creates or truncates filePath for writing. It reports
+// skipped when the file exists and skipExisting is set.
+// nolint: nestif
+func (c *Client) openDestination(filePath string, fileMode os.FileMode, isDebugLogMode, skipExisting, forceOverwrite bool) (*os.File, bool, error) {
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return false, fmt.Errorf("create directory: %w", err)
+		return nil, false, fmt.Errorf("create directory: %w", err)
 	}
 
 	if fileMode == 0 {
@@ -54,21 +128,21 @@ func (c *Client) DownloadFile(ctx context.Context, filePath, key string, fileMod
 
 	if fileInfo, err := os.Stat(filePath); err == nil {
 		if skipExisting {
-			return true, nil
+			return nil, true, nil
 		}
 
 		ownerWritable := (fileInfo.Mode().Perm() & 0o200) != 0
 		if !ownerWritable {
 			if !forceOverwrite {
-				return false, ErrFileExistsAndNotWritable
+				return nil, false, ErrFileExistsAndNotWritable
 			}
 
 			if err := os.Chmod(filePath, 0o666); err != nil {
-				return false, fmt.Errorf("force overwrite - failed to change existing file permissions: %w", err)
+				return nil, false, fmt.Errorf("force overwrite - failed to change existing file permissions: %w", err)
 			}
 
 			if err := os.Remove(filePath); err != nil {
-				return false, fmt.Errorf("force overwrite - failed to remove existing file: %w", err)
+				return nil, false, fmt.Errorf("force overwrite - failed to remove existing file: %w", err)
 			}
 		}
 	}
@@ -79,19 +153,114 @@ func (c *Client) DownloadFile(ctx context.Context, filePath, key string, fileMod
 			c.logFilePathDebugInfo(filePath)
 		}
 
-		return false, fmt.Errorf("create %q: %w", filePath, err)
+		return nil, false, fmt.Errorf("create %q: %w", filePath, err)
+	}
+
+	return file, false, nil
+}
+
+// resumeDownloadFile appends the rest of a raw blob to a partial file. The
+// partial content is hashed first so the whole blob can still be validated.
+func (c *Client) resumeDownloadFile(ctx context.Context, filePath, key, digest string) (downloadResult, error) {
+	file, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return downloadResult{}, fmt.Errorf("open partial file %q: %w", filePath, err)
 	}
 	defer file.Close()
 
-	return false, c.DownloadStream(ctx, file, key)
+	hasher := sha256.New()
+	offset, err := io.Copy(hasher, file)
+	if err != nil {
+		return downloadResult{}, fmt.Errorf("hash partial file %q: %w", filePath, err)
+	}
+
+	c.logger.Debugf("Resuming download of %s at offset %d", key, offset)
+
+	counter := &countingWriter{w: file}
+	stats, err := c.downloadStream(ctx, counter, key, offset, hasher)
+	stats.resumedBytes += offset
+	if err == nil {
+		// a raw blob is its content, so the wire hash is the content digest
+		err = c.verifyContent(ctx, key, digest, hasher)
+	}
+
+	return downloadResult{
+		transferStats: stats,
+		wireBytes:     counter.n,
+		resumable:     err != nil && !errors.Is(err, errHashMismatch) && !errors.Is(err, ErrCorruptedBlob),
+	}, err
 }
 
+// DownloadStream writes the blob stored under key to destination, decompressing
+// it if it was uploaded compressed.
 func (c *Client) DownloadStream(ctx context.Context, destination io.Writer, key string) error {
-	var offset int64
+	_, err := c.downloadDecompressed(ctx, destination, key, "")
+
+	return err
+}
+
+// downloadDecompressed writes the decompressed blob stored under key to
+// destination. A non-empty digest is verified against the decompressed content.
+func (c *Client) downloadDecompressed(ctx context.Context, destination io.Writer, key, digest string) (downloadResult, error) {
+	content := sha256.New()
+	if digest != "" {
+		destination = io.MultiWriter(destination, content)
+	}
+
+	writer := newDecompressingWriter(destination)
+	stats, err := c.downloadStream(ctx, writer, key, 0, sha256.New())
+	result := downloadResult{transferStats: stats}
+	if err != nil {
+		writer.Abort(err)
+		result.wireBytes = writer.wireBytes
+		result.resumable = writer.raw() && !errors.Is(err, errHashMismatch)
+
+		return result, err
+	}
+
+	err = writer.Close()
+	result.wireBytes = writer.wireBytes
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return result, c.verifyContent(ctx, key, digest, content)
+}
+
+// verifyContent checks the SHA-256 of a content-addressed blob against digest,
+// the key it is stored under for file groups. On a mismatch the key is
+// quarantined and ErrCorruptedBlob is returned. An empty digest is not checked.
+func (c *Client) verifyContent(ctx context.Context, key, digest string, content hash.Hash) error {
+	if digest == "" {
+		return nil
+	}
+
+	if got := hex.EncodeToString(content.Sum(nil)); got != digest {
+		return c.corrupted(ctx, key, digest, got)
+	}
+
+	return nil
+}
+
+// downloadStream writes the blob stored under key to destination, starting at
+// offset and resuming from the last written byte on retries. hasher must
+// already contain the first offset bytes of the blob.
+func (c *Client) downloadStream(ctx context.Context, destination io.Writer, key string, offset int64, hasher hash.Hash) (transferStats, error) {
+	if !c.breaker.Allow() {
+		c.logger.TDebugf("Skipping download of %s: remote cache is degraded", key)
+
+		return transferStats{}, ErrCacheNotFound
+	}
+	if c.quarantine.contains(key) {
+		c.logger.Debugf("Skipping download of %s: cache entry is quarantined as corrupted", key)
+
+		return transferStats{}, ErrCacheNotFound
+	}
+
+	var stats transferStats
 	var totalBytes int64
 	var attempts uint
 
-	hasher := sha256.New()
 	multiWriter := io.MultiWriter(hasher, destination)
 	expectedHash := ""
 
@@ -100,10 +269,23 @@ func (c *Client) DownloadStream(ctx context.Context, destination io.Writer, key
 		if attempt == 0 {
 			c.logger.TDebugf("Downloading %s", key)
 		} else {
+			stats.retries++
 			c.logger.TInfof("%d. attempt to download %s with offset %d", attempt+1, key, offset)
+			stats.resumedBytes += offset
 		}
 
-		timeoutCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		const downloadTimeout = 60 * time.Second
+		timeoutCtx, cancel := context.WithTimeout(ctx, downloadTimeout)
+		source := func(r io.Reader) io.Reader { return r }
+		if c.downloadBandwidth != nil {
+			// A throttled download can take longer than the deadline, so it is only aborted when it stalls
+			cancel()
+			var touch func()
+			timeoutCtx, cancel, touch = withIdleTimeout(ctx, downloadTimeout)
+			source = func(r io.Reader) io.Reader {
+				return &progressReader{r: c.downloadBandwidth.Reader(timeoutCtx, r), onProgress: touch}
+			}
+		}
 		defer cancel()
 
 		kvReader, err := c.initiateGet(timeoutCtx, c.logger, key, offset)
@@ -114,7 +296,7 @@ func (c *Client) DownloadStream(ctx context.Context, destination io.Writer, key
 		}
 		defer kvReader.Close()
 
-		n, copyErr := io.Copy(multiWriter, kvReader)
+		n, copyErr := io.Copy(multiWriter, source(kvReader))
 		totalBytes += n
 		if copyErr != nil {
 			st, ok := status.FromError(copyErr)
@@ -137,17 +319,18 @@ func (c *Client) DownloadStream(ctx context.Context, destination io.Writer, key
 
 		return nil, false
 	})
+	c.recordOutcome(downloadErr)
 	if downloadErr != nil {
 		//nolint: wrapcheck
-		return downloadErr
+		return stats, downloadErr
 	}
 
 	if expectedHash != "" {
 		fileHash := hex.EncodeToString(hasher.Sum(nil))
 		if expectedHash != fileHash {
-			return fmt.Errorf(
-				"downloaded file hash mismatch for key %q: expected %s, got %s (received %d bytes across %d attempt(s), retried offset %d, cache-operation-id=%q, invocation-id=%q)",
-				key, expectedHash, fileHash,
+			return stats, fmt.Errorf(
+				"%w for key %q: expected %s, got %s (received %d bytes across %d attempt(s), retried offset %d, cache-operation-id=%q, invocation-id=%q)",
+				errHashMismatch, key, expectedHash, fileHash,
 				totalBytes, attempts, offset,
 				c.cacheOperationID, c.invocationID,
 			)
@@ -156,7 +339,7 @@ func (c *Client) DownloadStream(ctx context.Context, destination io.Writer, key
 		c.logger.TDebugf("Downloaded %s hash matches expected: %s", key, expectedHash)
 	}
 
-	return nil
+	return stats, nil
 }
 
 func (c *Client) logFilePathDebugInfo(filePath string) {
@@ -21,7 +21,27 @@ type DownloadFilesStats struct {
 	FilesMissing          int
 	FilesFailedToDownload int
 	DownloadSize          int64
-	LargestFileSize       int64
+	// CompressedDownloadSize is the number of bytes received over the wire. It equals DownloadSize for raw blobs.
+	CompressedDownloadSize int64
+	LargestFileSize        int64
+	// RetryAttempts counts retried transfers, across all files.
+	RetryAttempts int
+	// ResumedBytes is the number of bytes retries did not have to download again.
+	ResumedBytes int64
+	// FilesCorrupted counts files whose content did not match their digest. They are also counted as missing.
+	FilesCorrupted int
+	// Concurrency is the maximum number of files transferred in parallel.
+	Concurrency int
+	// MinConcurrency is the lowest number of parallel transfers adaptive concurrency backed off to.
+	MinConcurrency int
+	// ConcurrencyBackoffs counts how many times adaptive concurrency backed off on ResourceExhausted or Unavailable errors.
+	ConcurrencyBackoffs int
+	// BandwidthLimit is the bandwidth limit in bytes per second, 0 when unlimited.
+	BandwidthLimit int64
+	// BandwidthWait is the total time transfers were held back by the bandwidth limit.
+	BandwidthWait time.Duration
+	// Endpoint is the cache host in use when the download finished.
+	Endpoint string
 }
 
 // nolint: gocognit
@@ -42,31 +62,52 @@ func (c *Client) DownloadFileGroupFromBuildCache(ctx context.Context, dd filegro
 	var filesDownloaded atomic.Int32
 	var filesMissing atomic.Int32
 	var filesFailedToDownload atomic.Int32
+	var filesCorrupted atomic.Int32
 	var downloadSize atomic.Int64
+	var compressedDownloadSize atomic.Int64
+	var retryAttempts atomic.Int64
+	var resumedBytes atomic.Int64
 	var skippedFiles atomic.Int32
 
+	workers := newConcurrencyLimiter(c.transfer.DownloadConcurrency, c.transfer.AdaptiveConcurrency)
+	bandwidthWaitBefore := c.downloadBandwidth.Waited()
+
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 20) // Limit parallelization
 	for _, file := range dd.Files {
 		wg.Add(1)
-		semaphore <- struct{}{} // Block if there are too many goroutines are running
+		workers.acquire() // Block if there are too many goroutines are running
 
 		go func(file *filegroup.FileInfo) {
 			defer wg.Done()
-			defer func() { <-semaphore }() // Release a slot in the semaphore
+			defer workers.release()
 
 			const retries = 3
+			var wireSize int64
+			var transfer transferStats
+			resume := false
 			err := retry.Times(retries).Wait(3 * time.Second).TryWithAbort(func(attempt uint) (error, bool) {
 				if attempt > 0 {
 					c.logger.Debugf("Retrying download... (attempt %d)", attempt)
+					transfer.retries++
 				}
 
-				skipped, err := c.DownloadFile(ctx, file.Path, file.Hash, file.Mode, isDebugLogMode, forceOverwrite, skipExisting)
-				if skipped {
+				var result downloadResult
+				var err error
+				if len(file.Chunks) > 0 {
+					result, err = c.downloadChunkedFile(ctx, file, isDebugLogMode, skipExisting, forceOverwrite)
+				} else {
+					result, err = c.downloadFile(ctx, file.Path, file.Hash, file.Hash, file.Mode, isDebugLogMode, skipExisting, forceOverwrite, resume)
+				}
+				wireSize += result.wireBytes
+				transfer.add(result.transferStats)
+				// A partial raw file is continued by the next attempt instead of being downloaded again.
+				resume = result.resumable
+				if result.skipped {
 					skippedFiles.Add(1)
 
 					return nil, false
 				}
+				workers.record(err)
 
 				if err != nil {
 					c.logger.Errorf("Error in download file attempt %d: %s", attempt, err)
@@ -79,6 +120,8 @@ func (c *Client) DownloadFileGroupFromBuildCache(ctx context.Context, dd filegro
 					return err, true
 				case errors.Is(err, ErrFileExistsAndNotWritable):
 					return err, true
+				case errors.Is(err, ErrCorruptedBlob):
+					return err, true
 				case err != nil:
 					return fmt.Errorf("download file: %w", err), false
 				}
@@ -90,8 +133,22 @@ func (c *Client) DownloadFileGroupFromBuildCache(ctx context.Context, dd filegro
 				return nil, false
 			})
 
+			retryAttempts.Add(int64(transfer.retries))
+			resumedBytes.Add(transfer.resumedBytes)
+
 			missingPlusFailed := filesMissing.Load() + filesFailedToDownload.Load()
 			switch {
+			case errors.Is(err, ErrCorruptedBlob):
+				if int(missingPlusFailed) < maxLoggedDownloadErrors {
+					c.logger.Warnf("Corrupted cache entry for file %s: %s", file.Path, err)
+				}
+				// the corrupted content must not be mistaken for a restored file
+				if removeErr := os.Remove(file.Path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+					c.logger.Warnf("Failed to remove corrupted file %s: %s", file.Path, removeErr)
+				}
+
+				filesCorrupted.Add(1)
+				filesMissing.Add(1)
 			case errors.Is(err, ErrCacheNotFound):
 				if int(missingPlusFailed) < maxLoggedDownloadErrors {
 					c.logger.Infof("Cache entry not found for file %s (%s)", file.Path, file.Hash)
@@ -107,6 +164,7 @@ func (c *Client) DownloadFileGroupFromBuildCache(ctx context.Context, dd filegro
 			default:
 				filesDownloaded.Add(1)
 				downloadSize.Add(file.Size)
+				compressedDownloadSize.Add(wireSize)
 			}
 		}(file)
 	}
@@ -114,31 +172,51 @@ func (c *Client) DownloadFileGroupFromBuildCache(ctx context.Context, dd filegro
 	wg.Wait()
 
 	//nolint: gosec
-	c.logger.TInfof("(i) Downloaded: %d files (%s). Missing: %d files. Failed: %d files", filesDownloaded.Load(), humanize.Bytes(uint64(downloadSize.Load())), filesMissing.Load(), filesFailedToDownload.Load())
+	c.logger.TInfof("(i) Downloaded: %d files (%s). Missing: %d files (%d corrupted). Failed: %d files", filesDownloaded.Load(), humanize.Bytes(uint64(downloadSize.Load())), filesMissing.Load(), filesCorrupted.Load(), filesFailedToDownload.Load())
 
 	stats := DownloadFilesStats{
-		FilesToBeDownloaded:   len(dd.Files) - int(skippedFiles.Load()),
-		FilesDownloaded:       int(filesDownloaded.Load()),
-		FilesMissing:          int(filesMissing.Load()),
-		FilesFailedToDownload: int(filesFailedToDownload.Load()),
-		DownloadSize:          downloadSize.Load(),
-		LargestFileSize:       largestFileSize,
+		FilesToBeDownloaded:    len(dd.Files) - int(skippedFiles.Load()),
+		FilesDownloaded:        int(filesDownloaded.Load()),
+		FilesMissing:           int(filesMissing.Load()),
+		FilesFailedToDownload:  int(filesFailedToDownload.Load()),
+		FilesCorrupted:         int(filesCorrupted.Load()),
+		DownloadSize:           downloadSize.Load(),
+		CompressedDownloadSize: compressedDownloadSize.Load(),
+		LargestFileSize:        largestFileSize,
+		RetryAttempts:          int(retryAttempts.Load()),
+		ResumedBytes:           resumedBytes.Load(),
+		BandwidthLimit:         c.downloadBandwidth.Limit(),
+		BandwidthWait:          c.downloadBandwidth.Waited() - bandwidthWaitBefore,
 	}
+	stats.Concurrency, stats.MinConcurrency, stats.ConcurrencyBackoffs = workers.stats()
 	c.logger.Debugf("Download stats:")
 	c.logger.Debugf("  Files to be downloaded: %d", stats.FilesToBeDownloaded)
 	c.logger.Debugf("  Files downloaded: %d", stats.FilesDownloaded)
 	c.logger.Debugf("  Files missing: %d", stats.FilesMissing)
 	c.logger.Debugf("  Files failed to download: %d", stats.FilesFailedToDownload)
+	c.logger.Debugf("  Files corrupted: %d", stats.FilesCorrupted)
 	c.logger.Debugf("  Files skipped (existing): %d", skippedFiles.Load())
 	//nolint: gosec
 	c.logger.Debugf("  Download size: %s", humanize.Bytes(uint64(stats.DownloadSize)))
 	//nolint: gosec
+	c.logger.Debugf("  Download size over the wire: %s", humanize.Bytes(uint64(stats.CompressedDownloadSize)))
+	//nolint: gosec
 	c.logger.Debugf("  Largest file size: %s", humanize.Bytes(uint64(stats.LargestFileSize)))
+	c.logger.Debugf("  Retry attempts: %d", stats.RetryAttempts)
+	//nolint: gosec
+	c.logger.Debugf("  Resumed: %s", humanize.Bytes(uint64(stats.ResumedBytes)))
+	c.logger.Debugf("  Concurrency: %d (min %d, %d back-offs)", stats.Concurrency, stats.MinConcurrency, stats.ConcurrencyBackoffs)
+	c.logger.Debugf("  Bandwidth wait: %s", stats.BandwidthWait)
 
 	if maxLoggedDownloadErrors < stats.FilesFailedToDownload+stats.FilesMissing {
 		c.logger.Warnf("Too many download errors or missing files, only the first %d errors were logged", maxLoggedDownloadErrors)
 	}
 
+	stats.Endpoint = c.Endpoint()
+
+	if stats.FilesCorrupted > 0 {
+		return stats, fmt.Errorf("failed to download some files: %w (%d files)", ErrCorruptedBlob, stats.FilesCorrupted)
+	}
 	if stats.FilesFailedToDownload > 0 || stats.FilesMissing > 0 {
 		return stats, fmt.Errorf("failed to download some files")
 	}
@@ -0,0 +1,62 @@
+//go:build unit
+
+package kv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+)
+
+// Regression test: DownloadFileGroupFromBuildCache used to pass skipExisting
+// and forceOverwrite to the single-file download in swapped order.
+func Test_DownloadFileGroupFromBuildCache_existingFileFlags(t *testing.T) {
+	const remoteContent = "remote content"
+	key := sha256Hex(remoteContent)
+
+	tests := []struct {
+		name           string
+		skipExisting   bool
+		forceOverwrite bool
+		wantContent    string
+		wantErr        bool
+	}{
+		{name: "skip existing keeps the local file", skipExisting: true, wantContent: "local"},
+		{name: "force overwrite replaces a read-only file", forceOverwrite: true, wantContent: remoteContent},
+		{name: "read-only file without force overwrite fails", wantContent: "local", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{blobs: map[string][]byte{key: []byte(remoteContent)}}
+			client, err := NewClient(NewClientParams{
+				Logger:            log.NewLogger(),
+				BitriseKVClient:   store.kvClient(),
+				DownloadRetryWait: 1,
+			})
+			require.NoError(t, err)
+
+			path := filepath.Join(t.TempDir(), "file")
+			require.NoError(t, os.WriteFile(path, []byte("local"), 0o444))
+
+			file := &filegroup.FileInfo{Path: path, Hash: key, Size: int64(len(remoteContent))}
+			_, err = client.DownloadFileGroupFromBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{file}},
+				false, tt.skipExisting, tt.forceOverwrite, 10)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+		})
+	}
+}
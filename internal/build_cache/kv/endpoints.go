@@ -0,0 +1,229 @@
+package kv
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/kv_storage"
+)
+
+const (
+	// failoverThreshold is the number of consecutive Unavailable errors after
+	// which the client switches to the next endpoint.
+	failoverThreshold    = 3
+	endpointProbeTimeout = 3 * time.Second
+)
+
+// Endpoint is a remote cache the client can talk to.
+type Endpoint struct {
+	Host        string
+	UseInsecure bool
+}
+
+// EndpointProbeResult is the outcome of probing one endpoint with GetCapabilities.
+type EndpointProbeResult struct {
+	Endpoint Endpoint
+	Latency  time.Duration
+	Err      error
+}
+
+// endpointConn holds the gRPC stubs bound to one endpoint.
+type endpointConn struct {
+	Endpoint
+
+	conn               *grpc.ClientConn
+	kvClient           kv_storage.KVStorageClient
+	capabilitiesClient remoteexecution.CapabilitiesClient
+	casClient          remoteexecution.ContentAddressableStorageClient
+	// acceptsZstd is set once the endpoint advertised zstd among its
+	// supported compressors.
+	acceptsZstd atomic.Bool
+}
+
+func (e *endpointConn) recordCapabilities(capabilities *remoteexecution.ServerCapabilities) {
+	e.acceptsZstd.Store(slices.Contains(capabilities.GetCacheCapabilities().GetSupportedCompressors(), remoteexecution.Compressor_ZSTD))
+}
+
+func dialEndpoint(e Endpoint) (*endpointConn, error) {
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if e.UseInsecure {
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(e.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", e.Host, err)
+	}
+
+	return &endpointConn{
+		Endpoint:           e,
+		conn:               conn,
+		kvClient:           kv_storage.NewKVStorageClient(conn),
+		capabilitiesClient: remoteexecution.NewCapabilitiesClient(conn),
+		casClient:          remoteexecution.NewContentAddressableStorageClient(conn),
+	}, nil
+}
+
+func closeEndpoints(endpoints []*endpointConn) error {
+	var errs []error
+	for _, e := range endpoints {
+		if e.conn == nil {
+			continue
+		}
+		if err := e.conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close kv grpc conn to %s: %w", e.Host, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// endpointSet tracks the active endpoint and fails over on repeated
+// Unavailable errors.
+type endpointSet struct {
+	mutex       sync.Mutex
+	all         []*endpointConn
+	active      int
+	unavailable int
+}
+
+func (s *endpointSet) current() *endpointConn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.all[s.active]
+}
+
+func (s *endpointSet) use(index int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.active = index
+	s.unavailable = 0
+}
+
+// record returns the endpoint switched to, or nil if the active one is kept.
+func (s *endpointSet) record(err error) *endpointConn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if status.Code(err) != codes.Unavailable {
+		if err == nil || !errors.Is(err, context.Canceled) {
+			s.unavailable = 0
+		}
+
+		return nil
+	}
+
+	s.unavailable++
+	if s.unavailable < failoverThreshold || len(s.all) < 2 {
+		return nil
+	}
+
+	s.active = (s.active + 1) % len(s.all)
+	s.unavailable = 0
+
+	return s.all[s.active]
+}
+
+// Endpoint returns the host the client currently talks to.
+func (c *Client) Endpoint() string {
+	return c.endpoints.current().Host
+}
+
+// ProbeEndpoints calls GetCapabilities on every endpoint concurrently.
+// Results are in the order the endpoints were configured.
+func (c *Client) ProbeEndpoints(ctx context.Context) []EndpointProbeResult {
+	results := make([]EndpointProbeResult, len(c.endpoints.all))
+
+	var wg sync.WaitGroup
+	for i, e := range c.endpoints.all {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+			defer cancel()
+			callCtx := metadata.NewOutgoingContext(timeoutCtx, c.getMethodCallMetadata(false))
+
+			start := time.Now()
+			capabilities, err := e.capabilitiesClient.GetCapabilities(callCtx, &remoteexecution.GetCapabilitiesRequest{})
+			if err == nil {
+				e.recordCapabilities(capabilities)
+			}
+			results[i] = EndpointProbeResult{Endpoint: e.Endpoint, Latency: time.Since(start), Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// SelectEndpoint probes all endpoints and switches to the healthy one with the
+// lowest latency. With a single endpoint it does nothing. If none is healthy
+// the first one is kept.
+func (c *Client) SelectEndpoint(ctx context.Context) {
+	if len(c.endpoints.all) < 2 {
+		return
+	}
+
+	results := c.ProbeEndpoints(ctx)
+	for _, r := range results {
+		if r.Err != nil {
+			c.logger.Debugf("Endpoint %s is unhealthy: %s", r.Endpoint.Host, r.Err)
+		} else {
+			c.logger.Debugf("Endpoint %s responded in %s", r.Endpoint.Host, r.Latency)
+		}
+	}
+
+	best, ok := FastestEndpoint(results)
+	if !ok {
+		c.logger.Warnf("None of the %d Build Cache endpoints responded, using %s", len(results), c.Endpoint())
+
+		return
+	}
+
+	c.endpoints.use(best)
+	c.logger.Infof("(i) Selected Build Cache endpoint %s (%s)", results[best].Endpoint.Host, results[best].Latency.Round(time.Millisecond))
+}
+
+// FastestEndpoint returns the index of the healthy result with the lowest
+// latency, or false if no endpoint is healthy.
+func FastestEndpoint(results []EndpointProbeResult) (int, bool) {
+	best := -1
+	for i, r := range results {
+		if r.Err == nil && (best < 0 || r.Latency < results[best].Latency) {
+			best = i
+		}
+	}
+
+	return best, best >= 0
+}
+
+// recordOutcome feeds the result of a remote call to the circuit breaker and
+// fails over to the next endpoint on repeated Unavailable errors.
+func (c *Client) recordOutcome(err error) {
+	if next := c.endpoints.record(err); next != nil {
+		c.logger.TWarnf("Build Cache endpoint unavailable, failing over to %s", next.Host)
+		// the new endpoint gets a fresh chance before degraded mode kicks in
+		c.breaker.Record(nil)
+
+		return
+	}
+
+	c.breaker.Record(err)
+}
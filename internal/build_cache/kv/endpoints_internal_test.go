@@ -0,0 +1,98 @@
+//go:build unit
+
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+)
+
+type fakeCapabilities struct {
+	delay time.Duration
+	err   error
+}
+
+func (f fakeCapabilities) GetCapabilities(context.Context, *remoteexecution.GetCapabilitiesRequest, ...grpc.CallOption) (*remoteexecution.ServerCapabilities, error) {
+	time.Sleep(f.delay)
+
+	return &remoteexecution.ServerCapabilities{}, f.err
+}
+
+func newMultiEndpointClient(t *testing.T, hosts ...string) *Client {
+	t.Helper()
+
+	endpoints := make([]Endpoint, 0, len(hosts))
+	for _, h := range hosts {
+		endpoints = append(endpoints, Endpoint{Host: h, UseInsecure: true})
+	}
+
+	client, err := NewClient(NewClientParams{
+		Endpoints:         endpoints,
+		Logger:            log.NewLogger(),
+		DownloadRetryWait: 1,
+		UploadRetryWait:   1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func Test_SelectEndpoint_picksFastestHealthy(t *testing.T) {
+	client := newMultiEndpointClient(t, "a:80", "b:80", "c:80")
+	client.endpoints.all[0].capabilitiesClient = fakeCapabilities{delay: 50 * time.Millisecond}
+	client.endpoints.all[1].capabilitiesClient = fakeCapabilities{err: status.Error(codes.Unavailable, "down")}
+	client.endpoints.all[2].capabilitiesClient = fakeCapabilities{delay: 5 * time.Millisecond}
+
+	client.SelectEndpoint(context.Background())
+
+	assert.Equal(t, "c:80", client.Endpoint())
+}
+
+func Test_SelectEndpoint_keepsPrimaryWhenNoneHealthy(t *testing.T) {
+	client := newMultiEndpointClient(t, "a:80", "b:80")
+	for _, e := range client.endpoints.all {
+		e.capabilitiesClient = fakeCapabilities{err: status.Error(codes.Unavailable, "down")}
+	}
+
+	client.SelectEndpoint(context.Background())
+
+	assert.Equal(t, "a:80", client.Endpoint())
+}
+
+func Test_recordOutcome_failsOverAfterRepeatedUnavailable(t *testing.T) {
+	client := newMultiEndpointClient(t, "a:80", "b:80")
+	client.endpoints.all[0].capabilitiesClient = fakeCapabilities{err: status.Error(codes.Unavailable, "down")}
+	client.endpoints.all[1].capabilitiesClient = fakeCapabilities{}
+
+	for range failoverThreshold {
+		assert.Equal(t, "a:80", client.Endpoint())
+		require.Error(t, client.GetCapabilities(context.Background()))
+	}
+
+	assert.Equal(t, "b:80", client.Endpoint())
+	require.NoError(t, client.GetCapabilities(context.Background()))
+}
+
+func Test_recordOutcome_otherErrorsResetFailoverCount(t *testing.T) {
+	client := newMultiEndpointClient(t, "a:80", "b:80")
+
+	unavailable := status.Error(codes.Unavailable, "down")
+	for range failoverThreshold - 1 {
+		client.recordOutcome(unavailable)
+	}
+	client.recordOutcome(status.Error(codes.NotFound, "missing"))
+	client.recordOutcome(unavailable)
+
+	assert.Equal(t, "a:80", client.Endpoint())
+}
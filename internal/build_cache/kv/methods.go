@@ -41,7 +41,12 @@ func (c *Client) GetCapabilities(ctx context.Context) error {
 	defer cancel()
 	callCtx := metadata.NewOutgoingContext(timeoutCtx, c.getMethodCallMetadata(true))
 
-	_, err := c.capabilitiesClient.GetCapabilities(callCtx, &remoteexecution.GetCapabilitiesRequest{})
+	endpoint := c.endpoints.current()
+	capabilities, err := endpoint.capabilitiesClient.GetCapabilities(callCtx, &remoteexecution.GetCapabilitiesRequest{})
+	c.recordOutcome(err)
+	if err == nil {
+		endpoint.recordCapabilities(capabilities)
+	}
 	if err != nil {
 		st, ok := status.FromError(err)
 		if ok && st.Code() == codes.Unauthenticated {
@@ -55,6 +60,8 @@ func (c *Client) GetCapabilities(ctx context.Context) error {
 }
 
 func (c *Client) GetCapabilitiesWithRetry(ctx context.Context) error {
+	c.SelectEndpoint(ctx)
+
 	//nolint:wrapcheck
 	return retry.Times(10).Wait(3 * time.Second).TryWithAbort(func(attempt uint) (error, bool) {
 		if attempt > 0 {
@@ -66,6 +73,11 @@ func (c *Client) GetCapabilitiesWithRetry(ctx context.Context) error {
 			if errors.Is(err, ErrCacheUnauthenticated) {
 				return ErrCacheUnauthenticated, true
 			}
+			if c.breaker.Open() {
+				c.logger.Warnf("Remote cache unreachable, continuing without it")
+
+				return nil, true
+			}
 
 			return err, false
 		}
@@ -86,7 +98,7 @@ func (c *Client) initiatePut(ctx context.Context, params PutParams) (*writer, er
 	// Timeout is the responsibility of the caller
 	ctx = metadata.NewOutgoingContext(ctx, md)
 
-	stream, err := c.bitriseKVClient.Put(ctx)
+	stream, err := c.endpoints.current().kvClient.Put(ctx)
 	if err != nil {
 		st, ok := status.FromError(err)
 		if ok && st.Code() == codes.Unauthenticated {
@@ -117,7 +129,7 @@ func (c *Client) initiateGet(ctx context.Context, logger log.Logger, name string
 		ReadOffset:   offset,
 		ReadLimit:    0,
 	}
-	stream, err := c.bitriseKVClient.Get(ctx, readReq)
+	stream, err := c.endpoints.current().kvClient.Get(ctx, readReq)
 	if err != nil {
 		st, ok := status.FromError(err)
 		if ok && st.Code() == codes.Unauthenticated {
@@ -149,7 +161,7 @@ func (c *Client) Delete(ctx context.Context, name string) error {
 		ReadOffset:   0,
 		ReadLimit:    0,
 	}
-	_, err := c.bitriseKVClient.Delete(callCtx, readReq)
+	_, err := c.endpoints.current().kvClient.Delete(callCtx, readReq)
 	if err != nil {
 		return fmt.Errorf("initiate delete: %w", err)
 	}
@@ -170,7 +182,7 @@ func (c *Client) findMissing(ctx context.Context,
 		callCtx := metadata.NewOutgoingContext(timeoutCtx, c.getMethodCallMetadata(false))
 
 		var err error
-		resp, err = c.casClient.FindMissingBlobs(callCtx, req)
+		resp, err = c.endpoints.current().casClient.FindMissingBlobs(callCtx, req)
 
 		cancel()
 
@@ -329,7 +341,7 @@ func (c *Client) QueryWriteStatus(ctx context.Context, name string) (WriteStatus
 	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	callCtx := metadata.NewOutgoingContext(timeoutCtx, c.getMethodCallMetadata(false))
-	resp, err := c.bitriseKVClient.WriteStatus(callCtx, &bytestream.QueryWriteStatusRequest{
+	resp, err := c.endpoints.current().kvClient.WriteStatus(callCtx, &bytestream.QueryWriteStatusRequest{
 		ResourceName: resourceName,
 	})
 	if err != nil {
@@ -0,0 +1,64 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type DeleteKeysStats struct {
+	KeysDeleted int
+	// KeysMissing counts keys that were already gone, e.g. expired or deleted concurrently.
+	KeysMissing int
+	KeysFailed  int
+}
+
+// DeleteKeys deletes the keys with at most concurrency deletes in flight.
+// Keys that no longer exist are not treated as failures.
+func (c *Client) DeleteKeys(ctx context.Context, keys []string, concurrency int) (DeleteKeysStats, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var stats DeleteKeysStats
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	semaphore := make(chan struct{}, concurrency)
+	for _, key := range keys {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			err := c.Delete(ctx, key)
+			c.recordOutcome(err)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case err == nil:
+				stats.KeysDeleted++
+				c.logger.Debugf("Deleted %s", key)
+			case status.Code(err) == codes.NotFound:
+				stats.KeysMissing++
+				c.logger.Debugf("Already gone: %s", key)
+			default:
+				stats.KeysFailed++
+				c.logger.Errorf("Failed to delete %s: %s", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stats.KeysFailed > 0 {
+		return stats, fmt.Errorf("failed to delete %d of %d keys", stats.KeysFailed, len(keys))
+	}
+
+	return stats, nil
+}
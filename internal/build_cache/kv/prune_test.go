@@ -0,0 +1,52 @@
+package kv_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv/mocks"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/kv_storage"
+)
+
+func TestClient_DeleteKeys(t *testing.T) {
+	var mutex sync.Mutex
+	var deleted []string
+	kvMock := &mocks.KVStorageClientMock{
+		DeleteFunc: func(_ context.Context, in *bytestream.ReadRequest, _ ...grpc.CallOption) (*kv_storage.DeleteResponse, error) {
+			key := strings.TrimPrefix(in.GetResourceName(), "kv/")
+			switch key {
+			case "gone":
+				return nil, status.Error(codes.NotFound, "not found")
+			case "broken":
+				return nil, status.Error(codes.Internal, "boom")
+			}
+
+			mutex.Lock()
+			deleted = append(deleted, key)
+			mutex.Unlock()
+
+			return &kv_storage.DeleteResponse{Ok: 1}, nil
+		},
+	}
+	client, err := kv.NewClient(kv.NewClientParams{Logger: mockLogger, BitriseKVClient: kvMock})
+	require.NoError(t, err)
+
+	stats, err := client.DeleteKeys(context.Background(), []string{"a", "b", "gone"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, kv.DeleteKeysStats{KeysDeleted: 2, KeysMissing: 1}, stats)
+	assert.ElementsMatch(t, []string{"a", "b"}, deleted)
+
+	stats, err = client.DeleteKeys(context.Background(), []string{"c", "broken"}, 0)
+	require.Error(t, err)
+	assert.Equal(t, kv.DeleteKeysStats{KeysDeleted: 1, KeysFailed: 1}, stats)
+}
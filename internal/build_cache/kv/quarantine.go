@@ -0,0 +1,81 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrCorruptedBlob means the content of a content-addressed blob does not match
+// its digest. The entry is treated as a miss and quarantined for the session.
+var ErrCorruptedBlob = errors.New("downloaded content does not match its digest")
+
+// quarantine holds the keys of corrupted blobs. Downloads of a quarantined key
+// are misses until the key is uploaded again or the session changes.
+type quarantine struct {
+	keys      sync.Map
+	corrupted atomic.Int64
+}
+
+func (q *quarantine) contains(key string) bool {
+	_, ok := q.keys.Load(key)
+
+	return ok
+}
+
+// add reports whether key was not quarantined yet.
+func (q *quarantine) add(key string) bool {
+	if _, loaded := q.keys.LoadOrStore(key, struct{}{}); loaded {
+		return false
+	}
+	q.corrupted.Add(1)
+
+	return true
+}
+
+func (q *quarantine) lift(key string) {
+	q.keys.Delete(key)
+}
+
+func (q *quarantine) reset() {
+	q.keys.Clear()
+	q.corrupted.Store(0)
+}
+
+// QuarantineBlob marks the blob stored under key as corrupted: later downloads
+// of it in this session are misses. When the client was created with
+// DeleteCorrupted, the entry is deleted from the cache as well, so the next
+// build uploads it again.
+func (c *Client) QuarantineBlob(ctx context.Context, key string) {
+	if !c.quarantine.add(key) {
+		return
+	}
+
+	c.logger.TWarnf("Quarantined corrupted cache entry %s", key)
+
+	if !c.deleteCorrupted {
+		return
+	}
+
+	if err := c.Delete(ctx, key); err != nil {
+		c.logger.Warnf("Failed to delete corrupted cache entry %s: %s", key, err)
+
+		return
+	}
+	c.logger.Debugf("Deleted corrupted cache entry %s", key)
+}
+
+// CorruptedBlobs returns the number of blobs quarantined in the current session.
+func (c *Client) CorruptedBlobs() int64 {
+	return c.quarantine.corrupted.Load()
+}
+
+// corrupted quarantines key and returns the ErrCorruptedBlob describing the
+// digest mismatch.
+func (c *Client) corrupted(ctx context.Context, key, expected, got string) error {
+	c.QuarantineBlob(ctx, key)
+
+	return fmt.Errorf("%w: key %s, expected %s, got %s", ErrCorruptedBlob, key, expected, got)
+}
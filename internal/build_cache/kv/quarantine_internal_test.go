@@ -0,0 +1,84 @@
+//go:build unit
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/kv_storage"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_DownloadFileGroupFromBuildCache_quarantinesCorruptedBlob(t *testing.T) {
+	key := sha256Hex("expected content")
+	store := &memoryStore{blobs: map[string][]byte{key: []byte("corrupted content")}}
+
+	var deleted []string
+	kvClient := store.kvClient()
+	kvClient.DeleteFunc = func(_ context.Context, in *bytestream.ReadRequest, _ ...grpc.CallOption) (*kv_storage.DeleteResponse, error) {
+		deleted = append(deleted, strings.TrimPrefix(in.GetResourceName(), "kv/"))
+
+		return &kv_storage.DeleteResponse{}, nil
+	}
+
+	client, err := NewClient(NewClientParams{
+		Logger:            log.NewLogger(),
+		BitriseKVClient:   kvClient,
+		DownloadRetryWait: 1,
+		DeleteCorrupted:   true,
+	})
+	require.NoError(t, err)
+
+	file := &filegroup.FileInfo{Path: filepath.Join(t.TempDir(), "file"), Hash: key, Size: 16}
+	stats, err := client.DownloadFileGroupFromBuildCache(context.Background(), filegroup.Info{Files: []*filegroup.FileInfo{file}}, false, false, false, 10)
+
+	require.ErrorIs(t, err, ErrCorruptedBlob)
+	assert.Equal(t, 1, stats.FilesCorrupted)
+	assert.Equal(t, 1, stats.FilesMissing)
+	assert.NoFileExists(t, file.Path)
+	assert.Equal(t, int64(1), client.CorruptedBlobs())
+	assert.Equal(t, []string{key}, deleted)
+
+	// quarantined keys are misses for the rest of the session
+	require.ErrorIs(t, client.DownloadStream(context.Background(), &bytes.Buffer{}, key), ErrCacheNotFound)
+
+	// until a new upload replaces the entry
+	data := []byte("expected content")
+	require.NoError(t, client.UploadStreamToBuildCache(context.Background(), bytes.NewReader(data), key, int64(len(data))))
+
+	var restored bytes.Buffer
+	require.NoError(t, client.DownloadStream(context.Background(), &restored, key))
+	assert.Equal(t, data, restored.Bytes())
+}
+
+func Test_ChangeSession_resetsQuarantine(t *testing.T) {
+	client, err := NewClient(NewClientParams{Logger: log.NewLogger()})
+	require.NoError(t, err)
+
+	client.QuarantineBlob(context.Background(), "key")
+	client.QuarantineBlob(context.Background(), "key")
+	assert.Equal(t, int64(1), client.CorruptedBlobs())
+
+	client.ChangeSession("invocation", "app", "build", "step")
+
+	assert.Zero(t, client.CorruptedBlobs())
+	assert.False(t, client.quarantine.contains("key"))
+}
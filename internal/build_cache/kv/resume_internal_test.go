@@ -0,0 +1,147 @@
+//go:build unit
+
+package kv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv/mocks"
+)
+
+func Test_downloadFile_resumesPartialFile(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	sum := sha256.Sum256(data)
+
+	var offsets []int64
+	kvMock := &mocks.KVStorageClientMock{
+		GetFunc: func(ctx context.Context, in *bytestream.ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bytestream.ReadResponse], error) {
+			offsets = append(offsets, in.GetReadOffset())
+			switch len(offsets) {
+			case 1:
+				return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+					{Response: &bytestream.ReadResponse{Data: data[:12]}},
+					{Error: status.Error(codes.Unavailable, "connection reset")},
+				}), nil
+			case 2:
+				return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+					{Error: status.Error(codes.Unavailable, "connection reset")},
+				}), nil
+			}
+
+			return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+				{
+					Response: &bytestream.ReadResponse{Data: data[in.GetReadOffset():]},
+					Metadata: map[string]string{"x-flare-blob-validation-sha256": hex.EncodeToString(sum[:])},
+				},
+				{Error: io.EOF},
+			}), nil
+		},
+	}
+
+	client, err := NewClient(NewClientParams{
+		Logger:            log.NewLogger(),
+		BitriseKVClient:   kvMock,
+		DownloadRetry:     1,
+		DownloadRetryWait: 1,
+	})
+	require.NoError(t, err)
+
+	filePath := filepath.Join(t.TempDir(), "blob")
+
+	result, err := client.downloadFile(context.Background(), filePath, "key", "", 0, false, false, false, false)
+	require.Error(t, err)
+	require.True(t, result.resumable)
+	// the client's own retry already resumed at the last written byte
+	assert.Equal(t, 1, result.retries)
+	assert.Equal(t, int64(12), result.resumedBytes)
+
+	result, err = client.downloadFile(context.Background(), filePath, "key", "", 0, false, false, false, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{0, 12, 12}, offsets)
+	assert.Equal(t, int64(12), result.resumedBytes)
+	assert.Equal(t, int64(len(data)-12), result.wireBytes)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, data, content)
+}
+
+func Test_downloadFile_corruptPartialFileIsNotResumable(t *testing.T) {
+	kvMock := &mocks.KVStorageClientMock{
+		GetFunc: func(ctx context.Context, in *bytestream.ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bytestream.ReadResponse], error) {
+			return mocks.NewServerStreamingClientMock[bytestream.ReadResponse]([]mocks.RecvResult[bytestream.ReadResponse]{
+				{
+					Response: &bytestream.ReadResponse{Data: []byte("corrupt")},
+					Metadata: map[string]string{"x-flare-blob-validation-sha256": "0000"},
+				},
+				{Error: io.EOF},
+			}), nil
+		},
+	}
+
+	client, err := NewClient(NewClientParams{
+		Logger:            log.NewLogger(),
+		BitriseKVClient:   kvMock,
+		DownloadRetry:     1,
+		DownloadRetryWait: 1,
+	})
+	require.NoError(t, err)
+
+	result, err := client.downloadFile(context.Background(), filepath.Join(t.TempDir(), "blob"), "key", "", 0, false, false, false, false)
+	require.ErrorIs(t, err, errHashMismatch)
+	assert.False(t, result.resumable)
+}
+
+func Test_uploadFile_resumeContinuesFromCommittedSize(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	filePath := filepath.Join(t.TempDir(), "blob")
+	require.NoError(t, os.WriteFile(filePath, data, 0o600))
+
+	putStream := mocks.NewClientStreamClientMock[bytestream.WriteRequest, bytestream.WriteResponse](
+		&bytestream.WriteResponse{CommittedSize: int64(len(data) - 8)},
+		[]error{nil, nil},
+	)
+	kvMock := &mocks.KVStorageClientMock{
+		PutFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bytestream.WriteRequest, bytestream.WriteResponse], error) {
+			return putStream, nil
+		},
+		WriteStatusFunc: func(ctx context.Context, in *bytestream.QueryWriteStatusRequest, opts ...grpc.CallOption) (*bytestream.QueryWriteStatusResponse, error) {
+			return &bytestream.QueryWriteStatusResponse{CommittedSize: 8}, nil
+		},
+	}
+
+	client, err := NewClient(NewClientParams{
+		Logger:          log.NewLogger(),
+		BitriseKVClient: kvMock,
+		UploadRetry:     1,
+		UploadRetryWait: 1,
+	})
+	require.NoError(t, err)
+
+	result, err := client.uploadFile(context.Background(), filePath, "key", "", true)
+	require.NoError(t, err)
+
+	assert.Len(t, kvMock.WriteStatusCalls(), 1)
+	assert.Equal(t, int64(8), result.resumedBytes)
+	assert.Equal(t, 0, result.retries)
+
+	requests := putStream.Requests()
+	require.NotEmpty(t, requests)
+	assert.Equal(t, int64(8), requests[0].GetWriteOffset())
+	assert.Equal(t, data[8:], requests[0].GetData())
+}
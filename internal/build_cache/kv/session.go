@@ -8,4 +8,6 @@ func (c *Client) ChangeSession(invocationID string, appSlug string, buildSlug st
 	c.cacheConfigMetadata.BitriseAppID = appSlug
 	c.cacheConfigMetadata.BitriseBuildID = buildSlug
 	c.cacheConfigMetadata.BitriseStepExecutionID = stepSlug
+	c.breaker.ResetSession()
+	c.quarantine.reset()
 }
@@ -14,6 +14,7 @@ func init() {
 	mockLogger.On("Debugf", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Debugf", mock.Anything).Return()
 	mockLogger.On("Debugf").Return()
+	mockLogger.On("Errorf", mock.Anything, mock.Anything, mock.Anything).Return()
 	mockLogger.On("Errorf", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Errorf", mock.Anything).Return()
 	mockLogger.On("Infof", mock.Anything, mock.Anything, mock.Anything).Return()
@@ -0,0 +1,296 @@
+package kv
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultTransferConcurrency is the number of files transferred in parallel
+	// by file group uploads and downloads.
+	DefaultTransferConcurrency = 20
+
+	// concurrencyBackoffInterval is the minimum time between two back-offs, so a
+	// burst of failing in-flight transfers only halves the concurrency once.
+	concurrencyBackoffInterval = time.Second
+)
+
+// TransferOptions tune the parallelism and bandwidth of file group transfers.
+// The zero value transfers DefaultTransferConcurrency files at a time without
+// bandwidth limits.
+type TransferOptions struct {
+	UploadConcurrency   int
+	DownloadConcurrency int
+	// UploadBandwidth and DownloadBandwidth are limits in bytes per second,
+	// shared by all transfers of the client. 0 means unlimited.
+	UploadBandwidth   int64
+	DownloadBandwidth int64
+	// AdaptiveConcurrency halves the number of parallel transfers on
+	// ResourceExhausted or Unavailable errors and slowly grows it back.
+	AdaptiveConcurrency bool
+}
+
+// BandwidthLimiter is a token bucket limiting the throughput of the readers it
+// wraps. Tokens are reserved up front, so concurrent readers share the limit.
+//
+// A nil *BandwidthLimiter is valid and never limits.
+type BandwidthLimiter struct {
+	bytesPerSecond float64
+	burst          float64
+
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+	waited time.Duration
+}
+
+// NewBandwidthLimiter returns a limiter of bytesPerSecond, or nil when it is
+// not positive.
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	//nolint:exhaustruct
+	return &BandwidthLimiter{
+		bytesPerSecond: float64(bytesPerSecond),
+		burst:          float64(bytesPerSecond),
+		tokens:         float64(bytesPerSecond),
+		last:           time.Now(),
+	}
+}
+
+// Limit returns the limit in bytes per second, 0 when unlimited.
+func (l *BandwidthLimiter) Limit() int64 {
+	if l == nil {
+		return 0
+	}
+
+	return int64(l.bytesPerSecond)
+}
+
+// Waited returns the total time callers were held back by the limiter.
+func (l *BandwidthLimiter) Waited() time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.waited
+}
+
+// WaitN blocks until n bytes may be transferred or ctx is done.
+func (l *BandwidthLimiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.bytesPerSecond)
+	l.last = now
+	l.tokens -= float64(n)
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+		l.waited += wait
+	}
+	l.mutex.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-timer.C:
+		return nil
+	}
+}
+
+// duration is the time transferring size bytes takes at the limit.
+func (l *BandwidthLimiter) duration(size int64) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	return time.Duration(float64(size) / l.bytesPerSecond * float64(time.Second))
+}
+
+// Reader wraps r so reads are throttled to the limit.
+func (l *BandwidthLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+
+	return &throttledReader{ctx: ctx, r: r, limiter: l}
+}
+
+type throttledReader struct {
+	ctx     context.Context //nolint:containedctx
+	r       io.Reader
+	limiter *BandwidthLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// withIdleTimeout returns a context that is cancelled once touch was not called
+// for timeout.
+func withIdleTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+
+	stop := func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+	touch := func() { timer.Reset(timeout) }
+
+	return ctx, stop, touch
+}
+
+type progressReader struct {
+	r          io.Reader
+	onProgress func()
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.onProgress()
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// concurrencyLimiter bounds the number of parallel transfers of a file group.
+// When adaptive, overload errors halve the limit and every limit successful
+// transfers grow it by one again, up to the configured maximum.
+type concurrencyLimiter struct {
+	maxLimit int
+	adaptive bool
+
+	mutex       sync.Mutex
+	cond        *sync.Cond
+	limit       int
+	inFlight    int
+	successes   int
+	minLimit    int
+	backoffs    int
+	lastBackoff time.Time
+}
+
+func newConcurrencyLimiter(maxLimit int, adaptive bool) *concurrencyLimiter {
+	if maxLimit <= 0 {
+		maxLimit = DefaultTransferConcurrency
+	}
+
+	//nolint:exhaustruct
+	l := &concurrencyLimiter{
+		maxLimit: maxLimit,
+		adaptive: adaptive,
+		limit:    maxLimit,
+		minLimit: maxLimit,
+	}
+	l.cond = sync.NewCond(&l.mutex)
+
+	return l
+}
+
+func (l *concurrencyLimiter) acquire() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for l.inFlight >= l.limit {
+		l.cond.Wait()
+	}
+	l.inFlight++
+}
+
+func (l *concurrencyLimiter) release() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.inFlight--
+	l.cond.Broadcast()
+}
+
+// record feeds the outcome of a transfer attempt to the adaptive limit.
+func (l *concurrencyLimiter) record(err error) {
+	if !l.adaptive {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if isOverloaded(err) {
+		if time.Since(l.lastBackoff) < concurrencyBackoffInterval {
+			return
+		}
+		l.lastBackoff = time.Now()
+		l.limit = max(1, l.limit/2)
+		l.minLimit = min(l.minLimit, l.limit)
+		l.successes = 0
+		l.backoffs++
+
+		return
+	}
+
+	if err != nil || l.limit >= l.maxLimit {
+		return
+	}
+
+	l.successes++
+	if l.successes >= l.limit {
+		l.limit++
+		l.successes = 0
+		l.cond.Broadcast()
+	}
+}
+
+func (l *concurrencyLimiter) stats() (maxLimit, minLimit, backoffs int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.maxLimit, l.minLimit, l.backoffs
+}
+
+// isOverloaded reports whether err signals that the remote is overloaded and
+// transfers should slow down.
+func isOverloaded(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch st.Code() {
+	case codes.ResourceExhausted, codes.Unavailable:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,106 @@
+//go:build unit
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_BandwidthLimiter_nilNeverLimits(t *testing.T) {
+	var limiter *BandwidthLimiter
+
+	assert.Nil(t, NewBandwidthLimiter(0))
+	require.NoError(t, limiter.WaitN(context.Background(), 1<<30))
+	assert.Equal(t, int64(0), limiter.Limit())
+	assert.Zero(t, limiter.Waited())
+}
+
+func Test_BandwidthLimiter_throttlesReader(t *testing.T) {
+	limiter := NewBandwidthLimiter(100 * 1024)
+
+	// the first second is covered by the initial burst, the rest has to wait
+	start := time.Now()
+	n, err := io.Copy(io.Discard, limiter.Reader(context.Background(), bytes.NewReader(make([]byte, 120*1024))))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(120*1024), n)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	assert.Greater(t, limiter.Waited(), time.Duration(0))
+}
+
+func Test_BandwidthLimiter_stopsWaitingOnCancel(t *testing.T) {
+	limiter := NewBandwidthLimiter(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, limiter.WaitN(ctx, 1024))
+	require.ErrorIs(t, limiter.WaitN(ctx, 1024), context.Canceled)
+}
+
+func Test_concurrencyLimiter_backsOffOnOverload(t *testing.T) {
+	limiter := newConcurrencyLimiter(8, true)
+	overloaded := fmt.Errorf("upload: %w", status.Error(codes.ResourceExhausted, "slow down"))
+
+	limiter.record(overloaded)
+	// a burst of failures within the back-off interval only counts once
+	limiter.record(status.Error(codes.Unavailable, "unavailable"))
+
+	maxLimit, minLimit, backoffs := limiter.stats()
+	assert.Equal(t, 8, maxLimit)
+	assert.Equal(t, 4, minLimit)
+	assert.Equal(t, 1, backoffs)
+
+	// other errors neither back off nor grow the limit
+	limiter.record(errors.New("hash mismatch"))
+	for range 4 {
+		limiter.record(nil)
+	}
+	assert.Equal(t, 5, limiter.limit)
+}
+
+func Test_concurrencyLimiter_staticIgnoresErrors(t *testing.T) {
+	limiter := newConcurrencyLimiter(0, false)
+
+	limiter.record(status.Error(codes.ResourceExhausted, "slow down"))
+
+	maxLimit, minLimit, backoffs := limiter.stats()
+	assert.Equal(t, DefaultTransferConcurrency, maxLimit)
+	assert.Equal(t, DefaultTransferConcurrency, minLimit)
+	assert.Zero(t, backoffs)
+}
+
+func Test_concurrencyLimiter_boundsInFlight(t *testing.T) {
+	limiter := newConcurrencyLimiter(2, false)
+	limiter.acquire()
+	limiter.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		limiter.acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired more slots than the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("slot was not handed over after release")
+	}
+}
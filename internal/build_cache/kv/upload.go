@@ -23,57 +23,97 @@ func (c *Client) UploadFileToBuildCache(ctx context.Context, filePath, key strin
 		return fmt.Errorf("checksum of %s: %w", filePath, err)
 	}
 
-	fileSize, err := c.uploadFile(ctx, filePath, key, checksum)
+	result, err := c.uploadFile(ctx, filePath, key, checksum, false)
 	if err != nil {
 		return fmt.Errorf("upload file: %w", err)
 	}
 
-	//nolint: gosec
-	c.logger.Infof("(i) Uploaded: %s", humanize.Bytes(uint64(fileSize)))
+	if result.wireSize < result.size {
+		//nolint: gosec
+		c.logger.Infof("(i) Uploaded: %s (%s compressed)", humanize.Bytes(uint64(result.size)), humanize.Bytes(uint64(result.wireSize)))
+	} else {
+		//nolint: gosec
+		c.logger.Infof("(i) Uploaded: %s", humanize.Bytes(uint64(result.size)))
+	}
 
 	return nil
 }
 
 func (c *Client) UploadStreamToBuildCache(ctx context.Context, source io.ReadSeeker, key string, size int64) error {
-	// Always seek to start before checksum
-	if _, err := source.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("seek to start: %w", err)
-	}
-	checksum, err := hash.Checksum(source)
+	body, err := c.prepareUpload(source, size, "")
 	if err != nil {
-		return fmt.Errorf("checksum: %w", err)
+		return fmt.Errorf("prepare upload: %w", err)
 	}
-	// Seek to start before upload
-	if _, err := source.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("seek to start: %w", err)
-	}
-	if err := c.uploadStream(ctx, source, key, checksum, size); err != nil {
+	defer body.cleanup()
+
+	if _, err := c.uploadStream(ctx, body.reader, key, body.checksum, body.size, false); err != nil {
 		return fmt.Errorf("upload stream: %w", err)
 	}
 
 	return nil
 }
 
-func (c *Client) uploadFile(ctx context.Context, filePath, key, checksum string) (int64, error) {
+// transferStats describes the retries a blob transfer took.
+type transferStats struct {
+	retries int
+	// resumedBytes is the number of bytes that did not have to be transferred
+	// again because a retry resumed from where the previous attempt stopped.
+	resumedBytes int64
+}
+
+func (s *transferStats) add(other transferStats) {
+	s.retries += other.retries
+	s.resumedBytes += other.resumedBytes
+}
+
+type uploadResult struct {
+	transferStats
+
+	size     int64
+	wireSize int64
+}
+
+// uploadFile uploads a file. With resume set, it continues an upload a
+// previous call left incomplete instead of starting over.
+func (c *Client) uploadFile(ctx context.Context, filePath, key, checksum string, resume bool) (uploadResult, error) {
+	return c.uploadFileSection(ctx, filePath, 0, -1, key, checksum, resume)
+}
+
+// uploadFileSection uploads size bytes of a file starting at offset. A
+// negative size means the rest of the file.
+func (c *Client) uploadFileSection(ctx context.Context, filePath string, offset, size int64, key, checksum string, resume bool) (uploadResult, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return 0, fmt.Errorf("open %q: %w", filePath, err)
+		return uploadResult{}, fmt.Errorf("open %q: %w", filePath, err)
 	}
 	defer file.Close()
-	stat, err := file.Stat()
+	if size < 0 {
+		stat, err := file.Stat()
+		if err != nil {
+			return uploadResult{}, fmt.Errorf("stat %q: %w", filePath, err)
+		}
+		size = stat.Size() - offset
+	}
+
+	body, err := c.prepareUpload(io.NewSectionReader(file, offset, size), size, checksum)
 	if err != nil {
-		return 0, fmt.Errorf("stat %q: %w", filePath, err)
+		return uploadResult{}, fmt.Errorf("prepare upload %q: %w", filePath, err)
 	}
+	defer body.cleanup()
 
-	if err = c.uploadStream(ctx, file, key, checksum, stat.Size()); err != nil {
-		return 0, fmt.Errorf("upload %q: %w", filePath, err)
+	result := uploadResult{size: size, wireSize: body.size}
+	result.transferStats, err = c.uploadStream(ctx, body.reader, key, body.checksum, body.size, resume)
+	if err != nil {
+		return result, fmt.Errorf("upload %q: %w", filePath, err)
 	}
 
-	return stat.Size(), nil
+	return result, nil
 }
 
+// uploadStream uploads source under key, resuming from the size the server
+// already committed on retries. With resume set, the first attempt resumes too.
 // nolint: gocognit
-func (c *Client) uploadStream(ctx context.Context, source io.ReadSeeker, key, checksum string, size int64) error {
+func (c *Client) uploadStream(ctx context.Context, source io.ReadSeeker, key, checksum string, size int64, resume bool) (transferStats, error) {
 	const divisor = 10 * 1024 * 1024 // 10 MB
 
 	// give each 10 MB a second, min 20s max 2m
@@ -84,19 +124,30 @@ func (c *Client) uploadStream(ctx context.Context, source io.ReadSeeker, key, ch
 		),
 		2*time.Minute,
 	)
+	// a bandwidth limit can make the upload slower than that
+	timeout += c.uploadBandwidth.duration(size)
+
+	if !c.breaker.Allow() {
+		c.logger.TDebugf("Skipping upload of %s: remote cache is degraded", key)
+
+		return transferStats{}, ErrDegraded
+	}
 
+	var stats transferStats
 	lastCommittedSize := int64(0)
 	hasAlreadyExists := false
 
-	//nolint:wrapcheck
-	return retry.Times(c.uploadRetry).Wait(c.uploadRetryWait).TryWithAbort(func(attempt uint) (error, bool) {
+	err := retry.Times(c.uploadRetry).Wait(c.uploadRetryWait).TryWithAbort(func(attempt uint) (error, bool) {
+		if attempt > 0 {
+			stats.retries++
+		}
 		if attempt == 0 {
 			c.logger.TDebugf("Uploading %s (size: %d, timeout: %s)", key, size, timeout.String())
 		} else {
 			c.logger.TInfof("%d. attempt to upload %s (size: %d, previously uploaded: %d, timeout: %s)", attempt+1, key, size, lastCommittedSize, timeout.String())
 		}
 
-		if attempt > 0 {
+		if attempt > 0 || resume {
 			writeStatus, err := c.QueryWriteStatus(ctx, key)
 			switch {
 			case err != nil:
@@ -114,6 +165,9 @@ func (c *Client) uploadStream(ctx context.Context, source io.ReadSeeker, key, ch
 				c.logger.Infof("Last committed %s by server: %d", key, lastCommittedSize)
 			}
 		}
+		if lastCommittedSize > 0 && lastCommittedSize < size {
+			stats.resumedBytes += lastCommittedSize
+		}
 
 		if lastCommittedSize >= size {
 			c.logger.Infof("Already written %s by server", key)
@@ -150,7 +204,7 @@ func (c *Client) uploadStream(ctx context.Context, source io.ReadSeeker, key, ch
 			}
 
 			// io.Copy does not write if there was no read
-			bytesSent, err = io.Copy(kvWriter, source)
+			bytesSent, err = io.Copy(kvWriter, c.uploadBandwidth.Reader(timeoutCtx, source))
 		} else {
 			// io.Copy does not write if there was no read
 			_, err = kvWriter.Write([]byte{})
@@ -186,7 +240,13 @@ func (c *Client) uploadStream(ctx context.Context, source io.ReadSeeker, key, ch
 		if lastCommittedSize > 0 && attempt > 0 {
 			c.logger.Infof("Upload %s success (size: %d) in %d attempts", key, size, attempt+1)
 		}
+		// the blob was rewritten, so a corrupted entry got replaced
+		c.quarantine.lift(key)
 
 		return nil, false
 	})
+	c.recordOutcome(err)
+
+	//nolint:wrapcheck
+	return stats, err
 }
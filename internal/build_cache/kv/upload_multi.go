@@ -17,24 +17,116 @@ type UploadFilesStats struct {
 	FilesToUpload       int
 	FilesUploaded       int
 	FilesFailedToUpload int
-	TotalFiles          int
-	UploadSize          int64
-	LargestFileSize     int64
+	// FilesSkippedDegraded counts files not uploaded because the remote cache
+	// was degraded. They are not stored, so the group is incomplete.
+	FilesSkippedDegraded int
+	TotalFiles           int
+	UploadSize           int64
+	// CompressedUploadSize is the number of bytes sent over the wire. It equals UploadSize when compression is off.
+	CompressedUploadSize int64
+	LargestFileSize      int64
+	// RetryAttempts counts retried transfers, across all files.
+	RetryAttempts int
+	// ResumedBytes is the number of bytes retries did not have to send again.
+	ResumedBytes int64
+	// ChunksUploaded counts the content-defined chunks uploaded for chunked files.
+	ChunksUploaded int
+	// Concurrency is the maximum number of files transferred in parallel.
+	Concurrency int
+	// MinConcurrency is the lowest number of parallel transfers adaptive concurrency backed off to.
+	MinConcurrency int
+	// ConcurrencyBackoffs counts how many times adaptive concurrency backed off on ResourceExhausted or Unavailable errors.
+	ConcurrencyBackoffs int
+	// BandwidthLimit is the bandwidth limit in bytes per second, 0 when unlimited.
+	BandwidthLimit int64
+	// BandwidthWait is the total time transfers were held back by the bandwidth limit.
+	BandwidthWait time.Duration
+	// Endpoint is the cache host in use when the upload finished.
+	Endpoint string
 }
 
-func (c *Client) uploadFileToBuildCache(ctx context.Context, file *filegroup.FileInfo, mutex *sync.Mutex, stats *UploadFilesStats) {
+// filePart is a blob a file is stored as: the whole file, or one of its chunks.
+type filePart struct {
+	offset int64
+	size   int64
+	hash   string
+}
+
+func fileParts(file *filegroup.FileInfo) []filePart {
+	if len(file.Chunks) == 0 {
+		return []filePart{{offset: 0, size: file.Size, hash: file.Hash}}
+	}
+
+	parts := make([]filePart, 0, len(file.Chunks))
+	var offset int64
+	for _, chunk := range file.Chunks {
+		parts = append(parts, filePart{offset: offset, size: chunk.Size, hash: chunk.Hash})
+		offset += chunk.Size
+	}
+
+	return parts
+}
+
+func (c *Client) uploadFileToBuildCache(ctx context.Context, file *filegroup.FileInfo, parts []filePart, workers *concurrencyLimiter, mutex *sync.Mutex, stats *UploadFilesStats) {
+	var uploadedSize int64
+	var wireSize int64
+	var transfer transferStats
+	var err error
+	for _, part := range parts {
+		var partWireSize int64
+		partWireSize, err = c.uploadFilePart(ctx, file, part, workers, &transfer)
+		if err != nil {
+			break
+		}
+		uploadedSize += part.size
+		wireSize += partWireSize
+	}
+
+	mutex.Lock()
+	stats.RetryAttempts += transfer.retries
+	stats.ResumedBytes += transfer.resumedBytes
+	switch {
+	case errors.Is(err, ErrDegraded):
+		stats.FilesSkippedDegraded++
+	case err != nil:
+		c.logger.Errorf("Failed to upload file %s with error: %v", file.Path, err)
+		stats.FilesFailedToUpload++
+	default:
+		stats.FilesUploaded++
+		stats.UploadSize += uploadedSize
+		stats.CompressedUploadSize += wireSize
+		if len(file.Chunks) > 0 {
+			stats.ChunksUploaded += len(parts)
+		}
+		if file.Size > stats.LargestFileSize {
+			stats.LargestFileSize = file.Size
+		}
+	}
+	mutex.Unlock()
+}
+
+func (c *Client) uploadFilePart(ctx context.Context, file *filegroup.FileInfo, part filePart, workers *concurrencyLimiter, transfer *transferStats) (int64, error) {
 	const retries = 2
+	var wireSize int64
 	err := retry.Times(retries).Wait(3 * time.Second).TryWithAbort(func(attempt uint) (error, bool) {
 		if attempt > 0 {
 			c.logger.Debugf("Retrying upload... (attempt %d)", attempt)
+			transfer.retries++
 		}
 
-		_, err := c.uploadFile(ctx, file.Path, file.Hash, file.Hash)
+		// Later attempts continue whatever the server already committed.
+		result, err := c.uploadFileSection(ctx, file.Path, part.offset, part.size, part.hash, part.hash, attempt > 0)
+		wireSize = result.wireSize
+		transfer.add(result.transferStats)
+		workers.record(err)
 		if err != nil {
 			c.logger.Errorf("Error in upload file attempt %d: %s", attempt, err)
 			if errors.Is(err, ErrCacheUnauthenticated) {
 				return ErrCacheUnauthenticated, true
 			}
+			if errors.Is(err, ErrDegraded) {
+				return err, true
+			}
 
 			return fmt.Errorf("upload file %s: %w", file.Path, err), false
 		}
@@ -42,18 +134,8 @@ func (c *Client) uploadFileToBuildCache(ctx context.Context, file *filegroup.Fil
 		return nil, false
 	})
 
-	mutex.Lock()
-	if err != nil {
-		c.logger.Errorf("Failed to upload file %s with error: %v", file.Path, err)
-		stats.FilesFailedToUpload++
-	} else {
-		stats.FilesUploaded++
-		stats.UploadSize += file.Size
-		if file.Size > stats.LargestFileSize {
-			stats.LargestFileSize = file.Size
-		}
-	}
-	mutex.Unlock()
+	//nolint:wrapcheck
+	return wireSize, err
 }
 
 func (c *Client) UploadFileGroupToBuildCache(ctx context.Context, dd filegroup.Info) (UploadFilesStats, error) {
@@ -63,43 +145,80 @@ func (c *Client) UploadFileGroupToBuildCache(ctx context.Context, dd filegroup.I
 	}
 
 	stats := UploadFilesStats{
-		TotalFiles:    len(dd.Files),
-		FilesToUpload: len(missingBlobs),
+		TotalFiles: len(dd.Files),
 	}
 
 	c.logger.TInfof("(i) Uploading missing blobs...")
 
+	workers := newConcurrencyLimiter(c.transfer.UploadConcurrency, c.transfer.AdaptiveConcurrency)
+	bandwidthWaitBefore := c.uploadBandwidth.Waited()
+
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
-	semaphore := make(chan struct{}, 20) // Limit parallelization
 	for _, file := range dd.Files {
+		var parts []filePart
 		mutex.Lock()
-		_, ok := missingBlobs[file.Hash]
-		delete(missingBlobs, file.Hash) // Remove the blob from the list of missing blobs as it's being uploaded
+		for _, part := range fileParts(file) {
+			if _, ok := missingBlobs[part.hash]; ok {
+				delete(missingBlobs, part.hash) // Remove the blob from the list of missing blobs as it's being uploaded
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			stats.FilesToUpload++
+		}
 		mutex.Unlock()
-		if !ok {
+		if len(parts) == 0 {
 			continue
 		}
 
 		wg.Add(1)
-		semaphore <- struct{}{} // Block if there are too many goroutines are running
+		workers.acquire() // Block if there are too many goroutines are running
 
 		go func(file *filegroup.FileInfo) {
 			defer wg.Done()
-			defer func() { <-semaphore }() // Release a slot in the semaphore
+			defer workers.release()
 
-			c.uploadFileToBuildCache(ctx, file, &mutex, &stats)
+			c.uploadFileToBuildCache(ctx, file, parts, workers, &mutex, &stats)
 		}(file)
 	}
 
 	wg.Wait()
 
+	stats.Concurrency, stats.MinConcurrency, stats.ConcurrencyBackoffs = workers.stats()
+	stats.BandwidthLimit = c.uploadBandwidth.Limit()
+	stats.BandwidthWait = c.uploadBandwidth.Waited() - bandwidthWaitBefore
+
 	//nolint: gosec
 	c.logger.TInfof("(i) Uploaded %s in %d keys", humanize.Bytes(uint64(stats.UploadSize)), stats.FilesUploaded)
+	if stats.CompressedUploadSize < stats.UploadSize {
+		//nolint: gosec
+		c.logger.Infof("(i) Sent %s over the wire after compression", humanize.Bytes(uint64(stats.CompressedUploadSize)))
+	}
+	if stats.ChunksUploaded > 0 {
+		c.logger.Infof("(i) Uploaded %d chunks of large files", stats.ChunksUploaded)
+	}
+	if stats.RetryAttempts > 0 {
+		//nolint: gosec
+		c.logger.Infof("(i) Retried %d times, resuming avoided re-sending %s", stats.RetryAttempts, humanize.Bytes(uint64(stats.ResumedBytes)))
+	}
+
+	if stats.ConcurrencyBackoffs > 0 {
+		c.logger.Infof("(i) Backed off %d times on an overloaded cache, down to %d parallel uploads", stats.ConcurrencyBackoffs, stats.MinConcurrency)
+	}
+	if stats.BandwidthWait > 0 {
+		//nolint: gosec
+		c.logger.Infof("(i) Throttled to %s/s for %s", humanize.Bytes(uint64(stats.BandwidthLimit)), stats.BandwidthWait.Round(time.Millisecond))
+	}
+
+	stats.Endpoint = c.Endpoint()
 
 	if stats.FilesFailedToUpload > 0 {
 		return stats, fmt.Errorf("failed to upload some files")
 	}
+	if stats.FilesSkippedDegraded > 0 {
+		return stats, fmt.Errorf("%d files not uploaded: %w", stats.FilesSkippedDegraded, ErrDegraded)
+	}
 
 	return stats, nil
 }
@@ -111,13 +230,15 @@ func (c *Client) findMissingBlobs(ctx context.Context, dd filegroup.Info) (map[s
 
 	allDigests := make([]*FileDigest, 0, len(dd.Files))
 	for _, file := range dd.Files {
-		if _, ok := blobs[file.Hash]; !ok {
-			allDigests = append(allDigests, &FileDigest{
-				Sha256Sum:   file.Hash,
-				SizeInBytes: file.Size,
-			})
+		for _, part := range fileParts(file) {
+			if _, ok := blobs[part.hash]; !ok {
+				allDigests = append(allDigests, &FileDigest{
+					Sha256Sum:   part.hash,
+					SizeInBytes: part.size,
+				})
 
-			blobs[file.Hash] = true
+				blobs[part.hash] = true
+			}
 		}
 	}
 
@@ -28,3 +28,17 @@ func ParseURLGRPC(s string) (string, bool, error) {
 
 	return host, !isSecure, nil
 }
+
+// ParseEndpoints parses grpc[s]:// URLs into endpoints, keeping their order.
+func ParseEndpoints(urls []string) ([]Endpoint, error) {
+	endpoints := make([]Endpoint, 0, len(urls))
+	for _, u := range urls {
+		host, insecureGRPC, err := ParseURLGRPC(u)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: %w", u, err)
+		}
+		endpoints = append(endpoints, Endpoint{Host: host, UseInsecure: insecureGRPC})
+	}
+
+	return endpoints, nil
+}
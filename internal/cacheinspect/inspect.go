@@ -0,0 +1,164 @@
+// Package cacheinspect classifies and pretty-prints entries stored in the
+// remote KV cache: DerivedData and Gradle metadata, xcelerate CAS blobs and
+// opaque bytes.
+package cacheinspect
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/casblob"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/deriveddata"
+)
+
+type Kind string
+
+const (
+	KindDerivedDataMetadata Kind = "deriveddata-metadata"
+	KindGradleMetadata      Kind = "gradle-metadata"
+	KindCASBlob             Kind = "cas-blob"
+	KindRaw                 Kind = "raw"
+)
+
+// DefaultMaxBytes is how much of an opaque payload is dumped by default.
+const DefaultMaxBytes = 256
+
+type Entry struct {
+	Key string `json:"key"`
+	// MetadataChecksum is set when Key only stores the checksum of the metadata
+	// blob, as the DerivedData and Gradle metadata keys do. The rest of the
+	// entry then describes that blob.
+	MetadataChecksum string                `json:"metadataChecksum,omitempty"`
+	Kind             Kind                  `json:"kind"`
+	Size             int64                 `json:"size"`
+	DerivedData      *deriveddata.Metadata `json:"derivedDataMetadata,omitempty"`
+	Gradle           *gradle.Metadata      `json:"gradleMetadata,omitempty"`
+	CASBlob          *CASBlob              `json:"casBlob,omitempty"`
+	// Head is the hex encoded beginning of a raw entry, or of a CAS blob's payload.
+	Head string `json:"head,omitempty"`
+
+	head []byte
+}
+
+type CASBlob struct {
+	References  []string `json:"references"`
+	PayloadSize int64    `json:"payloadSize"`
+}
+
+// Inspect classifies data stored under key. At most maxBytes of an opaque
+// payload are kept for dumping.
+func Inspect(key string, data []byte, maxBytes int) Entry {
+	entry := Entry{Key: key, Kind: KindRaw, Size: int64(len(data))}
+
+	switch {
+	case bytes.HasPrefix(data, casblob.Magic[:]):
+		if entry.fillCASBlob(data, maxBytes) {
+			return entry
+		}
+	case isJSONObject(data):
+		if entry.fillMetadata(data) {
+			return entry
+		}
+	default:
+		// entries written before the container format are gob encoded
+		if entry.fillCASBlob(data, maxBytes) {
+			return entry
+		}
+	}
+
+	entry.setHead(data[:min(len(data), maxBytes)])
+
+	return entry
+}
+
+// ChecksumPointer reports whether data is a bare SHA-256 checksum, which is
+// what the metadata keys store instead of the metadata itself.
+func ChecksumPointer(data []byte) (string, bool) {
+	checksum := string(bytes.TrimSpace(data))
+	if len(checksum) != sha256.Size*2 || strings.ToLower(checksum) != checksum {
+		return "", false
+	}
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return "", false
+	}
+
+	return checksum, true
+}
+
+func isJSONObject(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+
+	return len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed)
+}
+
+func (e *Entry) fillMetadata(data []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+
+	switch {
+	case fields["derivedData"] != nil:
+		var m deriveddata.Metadata
+		if err := json.Unmarshal(data, &m); err != nil {
+			return false
+		}
+		e.Kind = KindDerivedDataMetadata
+		e.DerivedData = &m
+	case fields["configCacheFiles"] != nil:
+		var m gradle.Metadata
+		if err := json.Unmarshal(data, &m); err != nil {
+			return false
+		}
+		e.Kind = KindGradleMetadata
+		e.Gradle = &m
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (e *Entry) fillCASBlob(data []byte, maxBytes int) bool {
+	payload := &headWriter{max: maxBytes}
+	references, err := casblob.Decode(bytes.NewReader(data), payload)
+	if err != nil {
+		return false
+	}
+
+	blob := &CASBlob{References: make([]string, 0, len(references)), PayloadSize: payload.size}
+	for _, ref := range references {
+		blob.References = append(blob.References, hex.EncodeToString(ref))
+	}
+
+	e.Kind = KindCASBlob
+	e.CASBlob = blob
+	e.setHead(payload.head)
+
+	return true
+}
+
+func (e *Entry) setHead(head []byte) {
+	e.head = head
+	e.Head = hex.EncodeToString(head)
+}
+
+// headWriter keeps the first max bytes written and counts the rest.
+type headWriter struct {
+	max  int
+	head []byte
+	size int64
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if room := w.max - len(w.head); room > 0 {
+		w.head = append(w.head, p[:min(room, len(p))]...)
+	}
+	w.size += int64(len(p))
+
+	return len(p), nil
+}
@@ -0,0 +1,138 @@
+//go:build unit
+
+package cacheinspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradle"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/casblob"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/deriveddata"
+)
+
+func renderText(t *testing.T, entry Entry, depth int) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	require.NoError(t, WriteText(&out, entry, depth))
+
+	return out.String()
+}
+
+func TestInspect_derivedDataMetadata(t *testing.T) {
+	md := deriveddata.Metadata{
+		CacheKey:  "xcode-key",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GitBranch: "main",
+		DerivedData: filegroup.Info{Files: []*filegroup.FileInfo{
+			{Path: "/dd/Build/Products/App.app/App", Size: 3000},
+			{Path: "/dd/Build/Products/App.app/Info.plist", Size: 100},
+			{Path: "/dd/Build/Intermediates/lib.a", Size: 2000, Chunks: []filegroup.ChunkInfo{{Hash: "a", Size: 1000}, {Hash: "b", Size: 1000}}},
+			{Path: "/dd/info.plist", Size: 10},
+		}},
+		MetadataVersion: 2,
+	}
+	data, err := json.Marshal(md)
+	require.NoError(t, err)
+
+	entry := Inspect("xcode-key", data, DefaultMaxBytes)
+	require.Equal(t, KindDerivedDataMetadata, entry.Kind)
+	require.NotNil(t, entry.DerivedData)
+
+	text := renderText(t, entry, 0)
+	assert.Contains(t, text, "Git branch: main")
+	assert.Contains(t, text, "DerivedData: 4 files, 0 symlinks, 5.1 kB")
+	assert.Contains(t, text, "  /dd/\n")
+	assert.Contains(t, text, "    Build/ (5.1 kB)\n")
+	assert.Contains(t, text, "          Info.plist (100 B)\n")
+	assert.Contains(t, text, "lib.a (2.0 kB, 2 chunks)")
+	assert.NotContains(t, text, "Project files")
+
+	collapsed := renderText(t, entry, 1)
+	assert.Contains(t, collapsed, "    Build/ (3 files, 5.1 kB)\n")
+	assert.NotContains(t, collapsed, "App.app")
+}
+
+func TestInspect_gradleMetadata(t *testing.T) {
+	md := gradle.Metadata{
+		CacheKey: "gradle-key",
+		OS:       "linux",
+		ConfigCacheFiles: filegroup.Info{Files: []*filegroup.FileInfo{
+			{Path: "/p/.gradle/configuration-cache/a.bin", Size: 42},
+		}},
+	}
+	data, err := json.Marshal(md)
+	require.NoError(t, err)
+
+	entry := Inspect("gradle-key", data, DefaultMaxBytes)
+	require.Equal(t, KindGradleMetadata, entry.Kind)
+
+	text := renderText(t, entry, 0)
+	assert.Contains(t, text, "OS: linux")
+	assert.Contains(t, text, "Configuration cache: 1 files")
+	assert.Contains(t, text, "a.bin (42 B)")
+}
+
+func TestInspect_casBlob(t *testing.T) {
+	payload := []byte("object payload")
+	encoded, err := io.ReadAll(casblob.NewReader([][]byte{{0xab, 0xcd}, {0x01}}, bytes.NewReader(payload), int64(len(payload))))
+	require.NoError(t, err)
+
+	entry := Inspect("cas-key", encoded, 6)
+	require.Equal(t, KindCASBlob, entry.Kind)
+	assert.Equal(t, []string{"abcd", "01"}, entry.CASBlob.References)
+	assert.Equal(t, int64(len(payload)), entry.CASBlob.PayloadSize)
+
+	text := renderText(t, entry, 0)
+	assert.Contains(t, text, "References (2):\n  abcd\n  01\n")
+	assert.Contains(t, text, "|object|")
+	assert.Contains(t, text, "... 8 B more")
+}
+
+func TestInspect_rawBytes(t *testing.T) {
+	entry := Inspect("raw-key", []byte("not a known format, just bytes"), 4)
+	require.Equal(t, KindRaw, entry.Kind)
+	assert.Equal(t, "6e6f7420", entry.Head)
+
+	text := renderText(t, entry, 0)
+	assert.Contains(t, text, "|not |")
+	assert.Contains(t, text, "... 26 B more")
+
+	// JSON that is not a known metadata document stays raw
+	assert.Equal(t, KindRaw, Inspect("k", []byte(`{"hello":"world"}`), 4).Kind)
+	assert.Contains(t, renderText(t, Inspect("k", nil, 4), 0), "Content: empty")
+}
+
+func TestChecksumPointer(t *testing.T) {
+	checksum := strings.Repeat("ab", 32)
+
+	got, ok := ChecksumPointer([]byte(checksum + "\n"))
+	assert.True(t, ok)
+	assert.Equal(t, checksum, got)
+
+	for _, data := range []string{"", checksum[:62], strings.ToUpper(checksum), strings.Repeat("zz", 32), `{"derivedData":{}}`} {
+		_, ok := ChecksumPointer([]byte(data))
+		assert.False(t, ok, data)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteJSON(&out, Inspect("raw-key", []byte{1, 2, 3}, DefaultMaxBytes)))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "raw-key", decoded["key"])
+	assert.Equal(t, "raw", decoded["kind"])
+	assert.Equal(t, "010203", decoded["head"])
+	assert.False(t, strings.Contains(out.String(), "casBlob"))
+}
@@ -0,0 +1,222 @@
+package cacheinspect
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+)
+
+// WriteJSON writes the entry as a single JSON document.
+func WriteJSON(out io.Writer, entry Entry) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entry); err != nil {
+		return fmt.Errorf("encode entry JSON: %w", err)
+	}
+
+	return nil
+}
+
+// WriteText pretty-prints the entry. File trees are expanded down to depth
+// directory levels below their common root; 0 expands them fully.
+func WriteText(out io.Writer, entry Entry, depth int) error {
+	p := &printer{out: out}
+
+	p.line(0, "Key: %s", entry.Key)
+	if entry.MetadataChecksum != "" {
+		p.line(0, "Metadata checksum: %s", entry.MetadataChecksum)
+	}
+	p.line(0, "Kind: %s", entry.Kind)
+	p.line(0, "Size: %s", humanizeSize(entry.Size))
+
+	switch entry.Kind {
+	case KindDerivedDataMetadata:
+		md := entry.DerivedData
+		p.header(md.CacheKey, md.CreatedAt, md.GitCommit, md.GitBranch, md.BuildCacheCLIVersion, md.MetadataVersion)
+		p.fileGroup("Project files", md.ProjectFiles, depth)
+		p.fileGroup("DerivedData", md.DerivedData, depth)
+		p.fileGroup("Xcode cache", md.XcodeCacheDir, depth)
+	case KindGradleMetadata:
+		md := entry.Gradle
+		p.header(md.CacheKey, md.CreatedAt, md.GitCommit, md.GitBranch, md.BuildCacheCLIVersion, md.MetadataVersion)
+		p.line(0, "OS: %s", md.OS)
+		p.fileGroup("Configuration cache", md.ConfigCacheFiles, depth)
+	case KindCASBlob:
+		p.line(0, "Payload size: %s", humanizeSize(entry.CASBlob.PayloadSize))
+		p.line(0, "References (%d):", len(entry.CASBlob.References))
+		for _, ref := range entry.CASBlob.References {
+			p.line(1, "%s", ref)
+		}
+		p.hexdump("Payload", entry.head, entry.CASBlob.PayloadSize)
+	case KindRaw:
+		p.hexdump("Content", entry.head, entry.Size)
+	}
+
+	return p.err
+}
+
+type printer struct {
+	out io.Writer
+	err error
+}
+
+func (p *printer) line(indent int, format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.out, strings.Repeat("  ", indent)+format+"\n", args...)
+}
+
+func (p *printer) header(cacheKey string, createdAt time.Time, commit, branch, cliVersion string, version int) {
+	p.line(0, "Cache key: %s", cacheKey)
+	if !createdAt.IsZero() {
+		p.line(0, "Created at: %s", createdAt.Format(time.RFC3339))
+	}
+	if commit != "" {
+		p.line(0, "Git commit: %s", commit)
+	}
+	if branch != "" {
+		p.line(0, "Git branch: %s", branch)
+	}
+	if cliVersion != "" {
+		p.line(0, "Build Cache CLI version: %s", cliVersion)
+	}
+	p.line(0, "Metadata version: %d", version)
+}
+
+func (p *printer) fileGroup(title string, info filegroup.Info, depth int) {
+	if len(info.Files) == 0 && len(info.Symlinks) == 0 {
+		return
+	}
+
+	root := newTree(info.Files)
+	p.line(0, "%s: %d files, %d symlinks, %s", title, len(info.Files), len(info.Symlinks), humanizeSize(root.size))
+	if len(info.Files) == 0 {
+		return
+	}
+
+	p.line(1, "%s/", strings.TrimSuffix(root.name, "/"))
+	p.children(root, 2, depth)
+}
+
+func (p *printer) children(n *treeNode, indent, depth int) {
+	for _, child := range n.sortedChildren() {
+		if child.children == nil {
+			suffix := ""
+			if child.chunks > 0 {
+				suffix = fmt.Sprintf(", %d chunks", child.chunks)
+			}
+			p.line(indent, "%s (%s%s)", child.name, humanizeSize(child.size), suffix)
+
+			continue
+		}
+
+		if depth > 0 && indent-1 >= depth {
+			p.line(indent, "%s/ (%d files, %s)", child.name, child.files, humanizeSize(child.size))
+
+			continue
+		}
+
+		p.line(indent, "%s/ (%s)", child.name, humanizeSize(child.size))
+		p.children(child, indent+1, depth)
+	}
+}
+
+func (p *printer) hexdump(title string, head []byte, size int64) {
+	if size == 0 {
+		p.line(0, "%s: empty", title)
+
+		return
+	}
+
+	p.line(0, "%s:", title)
+	if p.err == nil {
+		_, p.err = io.WriteString(p.out, hex.Dump(head))
+	}
+	if rest := size - int64(len(head)); rest > 0 {
+		p.line(0, "... %s more", humanizeSize(rest))
+	}
+}
+
+// treeNode is a directory, or a file when children is nil.
+type treeNode struct {
+	name     string
+	size     int64
+	files    int
+	chunks   int
+	children map[string]*treeNode
+}
+
+// newTree builds a directory tree of the files, rooted at their deepest common directory.
+func newTree(files []*filegroup.FileInfo) *treeNode {
+	root := &treeNode{name: commonDir(files), children: map[string]*treeNode{}}
+
+	for _, f := range files {
+		rel, err := filepath.Rel(root.name, f.Path)
+		if err != nil {
+			rel = f.Path
+		}
+
+		node := root
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		for i, part := range parts {
+			node.size += f.Size
+			node.files++
+
+			if i == len(parts)-1 {
+				node.children[part] = &treeNode{name: part, size: f.Size, files: 1, chunks: len(f.Chunks)}
+
+				break
+			}
+
+			next, ok := node.children[part]
+			if !ok || next.children == nil {
+				next = &treeNode{name: part, children: map[string]*treeNode{}}
+				node.children[part] = next
+			}
+			node = next
+		}
+	}
+
+	return root
+}
+
+func (n *treeNode) sortedChildren() []*treeNode {
+	children := make([]*treeNode, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].name < children[j].name })
+
+	return children
+}
+
+func commonDir(files []*filegroup.FileInfo) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	prefix := filepath.Dir(files[0].Path)
+	for _, f := range files[1:] {
+		for prefix != "." && prefix != string(filepath.Separator) &&
+			!strings.HasPrefix(f.Path, prefix+string(filepath.Separator)) {
+			prefix = filepath.Dir(prefix)
+		}
+	}
+
+	return prefix
+}
+
+func humanizeSize(size int64) string {
+	//nolint:gosec
+	return humanize.Bytes(uint64(size))
+}
@@ -0,0 +1,176 @@
+// Package cachekey renders the metadata cache keys of the DerivedData and
+// Gradle configuration cache commands from text/template key templates, e.g.
+//
+//	{{ .App }}-{{ .Branch }}-{{ checksum "Podfile.lock" "Package.resolved" }}-{{ .Arch }}
+//
+// Variables: .App, .Branch, .Commit, .Build, .CI, .OS, .Arch and .XcodeVersion.
+// Functions: checksum (sha256 of the matching files, globs allowed) and env.
+package cachekey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// Templates is an ordered list of key templates: Primary is used for saving and
+// tried first on restore, Fallbacks are tried in order after it.
+type Templates struct {
+	Primary   string
+	Fallbacks []string
+}
+
+// Keys are the rendered Templates. Fallbacks never contain Primary or duplicates.
+type Keys struct {
+	Primary   string
+	Fallbacks []string
+}
+
+// WithDefaults fills the empty parts of t from defaults.
+func (t Templates) WithDefaults(defaults Templates) Templates {
+	if t.Primary == "" {
+		t.Primary = defaults.Primary
+	}
+	if len(t.Fallbacks) == 0 {
+		t.Fallbacks = defaults.Fallbacks
+	}
+
+	return t
+}
+
+// Render renders every template with vars. A fallback that cannot be rendered
+// (e.g. a variable is undetected) is skipped. When the primary key cannot be
+// rendered the error is returned along with the fallbacks that could.
+func (t Templates) Render(vars *Vars) (Keys, error) {
+	primary, primaryErr := Render(t.Primary, vars)
+
+	keys := Keys{Primary: primary}
+	seen := map[string]bool{}
+	if primaryErr == nil {
+		seen[primary] = true
+	}
+	for _, fallback := range t.Fallbacks {
+		key, err := Render(fallback, vars)
+		if err != nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys.Fallbacks = append(keys.Fallbacks, key)
+	}
+
+	return keys, primaryErr
+}
+
+// Render renders a single key template. The result is sanitized to stay within
+// one segment of the kv resource name.
+func Render(text string, vars *Vars) (string, error) {
+	tmpl, err := template.New("key").Option("missingkey=error").Funcs(template.FuncMap{
+		"checksum": checksumFiles,
+		"env":      func(name string) string { return vars.envs[name] },
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse cache key template %q: %w", text, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		var missing *MissingValueError
+		if errors.As(err, &missing) {
+			return "", missing
+		}
+
+		return "", fmt.Errorf("render cache key template %q: %w", text, err)
+	}
+
+	key := common.SanitizeCacheKeyComponent(strings.TrimSpace(sb.String()))
+	if key == "" {
+		return "", fmt.Errorf("cache key template %q rendered an empty key", text)
+	}
+
+	return key, nil
+}
+
+// FamilyVars are the values a key template is expanded with by Family.
+type FamilyVars struct {
+	App    string
+	Branch string
+	OS     string
+}
+
+// Family renders text for every combination of apps, branches and oses, e.g. to
+// enumerate the keys a renamed app left behind. Only .App, .Branch and .OS can
+// be used; a template using anything else cannot be expanded offline and is
+// rejected. The keys are returned sorted and without duplicates.
+func Family(text string, apps, branches, oses []string) ([]string, error) {
+	tmpl, err := template.New("key").Option("missingkey=error").Funcs(template.FuncMap{
+		"checksum": func(...string) (string, error) { return "", errors.New("checksum cannot be expanded") },
+		"env":      func(string) (string, error) { return "", errors.New("env cannot be expanded") },
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse cache key template %q: %w", text, err)
+	}
+
+	seen := map[string]bool{}
+	var keys []string
+	for _, app := range apps {
+		for _, branch := range branches {
+			for _, goos := range oses {
+				var sb strings.Builder
+				if err := tmpl.Execute(&sb, FamilyVars{App: app, Branch: branch, OS: goos}); err != nil {
+					return nil, fmt.Errorf("expand cache key template %q: %w", text, err)
+				}
+
+				key := common.SanitizeCacheKeyComponent(strings.TrimSpace(sb.String()))
+				if key == "" || seen[key] {
+					continue
+				}
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// checksumFiles hashes the content of the files matching patterns, so the key
+// changes whenever a lockfile does. Patterns matching nothing are ignored, but
+// at least one file has to exist.
+func checksumFiles(patterns ...string) (string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", fmt.Errorf("checksum: invalid pattern %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+
+	if len(files) == 0 {
+		return "", fmt.Errorf("checksum: no file matches %s", strings.Join(patterns, ", "))
+	}
+
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file) //nolint:gosec // paths come from the key template
+		if err != nil {
+			return "", fmt.Errorf("checksum: read %s: %w", file, err)
+		}
+
+		_, _ = fmt.Fprintf(h, "%s\n%d\n", filepath.ToSlash(file), len(content))
+		_, _ = h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
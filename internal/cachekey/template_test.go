@@ -0,0 +1,186 @@
+//go:build unit
+
+package cachekey
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_bitriseVariables(t *testing.T) {
+	vars := NewVars(map[string]string{
+		"BITRISE_IO":         "true",
+		"BITRISE_BUILD_SLUG": "build-1",
+		"BITRISE_APP_SLUG":   "app-slug",
+		"BITRISE_GIT_BRANCH": "feature/x",
+	}, nil)
+
+	key, err := Render("prefix-{{ .App }}-{{ .Branch }}-{{ .OS }}-{{ .Arch }}-{{ .CI }}", vars)
+
+	require.NoError(t, err)
+	assert.Equal(t, "prefix-app-slug-feature_x-"+runtime.GOOS+"-"+runtime.GOARCH+"-bitrise", key)
+}
+
+func TestRender_otherCIProviders(t *testing.T) {
+	tests := []struct {
+		name string
+		envs map[string]string
+		want string
+	}{
+		{
+			name: "GitHub Actions pull request",
+			envs: map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REPOSITORY": "org/repo", "GITHUB_HEAD_REF": "pr-branch", "GITHUB_REF_NAME": "12/merge"},
+			want: "org_repo-pr-branch",
+		},
+		{
+			name: "GitHub Actions push",
+			envs: map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REPOSITORY": "org/repo", "GITHUB_REF_NAME": "main"},
+			want: "org_repo-main",
+		},
+		{
+			name: "CircleCI",
+			envs: map[string]string{"CIRCLECI": "true", "CIRCLE_PROJECT_REPONAME": "repo", "CIRCLE_BRANCH": "main"},
+			want: "repo-main",
+		},
+		{
+			name: "GitLab CI",
+			envs: map[string]string{"GITLAB_CI": "true", "CI_PROJECT_PATH": "group/repo", "CI_COMMIT_REF_NAME": "main"},
+			want: "group_repo-main",
+		},
+		{
+			name: "Buildkite",
+			envs: map[string]string{"BUILDKITE": "true", "BUILDKITE_PIPELINE_SLUG": "pipeline", "BUILDKITE_BRANCH": "main"},
+			want: "pipeline-main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Render("{{ .App }}-{{ .Branch }}", NewVars(tt.envs, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
+
+func TestRender_missingValue(t *testing.T) {
+	_, err := Render("{{ .App }}-{{ .Branch }}", NewVars(map[string]string{"BITRISE_APP_SLUG": "app"}, nil))
+
+	var missing *MissingValueError
+	require.ErrorAs(t, err, &missing)
+	assert.EqualError(t, err, "cache key is required if BITRISE_GIT_BRANCH env var is not set")
+
+	_, err = Render("{{ .Branch }}", NewVars(map[string]string{"CIRCLECI": "true"}, nil))
+	assert.EqualError(t, err, "cache key is required if CIRCLE_BRANCH or BITRISE_GIT_BRANCH env var is not set")
+}
+
+func TestRender_checksum(t *testing.T) {
+	dir := t.TempDir()
+	podfileLock := filepath.Join(dir, "Podfile.lock")
+	require.NoError(t, os.WriteFile(podfileLock, []byte("PODS: []"), 0o600))
+	vars := NewVars(map[string]string{}, nil)
+
+	key, err := Render(`deps-{{ checksum "`+podfileLock+`" "`+filepath.Join(dir, "Package.resolved")+`" }}`, vars)
+	require.NoError(t, err)
+	assert.Len(t, key, len("deps-")+64)
+
+	require.NoError(t, os.WriteFile(podfileLock, []byte("PODS: [a]"), 0o600))
+	changed, err := Render(`deps-{{ checksum "`+filepath.Join(dir, "*.lock")+`" }}`, vars)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, changed)
+
+	_, err = Render(`{{ checksum "`+filepath.Join(dir, "missing")+`" }}`, vars)
+	assert.ErrorContains(t, err, "no file matches")
+}
+
+func TestRender_xcodeVersion(t *testing.T) {
+	calls := 0
+	vars := NewVars(map[string]string{}, func(name string, args ...string) (string, error) {
+		calls++
+		assert.Equal(t, "xcodebuild", name)
+		assert.Equal(t, []string{"-version"}, args)
+
+		return "Xcode 16.2\nBuild version 16C5032a\n", nil
+	})
+
+	key, err := Render("{{ .XcodeVersion }}-{{ .XcodeVersion }}", vars)
+
+	require.NoError(t, err)
+	assert.Equal(t, "16.2-16.2", key)
+	assert.Equal(t, 1, calls)
+
+	_, err = Render("{{ .XcodeVersion }}", NewVars(map[string]string{}, func(string, ...string) (string, error) {
+		return "", errors.New("xcodebuild not found")
+	}))
+	assert.ErrorContains(t, err, "xcodebuild not found")
+}
+
+func TestRender_invalidTemplate(t *testing.T) {
+	_, err := Render("{{ .App", NewVars(map[string]string{}, nil))
+	assert.ErrorContains(t, err, "parse cache key template")
+
+	_, err = Render("{{ .Unknown }}", NewVars(map[string]string{}, nil))
+	assert.ErrorContains(t, err, "render cache key template")
+}
+
+func TestTemplates_Render(t *testing.T) {
+	vars := NewVars(map[string]string{"BITRISE_APP_SLUG": "app", "BITRISE_GIT_BRANCH": "main"}, nil)
+
+	keys, err := Templates{
+		Primary:   "k-{{ .App }}-{{ .Branch }}",
+		Fallbacks: []string{"k-{{ .App }}-{{ .Branch }}", "k-{{ .App }}-{{ .XcodeVersion }}", "k-{{ .App }}", "k-{{ .App }}"},
+	}.Render(vars)
+
+	require.NoError(t, err)
+	assert.Equal(t, Keys{Primary: "k-app-main", Fallbacks: []string{"k-app"}}, keys)
+}
+
+func TestTemplates_Render_primaryFails(t *testing.T) {
+	vars := NewVars(map[string]string{"BITRISE_APP_SLUG": "app"}, nil)
+
+	keys, err := Templates{
+		Primary:   "k-{{ .App }}-{{ .Branch }}",
+		Fallbacks: []string{"k-{{ .App }}"},
+	}.Render(vars)
+
+	require.Error(t, err)
+	assert.Equal(t, []string{"k-app"}, keys.Fallbacks)
+}
+
+func TestTemplates_WithDefaults(t *testing.T) {
+	defaults := Templates{Primary: "p", Fallbacks: []string{"f"}}
+
+	assert.Equal(t, defaults, Templates{}.WithDefaults(defaults))
+	assert.Equal(t, Templates{Primary: "custom", Fallbacks: []string{"f"}}, Templates{Primary: "custom"}.WithDefaults(defaults))
+}
+
+func TestFamily(t *testing.T) {
+	keys, err := Family("prefix-{{ .App }}-{{ .Branch }}-{{ .OS }}", []string{"app"}, []string{"main", "feature/x"}, []string{"darwin", "linux"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"prefix-app-feature_x-darwin",
+		"prefix-app-feature_x-linux",
+		"prefix-app-main-darwin",
+		"prefix-app-main-linux",
+	}, keys)
+
+	// templates without .Branch collapse to one key per app and OS
+	keys, err = Family("prefix-{{ .App }}-{{ .OS }}", []string{"app"}, []string{"main", "feature/x"}, []string{"darwin"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prefix-app-darwin"}, keys)
+
+	for _, text := range []string{
+		"prefix-{{ .Commit }}",
+		`prefix-{{ checksum "Podfile.lock" }}`,
+		`prefix-{{ env "FOO" }}`,
+	} {
+		_, err := Family(text, []string{"app"}, []string{"main"}, []string{"darwin"})
+		require.Error(t, err, text)
+	}
+}
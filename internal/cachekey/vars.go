@@ -0,0 +1,118 @@
+package cachekey
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// MissingValueError is returned when a template uses a variable that cannot be
+// detected in the current environment.
+type MissingValueError struct {
+	EnvKeys []string
+}
+
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("cache key is required if %s env var is not set", strings.Join(e.EnvKeys, " or "))
+}
+
+// Vars are the variables available in key templates. Values are looked up in
+// the env vars of the detected CI provider first, then in the Bitrise ones.
+type Vars struct {
+	envs        map[string]string
+	commandFunc common.CommandFunc
+	provider    string
+	keys        common.CIEnvKeys
+
+	xcodeVersion string
+}
+
+// NewVars creates the template variables of envs. commandFunc is only used for
+// .XcodeVersion and may be nil.
+func NewVars(envs map[string]string, commandFunc common.CommandFunc) *Vars {
+	provider := common.DetectCIProvider(envs)
+
+	return &Vars{
+		envs:        envs,
+		commandFunc: commandFunc,
+		provider:    provider,
+		keys:        common.CIProviderEnvKeys(provider),
+	}
+}
+
+// App is the app slug on Bitrise, or the repository of other CI providers.
+func (v *Vars) App() (string, error) {
+	return v.lookup(v.keys.App)
+}
+
+// Branch is the git branch being built; for pull requests the source branch.
+func (v *Vars) Branch() (string, error) {
+	return v.lookup(v.keys.Branch)
+}
+
+// Commit is the git commit hash being built.
+func (v *Vars) Commit() (string, error) {
+	return v.lookup(v.keys.Commit)
+}
+
+// Build is the build (or pipeline run) identifier.
+func (v *Vars) Build() (string, error) {
+	return v.lookup(v.keys.Build)
+}
+
+// CI is the detected CI provider, "local" when none is detected.
+func (v *Vars) CI() string {
+	if v.provider == "" {
+		return "local"
+	}
+
+	return v.provider
+}
+
+// OS is the operating system, as in runtime.GOOS.
+func (v *Vars) OS() string {
+	return runtime.GOOS
+}
+
+// Arch is the CPU architecture, as in runtime.GOARCH.
+func (v *Vars) Arch() string {
+	return runtime.GOARCH
+}
+
+// XcodeVersion is the version of the selected Xcode, e.g. "16.2".
+func (v *Vars) XcodeVersion() (string, error) {
+	if v.xcodeVersion != "" {
+		return v.xcodeVersion, nil
+	}
+
+	if v.commandFunc == nil {
+		return "", fmt.Errorf("xcode version is not available")
+	}
+
+	output, err := v.commandFunc("xcodebuild", "-version")
+	if err != nil {
+		return "", fmt.Errorf("get xcode version: %w", err)
+	}
+
+	// Xcode 16.2
+	// Build version 16C5032a
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	version := strings.TrimSpace(strings.TrimPrefix(firstLine, "Xcode"))
+	if version == "" {
+		return "", fmt.Errorf("unexpected xcodebuild -version output: %q", output)
+	}
+
+	v.xcodeVersion = version
+
+	return version, nil
+}
+
+func (v *Vars) lookup(keys []string) (string, error) {
+	if value := common.LookupEnv(v.envs, keys); value != "" {
+		return value, nil
+	}
+
+	return "", &MissingValueError{EnvKeys: keys}
+}
@@ -173,4 +173,8 @@ type CcacheInvocation struct {
 	BuildToolStats     CcacheStats `json:"buildToolStats"`
 	DownloadedBytes    int64       `json:"downloadedBytes"`
 	UploadedBytes      int64       `json:"uploadedBytes"`
+	// LocalHits / RemoteHits split the storage helper's GET hits by the tier
+	// (on-disk or remote) that served them.
+	LocalHits  int64 `json:"localHits,omitempty"`
+	RemoteHits int64 `json:"remoteHits,omitempty"`
 }
@@ -15,6 +15,8 @@ const (
 	CALL_METHOD_STOP              callMethod = "Stop"
 	CALL_METHOD_SET_INVOCATION_ID callMethod = "SetInvocationID"
 	CALL_METHOD_GET_SESSION_STATS callMethod = "GetSessionStats"
+	CALL_METHOD_GET_TIER_HITS     callMethod = "GetTierHits"
+	CALL_METHOD_GET_DEGRADED      callMethod = "GetDegraded"
 	CALL_METHOD_HEALTH_CHECK      callMethod = "HealthCheck"
 )
 
@@ -24,6 +26,8 @@ type callStats struct {
 	key           string
 	uploadBytes   int64
 	downloadBytes int64
+	// localHit is set when a GET was served by the on-disk tier.
+	localHit bool
 }
 
 type statBuilder struct {
@@ -55,6 +59,12 @@ func (b *statBuilder) withDownloadBytes(bytes int64) *statBuilder {
 	return b
 }
 
+func (b *statBuilder) withLocalHit() *statBuilder {
+	b.stats.localHit = true
+
+	return b
+}
+
 func (b *statBuilder) build() callStats {
 	return b.stats
 }
@@ -71,6 +81,8 @@ func (b *statBuilder) Prefix() string {
 type sessionState struct {
 	downloadBytes atomic.Int64
 	uploadBytes   atomic.Int64
+	localHits     atomic.Int64
+	remoteHits    atomic.Int64
 }
 
 func newSessionState() *sessionState {
@@ -78,6 +90,9 @@ func newSessionState() *sessionState {
 }
 
 func (s *sessionState) resetAndGet() (int64, int64) {
+	s.localHits.Store(0)
+	s.remoteHits.Store(0)
+
 	return s.downloadBytes.Swap(0), s.uploadBytes.Swap(0)
 }
 
@@ -89,11 +104,16 @@ func (s *sessionState) updateWithResult(result processResult) {
 	switch result.CallStats.method {
 	case CALL_METHOD_GET:
 		s.downloadBytes.Add(result.CallStats.downloadBytes)
+		if result.CallStats.localHit {
+			s.localHits.Add(1)
+		} else {
+			s.remoteHits.Add(1)
+		}
 
 	case CALL_METHOD_PUT:
 		s.uploadBytes.Add(result.CallStats.uploadBytes)
 
-	case CALL_METHOD_REMOVE, CALL_METHOD_STOP, CALL_METHOD_SET_INVOCATION_ID, CALL_METHOD_GET_SESSION_STATS, CALL_METHOD_HEALTH_CHECK:
+	case CALL_METHOD_REMOVE, CALL_METHOD_STOP, CALL_METHOD_SET_INVOCATION_ID, CALL_METHOD_GET_SESSION_STATS, CALL_METHOD_GET_TIER_HITS, CALL_METHOD_GET_DEGRADED, CALL_METHOD_HEALTH_CHECK:
 		// no byte tracking for these methods
 	}
 }
@@ -49,6 +49,26 @@ func Test_sessionState_updateWithResult(t *testing.T) {
 		assert.Equal(t, int64(0), s.uploadBytes.Load())
 	})
 
+	t.Run("GET OK counts local and remote hits separately", func(t *testing.T) {
+		s := newSessionState()
+
+		s.updateWithResult(processResult{
+			Outcome:   PROCESS_REQUEST_OK,
+			CallStats: callStats{method: CALL_METHOD_GET, localHit: true},
+		})
+		s.updateWithResult(processResult{
+			Outcome:   PROCESS_REQUEST_OK,
+			CallStats: callStats{method: CALL_METHOD_GET},
+		})
+
+		assert.Equal(t, int64(1), s.localHits.Load())
+		assert.Equal(t, int64(1), s.remoteHits.Load())
+
+		s.resetAndGet()
+		assert.Equal(t, int64(0), s.localHits.Load())
+		assert.Equal(t, int64(0), s.remoteHits.Load())
+	})
+
 	t.Run("GET MISS does not change any counters", func(t *testing.T) {
 		s := newSessionState()
 		result := processResult{
@@ -15,6 +15,7 @@ type Client interface {
 	DownloadStream(ctx context.Context, writer io.Writer, key string) error
 	UploadStreamToBuildCache(ctx context.Context, reader io.ReadSeeker, key string, size int64) error
 	GetCapabilitiesWithRetry(ctx context.Context) error
+	Degraded() bool
 }
 
 // LoggerFactory creates a logger for a given invocation ID.
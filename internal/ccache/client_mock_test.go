@@ -22,6 +22,9 @@ var _ Client = &ClientMock{}
 //			ChangeSessionFunc: func(invocationID string, appSlug string, buildSlug string, stepSlug string)  {
 //				panic("mock out the ChangeSession method")
 //			},
+//			DegradedFunc: func() bool {
+//				panic("mock out the Degraded method")
+//			},
 //			DownloadStreamFunc: func(ctx context.Context, writer io.Writer, key string) error {
 //				panic("mock out the DownloadStream method")
 //			},
@@ -41,6 +44,9 @@ type ClientMock struct {
 	// ChangeSessionFunc mocks the ChangeSession method.
 	ChangeSessionFunc func(invocationID string, appSlug string, buildSlug string, stepSlug string)
 
+	// DegradedFunc mocks the Degraded method.
+	DegradedFunc func() bool
+
 	// DownloadStreamFunc mocks the DownloadStream method.
 	DownloadStreamFunc func(ctx context.Context, writer io.Writer, key string) error
 
@@ -63,6 +69,9 @@ type ClientMock struct {
 			// StepSlug is the stepSlug argument value.
 			StepSlug string
 		}
+		// Degraded holds details about calls to the Degraded method.
+		Degraded []struct {
+		}
 		// DownloadStream holds details about calls to the DownloadStream method.
 		DownloadStream []struct {
 			// Ctx is the ctx argument value.
@@ -90,6 +99,7 @@ type ClientMock struct {
 		}
 	}
 	lockChangeSession            sync.RWMutex
+	lockDegraded                 sync.RWMutex
 	lockDownloadStream           sync.RWMutex
 	lockGetCapabilitiesWithRetry sync.RWMutex
 	lockUploadStreamToBuildCache sync.RWMutex
@@ -139,6 +149,36 @@ func (mock *ClientMock) ChangeSessionCalls() []struct {
 	return calls
 }
 
+// Degraded calls DegradedFunc.
+func (mock *ClientMock) Degraded() bool {
+	callInfo := struct {
+	}{}
+	mock.lockDegraded.Lock()
+	mock.calls.Degraded = append(mock.calls.Degraded, callInfo)
+	mock.lockDegraded.Unlock()
+	if mock.DegradedFunc == nil {
+		var (
+			bOut bool
+		)
+		return bOut
+	}
+	return mock.DegradedFunc()
+}
+
+// DegradedCalls gets all the calls that were made to Degraded.
+// Check the length with:
+//
+//	len(mockedClient.DegradedCalls())
+func (mock *ClientMock) DegradedCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockDegraded.RLock()
+	calls = mock.calls.Degraded
+	mock.lockDegraded.RUnlock()
+	return calls
+}
+
 // DownloadStream calls DownloadStreamFunc.
 func (mock *ClientMock) DownloadStream(ctx context.Context, writer io.Writer, key string) error {
 	callInfo := struct {
@@ -2,7 +2,9 @@ package ccache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"time"
 
@@ -12,14 +14,28 @@ import (
 const (
 	defaultDialTimeout = 2 * time.Second
 	isListeningTimeout = 100 * time.Millisecond
+	// defaultReadTimeout bounds a stats exchange when ctx has no deadline, so a
+	// helper that never answers cannot hang collect-stats.
+	defaultReadTimeout = 5 * time.Second
 )
 
+// ErrRequestNotSupported is returned when the storage helper predates the
+// request and closed the connection instead of answering it.
+var ErrRequestNotSupported = errors.New("request not supported by the running storage helper")
+
 // SessionStats holds the stats returned by a GetSessionStats IPC call.
 type SessionStats struct {
 	DownloadedBytes int64
 	UploadedBytes   int64
 	InvocationID    string
 	ParentID        string
+	// LocalHits / RemoteHits split GET hits by the tier that served them.
+	// Filled by SendGetTierHits, not by SendGetSessionStats.
+	LocalHits  int64
+	RemoteHits int64
+	// Degraded is set when the remote was short-circuited during the session.
+	// Filled by SendGetDegraded, not by SendGetSessionStats.
+	Degraded bool
 }
 
 // IsListening returns true if a process is actively listening on the given Unix socket path.
@@ -81,6 +97,7 @@ func SendGetSessionStats(ctx context.Context, socketPath string) (SessionStats,
 		return SessionStats{}, fmt.Errorf("connect to ccache socket %s: %w", socketPath, err)
 	}
 	defer conn.Close()
+	setReadDeadline(ctx, conn)
 
 	if err := protocol.ReadGreeting(conn); err != nil {
 		return SessionStats{}, fmt.Errorf("read greeting: %w", err)
@@ -117,6 +134,103 @@ func SendGetSessionStats(ctx context.Context, socketPath string) (SessionStats,
 	}
 }
 
+// SendGetTierHits requests the local / remote split of the session's GET hits.
+// Returns ErrRequestNotSupported when the helper predates the request.
+func SendGetTierHits(ctx context.Context, socketPath string) (localHits, remoteHits int64, err error) {
+	conn, err := (&net.Dialer{Timeout: defaultDialTimeout}).DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("connect to ccache socket %s: %w", socketPath, err)
+	}
+	defer conn.Close()
+	setReadDeadline(ctx, conn)
+
+	if err := protocol.ReadGreeting(conn); err != nil {
+		return 0, 0, fmt.Errorf("read greeting: %w", err)
+	}
+
+	if err := protocol.WriteByte(conn, protocol.RequestGetTierHits); err != nil {
+		return 0, 0, fmt.Errorf("send get-tier-hits request: %w", err)
+	}
+
+	resp, err := protocol.ReadByte(conn)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, 0, ErrRequestNotSupported
+		}
+
+		return 0, 0, fmt.Errorf("read response: %w", err)
+	}
+
+	switch resp {
+	case protocol.ResponseOK:
+		localHits, remoteHits, err := protocol.ReadTierHits(conn)
+		if err != nil {
+			return 0, 0, fmt.Errorf("read tier hits: %w", err)
+		}
+
+		return localHits, remoteHits, nil
+	case protocol.ResponseErr:
+		msg, _ := protocol.ReadMsg(conn)
+
+		return 0, 0, fmt.Errorf("server error: %s", msg)
+	default:
+		return 0, 0, fmt.Errorf("unexpected response: 0x%02x", resp)
+	}
+}
+
+// SendGetDegraded reports whether the helper's kv client ran in degraded mode.
+// Returns ErrRequestNotSupported when the helper predates the request.
+func SendGetDegraded(ctx context.Context, socketPath string) (bool, error) {
+	conn, err := (&net.Dialer{Timeout: defaultDialTimeout}).DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return false, fmt.Errorf("connect to ccache socket %s: %w", socketPath, err)
+	}
+	defer conn.Close()
+	setReadDeadline(ctx, conn)
+
+	if err := protocol.ReadGreeting(conn); err != nil {
+		return false, fmt.Errorf("read greeting: %w", err)
+	}
+
+	if err := protocol.WriteByte(conn, protocol.RequestGetDegraded); err != nil {
+		return false, fmt.Errorf("send get-degraded request: %w", err)
+	}
+
+	resp, err := protocol.ReadByte(conn)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, ErrRequestNotSupported
+		}
+
+		return false, fmt.Errorf("read response: %w", err)
+	}
+
+	switch resp {
+	case protocol.ResponseOK:
+		degraded, err := protocol.ReadDegraded(conn)
+		if err != nil {
+			return false, fmt.Errorf("read degraded flag: %w", err)
+		}
+
+		return degraded, nil
+	case protocol.ResponseErr:
+		msg, _ := protocol.ReadMsg(conn)
+
+		return false, fmt.Errorf("server error: %s", msg)
+	default:
+		return false, fmt.Errorf("unexpected response: 0x%02x", resp)
+	}
+}
+
+// setReadDeadline bounds the exchange by ctx's deadline, or defaultReadTimeout.
+func setReadDeadline(ctx context.Context, conn net.Conn) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultReadTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+}
+
 // SendHealthCheck connects to the ccache storage helper and sends a health-check request.
 // Returns nil if the server is up and responding, or an error if unreachable or unhealthy.
 func SendHealthCheck(ctx context.Context, socketPath string) error {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -307,3 +308,94 @@ func Test_SendHealthCheck(t *testing.T) {
 		assert.NoError(t, <-errCh)
 	})
 }
+
+// serveOnce accepts one connection, writes the greeting, reads the request
+// type and hands the connection to respond.
+func serveOnce(t *testing.T, socketPath string, respond func(conn net.Conn, reqType byte)) {
+	t.Helper()
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if err := protocol.WriteGreeting(conn); err != nil {
+			return
+		}
+		reqType, err := protocol.ReadByte(conn)
+		if err != nil {
+			return
+		}
+		respond(conn, reqType)
+	}()
+}
+
+func Test_SendGetTierHits(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		socketPath := shortTempSocket(t, "t.sock")
+		serveOnce(t, socketPath, func(conn net.Conn, reqType byte) {
+			if reqType != protocol.RequestGetTierHits {
+				return
+			}
+			_ = protocol.WriteOK(conn)
+			_ = protocol.WriteTierHits(conn, 3, 7)
+		})
+
+		local, remote, err := SendGetTierHits(context.Background(), socketPath)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), local)
+		assert.Equal(t, int64(7), remote)
+	})
+
+	t.Run("older helper closes the connection", func(t *testing.T) {
+		socketPath := shortTempSocket(t, "t.sock")
+		serveOnce(t, socketPath, func(net.Conn, byte) {})
+
+		_, _, err := SendGetTierHits(context.Background(), socketPath)
+		require.ErrorIs(t, err, ErrRequestNotSupported)
+	})
+
+	t.Run("helper that never answers times out", func(t *testing.T) {
+		socketPath := shortTempSocket(t, "t.sock")
+		release := make(chan struct{})
+		t.Cleanup(func() { close(release) })
+		serveOnce(t, socketPath, func(net.Conn, byte) { <-release })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		_, _, err := SendGetTierHits(ctx, socketPath)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrRequestNotSupported)
+	})
+}
+
+func Test_SendGetDegraded(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		socketPath := shortTempSocket(t, "t.sock")
+		serveOnce(t, socketPath, func(conn net.Conn, reqType byte) {
+			if reqType != protocol.RequestGetDegraded {
+				return
+			}
+			_ = protocol.WriteOK(conn)
+			_ = protocol.WriteDegraded(conn, true)
+		})
+
+		degraded, err := SendGetDegraded(context.Background(), socketPath)
+		require.NoError(t, err)
+		assert.True(t, degraded)
+	})
+
+	t.Run("older helper closes the connection", func(t *testing.T) {
+		socketPath := shortTempSocket(t, "t.sock")
+		serveOnce(t, socketPath, func(net.Conn, byte) {})
+
+		_, err := SendGetDegraded(context.Background(), socketPath)
+		require.ErrorIs(t, err, ErrRequestNotSupported)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/ccache/protocol"
 	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
 )
 
 type IpcServer struct {
@@ -32,6 +33,7 @@ type IpcServer struct {
 	activeInvocationID string
 	activeParentID     string
 	activeInvocationMu sync.Mutex
+	localStore         *localcache.Store
 }
 
 func NewServer(
@@ -42,6 +44,17 @@ func NewServer(
 	loggerFactory LoggerFactory,
 	initialInvocationID string,
 ) (*IpcServer, error) {
+	var localStore *localcache.Store
+	if config.LocalCacheMaxBytes > 0 {
+		store, err := localcache.Open(config.LocalCacheDir, config.LocalCacheMaxBytes)
+		if err != nil {
+			logger.TWarnf("Local cache tier disabled: %v", err)
+		} else {
+			logger.TInfof("Local cache tier: %s (%d of %d bytes used)", store.Dir(), store.Size(), config.LocalCacheMaxBytes)
+			localStore = store
+		}
+	}
+
 	return &IpcServer{
 		config:             config,
 		metadata:           metadata,
@@ -50,6 +63,7 @@ func NewServer(
 		loggerFactory:      loggerFactory,
 		sessionState:       newSessionState(),
 		activeInvocationID: initialInvocationID,
+		localStore:         localStore,
 	}, nil
 }
 
@@ -113,6 +127,7 @@ func (s *IpcServer) handleConnection(ctx context.Context, cancelFn context.Cance
 	}
 
 	processor := newRequestProcessor(conn, s.config, s.metadata, s.client, s.logger, s.loggerFactory, s.getCapabilities)
+	processor.localStore = s.localStore
 
 	if err := processor.initCapabilities(ctx); err != nil {
 		s.logger.TErrorf("[%s] Capabilities check failed: %v", conID, err)
@@ -132,6 +147,14 @@ func (s *IpcServer) handleConnection(ctx context.Context, cancelFn context.Cance
 			s.handleGetSessionStatsResult(conn, conID)
 		}
 
+		if result.CallStats.method == CALL_METHOD_GET_TIER_HITS && result.Outcome == PROCESS_REQUEST_OK {
+			s.handleGetTierHitsResult(conn, conID)
+		}
+
+		if result.CallStats.method == CALL_METHOD_GET_DEGRADED && result.Outcome == PROCESS_REQUEST_OK {
+			s.handleGetDegradedResult(conn, conID)
+		}
+
 		if result.CallStats.method == CALL_METHOD_STOP && result.Outcome == PROCESS_REQUEST_OK {
 			s.handleStopResult(conn, conID, cancelFn)
 
@@ -184,6 +207,35 @@ func (s *IpcServer) handleGetSessionStatsResult(conn net.Conn, conID string) {
 	}
 }
 
+func (s *IpcServer) handleGetTierHitsResult(conn net.Conn, conID string) {
+	s.activeInvocationMu.Lock()
+	localHits := s.sessionState.localHits.Load()
+	remoteHits := s.sessionState.remoteHits.Load()
+	s.activeInvocationMu.Unlock()
+
+	if err := protocol.WriteOK(conn); err != nil {
+		s.logger.TErrorf("[%s] Failed to write tier hits response: %v", conID, err)
+
+		return
+	}
+
+	if err := protocol.WriteTierHits(conn, localHits, remoteHits); err != nil {
+		s.logger.TErrorf("[%s] Failed to write tier hits: %v", conID, err)
+	}
+}
+
+func (s *IpcServer) handleGetDegradedResult(conn net.Conn, conID string) {
+	if err := protocol.WriteOK(conn); err != nil {
+		s.logger.TErrorf("[%s] Failed to write degraded response: %v", conID, err)
+
+		return
+	}
+
+	if err := protocol.WriteDegraded(conn, s.client.Degraded()); err != nil {
+		s.logger.TErrorf("[%s] Failed to write degraded flag: %v", conID, err)
+	}
+}
+
 // SessionBytes returns the accumulated bytes downloaded and uploaded since the last SetInvocationID reset.
 func (s *IpcServer) SessionBytes() (int64, int64) {
 	return s.sessionState.downloadBytes.Load(), s.sessionState.uploadBytes.Load()
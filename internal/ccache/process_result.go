@@ -9,6 +9,9 @@ const (
 	PROCESS_REQUEST_MISS          processResultOutcome = 1
 	PROCESS_REQUEST_ERROR         processResultOutcome = 3
 	PROCESS_REQUEST_PUSH_DISABLED processResultOutcome = 4
+	// PROCESS_REQUEST_DEGRADED is a PUT skipped because the remote cache is
+	// degraded. ccache sees a no-op, and it is not counted as an upload.
+	PROCESS_REQUEST_DEGRADED processResultOutcome = 5
 )
 
 type processResult struct {
@@ -30,6 +33,8 @@ func (result processResult) OutcomeString() string {
 		return fmt.Sprintf("ERROR: %v", result.Err)
 	case PROCESS_REQUEST_PUSH_DISABLED:
 		return "PUSH_DISABLED"
+	case PROCESS_REQUEST_DEGRADED:
+		return "DEGRADED"
 	default:
 		return fmt.Sprintf("UNKNOWN(%d)", result.Outcome)
 	}
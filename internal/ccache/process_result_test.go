@@ -36,6 +36,11 @@ func Test_processResult_OutcomeString(t *testing.T) {
 			result:   processResult{Outcome: PROCESS_REQUEST_PUSH_DISABLED},
 			expected: "PUSH_DISABLED",
 		},
+		{
+			name:     "DEGRADED outcome",
+			result:   processResult{Outcome: PROCESS_REQUEST_DEGRADED},
+			expected: "DEGRADED",
+		},
 		{
 			name:     "unknown outcome",
 			result:   processResult{Outcome: processResultOutcome(99)},
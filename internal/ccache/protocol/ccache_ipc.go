@@ -17,6 +17,12 @@ const (
 	RequestSetInvocationID  = 0xB1
 	RequestGetSessionStats  = 0xB2
 	RequestHealthCheck      = 0xB3
+	// RequestGetTierHits is answered with the local / remote hit split. A
+	// storage helper predating it closes the connection instead.
+	RequestGetTierHits = 0xB4
+	// RequestGetDegraded is answered with the degraded-mode flag. A storage
+	// helper predating it closes the connection instead.
+	RequestGetDegraded = 0xB5
 
 	ResponseOK   = 0x00
 	ResponseNoop = 0x01
@@ -209,6 +215,47 @@ func ReadSessionStats(r io.Reader) (downloadBytes, uploadBytes int64, invocation
 	return downloadBytes, uploadBytes, invocationID, parentID, nil
 }
 
+// WriteTierHits writes the local / remote hit split answering RequestGetTierHits.
+func WriteTierHits(w io.Writer, localHits, remoteHits int64) error {
+	if err := binary.Write(w, binary.NativeEndian, localHits); err != nil {
+		return err
+	}
+
+	return binary.Write(w, binary.NativeEndian, remoteHits)
+}
+
+// ReadTierHits reads the hit split written by WriteTierHits.
+func ReadTierHits(r io.Reader) (localHits, remoteHits int64, err error) {
+	if err := binary.Read(r, binary.NativeEndian, &localHits); err != nil {
+		return 0, 0, fmt.Errorf("read local hits: %w", err)
+	}
+	if err := binary.Read(r, binary.NativeEndian, &remoteHits); err != nil {
+		return 0, 0, fmt.Errorf("read remote hits: %w", err)
+	}
+
+	return localHits, remoteHits, nil
+}
+
+// WriteDegraded writes the degraded-mode flag answering RequestGetDegraded.
+func WriteDegraded(w io.Writer, degraded bool) error {
+	var b byte
+	if degraded {
+		b = 1
+	}
+
+	return WriteByte(w, b)
+}
+
+// ReadDegraded reads the flag written by WriteDegraded.
+func ReadDegraded(r io.Reader) (bool, error) {
+	b, err := ReadByte(r)
+	if err != nil {
+		return false, fmt.Errorf("read degraded flag: %w", err)
+	}
+
+	return b != 0, nil
+}
+
 func ReadSetInvocationID(r io.Reader) (parentID, childID string, err error) {
 	parentID, err = ReadMsg(r)
 	if err != nil {
@@ -161,3 +161,36 @@ func Test_WriteErr(t *testing.T) {
 		assert.Equal(t, errMsg, got)
 	})
 }
+
+func Test_WriteReadTierHits(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, protocol.WriteTierHits(&buf, 3, 7))
+
+		local, remote, err := protocol.ReadTierHits(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), local)
+		assert.Equal(t, int64(7), remote)
+	})
+
+	t.Run("truncated response", func(t *testing.T) {
+		_, _, err := protocol.ReadTierHits(bytes.NewReader(make([]byte, 8)))
+		require.Error(t, err)
+	})
+}
+
+func Test_WriteReadDegraded(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, protocol.WriteDegraded(&buf, true))
+
+		degraded, err := protocol.ReadDegraded(&buf)
+		require.NoError(t, err)
+		assert.True(t, degraded)
+	})
+
+	t.Run("missing flag", func(t *testing.T) {
+		_, err := protocol.ReadDegraded(&bytes.Buffer{})
+		require.Error(t, err)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/ccache/protocol"
 	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
 )
 
 type requestProcessor struct {
@@ -27,6 +28,8 @@ type requestProcessor struct {
 	metadata        configcommon.CacheConfigMetadata
 	loggerFactory   LoggerFactory
 	getCapabilities func(context.Context) error
+	// localStore is the optional on-disk tier consulted before the remote.
+	localStore *localcache.Store
 }
 
 func newRequestProcessor(
@@ -63,7 +66,7 @@ func (p *requestProcessor) notifyClient(result processResult) processResult {
 	case PROCESS_REQUEST_ERROR:
 		err = protocol.WriteErr(p.writer, result.Err.Error())
 
-	case PROCESS_REQUEST_MISS, PROCESS_REQUEST_PUSH_DISABLED:
+	case PROCESS_REQUEST_MISS, PROCESS_REQUEST_PUSH_DISABLED, PROCESS_REQUEST_DEGRADED:
 		err = protocol.WriteNoop(p.writer)
 
 	case PROCESS_REQUEST_OK:
@@ -134,6 +137,17 @@ func (p *requestProcessor) handleGet(ctx context.Context) processResult {
 	p.logger.TDebugf("%s Called", statBuilder.Prefix())
 
 	buffer := bytes.NewBuffer(nil)
+	if p.getLocal(key, buffer) {
+		statBuilder.withLocalHit()
+		statBuilder.withDownloadBytes(int64(buffer.Len()))
+
+		return p.notifyClient(processResult{
+			Outcome:   PROCESS_REQUEST_OK,
+			CallStats: statBuilder.build(),
+			Data:      buffer.Bytes(),
+		})
+	}
+
 	err = p.client.DownloadStream(ctx, buffer, key)
 
 	switch {
@@ -154,6 +168,7 @@ func (p *requestProcessor) handleGet(ctx context.Context) processResult {
 
 	size := int64(buffer.Len())
 	statBuilder.withDownloadBytes(size)
+	p.putLocal(key, buffer.Bytes())
 
 	data, err := io.ReadAll(buffer)
 	if err != nil {
@@ -203,6 +218,8 @@ func (p *requestProcessor) handlePut(ctx context.Context) processResult {
 		}
 	}
 
+	p.putLocal(key, value)
+
 	if !p.config.PushEnabled {
 		return p.notifyClient(processResult{
 			Outcome:   PROCESS_REQUEST_PUSH_DISABLED,
@@ -214,7 +231,12 @@ func (p *requestProcessor) handlePut(ctx context.Context) processResult {
 	statBuilder.withUploadBytes(size)
 	p.logger.TDebugf("%s Called (%d bytes)", statBuilder.Prefix(), size)
 
-	if err = p.client.UploadStreamToBuildCache(ctx, bytes.NewReader(value), key, size); err != nil {
+	if err = p.client.UploadStreamToBuildCache(ctx, bytes.NewReader(value), key, size); errors.Is(err, kv.ErrDegraded) {
+		return p.notifyClient(processResult{
+			Outcome:   PROCESS_REQUEST_DEGRADED,
+			CallStats: statBuilder.build(),
+		})
+	} else if err != nil {
 		return p.notifyClient(processResult{
 			Outcome:   PROCESS_REQUEST_ERROR,
 			Err:       fmt.Errorf("failed to upload data: %w", err),
@@ -228,6 +250,35 @@ func (p *requestProcessor) handlePut(ctx context.Context) processResult {
 	})
 }
 
+// getLocal copies the local tier's entry for key into w. Reports false on a
+// miss or when no local tier is configured.
+func (p *requestProcessor) getLocal(key string, w *bytes.Buffer) bool {
+	if p.localStore == nil {
+		return false
+	}
+
+	if _, err := p.localStore.Get(key, w); err != nil {
+		if !errors.Is(err, localcache.ErrNotFound) {
+			p.logger.TDebugf("Local cache read failed for %s: %v", key, err)
+		}
+		w.Reset()
+
+		return false
+	}
+
+	return true
+}
+
+func (p *requestProcessor) putLocal(key string, data []byte) {
+	if p.localStore == nil {
+		return
+	}
+
+	if _, err := p.localStore.Put(key, bytes.NewReader(data)); err != nil {
+		p.logger.TDebugf("Local cache write failed for %s: %v", key, err)
+	}
+}
+
 func (p *requestProcessor) handleRemove() processResult {
 	statBuilder := newStatBuilder(CALL_METHOD_REMOVE)
 	keyBytes, err := protocol.ReadKey(p.reader)
@@ -305,6 +356,28 @@ func (p *requestProcessor) handleGetSessionStats() processResult {
 	}
 }
 
+func (p *requestProcessor) handleGetTierHits() processResult {
+	statBuilder := newStatBuilder(CALL_METHOD_GET_TIER_HITS)
+	p.logger.TDebugf("%s received", statBuilder.Prefix())
+
+	// Response (OK + hits) is written by handleConnection which has access to sessionState.
+	return processResult{
+		Outcome:   PROCESS_REQUEST_OK,
+		CallStats: statBuilder.build(),
+	}
+}
+
+func (p *requestProcessor) handleGetDegraded() processResult {
+	statBuilder := newStatBuilder(CALL_METHOD_GET_DEGRADED)
+	p.logger.TDebugf("%s received", statBuilder.Prefix())
+
+	// Response (OK + flag) is written by handleConnection which has access to the kv client.
+	return processResult{
+		Outcome:   PROCESS_REQUEST_OK,
+		CallStats: statBuilder.build(),
+	}
+}
+
 func (p *requestProcessor) handleHealthCheck() processResult {
 	statBuilder := newStatBuilder(CALL_METHOD_HEALTH_CHECK)
 	p.logger.TDebugf("%s received", statBuilder.Prefix())
@@ -368,6 +441,16 @@ func (p *requestProcessor) processRequest(ctx context.Context) processResult {
 
 		return result
 
+	case protocol.RequestGetTierHits:
+		result = p.handleGetTierHits()
+
+		return result
+
+	case protocol.RequestGetDegraded:
+		result = p.handleGetDegraded()
+
+		return result
+
 	case protocol.RequestHealthCheck:
 		result = p.handleHealthCheck()
 
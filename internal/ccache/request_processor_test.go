@@ -19,6 +19,7 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/ccache/protocol"
 	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
 )
 
 // connStub implements io.ReadWriter using separate read/write buffers.
@@ -214,6 +215,30 @@ func Test_requestProcessor_processRequest(t *testing.T) {
 		assert.Equal(t, byte(protocol.ResponseOK), resp[0])
 	})
 
+	t.Run("PUT skipped while degraded is a no-op", func(t *testing.T) {
+		key := []byte{0xAB, 0xCD}
+		value := []byte("cache content")
+
+		client := &ClientMock{
+			UploadStreamToBuildCacheFunc: func(_ context.Context, _ io.ReadSeeker, _ string, _ int64) error {
+				return kv.ErrDegraded
+			},
+		}
+
+		conn := &connStub{
+			r: bytes.NewBuffer(buildPutRequest(key, value, 0x00)),
+			w: &bytes.Buffer{},
+		}
+
+		proc := newRequestProcessor(conn, defaultConfig(), configcommon.CacheConfigMetadata{}, client, mockLogger, nil, noOpCaps)
+		result := proc.processRequest(context.Background())
+
+		assert.Equal(t, PROCESS_REQUEST_DEGRADED, result.Outcome)
+		resp := conn.w.Bytes()
+		require.NotEmpty(t, resp)
+		assert.Equal(t, byte(protocol.ResponseNoop), resp[0])
+	})
+
 	t.Run("PUT push disabled", func(t *testing.T) {
 		key := []byte{0xAB, 0xCD}
 		value := []byte("cache content")
@@ -388,6 +413,61 @@ func Test_requestProcessor_processRequest(t *testing.T) {
 	})
 }
 
+func Test_requestProcessor_localStore(t *testing.T) {
+	t.Run("GET remote hit is written through and then served locally", func(t *testing.T) {
+		key := []byte{0xAB, 0xCD}
+		data := []byte("object")
+
+		client := &ClientMock{
+			DownloadStreamFunc: func(_ context.Context, w io.Writer, _ string) error {
+				_, err := w.Write(data)
+				return err
+			},
+		}
+
+		store, err := localcache.Open(t.TempDir(), 1024)
+		require.NoError(t, err)
+
+		for _, wantLocal := range []bool{false, true} {
+			conn := &connStub{r: bytes.NewBuffer(buildGetRequest(key)), w: &bytes.Buffer{}}
+			proc := newRequestProcessor(conn, defaultConfig(), configcommon.CacheConfigMetadata{}, client, mockLogger, nil, noOpCaps)
+			proc.localStore = store
+
+			result := proc.processRequest(context.Background())
+
+			assert.Equal(t, PROCESS_REQUEST_OK, result.Outcome)
+			assert.Equal(t, wantLocal, result.CallStats.localHit)
+			assert.Equal(t, int64(len(data)), result.CallStats.downloadBytes)
+
+			got, err := protocol.ReadValue(bytes.NewReader(conn.w.Bytes()[1:]))
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		}
+
+		assert.Len(t, client.DownloadStreamCalls(), 1)
+	})
+
+	t.Run("PUT stores locally even when push is disabled", func(t *testing.T) {
+		key := []byte{0xAB, 0xCD}
+		value := []byte("object")
+
+		store, err := localcache.Open(t.TempDir(), 1024)
+		require.NoError(t, err)
+
+		config := defaultConfig()
+		config.PushEnabled = false
+
+		conn := &connStub{r: bytes.NewBuffer(buildPutRequest(key, value, 0)), w: &bytes.Buffer{}}
+		proc := newRequestProcessor(conn, config, configcommon.CacheConfigMetadata{}, &ClientMock{}, mockLogger, nil, noOpCaps)
+		proc.localStore = store
+
+		result := proc.processRequest(context.Background())
+
+		assert.Equal(t, PROCESS_REQUEST_PUSH_DISABLED, result.Outcome)
+		assert.True(t, store.Has("abcd"))
+	})
+}
+
 func Test_keyToPath(t *testing.T) {
 	t.Run("encodes key as hex", func(t *testing.T) {
 		proc := &requestProcessor{}
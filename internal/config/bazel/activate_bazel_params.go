@@ -2,6 +2,7 @@ package bazelconfig
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 
@@ -12,6 +13,11 @@ type CacheParams struct {
 	Enabled     bool
 	PushEnabled bool
 	Endpoint    string
+	// UseProxy routes Bazel's remote cache traffic through the local
+	// `bazel start-proxy` daemon instead of talking to Endpoint directly.
+	UseProxy bool
+	// ProxySocketPath overrides the proxy's default unix socket path.
+	ProxySocketPath string
 }
 
 type BESParams struct {
@@ -136,6 +142,17 @@ func (params ActivateBazelParams) cacheTemplateInventory(
 	logger.Infof("(i) Build Cache Endpoint URL: %s", cacheEndpointURL)
 	logger.Infof("(i) Push new cache entries: %t", params.Cache.PushEnabled)
 
+	if socketPath := params.CacheProxySocketPath(envs); socketPath != "" {
+		logger.Infof("(i) Remote cache calls go through the local Bazel proxy: %s", socketPath)
+
+		return CacheTemplateInventory{
+			Enabled:             true,
+			EndpointURLWithPort: "unix://" + socketPath,
+			IsPushEnabled:       params.Cache.PushEnabled,
+			UseProxy:            true,
+		}
+	}
+
 	return CacheTemplateInventory{
 		Enabled:             true,
 		EndpointURLWithPort: cacheEndpointURL,
@@ -143,6 +160,16 @@ func (params ActivateBazelParams) cacheTemplateInventory(
 	}
 }
 
+// CacheProxySocketPath returns the unix socket of the local Bazel proxy, or an
+// empty string when the remote cache is used directly.
+func (params ActivateBazelParams) CacheProxySocketPath(envs map[string]string) string {
+	if !params.Cache.Enabled || !params.Cache.UseProxy {
+		return ""
+	}
+
+	return ResolveProxySocketPath(params.Cache.ProxySocketPath, envs, os.TempDir())
+}
+
 func (params ActivateBazelParams) besTemplateInventory(
 	logger log.Logger,
 ) BESTemplateInventory {
@@ -220,6 +220,25 @@ func Test_Generate(t *testing.T) {
 			want:    expectedHelperCacheDisabled,
 			wantErr: "",
 		},
+		{
+			name: "Cache through the local proxy omits the token",
+			inventory: TemplateInventory{
+				Common: CommonTemplateInventory{
+					AuthToken:   "AuthTokenValue",
+					WorkspaceID: "WorkspaceIDValue",
+					AppSlug:     "AppSlugValue",
+					CIProvider:  "CIProviderValue",
+				},
+				Cache: CacheTemplateInventory{
+					Enabled:             true,
+					EndpointURLWithPort: "unix:///tmp/bazel-proxy.sock",
+					IsPushEnabled:       true,
+					UseProxy:            true,
+				},
+			},
+			want:    expectedProxyConfig,
+			wantErr: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,6 +265,16 @@ build --remote_header='x-app-id=AppSlugValue'
 build --remote_header='x-ci-provider=CIProviderValue'
 `
 
+const expectedProxyConfig = `build --remote_cache=unix:///tmp/bazel-proxy.sock
+build --remote_timeout=600s
+build --remote_header=x-flare-buildtool=bazel
+build --remote_header=x-flare-builduser=CIProviderValue
+build --remote_upload_local_results
+build --remote_header='x-org-id=WorkspaceIDValue'
+build --remote_header='x-app-id=AppSlugValue'
+build --remote_header='x-ci-provider=CIProviderValue'
+`
+
 const expectedBasicConfigJWT = `build --remote_cache=grpcs://cache.services.bitrise.io:443
 build --remote_timeout=600s
 build --remote_header=authorization="Bearer some-jwt-token"
@@ -32,6 +32,9 @@ type CacheTemplateInventory struct {
 	Enabled             bool
 	EndpointURLWithPort string
 	IsPushEnabled       bool
+	// UseProxy is set when EndpointURLWithPort is the local proxy's unix
+	// socket; the proxy authenticates itself, so no token is written.
+	UseProxy bool
 }
 
 type BESTemplateInventory struct {
@@ -0,0 +1,28 @@
+package bazelconfig
+
+import (
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+)
+
+// EnvProxySocketPath overrides the default Bazel proxy socket location when set.
+const EnvProxySocketPath = "BITRISE_BAZEL_PROXY_SOCKET_PATH"
+
+const proxyPidFileName = "proxy.pid"
+
+// ResolveProxySocketPath returns the Bazel proxy unix socket path:
+// explicit override → BITRISE_BAZEL_PROXY_SOCKET_PATH env var → <temp-dir>/bazel-proxy.sock.
+func ResolveProxySocketPath(override string, envs map[string]string, tempDir string) string {
+	if override != "" {
+		return override
+	}
+	if env := envs[EnvProxySocketPath]; env != "" {
+		return env
+	}
+
+	return paths.FromHome("").BazelProxySocketPath(tempDir)
+}
+
+// ProxyPidFilePath returns the pid file the running Bazel proxy holds, next to the sidecar.
+func ProxyPidFilePath(home string) string {
+	return paths.FromHome(home).BitriseCacheFile(bazelToolName, proxyPidFileName)
+}
@@ -25,6 +25,12 @@ type Sidecar struct {
 	BESEnabled        bool   `json:"besEnabled,omitempty"`
 	RBEEnabled        bool   `json:"rbeEnabled,omitempty"`
 	TimestampsEnabled bool   `json:"timestampsEnabled,omitempty"`
+
+	// CacheEndpoint is the --cache-endpoint flag value; empty means the default endpoint.
+	CacheEndpoint string `json:"cacheEndpoint,omitempty"`
+	// CacheProxySocketPath is set when the bazelrc points the remote cache at
+	// the local `bazel start-proxy` daemon listening on this socket.
+	CacheProxySocketPath string `json:"cacheProxySocketPath,omitempty"`
 }
 
 const (
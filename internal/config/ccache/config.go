@@ -38,6 +38,8 @@ type Params struct {
 	PushEnabled           bool
 	IPCSocketPathOverride string
 	BaseDirOverride       string
+	LocalCacheDir         string
+	LocalCacheMaxBytes    int64
 }
 
 type Config struct {
@@ -51,6 +53,14 @@ type Config struct {
 	Enabled            bool          `json:"enabled"`
 	DebugLogging       bool          `json:"debugLogging,omitempty"`
 	BuildCacheEndpoint string        `json:"buildCacheEndpoint,omitempty"`
+	// BuildCacheEndpoints is the ordered endpoint list the storage helper
+	// fails over and picks the fastest from. Only set when there is more than
+	// one; BuildCacheEndpoint is its first entry.
+	BuildCacheEndpoints []string `json:"buildCacheEndpoints,omitempty"`
+	// LocalCacheDir / LocalCacheMaxBytes configure the storage helper's on-disk
+	// L1 tier. The tier is disabled when LocalCacheMaxBytes is zero.
+	LocalCacheDir      string `json:"localCacheDir,omitempty"`
+	LocalCacheMaxBytes int64  `json:"localCacheMaxBytes,omitempty"`
 
 	// AuthConfig is populated at runtime from the multiplatform analytics
 	// config (single canonical source for auth credentials on disk). Not
@@ -85,6 +95,17 @@ func PathFor(osProxy utils.OsProxy, subpath string) string {
 	return filepath.Join(DirPath(osProxy), subpath)
 }
 
+// DefaultLocalCacheDir returns the storage helper's default on-disk L1 cache
+// dir (~/.bitrise/cache/ccache/local-blobs), falling back next to DirPath when
+// home cannot be resolved.
+func DefaultLocalCacheDir(osProxy utils.OsProxy) string {
+	if home, err := osProxy.UserHomeDir(); err == nil {
+		return paths.FromHome(home).LocalBlobCacheDir(ccacheToolName)
+	}
+
+	return PathFor(osProxy, "local-blobs")
+}
+
 // ConfigFile returns the absolute path of the ccache config.json.
 func ConfigFile(osProxy utils.OsProxy) string {
 	return PathFor(osProxy, ccacheConfigFile)
@@ -121,20 +142,35 @@ func NewConfig(envs map[string]string, osProxy utils.OsProxy, params Params) (Co
 
 	ipcEndpoint := ResolveIPCSocketPath(params.IPCSocketPathOverride, envs, osProxy)
 
-	buildCacheEndpoint := common.SelectCacheEndpointURL(params.BuildCacheEndpoint, envs)
+	endpoints := common.SelectCacheEndpointURLs(params.BuildCacheEndpoint, envs)
+	buildCacheEndpoint := endpoints[0]
+	if len(endpoints) == 1 {
+		endpoints = nil
+	}
 	idleTimeout, _ := time.ParseDuration(defaultIdleTimeout)
 
+	localCacheDir := ""
+	if params.LocalCacheMaxBytes > 0 {
+		localCacheDir = params.LocalCacheDir
+		if localCacheDir == "" {
+			localCacheDir = DefaultLocalCacheDir(osProxy)
+		}
+	}
+
 	return Config{
-		AuthConfig:         authConfig,
-		ConfigVersion:      toolconfig.CcacheConfigVersion,
-		WrittenAt:          time.Now().UTC(),
-		IPCEndpoint:        ipcEndpoint,
-		LogFile:            defaultLogFile,
-		ErrLogFile:         defaultErrLogFile,
-		IdleTimeout:        idleTimeout,
-		PushEnabled:        params.PushEnabled,
-		Enabled:            true,
-		BuildCacheEndpoint: buildCacheEndpoint,
+		AuthConfig:          authConfig,
+		ConfigVersion:       toolconfig.CcacheConfigVersion,
+		WrittenAt:           time.Now().UTC(),
+		IPCEndpoint:         ipcEndpoint,
+		LogFile:             defaultLogFile,
+		ErrLogFile:          defaultErrLogFile,
+		IdleTimeout:         idleTimeout,
+		PushEnabled:         params.PushEnabled,
+		Enabled:             true,
+		BuildCacheEndpoint:  buildCacheEndpoint,
+		BuildCacheEndpoints: endpoints,
+		LocalCacheDir:       localCacheDir,
+		LocalCacheMaxBytes:  params.LocalCacheMaxBytes,
 	}, nil
 }
 
@@ -0,0 +1,35 @@
+//go:build unit
+
+package ccache_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	utilsMocks "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils/mocks"
+)
+
+func TestNewConfig_Endpoints(t *testing.T) {
+	envs := map[string]string{
+		common.EnvAuthToken:      "auth-token",
+		common.EnvWorkspaceID:    "workspace-id",
+		common.EnvCacheEndpoints: "grpc://primary:6666, grpc://secondary:6666",
+	}
+	osProxy := &utilsMocks.OsProxyMock{TempDirFunc: func() string { return "my-temp-dir" }}
+
+	config, err := ccacheconfig.NewConfig(envs, osProxy, ccacheconfig.DefaultParams())
+	require.NoError(t, err)
+	assert.Equal(t, "grpc://primary:6666", config.BuildCacheEndpoint)
+	assert.Equal(t, []string{"grpc://primary:6666", "grpc://secondary:6666"}, config.BuildCacheEndpoints)
+
+	params := ccacheconfig.DefaultParams()
+	params.BuildCacheEndpoint = "grpc://override:6666"
+	config, err = ccacheconfig.NewConfig(envs, osProxy, params)
+	require.NoError(t, err)
+	assert.Equal(t, "grpc://override:6666", config.BuildCacheEndpoint)
+	assert.Empty(t, config.BuildCacheEndpoints)
+}
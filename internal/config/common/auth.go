@@ -16,6 +16,9 @@ const (
 	EnvWorkspaceID = "BITRISE_BUILD_CACHE_WORKSPACE_ID" //nolint:gosec // env-var key, not a credential
 	EnvJWT         = "BITRISEIO_BITRISE_SERVICES_ACCESS_TOKEN"
 	EnvUsername    = "BITRISE_BUILD_CACHE_USERNAME"
+	// EnvTokenScope ("read" or "read-write") makes the credential helpers serve
+	// a short-lived token of that scope minted from the stored login.
+	EnvTokenScope = "BITRISE_BUILD_CACHE_TOKEN_SCOPE" //nolint:gosec // env-var key, not a credential
 )
 
 var (
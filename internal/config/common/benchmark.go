@@ -148,7 +148,12 @@ func BenchmarkPhaseFilePath(buildTool string) (string, error) {
 		return "", fmt.Errorf("get home directory: %w", err)
 	}
 
-	return filepath.Join(homeDir, ".local", "state", "xcelerate", "benchmark", "benchmark-phase-"+buildTool+".json"), nil
+	return BenchmarkPhaseFileIn(homeDir, buildTool), nil
+}
+
+// BenchmarkPhaseFileIn returns the path to the benchmark phase file for a build tool under home.
+func BenchmarkPhaseFileIn(home, buildTool string) string {
+	return filepath.Join(home, ".local", "state", "xcelerate", "benchmark", "benchmark-phase-"+buildTool+".json")
 }
 
 // WriteBenchmarkPhaseFile writes the benchmark phase to a JSON file for the given build tool.
@@ -42,6 +42,8 @@ const (
 	CIProviderGitHubActions = "github-actions"
 	// CIProviderGitLabCI ...
 	CIProviderGitLabCI = "gitlab-ci"
+	// CIProviderBuildkite ...
+	CIProviderBuildkite = "buildkite"
 
 	RedactorSeed = "BitriseBuildCacheRedactor"
 )
@@ -65,6 +67,10 @@ func DetectCIProvider(envs map[string]string) string {
 		// https://docs.gitlab.com/ci/variables/predefined_variables/
 		return CIProviderGitLabCI
 	}
+	if envs["BUILDKITE"] == "true" {
+		// https://buildkite.com/docs/pipelines/configure/environment-variables
+		return CIProviderBuildkite
+	}
 	if envs["BITRISE_IO"] != "" && envs["BITRISE_BUILD_SLUG"] != "" {
 		// https://devcenter.bitrise.io/en/references/available-environment-variables.html
 		// Build Hub sets BITRISE_IO but not BITRISE_BUILD_SLUG
@@ -75,16 +81,12 @@ func DetectCIProvider(envs map[string]string) string {
 }
 
 func detectExternalIDs(provider string, envs map[string]string) (string, string, string) {
-	switch provider {
-	case CIProviderCircleCI:
-		return envs["CIRCLE_PROJECT_REPONAME"], envs["CIRCLE_WORKFLOW_ID"], envs["CIRCLE_JOB"]
-	case CIProviderGitHubActions:
-		return envs["GITHUB_REPOSITORY"], envs["GITHUB_RUN_ID"], envs["GITHUB_JOB"]
-	case CIProviderGitLabCI:
-		return envs["CI_PROJECT_PATH"], envs["CI_PIPELINE_ID"], envs["CI_JOB_NAME"]
-	default:
+	keys, ok := ciProviderEnvKeys[provider]
+	if !ok {
 		return "", "", ""
 	}
+
+	return LookupEnv(envs, keys.App), LookupEnv(envs, keys.Build), LookupEnv(envs, keys.Workflow)
 }
 
 // HostMetadata contains metadata about the local environment. Only used for Bazel to
@@ -232,7 +234,7 @@ func generateGitMetadata(logger log.Logger, commandFunc CommandFunc, envs map[st
 	gitMetadata.CommitHash = strings.TrimSpace(commitHash)
 
 	// Branch
-	branch := envs["BITRISE_GIT_BRANCH"]
+	branch := LookupEnv(envs, CIProviderEnvKeys(DetectCIProvider(envs)).Branch)
 	if branch == "" {
 		branch, err = commandFunc("git", "branch", "--show-current")
 		if err != nil {
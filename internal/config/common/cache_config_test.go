@@ -133,6 +133,27 @@ func TestNewCacheConfigMetadata(t *testing.T) {
 				ExternalWorkflowName: "compile",
 			},
 		},
+		{
+			name: "Buildkite",
+			envs: map[string]string{
+				"BUILDKITE":               "true",
+				"BUILDKITE_PIPELINE_SLUG": "my-pipeline",
+				"BUILDKITE_BUILD_ID":      "build-123",
+				"BUILDKITE_LABEL":         ":hammer: Build",
+				"BUILDKITE_BRANCH":        "main",
+			},
+			commandFunc: func(_ string, _ ...string) (string, error) {
+				return "", nil
+			},
+			want: CacheConfigMetadata{
+				CIProvider:           CIProviderBuildkite,
+				CLIVersion:           GetCLIVersion(logger),
+				GitMetadata:          GitMetadata{Branch: "main"},
+				ExternalAppID:        "my-pipeline",
+				ExternalBuildID:      "build-123",
+				ExternalWorkflowName: ":hammer: Build",
+			},
+		},
 		{
 			name: "OS",
 			envs: map[string]string{
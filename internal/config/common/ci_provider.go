@@ -0,0 +1,87 @@
+package common
+
+import "strings"
+
+// CIEnvKeys lists the env vars holding a build's identifiers on a CI provider,
+// in order of preference.
+type CIEnvKeys struct {
+	App      []string
+	Branch   []string
+	Commit   []string
+	Build    []string
+	Workflow []string
+}
+
+//nolint:gochecknoglobals
+var bitriseEnvKeys = CIEnvKeys{
+	App:      []string{"BITRISE_APP_SLUG"},
+	Branch:   []string{"BITRISE_GIT_BRANCH"},
+	Commit:   []string{"BITRISE_GIT_COMMIT", "GIT_CLONE_COMMIT_HASH"},
+	Build:    []string{"BITRISE_BUILD_SLUG"},
+	Workflow: []string{"BITRISE_TRIGGERED_WORKFLOW_ID"},
+}
+
+//nolint:gochecknoglobals
+var ciProviderEnvKeys = map[string]CIEnvKeys{
+	CIProviderCircleCI: {
+		// https://circleci.com/docs/variables/#built-in-environment-variables
+		App:      []string{"CIRCLE_PROJECT_REPONAME"},
+		Branch:   []string{"CIRCLE_BRANCH"},
+		Commit:   []string{"CIRCLE_SHA1"},
+		Build:    []string{"CIRCLE_WORKFLOW_ID"},
+		Workflow: []string{"CIRCLE_JOB"},
+	},
+	CIProviderGitHubActions: {
+		// https://docs.github.com/en/actions/learn-github-actions/variables#default-environment-variables
+		App:      []string{"GITHUB_REPOSITORY"},
+		Branch:   []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME"},
+		Commit:   []string{"GITHUB_SHA"},
+		Build:    []string{"GITHUB_RUN_ID"},
+		Workflow: []string{"GITHUB_JOB"},
+	},
+	CIProviderGitLabCI: {
+		// https://docs.gitlab.com/ci/variables/predefined_variables/
+		App:      []string{"CI_PROJECT_PATH"},
+		Branch:   []string{"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_REF_NAME"},
+		Commit:   []string{"CI_COMMIT_SHA"},
+		Build:    []string{"CI_PIPELINE_ID"},
+		Workflow: []string{"CI_JOB_NAME"},
+	},
+	CIProviderBuildkite: {
+		// https://buildkite.com/docs/pipelines/configure/environment-variables
+		App:      []string{"BUILDKITE_PIPELINE_SLUG"},
+		Branch:   []string{"BUILDKITE_BRANCH"},
+		Commit:   []string{"BUILDKITE_COMMIT"},
+		Build:    []string{"BUILDKITE_BUILD_ID"},
+		Workflow: []string{"BUILDKITE_LABEL"},
+	},
+}
+
+// CIProviderEnvKeys returns the identifier env vars of provider followed by
+// the Bitrise ones, so Build Hub builds still pick up the Bitrise values.
+// Unknown providers (and local runs) use the Bitrise env vars only.
+func CIProviderEnvKeys(provider string) CIEnvKeys {
+	p, ok := ciProviderEnvKeys[provider]
+	if !ok {
+		return bitriseEnvKeys
+	}
+
+	return CIEnvKeys{
+		App:      append(append([]string{}, p.App...), bitriseEnvKeys.App...),
+		Branch:   append(append([]string{}, p.Branch...), bitriseEnvKeys.Branch...),
+		Commit:   append(append([]string{}, p.Commit...), bitriseEnvKeys.Commit...),
+		Build:    append(append([]string{}, p.Build...), bitriseEnvKeys.Build...),
+		Workflow: append(append([]string{}, p.Workflow...), bitriseEnvKeys.Workflow...),
+	}
+}
+
+// LookupEnv returns the first non-empty value of keys in envs.
+func LookupEnv(envs map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(envs[key]); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
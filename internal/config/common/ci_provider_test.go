@@ -0,0 +1,23 @@
+//go:build unit
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIProviderEnvKeys(t *testing.T) {
+	assert.Equal(t, []string{"BITRISE_GIT_BRANCH"}, CIProviderEnvKeys("").Branch)
+	assert.Equal(t, []string{"BITRISE_APP_SLUG"}, CIProviderEnvKeys(CIProviderBitrise).App)
+	assert.Equal(t, []string{"BUILDKITE_BRANCH", "BITRISE_GIT_BRANCH"}, CIProviderEnvKeys(CIProviderBuildkite).Branch)
+	assert.Equal(t, []string{"GITHUB_RUN_ID", "BITRISE_BUILD_SLUG"}, CIProviderEnvKeys(CIProviderGitHubActions).Build)
+}
+
+func TestLookupEnv(t *testing.T) {
+	envs := map[string]string{"A": " ", "B": "b", "C": "c"}
+
+	assert.Equal(t, "b", LookupEnv(envs, []string{"A", "B", "C"}))
+	assert.Empty(t, LookupEnv(envs, []string{"X"}))
+}
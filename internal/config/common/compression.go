@@ -0,0 +1,14 @@
+package common
+
+import "strings"
+
+// EnvCompression selects the compression applied to uploaded cache blobs.
+// Only "zstd" is supported; anything else uploads raw bytes. Uploads are only
+// compressed when the cache endpoint also advertises zstd support. Downloads
+// are always decompressed transparently, whatever the setting.
+const EnvCompression = "BITRISE_BUILD_CACHE_COMPRESSION"
+
+// CompressionEnabled reports whether uploads should be zstd-compressed.
+func CompressionEnabled(envs map[string]string) bool {
+	return strings.EqualFold(strings.TrimSpace(envs[EnvCompression]), "zstd")
+}
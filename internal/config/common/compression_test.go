@@ -0,0 +1,14 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionEnabled(t *testing.T) {
+	assert.False(t, CompressionEnabled(map[string]string{}))
+	assert.False(t, CompressionEnabled(map[string]string{EnvCompression: "gzip"}))
+	assert.True(t, CompressionEnabled(map[string]string{EnvCompression: "zstd"}))
+	assert.True(t, CompressionEnabled(map[string]string{EnvCompression: " ZSTD "}))
+}
@@ -2,11 +2,18 @@ package common
 
 import (
 	"slices"
+	"strings"
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
 )
 
-const datacenterEnvKey = "BITRISE_DEN_VM_DATACENTER"
+const (
+	datacenterEnvKey = "BITRISE_DEN_VM_DATACENTER"
+	// EnvCacheEndpoints is an ordered, comma separated list of build cache
+	// endpoints. The client picks the fastest healthy one and fails over to
+	// the others.
+	EnvCacheEndpoints = "BITRISE_BUILD_CACHE_ENDPOINTS"
+)
 
 //nolint:gochecknoglobals
 var (
@@ -29,6 +36,25 @@ func SelectCacheEndpointURL(endpointURL string, envs map[string]string) string {
 	return consts.BitriseAccelerate
 }
 
+// SelectCacheEndpointURLs - if endpointURL provided use only that,
+// otherwise the endpoints listed in BITRISE_BUILD_CACHE_ENDPOINTS,
+// falling back to SelectCacheEndpointURL
+func SelectCacheEndpointURLs(endpointURL string, envs map[string]string) []string {
+	if endpointURL == "" {
+		var urls []string
+		for _, u := range strings.Split(envs[EnvCacheEndpoints], ",") {
+			if u = strings.TrimSpace(u); u != "" && !slices.Contains(urls, u) {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) > 0 {
+			return urls
+		}
+	}
+
+	return []string{SelectCacheEndpointURL(endpointURL, envs)}
+}
+
 // SelectRBEEndpointURL - if endpointURL provided use that,
 // otherwise select the RBE endpoint from environment
 func SelectRBEEndpointURL(endpointURL string, envs map[string]string) string {
@@ -127,3 +127,42 @@ func TestCacheAuthConfig_TokenInGradleFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectCacheEndpointURLs(t *testing.T) {
+	tests := []struct {
+		name        string
+		endpointURL string
+		envs        map[string]string
+		want        []string
+	}{
+		{
+			name: "default",
+			envs: map[string]string{},
+			want: []string{"grpcs://bitrise-accelerate.services.bitrise.io"},
+		},
+		{
+			name: "single endpoint env",
+			envs: map[string]string{"BITRISE_BUILD_CACHE_ENDPOINT": "grpcs://a.example.com"},
+			want: []string{"grpcs://a.example.com"},
+		},
+		{
+			name: "endpoint list env takes precedence over single endpoint env",
+			envs: map[string]string{
+				"BITRISE_BUILD_CACHE_ENDPOINT":  "grpcs://a.example.com",
+				"BITRISE_BUILD_CACHE_ENDPOINTS": " grpcs://b.example.com, ,grpcs://c.example.com,grpcs://b.example.com",
+			},
+			want: []string{"grpcs://b.example.com", "grpcs://c.example.com"},
+		},
+		{
+			name:        "explicit endpoint wins",
+			endpointURL: "grpc://localhost:6666",
+			envs:        map[string]string{"BITRISE_BUILD_CACHE_ENDPOINTS": "grpcs://b.example.com"},
+			want:        []string{"grpc://localhost:6666"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SelectCacheEndpointURLs(tt.endpointURL, tt.envs))
+		})
+	}
+}
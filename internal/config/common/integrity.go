@@ -0,0 +1,18 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EnvDeleteCorrupted enables deleting cache entries that fail digest
+// verification on download. Corrupted entries are always treated as misses and
+// quarantined for the session; deleting them lets the next build replace them.
+const EnvDeleteCorrupted = "BITRISE_BUILD_CACHE_DELETE_CORRUPTED"
+
+// DeleteCorruptedEnabled reports whether corrupted cache entries should be deleted.
+func DeleteCorruptedEnabled(envs map[string]string) bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(envs[EnvDeleteCorrupted]))
+
+	return err == nil && enabled
+}
@@ -0,0 +1,14 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteCorruptedEnabled(t *testing.T) {
+	assert.False(t, DeleteCorruptedEnabled(map[string]string{}))
+	assert.False(t, DeleteCorruptedEnabled(map[string]string{EnvDeleteCorrupted: "nope"}))
+	assert.True(t, DeleteCorruptedEnabled(map[string]string{EnvDeleteCorrupted: "true"}))
+	assert.True(t, DeleteCorruptedEnabled(map[string]string{EnvDeleteCorrupted: " 1 "}))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/envexport"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
 )
 
 const (
@@ -19,6 +20,8 @@ const (
 	errFmtCacheConfigCreation      = "couldn't create cache configuration: %w"
 	errFmtTestDistroConfigCreation = "couldn't create test distribution configuration: %w"
 	errFmtInvalidValidationLevel   = "invalid validation level: '%s'"
+	errFmtInvalidCacheConnector    = "invalid cache connector: '%s', valid options: plugin, local-http"
+	errFmtInvalidLocalHTTPPort     = "invalid local HTTP cache port: %d"
 )
 
 type CacheParams struct {
@@ -27,11 +30,24 @@ type CacheParams struct {
 	PushEnabled     bool
 	ValidationLevel string
 	Endpoint        string
+	// Connector selects how Gradle reaches the remote cache: the Bitrise
+	// Gradle plugin (default) or Gradle's built-in HttpBuildCache pointed at
+	// the CLI's local HTTP connector.
+	Connector string
+	// LocalHTTPPort is the loopback port of the local HTTP connector.
+	LocalHTTPPort int
 }
 
 type AnalyticsParams struct {
 	Enabled        bool
 	JustDependency bool
+	// TaskReport injects a plugin-free task listener into the init script that
+	// records every task's outcome to an NDJSON report uploaded by
+	// `gradle upload-task-report`.
+	TaskReport bool
+	// TaskReportDir overrides where the reports are written; defaults to
+	// paths.GradleTaskReportsDir.
+	TaskReportDir string
 }
 
 type TestDistroParams struct {
@@ -56,6 +72,8 @@ func DefaultActivateGradleParams() ActivateGradleParams {
 			JustDependency:  false,
 			PushEnabled:     false,
 			ValidationLevel: string(CacheValidationLevelWarning),
+			Connector:       string(CacheConnectorPlugin),
+			LocalHTTPPort:   DefaultLocalHTTPCachePort,
 		},
 		Analytics: AnalyticsParams{
 			Enabled:        true,
@@ -176,6 +194,27 @@ func (params ActivateGradleParams) cacheTemplateInventory(
 		return CacheTemplateInventory{}, errors.New(errFmtInvalidCacheLevel)
 	}
 
+	switch CacheConnector(params.Cache.Connector) {
+	case "", CacheConnectorPlugin:
+	case CacheConnectorLocalHTTP:
+		if params.Cache.LocalHTTPPort <= 0 || params.Cache.LocalHTTPPort > 65535 {
+			return CacheTemplateInventory{}, fmt.Errorf(errFmtInvalidLocalHTTPPort, params.Cache.LocalHTTPPort)
+		}
+
+		localHTTPURL := LocalHTTPCacheURL(params.Cache.LocalHTTPPort)
+		logger.Infof("(i) Cache calls go through the local HTTP connector: %s", localHTTPURL)
+
+		return CacheTemplateInventory{
+			Usage:               UsageLevelEnabled,
+			EndpointURLWithPort: cacheEndpointURL,
+			IsPushEnabled:       params.Cache.PushEnabled,
+			ValidationLevel:     params.Cache.ValidationLevel,
+			LocalHTTPURL:        localHTTPURL,
+		}, nil
+	default:
+		return CacheTemplateInventory{}, fmt.Errorf(errFmtInvalidCacheConnector, params.Cache.Connector)
+	}
+
 	return CacheTemplateInventory{
 		Usage:               UsageLevelEnabled,
 		Version:             consts.GradleRemoteBuildCachePluginDepVersion,
@@ -187,6 +226,30 @@ func (params ActivateGradleParams) cacheTemplateInventory(
 
 func (params ActivateGradleParams) analyticsTemplateInventory(
 	logger log.Logger,
+) AnalyticsTemplateInventory {
+	inventory := params.analyticsPluginTemplateInventory(logger)
+
+	if params.Analytics.TaskReport {
+		inventory.TaskReportDir = params.Analytics.TaskReportDir
+		if inventory.TaskReportDir == "" {
+			p, err := paths.Default()
+			if err != nil {
+				logger.Warnf("Task analytics disabled: %s", err)
+
+				return inventory
+			}
+
+			inventory.TaskReportDir = p.GradleTaskReportsDir()
+		}
+
+		logger.Infof("(i) Task analytics reports: %s", inventory.TaskReportDir)
+	}
+
+	return inventory
+}
+
+func (params ActivateGradleParams) analyticsPluginTemplateInventory(
+	logger log.Logger,
 ) AnalyticsTemplateInventory {
 	if !params.Analytics.JustDependency && !params.Analytics.Enabled {
 		logger.Infof("(i) Analytics plugin usage: %+v", UsageLevelNone)
@@ -194,6 +194,70 @@ func Test_activateGradleParams(t *testing.T) {
 			},
 			wantErr: fmt.Errorf(errFmtCacheConfigCreation, errors.New(errFmtInvalidCacheLevel)).Error(),
 		},
+		{
+			name: "activate cache with local HTTP connector",
+			params: ActivateGradleParams{
+				Cache: CacheParams{
+					Enabled:         true,
+					ValidationLevel: string(CacheValidationLevelWarning),
+					Endpoint:        "EndpointValue",
+					PushEnabled:     true,
+					Connector:       string(CacheConnectorLocalHTTP),
+					LocalHTTPPort:   7072,
+				},
+				Analytics: AnalyticsParams{
+					Enabled: false,
+				},
+				TestDistro: TestDistroParams{
+					Enabled: false,
+				},
+			},
+			envVars: map[string]string{
+				"BITRISE_BUILD_CACHE_AUTH_TOKEN":   "AuthTokenValue",
+				"BITRISE_BUILD_CACHE_WORKSPACE_ID": "WorkspaceIDValue",
+			},
+			want: TemplateInventory{
+				Common: PluginCommonTemplateInventory{
+					AuthToken: "WorkspaceIDValue:AuthTokenValue",
+					Version:   consts.GradleCommonPluginDepVersion,
+					CLIPath:   "bitrise-build-cache",
+				},
+				Cache: CacheTemplateInventory{
+					Usage:               UsageLevelEnabled,
+					EndpointURLWithPort: "EndpointValue",
+					IsPushEnabled:       true,
+					ValidationLevel:     string(CacheValidationLevelWarning),
+					LocalHTTPURL:        "http://127.0.0.1:7072/cache/",
+				},
+				Analytics: AnalyticsTemplateInventory{
+					Usage: UsageLevelNone,
+				},
+				TestDistro: TestDistroTemplateInventory{
+					Usage: UsageLevelNone,
+				},
+			},
+		},
+		{
+			name: "given unknown cache connector cache activation throws error",
+			params: ActivateGradleParams{
+				Cache: CacheParams{
+					Enabled:         true,
+					ValidationLevel: string(CacheValidationLevelWarning),
+					Connector:       "grpc",
+				},
+				Analytics: AnalyticsParams{
+					Enabled: false,
+				},
+				TestDistro: TestDistroParams{
+					Enabled: false,
+				},
+			},
+			envVars: map[string]string{
+				"BITRISE_BUILD_CACHE_AUTH_TOKEN":   "AuthTokenValue",
+				"BITRISE_BUILD_CACHE_WORKSPACE_ID": "WorkspaceIDValue",
+			},
+			wantErr: fmt.Errorf(errFmtCacheConfigCreation, fmt.Errorf(errFmtInvalidCacheConnector, "grpc")).Error(),
+		},
 		{
 			name: "activate analytics",
 			params: ActivateGradleParams{
@@ -234,6 +298,43 @@ func Test_activateGradleParams(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "task report without the analytics plugin",
+			params: ActivateGradleParams{
+				Cache: CacheParams{
+					Enabled: false,
+				},
+				Analytics: AnalyticsParams{
+					Enabled:       false,
+					TaskReport:    true,
+					TaskReportDir: "TaskReportDirValue",
+				},
+				TestDistro: TestDistroParams{
+					Enabled: false,
+				},
+			},
+			envVars: map[string]string{
+				"BITRISE_BUILD_CACHE_AUTH_TOKEN":   "AuthTokenValue",
+				"BITRISE_BUILD_CACHE_WORKSPACE_ID": "WorkspaceIDValue",
+			},
+			want: TemplateInventory{
+				Common: PluginCommonTemplateInventory{
+					AuthToken: "WorkspaceIDValue:AuthTokenValue",
+					Version:   consts.GradleCommonPluginDepVersion,
+					CLIPath:   "bitrise-build-cache",
+				},
+				Cache: CacheTemplateInventory{
+					Usage: UsageLevelNone,
+				},
+				Analytics: AnalyticsTemplateInventory{
+					Usage:         UsageLevelNone,
+					TaskReportDir: "TaskReportDirValue",
+				},
+				TestDistro: TestDistroTemplateInventory{
+					Usage: UsageLevelNone,
+				},
+			},
+		},
 		{
 			name: "activate test distro",
 			params: ActivateGradleParams{
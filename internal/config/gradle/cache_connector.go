@@ -0,0 +1,33 @@
+package gradleconfig
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+)
+
+type CacheConnector string
+
+//nolint:gochecknoglobals
+var (
+	// CacheConnectorPlugin talks to the remote cache through the Bitrise Gradle plugin.
+	CacheConnectorPlugin CacheConnector = "plugin"
+	// CacheConnectorLocalHTTP points Gradle's built-in HttpBuildCache at the
+	// `gradle start-cache-connector` server, no third-party plugin involved.
+	CacheConnectorLocalHTTP CacheConnector = "local-http"
+)
+
+// DefaultLocalHTTPCachePort is the loopback port the local HTTP cache connector listens on.
+const DefaultLocalHTTPCachePort = 7071
+
+const connectorPidFileName = "connector.pid"
+
+// LocalHTTPCacheURL is the HttpBuildCache URL of the local connector.
+func LocalHTTPCacheURL(port int) string {
+	return fmt.Sprintf("http://127.0.0.1:%d/cache/", port)
+}
+
+// ConnectorPidFilePath returns the pid file the running connector holds, next to the sidecar.
+func ConnectorPidFilePath(home string) string {
+	return paths.FromHome(home).BitriseCacheFile(gradleToolName, connectorPidFileName)
+}
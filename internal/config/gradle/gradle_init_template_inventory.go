@@ -15,6 +15,18 @@ type CacheTemplateInventory struct {
 	EndpointURLWithPort string
 	IsPushEnabled       bool
 	ValidationLevel     string
+	// LocalHTTPURL is set when Gradle's built-in HttpBuildCache talks to the
+	// local connector instead of the Bitrise cache plugin.
+	LocalHTTPURL string
+}
+
+// UsesPlugin reports whether the Bitrise cache plugin is on the init script classpath.
+func (inventory CacheTemplateInventory) UsesPlugin() bool {
+	if inventory.LocalHTTPURL != "" {
+		return false
+	}
+
+	return inventory.Usage == UsageLevelDependency || inventory.Usage == UsageLevelEnabled
 }
 
 type AnalyticsTemplateInventory struct {
@@ -24,6 +36,9 @@ type AnalyticsTemplateInventory struct {
 	Port         int
 	HTTPEndpoint string
 	GRPCEndpoint string
+	// TaskReportDir is set when the init script records per-task outcomes
+	// into NDJSON reports under this directory.
+	TaskReportDir string
 }
 
 type TestDistroTemplateInventory struct {
@@ -64,7 +79,7 @@ func (inventory TemplateInventory) HasDependencies() bool {
 	if inventory.Analytics.Usage == UsageLevelDependency || inventory.Analytics.Usage == UsageLevelEnabled {
 		return true
 	}
-	if inventory.Cache.Usage == UsageLevelDependency || inventory.Cache.Usage == UsageLevelEnabled {
+	if inventory.Cache.UsesPlugin() {
 		return true
 	}
 	if inventory.TestDistro.Usage == UsageLevelDependency || inventory.TestDistro.Usage == UsageLevelEnabled {
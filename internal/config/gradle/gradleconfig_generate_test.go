@@ -1,6 +1,7 @@
 package gradleconfig
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -143,6 +144,34 @@ func Test_GenerateInitGradle(t *testing.T) {
 			want:    expectedAllPluginsLocal,
 			wantErr: "",
 		},
+		{
+			name: "Local HTTP connector uses Gradle's HttpBuildCache without the cache plugin",
+			inventory: TemplateInventory{
+				Common: PluginCommonTemplateInventory{
+					AuthToken:  "AuthTokenValue",
+					Debug:      true,
+					AppSlug:    "AppSlugValue",
+					CIProvider: "",
+					Version:    "CommonVersionValue",
+					CLIPath:    "CLIPathValue",
+				},
+				Cache: CacheTemplateInventory{
+					Usage:               UsageLevelEnabled,
+					EndpointURLWithPort: "CacheEndpointURLValue",
+					IsPushEnabled:       true,
+					ValidationLevel:     "ValidationLevelValue",
+					LocalHTTPURL:        "http://127.0.0.1:7071/cache/",
+				},
+				Analytics: AnalyticsTemplateInventory{
+					Usage: UsageLevelNone,
+				},
+				TestDistro: TestDistroTemplateInventory{
+					Usage: UsageLevelNone,
+				},
+			},
+			want:    expectedLocalHTTPConnector,
+			wantErr: "",
+		},
 	}
 	for _, tt := range tests { //nolint:varnamelen
 		t.Run(tt.name, func(t *testing.T) {
@@ -157,6 +186,37 @@ func Test_GenerateInitGradle(t *testing.T) {
 	}
 }
 
+func Test_GenerateInitGradle_taskReport(t *testing.T) {
+	inventory := TemplateInventory{
+		Common: PluginCommonTemplateInventory{
+			AuthToken:  "AuthTokenValue",
+			CIProvider: "CIProviderValue",
+			Version:    "CommonVersionValue",
+			CLIPath:    "CLIPathValue",
+		},
+		Cache: CacheTemplateInventory{
+			Usage: UsageLevelNone,
+		},
+		Analytics: AnalyticsTemplateInventory{
+			Usage:         UsageLevelNone,
+			TaskReportDir: "TaskReportDirValue",
+		},
+		TestDistro: TestDistroTemplateInventory{
+			Usage: UsageLevelNone,
+		},
+	}
+
+	got, err := inventory.GenerateInitGradle(GradleTemplateProxy())
+	require.NoError(t, err)
+
+	// The listener needs no plugin dependency, the initscript block stays empty.
+	assert.True(t, strings.HasPrefix(got, expectedNoPluginActivated+"\n\n// Task analytics without a plugin"), got)
+	assert.Contains(t, got, `parameters.reportDir.set("TaskReportDirValue")`)
+	assert.Contains(t, got, `parameters.cliPath.set("CLIPathValue")`)
+	assert.Contains(t, got, `"gradle", "upload-task-report", "--file"`)
+	assert.True(t, strings.HasSuffix(got, "apply<BitriseTaskReportPlugin>()"), got)
+}
+
 const expectedImports = `import io.bitrise.gradle.analytics.AnalyticsPluginExtension
 import io.bitrise.gradle.cache.BitriseBuildCache
 import io.bitrise.gradle.cache.BitriseBuildCacheServiceFactory`
@@ -339,3 +399,20 @@ rootProject {
 
     apply<io.bitrise.gradle.rbe.RBEPlugin>()
 }`
+
+const expectedLocalHTTPConnector = "initscript {\n" +
+	expectedRepositories + "\n}" +
+	`
+settingsEvaluated {
+    buildCache {
+        local {
+            isEnabled = false
+        }
+
+        remote(HttpBuildCache::class.java) {
+            url = uri("http://127.0.0.1:7071/cache/")
+            isPush = true
+            isAllowInsecureProtocol = true
+        }
+    }
+}`
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/bitrise-io/go-utils/v2/log"
@@ -33,20 +34,27 @@ type Exporter interface {
 // Params bundles the inputs needed to write the Gradle mirrors init script.
 type Params struct {
 	GradleHome  string       // absolute path to the Gradle home (e.g. ~/.gradle expanded)
-	Mirrors     []RepoMirror // mirrors to install
+	Mirrors     []RepoMirror // mirrors to install, custom ones (see WithCustomMirrors) included
 	Datacenter  string       // datacenter (e.g. "AMS1") used to build the mirror URL
 	Enabled     bool         // when false, Activate is a no-op
 	ProjectRoot string       // project root scanned for scope-gap warnings; empty disables scanning
 	Exporter    Exporter     // when non-nil, exports BITRISE_MAVENCENTRAL_PROXY_URL_<ID> per activated mirror
 	CLIVersion  string       // CLI version stamped into the init script logs; empty resolves via configcommon.GetCLIVersion
+	// LocalProxyPort points the mirrors at the local dependency proxy on this
+	// port instead of the datacenter mirrors. Enabled and Datacenter are not
+	// required then, so it also works on dev machines and non-Bitrise CI.
+	LocalProxyPort int
 }
 
 type templateEntry struct {
+	Name                    string
+	Custom                  bool
 	ID                      string
 	GradleMatch             string
 	MirrorURL               string
 	ApplyToPluginManagement bool
 	UseAsRobolectricRepo    bool
+	AllowInsecureProtocol   bool
 }
 
 type templateData struct {
@@ -56,44 +64,56 @@ type templateData struct {
 
 // Activate writes the Gradle init script when mirror activation is enabled and
 // at least one mirror is selected. Otherwise it logs the reason and returns
-// nil. The init script is placed at <GradleHome>/init.d/<InitFileName>.
+// nil. The init script is placed at <GradleHome>/init.d/<InitFileName>. With
+// LocalProxyPort set the mirrors point at the local dependency proxy and only
+// a mirror selection is required. Custom mirrors carry their own URL, so they
+// are installed even when the Bitrise datacenter mirrors cannot be used.
 func Activate(logger log.Logger, osProxy utils.OsProxy, params Params) error {
-	if !params.Enabled {
-		logger.Infof("%s is not set to \"true\", skipping Gradle mirror activation", EnabledEnvKey)
-
-		return nil
-	}
-
-	if params.Datacenter == "" {
-		logger.Infof("%s is not set, skipping Gradle mirror activation (e.g. local dev environment)", DatacenterEnvKey)
-
-		return nil
-	}
-
 	if len(params.Mirrors) == 0 {
 		logger.Infof("No mirrors selected, skipping Gradle mirror activation")
 
 		return nil
 	}
 
-	region := DatacenterToRegion(params.Datacenter)
-	if !IsSupportedRegion(region) {
-		logger.Infof("Datacenter %q (region %q) has no Bitrise mirror deployment, skipping Gradle mirror activation", params.Datacenter, region)
-
-		return nil
+	selected := params.Mirrors
+	var urlFor func(m RepoMirror) string
+	if params.LocalProxyPort > 0 {
+		urlFor = func(m RepoMirror) string {
+			return fmt.Sprintf(LocalProxyURLPattern, params.LocalProxyPort, m.URLSegment)
+		}
+	} else {
+		region, ok := datacenterRegion(logger, params)
+		if !ok {
+			selected = customMirrors(params.Mirrors)
+			if len(selected) == 0 {
+				return nil
+			}
+			logger.Infof("Activating only the custom mirrors")
+		}
+		urlFor = func(m RepoMirror) string {
+			url := m.MirrorURL
+			if url == "" {
+				url = fmt.Sprintf(URLPattern, m.URLSegment)
+			}
+			logger.Debugf("Mirror %s: region=%s, URL=%s", m.FlagName, region, url)
+
+			return url
+		}
 	}
 
-	entries := make([]templateEntry, 0, len(params.Mirrors))
-	for _, m := range params.Mirrors {
-		url := fmt.Sprintf(URLPattern, m.URLSegment)
+	entries := make([]templateEntry, 0, len(selected))
+	for _, m := range selected {
+		mirrorURL := urlFor(m)
 		entries = append(entries, templateEntry{
+			Name:                    m.FlagName,
+			Custom:                  m.MirrorURL != "",
 			ID:                      m.TemplateID,
 			GradleMatch:             m.GradleMatch,
-			MirrorURL:               url,
+			MirrorURL:               mirrorURL,
 			ApplyToPluginManagement: m.ApplyToPluginManagement,
 			UseAsRobolectricRepo:    m.UseAsRobolectricRepo,
+			AllowInsecureProtocol:   strings.HasPrefix(mirrorURL, "http://"),
 		})
-		logger.Debugf("Mirror %s: region=%s, URL=%s", m.FlagName, region, url)
 	}
 
 	tmpl, err := template.New("gradle-mirrors").Parse(initTemplate)
@@ -123,7 +143,11 @@ func Activate(logger log.Logger, osProxy utils.OsProxy, params Params) error {
 		return fmt.Errorf("write %s: %w", initFilePath, err)
 	}
 
-	logger.Infof("Gradle mirrors activated")
+	if params.LocalProxyPort > 0 {
+		logger.Infof("Gradle mirrors activated, pointing at the local dependency proxy on port %d", params.LocalProxyPort)
+	} else {
+		logger.Infof("Gradle mirrors activated")
+	}
 
 	if params.Exporter != nil {
 		for _, e := range entries {
@@ -139,3 +163,40 @@ func Activate(logger log.Logger, osProxy utils.OsProxy, params Params) error {
 
 	return nil
 }
+
+// datacenterRegion checks that the datacenter mirrors can be used and returns
+// the mirror region. It logs the reason and returns false otherwise.
+func datacenterRegion(logger log.Logger, params Params) (string, bool) {
+	if !params.Enabled {
+		logger.Infof("%s is not set to \"true\", skipping the Bitrise Gradle mirrors", EnabledEnvKey)
+
+		return "", false
+	}
+
+	if params.Datacenter == "" {
+		logger.Infof("%s is not set, skipping the Bitrise Gradle mirrors (e.g. local dev environment)", DatacenterEnvKey)
+
+		return "", false
+	}
+
+	region := DatacenterToRegion(params.Datacenter)
+	if !IsSupportedRegion(region) {
+		logger.Infof("Datacenter %q (region %q) has no Bitrise mirror deployment, skipping the Bitrise Gradle mirrors", params.Datacenter, region)
+
+		return "", false
+	}
+
+	return region, true
+}
+
+// customMirrors returns the mirrors with their own MirrorURL.
+func customMirrors(mirrors []RepoMirror) []RepoMirror {
+	custom := make([]RepoMirror, 0, len(mirrors))
+	for _, m := range mirrors {
+		if m.MirrorURL != "" {
+			custom = append(custom, m)
+		}
+	}
+
+	return custom
+}
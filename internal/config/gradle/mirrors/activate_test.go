@@ -134,6 +134,25 @@ func TestActivate(t *testing.T) {
 				"mirrorBaseUrl",
 			},
 		},
+		{
+			name:          "local proxy without datacenter",
+			params:        mirrors.Params{Enabled: false, Datacenter: "", Mirrors: allMirrors, LocalProxyPort: 7072},
+			expectCreated: true,
+			expectContains: []string{
+				"http://127.0.0.1:7072/maven/central",
+				"http://127.0.0.1:7072/maven/gradle-plugins",
+				"setAllowInsecureProtocol(true)",
+				`log("prepending pluginManagement mirror http://127.0.0.1:7072/maven/apache-central")`,
+			},
+			expectNotContain: []string{
+				"repository-manager.services.bitrise.io",
+			},
+		},
+		{
+			name:          "local proxy but no mirrors selected",
+			params:        mirrors.Params{Mirrors: nil, LocalProxyPort: 7072},
+			expectCreated: false,
+		},
 		{
 			name:          "ORD1 google only",
 			params:        mirrors.Params{Enabled: true, Datacenter: "ORD1", Mirrors: googleOnly},
@@ -145,6 +164,7 @@ func TestActivate(t *testing.T) {
 			expectNotContain: []string{
 				"https://repository-manager.services.bitrise.io:8090/maven/central",
 				"mirrorBaseUrl",
+				"setAllowInsecureProtocol",
 				// google is not flagged ApplyToPluginManagement, so no PM mirror prepend log
 				"prepending pluginManagement mirror",
 			},
@@ -0,0 +1,249 @@
+package mirrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+// CustomMirror is a user-defined mirror, declared in the mirrors config file
+// or with `activate gradle-mirrors --mirror`. Repositories matching any of
+// URLPrefixes or RepoNames are redirected to URL.
+type CustomMirror struct {
+	// Name identifies the mirror, e.g. "jitpack". It is also the URL segment
+	// the local dependency proxy serves it under.
+	Name string `json:"name"`
+	// URL is the repository requests are redirected to. With the local
+	// dependency proxy it is the upstream the proxy fetches from instead.
+	URL string `json:"url"`
+	// URLPrefixes matches repositories whose URL starts with any of these.
+	URLPrefixes []string `json:"urlPrefixes,omitempty"`
+	// RepoNames matches repositories with any of these names.
+	RepoNames []string `json:"repoNames,omitempty"`
+	// PluginManagement also applies the mirror to pluginManagement.repositories.
+	PluginManagement bool `json:"pluginManagement,omitempty"`
+}
+
+// CustomMirrorsConfig is the mirrors config file, by default
+// ~/.bitrise/gradle-mirrors.json.
+type CustomMirrorsConfig struct {
+	Mirrors []CustomMirror `json:"mirrors"`
+}
+
+var (
+	// ErrInvalidCustomMirror is wrapped by every validation error of a custom mirror.
+	ErrInvalidCustomMirror = errors.New("invalid custom mirror")
+
+	customMirrorNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`) //nolint:gochecknoglobals
+)
+
+const (
+	matchURLPrefix = "url:"
+	matchRepoName  = "name:"
+)
+
+// LoadCustomMirrors reads the mirrors in configFile, when it exists, followed
+// by the mirrors declared by specs (see ParseMirrorSpec). An empty configFile
+// skips the file.
+func LoadCustomMirrors(osProxy utils.OsProxy, configFile string, specs []string) ([]CustomMirror, error) {
+	var custom []CustomMirror
+
+	if configFile != "" {
+		content, exists, err := osProxy.ReadFileIfExists(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("read mirrors config (%s): %w", configFile, err)
+		}
+		if exists {
+			var cfg CustomMirrorsConfig
+			if err := json.Unmarshal([]byte(content), &cfg); err != nil {
+				return nil, fmt.Errorf("parse mirrors config (%s): %w", configFile, err)
+			}
+			custom = append(custom, cfg.Mirrors...)
+		}
+	}
+
+	for _, spec := range specs {
+		m, err := ParseMirrorSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		custom = append(custom, m)
+	}
+
+	return custom, nil
+}
+
+// ParseMirrorSpec parses a `--mirror` value:
+//
+//	<name>=<url>,match=url:<prefix>,match=name:<repo name>[,plugins]
+//
+// match can be repeated; plugins also applies the mirror to
+// pluginManagement.repositories. The result is not validated.
+func ParseMirrorSpec(spec string) (CustomMirror, error) {
+	fields := strings.Split(spec, ",")
+
+	name, mirrorURL, ok := strings.Cut(fields[0], "=")
+	if !ok {
+		return CustomMirror{}, fmt.Errorf("%w: %q: expected <name>=<url> first", ErrInvalidCustomMirror, spec)
+	}
+	m := CustomMirror{Name: strings.TrimSpace(name), URL: strings.TrimSpace(mirrorURL)}
+
+	for _, field := range fields[1:] {
+		field = strings.TrimSpace(field)
+		if field == "plugins" {
+			m.PluginManagement = true
+
+			continue
+		}
+
+		match, ok := strings.CutPrefix(field, "match=")
+		if !ok {
+			return CustomMirror{}, fmt.Errorf("%w: %q: unknown option %q", ErrInvalidCustomMirror, spec, field)
+		}
+
+		switch {
+		case strings.HasPrefix(match, matchURLPrefix):
+			m.URLPrefixes = append(m.URLPrefixes, strings.TrimPrefix(match, matchURLPrefix))
+		case strings.HasPrefix(match, matchRepoName):
+			m.RepoNames = append(m.RepoNames, strings.TrimPrefix(match, matchRepoName))
+		default:
+			return CustomMirror{}, fmt.Errorf("%w: %q: match must start with %q or %q", ErrInvalidCustomMirror, spec, matchURLPrefix, matchRepoName)
+		}
+	}
+
+	return m, nil
+}
+
+// Validate checks that the mirror can be rendered into the init script and
+// served by the local dependency proxy.
+func (c CustomMirror) Validate() error {
+	if !customMirrorNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits and single dashes", ErrInvalidCustomMirror, c.Name)
+	}
+
+	if err := validateRepositoryURL(c.URL); err != nil {
+		return fmt.Errorf("%w %s: url: %w", ErrInvalidCustomMirror, c.Name, err)
+	}
+
+	if len(c.URLPrefixes) == 0 && len(c.RepoNames) == 0 {
+		return fmt.Errorf("%w %s: needs at least one URL prefix or repository name to match", ErrInvalidCustomMirror, c.Name)
+	}
+
+	for _, prefix := range c.URLPrefixes {
+		if err := validateRepositoryURL(prefix); err != nil {
+			return fmt.Errorf("%w %s: url prefix: %w", ErrInvalidCustomMirror, c.Name, err)
+		}
+	}
+
+	for _, repoName := range c.RepoNames {
+		if repoName == "" || !isKotlinStringSafe(repoName) {
+			return fmt.Errorf("%w %s: repository name %q must be non-empty and free of quotes, backslashes, $ and control characters", ErrInvalidCustomMirror, c.Name, repoName)
+		}
+	}
+
+	return nil
+}
+
+// RepoMirror converts the mirror to the registry shape. It assumes Validate passed.
+func (c CustomMirror) RepoMirror() RepoMirror {
+	conditions := make([]string, 0, len(c.URLPrefixes)+len(c.RepoNames))
+	for _, prefix := range c.URLPrefixes {
+		conditions = append(conditions, fmt.Sprintf(`r.getUrl().toString().startsWith("%s")`, prefix))
+	}
+	for _, repoName := range c.RepoNames {
+		conditions = append(conditions, fmt.Sprintf(`r.getName().equals("%s")`, repoName))
+	}
+
+	return RepoMirror{
+		FlagName:                c.Name,
+		TemplateID:              customTemplateID(c.Name),
+		URLSegment:              c.Name,
+		GradleMatch:             strings.Join(conditions, " || "),
+		ApplyToPluginManagement: c.PluginManagement,
+		UpstreamURL:             strings.TrimSuffix(c.URL, "/"),
+		MirrorURL:               c.URL,
+	}
+}
+
+// WithCustomMirrors validates custom and appends them to mirrors. Custom
+// mirrors go last so they take precedence over the known mirrors matching
+// the same repository. Names and template IDs must be unique across both.
+func WithCustomMirrors(mirrors []RepoMirror, custom []CustomMirror) ([]RepoMirror, error) {
+	if len(custom) == 0 {
+		return mirrors, nil
+	}
+
+	taken := make(map[string]struct{}, len(KnownMirrors)*3+len(custom)*2) //nolint:mnd
+	for _, m := range append(append([]RepoMirror{}, KnownMirrors...), mirrors...) {
+		taken[m.FlagName] = struct{}{}
+		taken[m.URLSegment] = struct{}{}
+		taken[m.TemplateID] = struct{}{}
+	}
+
+	all := append(make([]RepoMirror, 0, len(mirrors)+len(custom)), mirrors...)
+	for _, c := range custom {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+
+		m := c.RepoMirror()
+		for _, id := range []string{m.FlagName, m.TemplateID} {
+			if _, ok := taken[id]; ok {
+				return nil, fmt.Errorf("%w %s: name is already used by another mirror", ErrInvalidCustomMirror, c.Name)
+			}
+			taken[id] = struct{}{}
+		}
+
+		all = append(all, m)
+	}
+
+	return all, nil
+}
+
+func validateRepositoryURL(raw string) error {
+	if !isKotlinStringSafe(raw) {
+		return fmt.Errorf("%q must be free of quotes, backslashes, $ and control characters", raw)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("parse %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must be an http or https URL", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%q must not have a query or fragment", raw)
+	}
+
+	return nil
+}
+
+// isKotlinStringSafe reports whether s can be placed in a Kotlin string
+// literal verbatim.
+func isKotlinStringSafe(s string) bool {
+	return !strings.ContainsFunc(s, func(r rune) bool {
+		return r == '"' || r == '\\' || r == '$' || unicode.IsControl(r)
+	})
+}
+
+// customTemplateID turns a mirror name such as "sonatype-snapshots" into a
+// Kotlin identifier suffix such as "CustomSonatypeSnapshots".
+func customTemplateID(name string) string {
+	var b strings.Builder
+	b.WriteString("Custom")
+	for _, part := range strings.Split(name, "-") {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return b.String()
+}
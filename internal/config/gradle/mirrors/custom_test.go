@@ -0,0 +1,170 @@
+//go:build unit
+
+package mirrors_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle/mirrors"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+func TestParseMirrorSpec(t *testing.T) {
+	got, err := mirrors.ParseMirrorSpec("jitpack=https://artifactory.example.com/jitpack,match=url:https://jitpack.io,match=name:JitPack,plugins")
+	require.NoError(t, err)
+	assert.Equal(t, mirrors.CustomMirror{
+		Name:             "jitpack",
+		URL:              "https://artifactory.example.com/jitpack",
+		URLPrefixes:      []string{"https://jitpack.io"},
+		RepoNames:        []string{"JitPack"},
+		PluginManagement: true,
+	}, got)
+
+	for _, spec := range []string{
+		"jitpack",
+		"jitpack=https://example.com,unknown",
+		"jitpack=https://example.com,match=host:jitpack.io",
+	} {
+		_, err := mirrors.ParseMirrorSpec(spec)
+		require.ErrorIs(t, err, mirrors.ErrInvalidCustomMirror, "spec=%q", spec)
+	}
+}
+
+func TestCustomMirror_Validate(t *testing.T) {
+	valid := mirrors.CustomMirror{Name: "jitpack", URL: "https://example.com/jitpack", URLPrefixes: []string{"https://jitpack.io"}}
+	require.NoError(t, valid.Validate())
+
+	tests := map[string]func(m *mirrors.CustomMirror){
+		"uppercase name":      func(m *mirrors.CustomMirror) { m.Name = "JitPack" },
+		"double dash in name": func(m *mirrors.CustomMirror) { m.Name = "jit--pack" },
+		"non-http url":        func(m *mirrors.CustomMirror) { m.URL = "file:///tmp/repo" },
+		"url without host":    func(m *mirrors.CustomMirror) { m.URL = "https:///repo" },
+		"url with query":      func(m *mirrors.CustomMirror) { m.URL = "https://example.com/repo?x=1" },
+		"quote in url":        func(m *mirrors.CustomMirror) { m.URL = `https://example.com/"repo` },
+		"template in prefix":  func(m *mirrors.CustomMirror) { m.URLPrefixes = []string{"https://example.com/${x}"} },
+		"no match":            func(m *mirrors.CustomMirror) { m.URLPrefixes = nil },
+		"empty repo name":     func(m *mirrors.CustomMirror) { m.RepoNames = []string{""} },
+		"newline in name":     func(m *mirrors.CustomMirror) { m.RepoNames = []string{"a\nb"} },
+	}
+
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := valid
+			mutate(&m)
+			require.ErrorIs(t, m.Validate(), mirrors.ErrInvalidCustomMirror)
+		})
+	}
+}
+
+func TestWithCustomMirrors(t *testing.T) {
+	custom := []mirrors.CustomMirror{{
+		Name:        "sonatype-snapshots",
+		URL:         "https://nexus.example.com/snapshots/",
+		URLPrefixes: []string{"https://oss.sonatype.org/content/repositories/snapshots"},
+		RepoNames:   []string{"Sonatype"},
+	}}
+
+	got, err := mirrors.WithCustomMirrors(mirrors.KnownMirrors, custom)
+	require.NoError(t, err)
+	require.Len(t, got, len(mirrors.KnownMirrors)+1)
+
+	m := got[len(got)-1]
+	assert.Equal(t, "CustomSonatypeSnapshots", m.TemplateID)
+	assert.Equal(t, "sonatype-snapshots", m.URLSegment)
+	assert.Equal(t, "https://nexus.example.com/snapshots", m.UpstreamURL)
+	assert.Equal(t, `r.getUrl().toString().startsWith("https://oss.sonatype.org/content/repositories/snapshots") || r.getName().equals("Sonatype")`, m.GradleMatch)
+
+	t.Run("rejects names of known mirrors", func(t *testing.T) {
+		_, err := mirrors.WithCustomMirrors(nil, []mirrors.CustomMirror{{Name: "central", URL: "https://example.com", RepoNames: []string{"x"}}})
+		require.ErrorIs(t, err, mirrors.ErrInvalidCustomMirror)
+	})
+
+	t.Run("rejects duplicates", func(t *testing.T) {
+		_, err := mirrors.WithCustomMirrors(nil, append(custom, custom...))
+		require.ErrorIs(t, err, mirrors.ErrInvalidCustomMirror)
+	})
+}
+
+func TestLoadCustomMirrors(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "gradle-mirrors.json")
+
+	got, err := mirrors.LoadCustomMirrors(utils.DefaultOsProxy{}, configFile, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	require.NoError(t, os.WriteFile(configFile, []byte(`{"mirrors": [{"name": "jitpack", "url": "https://example.com/jitpack", "repoNames": ["JitPack"]}]}`), 0o600))
+	got, err = mirrors.LoadCustomMirrors(utils.DefaultOsProxy{}, configFile, []string{"internal=https://artifactory.example.com/libs,match=url:https://repo.example.com"})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "jitpack", got[0].Name)
+	assert.Equal(t, "internal", got[1].Name)
+
+	require.NoError(t, os.WriteFile(configFile, []byte(`{`), 0o600))
+	_, err = mirrors.LoadCustomMirrors(utils.DefaultOsProxy{}, configFile, nil)
+	require.Error(t, err)
+}
+
+func TestActivate_CustomMirror(t *testing.T) {
+	custom, err := mirrors.WithCustomMirrors(nil, []mirrors.CustomMirror{{
+		Name:             "internal",
+		URL:              "http://artifactory.internal/libs",
+		URLPrefixes:      []string{"https://repo.example.com"},
+		PluginManagement: true,
+	}})
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	require.NoError(t, mirrors.Activate(log.NewLogger(), utils.DefaultOsProxy{}, mirrors.Params{
+		GradleHome: tmpDir,
+		Mirrors:    custom,
+		Datacenter: "AMS1",
+		Enabled:    true,
+	}))
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "init.d", mirrors.InitFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `Spec { r -> r.getUrl().toString().startsWith("https://repo.example.com") }`)
+	assert.Contains(t, string(content), `val mirrorUrl: String = "http://artifactory.internal/libs"`)
+	assert.Contains(t, string(content), `setName("BitriseMirrorCustomInternal")`)
+	assert.Contains(t, string(content), "setAllowInsecureProtocol(true)")
+
+	installed, exists, err := mirrors.ReadInstalledMirrors(utils.DefaultOsProxy{}, tmpDir)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, []mirrors.InstalledMirror{{Name: "internal", URL: "http://artifactory.internal/libs", Custom: true}}, installed)
+}
+
+func TestActivate_CustomMirrorWithoutDatacenter(t *testing.T) {
+	withCustom, err := mirrors.WithCustomMirrors(mirrors.KnownMirrors, []mirrors.CustomMirror{{
+		Name:        "internal",
+		URL:         "https://artifactory.internal/libs",
+		URLPrefixes: []string{"https://repo.example.com"},
+	}})
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	require.NoError(t, mirrors.Activate(log.NewLogger(), utils.DefaultOsProxy{}, mirrors.Params{
+		GradleHome: tmpDir,
+		Mirrors:    withCustom,
+	}))
+
+	installed, exists, err := mirrors.ReadInstalledMirrors(utils.DefaultOsProxy{}, tmpDir)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, []mirrors.InstalledMirror{{Name: "internal", URL: "https://artifactory.internal/libs", Custom: true}}, installed,
+		"only the custom mirror is installed without a datacenter")
+}
+
+func TestReadInstalledMirrors_NotInstalled(t *testing.T) {
+	installed, exists, err := mirrors.ReadInstalledMirrors(utils.DefaultOsProxy{}, t.TempDir())
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Empty(t, installed)
+}
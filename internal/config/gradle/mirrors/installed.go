@@ -0,0 +1,54 @@
+package mirrors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+)
+
+// installedMirrorMarker starts the comment lines the init script records its
+// mirrors in: `// mirror: <name> <url>[ custom]`.
+const installedMirrorMarker = "// mirror: "
+
+// InstalledMirror is a mirror recorded in an installed init script.
+type InstalledMirror struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Custom bool   `json:"custom,omitempty"`
+}
+
+// ReadInstalledMirrors returns the mirrors recorded in the init script under
+// gradleHome, and whether the script is installed. Scripts written before the
+// mirrors were recorded report no mirrors.
+func ReadInstalledMirrors(osProxy utils.OsProxy, gradleHome string) ([]InstalledMirror, bool, error) {
+	initScript := paths.GradleMirrorsInitScript(gradleHome)
+	content, exists, err := osProxy.ReadFileIfExists(initScript)
+	if err != nil {
+		return nil, false, fmt.Errorf("read mirror init script (%s): %w", initScript, err)
+	}
+	if !exists {
+		return nil, false, nil
+	}
+
+	var installed []InstalledMirror
+	for _, line := range strings.Split(content, "\n") {
+		record, ok := strings.CutPrefix(line, installedMirrorMarker)
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(record)
+		if len(fields) < 2 { //nolint:mnd
+			continue
+		}
+		installed = append(installed, InstalledMirror{
+			Name:   fields[0],
+			URL:    fields[1],
+			Custom: len(fields) > 2 && fields[2] == "custom", //nolint:mnd
+		})
+	}
+
+	return installed, true, nil
+}
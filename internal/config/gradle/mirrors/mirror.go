@@ -18,16 +18,18 @@ type RepoMirror struct {
 	GradleMatch             string // Kotlin predicate body (using `r` as the repo) that decides whether the repo should be mirrored
 	ApplyToPluginManagement bool   // also apply this mirror to pluginManagement.repositories
 	UseAsRobolectricRepo    bool   // also expose this mirror via the robolectric.dependency.repo.url system property on Test tasks
+	UpstreamURL             string // repository the local dependency proxy fetches from, e.g. "https://repo1.maven.org/maven2"
+	MirrorURL               string // fixed mirror URL of a custom mirror; empty uses URLPattern
 }
 
 // KnownMirrors is the registry of supported mirrors.
 // Order matters: entries are applied in the listed order, so URL-based predicates
 // (e.g. apache-central) must run before name-based ones that overwrite the URL.
 var KnownMirrors = []RepoMirror{ //nolint:gochecknoglobals
-	{FlagName: "mavencentral-apache", TemplateID: "ApacheCentral", URLSegment: "apache-central", GradleMatch: `r.getUrl().toString().trimEnd('/').equals("https://repo.maven.apache.org/maven2")`, ApplyToPluginManagement: true},
-	{FlagName: "mavencentral", TemplateID: "Central", URLSegment: "central", GradleMatch: `r.getName().equals(ArtifactRepositoryContainer.DEFAULT_MAVEN_CENTRAL_REPO_NAME) || r.getUrl().toString().trimEnd('/') in setOf("https://repo1.maven.org/maven2", "https://jcenter.bintray.com")`, UseAsRobolectricRepo: true},
-	{FlagName: "google", TemplateID: "Google", URLSegment: "google", GradleMatch: `r.getName().equals("Google")`},
-	{FlagName: "gradle-plugin-portal", TemplateID: "PluginPortal", URLSegment: "gradle-plugins", GradleMatch: `r.getUrl().toString().trimEnd('/').equals("https://plugins.gradle.org/m2")`, ApplyToPluginManagement: true},
+	{FlagName: "mavencentral-apache", TemplateID: "ApacheCentral", URLSegment: "apache-central", GradleMatch: `r.getUrl().toString().trimEnd('/').equals("https://repo.maven.apache.org/maven2")`, ApplyToPluginManagement: true, UpstreamURL: "https://repo.maven.apache.org/maven2"},
+	{FlagName: "mavencentral", TemplateID: "Central", URLSegment: "central", GradleMatch: `r.getName().equals(ArtifactRepositoryContainer.DEFAULT_MAVEN_CENTRAL_REPO_NAME) || r.getUrl().toString().trimEnd('/') in setOf("https://repo1.maven.org/maven2", "https://jcenter.bintray.com")`, UseAsRobolectricRepo: true, UpstreamURL: "https://repo1.maven.org/maven2"},
+	{FlagName: "google", TemplateID: "Google", URLSegment: "google", GradleMatch: `r.getName().equals("Google")`, UpstreamURL: "https://dl.google.com/dl/android/maven2"},
+	{FlagName: "gradle-plugin-portal", TemplateID: "PluginPortal", URLSegment: "gradle-plugins", GradleMatch: `r.getUrl().toString().trimEnd('/').equals("https://plugins.gradle.org/m2")`, ApplyToPluginManagement: true, UpstreamURL: "https://plugins.gradle.org/m2"},
 }
 
 //go:embed asset/gradle-mirrors.init.gradle.kts.gotemplate
@@ -45,6 +47,14 @@ const InitFileName = paths.GradleMirrorsInitScriptName
 // via /etc/hosts entries written by the preboot reconciler (see ACI-4611).
 const URLPattern = "https://repository-manager.services.bitrise.io:8090/maven/%s"
 
+// DefaultLocalProxyPort is the loopback port `gradle start-dependency-proxy` listens on.
+const DefaultLocalProxyPort = 7072
+
+// LocalProxyURLPattern is the format string for mirror URLs served by the
+// local dependency proxy: port, URL segment. The path matches
+// mavenproxy.RepositoryPath.
+const LocalProxyURLPattern = "http://127.0.0.1:%d/maven/%s"
+
 // FilterByFlagNames returns the subset of KnownMirrors whose FlagName matches
 // any of names. If names is empty, KnownMirrors is returned unchanged. Order
 // follows KnownMirrors.
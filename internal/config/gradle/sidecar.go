@@ -27,6 +27,15 @@ type Sidecar struct {
 	CachePushEnabled bool `json:"cachePushEnabled,omitempty"`
 	// AnalyticsEnabled mirrors the --analytics flag.
 	AnalyticsEnabled bool `json:"analyticsEnabled,omitempty"`
+	// TaskAnalytics mirrors the --task-analytics flag.
+	TaskAnalytics bool `json:"taskAnalytics,omitempty"`
+	// CacheConnector mirrors the --connector flag.
+	CacheConnector string `json:"cacheConnector,omitempty"`
+	// CacheLocalHTTPPort is the port `gradle start-cache-connector` listens on;
+	// only set with the local-http connector.
+	CacheLocalHTTPPort int `json:"cacheLocalHTTPPort,omitempty"`
+	// CacheEndpoint mirrors the --cache-endpoint flag, forwarded to by the connector.
+	CacheEndpoint string `json:"cacheEndpoint,omitempty"`
 }
 
 const (
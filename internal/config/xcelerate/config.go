@@ -38,6 +38,8 @@ type Params struct {
 	ProxySocketPathOverride     string
 	PushEnabled                 bool
 	XcodebuildTimestampsEnabled bool
+	LocalCacheDir               string
+	LocalCacheMaxBytes          int64
 }
 
 // Config is the xcelerate config saved to ~/.bitrise-xcelerate/config.json.
@@ -58,10 +60,18 @@ type Config struct {
 	BuildCacheSkipFlags    bool      `json:"buildCacheSkipFlags"`
 	DisablePrefixMapping   bool      `json:"disablePrefixMapping,omitempty"`
 	BuildCacheEndpoint     string    `json:"buildCacheEndpoint"`
-	PushEnabled            bool      `json:"pushEnabled"`
-	DebugLogging           bool      `json:"debugLogging,omitempty"`
-	Silent                 bool      `json:"silent,omitempty"`
-	XcodebuildTimestamps   bool      `json:"xcodebuildTimestamps,omitempty"`
+	// BuildCacheEndpoints is the ordered endpoint list the proxy fails over
+	// and picks the fastest from. Only set when there is more than one;
+	// BuildCacheEndpoint is its first entry.
+	BuildCacheEndpoints  []string `json:"buildCacheEndpoints,omitempty"`
+	PushEnabled          bool     `json:"pushEnabled"`
+	DebugLogging         bool     `json:"debugLogging,omitempty"`
+	Silent               bool     `json:"silent,omitempty"`
+	XcodebuildTimestamps bool     `json:"xcodebuildTimestamps,omitempty"`
+	// LocalCacheDir / LocalCacheMaxBytes configure the proxy's on-disk L1 tier.
+	// The tier is disabled when LocalCacheMaxBytes is zero.
+	LocalCacheDir      string `json:"localCacheDir,omitempty"`
+	LocalCacheMaxBytes int64  `json:"localCacheMaxBytes,omitempty"`
 	// AuthConfig is sourced from the multiplatform analytics config at runtime
 	// (single canonical source for auth credentials on disk). The JSON tag is
 	// preserved for read-side backwards compatibility with older xcelerate
@@ -109,6 +119,8 @@ func DefaultParams() Params {
 		ProxySocketPathOverride:     "",
 		PushEnabled:                 true,
 		XcodebuildTimestampsEnabled: false,
+		LocalCacheDir:               "",
+		LocalCacheMaxBytes:          0,
 	}
 }
 
@@ -179,10 +191,21 @@ func NewConfig(ctx context.Context,
 		logger.Infof("Using new proxy socket path: %s", proxySocketPath)
 	}
 
-	if params.BuildCacheEndpoint == "" {
-		params.BuildCacheEndpoint = common.SelectCacheEndpointURL("", envs)
+	endpoints := common.SelectCacheEndpointURLs(params.BuildCacheEndpoint, envs)
+	params.BuildCacheEndpoint = endpoints[0]
+	logger.Infof("Using Build Cache Endpoint: %s. You can always override this by supplying --cache-endpoint.", strings.Join(endpoints, ", "))
+	if len(endpoints) == 1 {
+		endpoints = nil
+	}
+
+	localCacheDir := ""
+	if params.LocalCacheMaxBytes > 0 {
+		localCacheDir = params.LocalCacheDir
+		if localCacheDir == "" {
+			localCacheDir = DefaultLocalCacheDir(osProxy)
+		}
+		logger.Infof("Using local cache dir: %s (limit: %d bytes)", localCacheDir, params.LocalCacheMaxBytes)
 	}
-	logger.Infof("Using Build Cache Endpoint: %s. You can always override this by supplying --cache-endpoint.", params.BuildCacheEndpoint)
 
 	if params.DebugLogging && params.Silent {
 		logger.Warnf("Both debug and silent logging specified, silent will take precedence.")
@@ -205,10 +228,13 @@ func NewConfig(ctx context.Context,
 		BuildCacheSkipFlags:    params.BuildCacheSkipFlags,
 		DisablePrefixMapping:   params.DisablePrefixMapping,
 		BuildCacheEndpoint:     params.BuildCacheEndpoint,
+		BuildCacheEndpoints:    endpoints,
 		PushEnabled:            params.PushEnabled,
 		DebugLogging:           params.DebugLogging,
 		Silent:                 params.Silent,
 		XcodebuildTimestamps:   params.XcodebuildTimestampsEnabled,
+		LocalCacheDir:          localCacheDir,
+		LocalCacheMaxBytes:     params.LocalCacheMaxBytes,
 		AuthConfig:             authConfig,
 		ExternalAppID:          metadata.ExternalAppID,
 		ExternalBuildID:        metadata.ExternalBuildID,
@@ -576,6 +576,48 @@ func TestConfig_NewConfig(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 
+	t.Run("When several build cache endpoints are listed in the env, saves all of them", func(t *testing.T) {
+		envs := map[string]string{
+			"BITRISE_BUILD_CACHE_AUTH_TOKEN":   "auth-token",
+			"BITRISE_BUILD_CACHE_WORKSPACE_ID": "workspace-id",
+			common.EnvCacheEndpoints:           "grpc://primary:6666, grpc://secondary:6666",
+		}
+
+		osProxyMock := &utilsMocks.OsProxyMock{
+			TempDirFunc: func() string {
+				return "my-temp-dir"
+			},
+		}
+
+		cmdMock := &utilsMocks.CommandMock{
+			CombinedOutputFunc: func() ([]byte, error) {
+				return []byte("something-else"), errors.New("something went wrong")
+			},
+		}
+
+		actual, err := xcelerate.NewConfig(context.Background(), mockLogger, xcelerate.Params{
+			BuildCacheEnabled: true,
+		}, envs, osProxyMock, func(_ context.Context, _ string, _ ...string) utils.Command {
+			return cmdMock
+		}, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "grpc://primary:6666", actual.BuildCacheEndpoint)
+		assert.Equal(t, []string{"grpc://primary:6666", "grpc://secondary:6666"}, actual.BuildCacheEndpoints)
+
+		// an explicit --cache-endpoint still wins over the list
+		actual, err = xcelerate.NewConfig(context.Background(), mockLogger, xcelerate.Params{
+			BuildCacheEnabled:  true,
+			BuildCacheEndpoint: "grpc://override:6666",
+		}, envs, osProxyMock, func(_ context.Context, _ string, _ ...string) utils.Command {
+			return cmdMock
+		}, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "grpc://override:6666", actual.BuildCacheEndpoint)
+		assert.Empty(t, actual.BuildCacheEndpoints)
+	})
+
 	t.Run("benchmark provider is called on CI and baseline disables cache", func(t *testing.T) {
 		envs := map[string]string{
 			"BITRISE_BUILD_CACHE_AUTH_TOKEN":      "auth-token",
@@ -41,6 +41,17 @@ func ConfigFile(osProxy utils.OsProxy) string {
 	return PathFor(osProxy, xcelerateConfigFileName)
 }
 
+// DefaultLocalCacheDir returns the proxy's default on-disk L1 cache dir
+// (~/.bitrise/cache/xcelerate/local-blobs), falling back next to DirPath when
+// home cannot be resolved.
+func DefaultLocalCacheDir(osProxy utils.OsProxy) string {
+	if home, err := osProxy.UserHomeDir(); err == nil {
+		return paths.FromHome(home).LocalBlobCacheDir("xcelerate")
+	}
+
+	return PathFor(osProxy, "local-blobs")
+}
+
 // EnvProxySocketPath overrides the default xcelerate proxy socket location when set.
 const EnvProxySocketPath = "BITRISE_XCELERATE_PROXY_SOCKET_PATH"
 
@@ -0,0 +1,51 @@
+package credhelper
+
+import (
+	"context"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// AuthSource serves the credentials of a long-running proxy through Resolve,
+// once per request: a stored login is refreshed before it expires and
+// configcommon.EnvTokenScope swaps it for a scoped token, the same as the
+// credential helpers serve. It implements kv.AuthSource.
+type AuthSource struct {
+	ctx       context.Context //nolint:containedctx // Get is called per RPC without a ctx
+	envs      map[string]string
+	refresher Refresher
+	logger    log.Logger
+}
+
+func NewAuthSource(ctx context.Context, envs map[string]string, refresher Refresher, logger log.Logger) *AuthSource {
+	return &AuthSource{ctx: ctx, envs: envs, refresher: refresher, logger: logger}
+}
+
+// Get returns the current credentials. When they cannot be resolved, e.g. a
+// scoped token cannot be minted, it returns none rather than falling back to
+// broader access than configured.
+func (s *AuthSource) Get() configcommon.CacheAuthConfig {
+	scope, err := ScopeFrom(s.envs, "")
+	if err != nil {
+		s.warnf("Resolve build cache token scope: %s", err)
+
+		return configcommon.CacheAuthConfig{}
+	}
+
+	cfg, err := Resolve(s.ctx, s.envs, s.refresher, scope)
+	if err != nil {
+		s.warnf("Resolve build cache credentials: %s", err)
+
+		return configcommon.CacheAuthConfig{}
+	}
+
+	return cfg
+}
+
+func (s *AuthSource) warnf(format string, args ...any) {
+	if s.logger != nil {
+		s.logger.Warnf(format, args...)
+	}
+}
@@ -0,0 +1,113 @@
+// Package credhelper serves the resolved Bitrise Build Cache credentials to
+// build tools at the moment they need them, in the formats those tools
+// already understand: the Git and Docker credential helper protocols and
+// shell env exports. The Bazel helper protocol lives in bazelcredhelper.
+//
+// Serving credentials on demand means no long-lived token has to be written
+// into .bazelrc, gradle.properties or init scripts.
+package credhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
+)
+
+// Refresher renews the stored OAuth login and mints scoped tokens from it.
+// oauth.Config implements it.
+type Refresher interface {
+	EnsureFresh(ctx context.Context) (oauth.Credentials, error)
+	ScopedToken(ctx context.Context, workspaceID string, scope oauth.Scope) (oauth.ScopedToken, error)
+}
+
+// ErrScopeNeedsStoredLogin is returned by Resolve when a scope is requested
+// but the credential does not come from the stored login it is minted from.
+var ErrScopeNeedsStoredLogin = errors.New("a scoped token can only be minted from a stored login")
+
+// Resolve resolves the credentials via configcommon.ResolveAuthConfig. When
+// they come from the credential store (keychain or file) — where a browser
+// login keeps a PAT that expires — the login is refreshed first so the caller
+// never gets an expired token. A failed refresh is not fatal: the stored
+// credential is returned as-is, which is what a manual `auth set` token needs.
+//
+// A non-empty scope swaps the stored credential for a short-lived token
+// limited to that scope, minted from the OAuth login. Failing to mint one is
+// an error — falling back would hand out more access than asked for. For the
+// same reason env and CI credentials, which cannot be narrowed, fail with
+// ErrScopeNeedsStoredLogin when a scope is set.
+func Resolve(ctx context.Context, envs map[string]string, refresher Refresher, scope oauth.Scope) (configcommon.CacheAuthConfig, error) {
+	cfg, source, err := configcommon.ResolveAuthConfig(envs)
+	if err != nil {
+		return configcommon.CacheAuthConfig{}, fmt.Errorf("resolve auth config: %w", err)
+	}
+
+	stored := source == configcommon.AuthSourceKeychain || source == configcommon.AuthSourceFile
+	if scope != "" && (!stored || refresher == nil) {
+		return configcommon.CacheAuthConfig{}, fmt.Errorf("%w: unset %s or %s to serve a %s token", ErrScopeNeedsStoredLogin, configcommon.EnvAuthToken, configcommon.EnvJWT, scope)
+	}
+	if refresher == nil || !stored {
+		return cfg, nil
+	}
+
+	if scope != "" {
+		token, err := refresher.ScopedToken(ctx, cfg.WorkspaceID, scope)
+		if err != nil {
+			return configcommon.CacheAuthConfig{}, fmt.Errorf("mint %s token: %w", scope, err)
+		}
+
+		return configcommon.CacheAuthConfig{AuthToken: token.Token, WorkspaceID: token.WorkspaceID}, nil
+	}
+
+	if _, err := refresher.EnsureFresh(ctx); err != nil {
+		return cfg, nil //nolint:nilerr // not an OAuth login, or refresh failed: serve the stored token
+	}
+
+	refreshed, _, err := configcommon.ResolveAuthConfig(envs)
+	if err != nil {
+		return cfg, nil //nolint:nilerr // the pre-refresh credential is still usable
+	}
+
+	return refreshed, nil
+}
+
+// StoredLoginResolver returns a resolver for the helpers: Resolve with the
+// stored OAuth login refreshed quietly — the helpers' stdout belongs to the
+// protocol, so nothing may be logged. scope falls back to
+// configcommon.EnvTokenScope; both empty serves the stored credential.
+func StoredLoginResolver(ctx context.Context, envs map[string]string, scope string) func() (configcommon.CacheAuthConfig, error) {
+	return func() (configcommon.CacheAuthConfig, error) {
+		parsed, err := ScopeFrom(envs, scope)
+		if err != nil {
+			return configcommon.CacheAuthConfig{}, err
+		}
+
+		return Resolve(ctx, envs, oauth.NewConfigFromEnv(envs), parsed)
+	}
+}
+
+// ScopeFrom parses scope, or configcommon.EnvTokenScope when scope is empty.
+// Both empty is the empty Scope: no scoped token.
+func ScopeFrom(envs map[string]string, scope string) (oauth.Scope, error) {
+	if scope == "" {
+		scope = envs[configcommon.EnvTokenScope]
+	}
+	if scope == "" {
+		return "", nil
+	}
+
+	return oauth.ParseScope(scope) //nolint:wrapcheck // already user-facing
+}
+
+// basicAuthUsername is the user of the username/password pair handed to Git
+// and Docker: the workspace, matching the `<workspace>:<token>` pair of
+// TokenInGradleFormat.
+func basicAuthUsername(cfg configcommon.CacheAuthConfig) string {
+	if cfg.WorkspaceID == "" {
+		return "bitrise"
+	}
+
+	return cfg.WorkspaceID
+}
@@ -0,0 +1,182 @@
+//go:build unit
+
+package credhelper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
+)
+
+type countingRefresher struct{ calls int }
+
+func (r *countingRefresher) EnsureFresh(context.Context) (oauth.Credentials, error) {
+	r.calls++
+
+	return oauth.Credentials{}, nil
+}
+
+func (r *countingRefresher) ScopedToken(context.Context, string, oauth.Scope) (oauth.ScopedToken, error) {
+	r.calls++
+
+	return oauth.ScopedToken{}, nil
+}
+
+func patConfig() (configcommon.CacheAuthConfig, error) {
+	return configcommon.CacheAuthConfig{AuthToken: "tok'en", WorkspaceID: "ws-1"}, nil
+}
+
+func TestResolve_EnvCredentialsAreNotRefreshed(t *testing.T) {
+	refresher := &countingRefresher{}
+	cfg, err := credhelper.Resolve(context.Background(), map[string]string{
+		configcommon.EnvAuthToken:   "token",
+		configcommon.EnvWorkspaceID: "ws-1",
+	}, refresher, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, configcommon.CacheAuthConfig{AuthToken: "token", WorkspaceID: "ws-1"}, cfg)
+	assert.Zero(t, refresher.calls)
+}
+
+func TestResolve_ScopedEnvCredentialsAreRefused(t *testing.T) {
+	refresher := &countingRefresher{}
+	cfg, err := credhelper.Resolve(context.Background(), map[string]string{
+		configcommon.EnvAuthToken:   "token",
+		configcommon.EnvWorkspaceID: "ws-1",
+	}, refresher, oauth.ScopeRead)
+
+	require.ErrorIs(t, err, credhelper.ErrScopeNeedsStoredLogin)
+	assert.Empty(t, cfg.AuthToken, "the full env token must not be served")
+	assert.Zero(t, refresher.calls)
+}
+
+func TestAuthSource_Get(t *testing.T) {
+	envs := map[string]string{
+		configcommon.EnvAuthToken:   "token",
+		configcommon.EnvWorkspaceID: "ws-1",
+	}
+	refresher := &countingRefresher{}
+
+	source := credhelper.NewAuthSource(context.Background(), envs, refresher, nil)
+	assert.Equal(t, configcommon.CacheAuthConfig{AuthToken: "token", WorkspaceID: "ws-1"}, source.Get())
+
+	// a proxy asked for a scope must not fall back to the full env token
+	envs[configcommon.EnvTokenScope] = string(oauth.ScopeRead)
+	assert.Empty(t, source.Get())
+
+	envs[configcommon.EnvTokenScope] = "admin"
+	assert.Empty(t, source.Get())
+	assert.Zero(t, refresher.calls)
+}
+
+func TestScopeFrom(t *testing.T) {
+	envs := map[string]string{configcommon.EnvTokenScope: "read"}
+
+	scope, err := credhelper.ScopeFrom(envs, "")
+	require.NoError(t, err)
+	assert.Equal(t, oauth.ScopeRead, scope)
+
+	scope, err = credhelper.ScopeFrom(envs, "read-write")
+	require.NoError(t, err)
+	assert.Equal(t, oauth.ScopeReadWrite, scope, "the flag wins over the env var")
+
+	scope, err = credhelper.ScopeFrom(map[string]string{}, "")
+	require.NoError(t, err)
+	assert.Empty(t, scope)
+
+	_, err = credhelper.ScopeFrom(map[string]string{}, "admin")
+	require.ErrorIs(t, err, oauth.ErrInvalidScope)
+}
+
+var allowedHosts = []string{"cache.example.com", "registry.example.com"} //nolint:gochecknoglobals
+
+func TestRunGit(t *testing.T) {
+	out := &bytes.Buffer{}
+	in := strings.NewReader("protocol=https\nhost=cache.example.com\n\n")
+
+	require.NoError(t, credhelper.RunGit(credhelper.GitActionGet, in, out, allowedHosts, patConfig))
+	assert.Equal(t, "username=ws-1\npassword=tok'en\n", out.String())
+
+	failing := func() (configcommon.CacheAuthConfig, error) {
+		return configcommon.CacheAuthConfig{}, errors.New("must not resolve")
+	}
+
+	t.Run("store and erase do not resolve", func(t *testing.T) {
+		for _, action := range []string{credhelper.GitActionStore, credhelper.GitActionErase, "unknown"} {
+			out := &bytes.Buffer{}
+			require.NoError(t, credhelper.RunGit(action, strings.NewReader("host=cache.example.com\n"), out, allowedHosts, failing))
+			assert.Empty(t, out.String())
+		}
+	})
+
+	t.Run("foreign host gets no credentials", func(t *testing.T) {
+		for _, request := range []string{"protocol=https\nhost=github.com\n\n", "protocol=https\n\n"} {
+			out := &bytes.Buffer{}
+			require.NoError(t, credhelper.RunGit(credhelper.GitActionGet, strings.NewReader(request), out, allowedHosts, failing))
+			assert.Empty(t, out.String())
+		}
+	})
+
+	t.Run("malformed request", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.Error(t, credhelper.RunGit(credhelper.GitActionGet, strings.NewReader("garbage\n"), out, allowedHosts, patConfig))
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestRunDocker(t *testing.T) {
+	out := &bytes.Buffer{}
+	require.NoError(t, credhelper.RunDocker(credhelper.DockerActionGet, strings.NewReader("https://registry.example.com\n"), out, allowedHosts, patConfig))
+
+	var creds credhelper.DockerCredentials
+	require.NoError(t, json.Unmarshal(out.Bytes(), &creds))
+	assert.Equal(t, credhelper.DockerCredentials{ServerURL: "https://registry.example.com", Username: "ws-1", Secret: "tok'en"}, creds)
+
+	out.Reset()
+	require.NoError(t, credhelper.RunDocker(credhelper.DockerActionList, strings.NewReader(""), out, allowedHosts, patConfig))
+	assert.JSONEq(t, "{}", out.String())
+
+	require.NoError(t, credhelper.RunDocker(credhelper.DockerActionStore, strings.NewReader(`{"ServerURL":"x"}`), &bytes.Buffer{}, allowedHosts, patConfig))
+	require.ErrorIs(t, credhelper.RunDocker("version", strings.NewReader(""), &bytes.Buffer{}, allowedHosts, patConfig), credhelper.ErrUnknownAction)
+
+	t.Run("foreign registry gets not found", func(t *testing.T) {
+		failing := func() (configcommon.CacheAuthConfig, error) {
+			return configcommon.CacheAuthConfig{}, errors.New("must not resolve")
+		}
+		out := &bytes.Buffer{}
+		err := credhelper.RunDocker(credhelper.DockerActionGet, strings.NewReader("https://index.docker.io/v1/"), out, allowedHosts, failing)
+		require.ErrorIs(t, err, credhelper.ErrCredentialsNotFound)
+		assert.Equal(t, "credentials not found in native keychain\n", out.String())
+	})
+}
+
+func TestAllowedHosts(t *testing.T) {
+	hosts := credhelper.AllowedHosts(map[string]string{
+		configcommon.EnvCacheEndpoints: "grpcs://cache-1.example.com:443, grpcs://cache-2.example.com",
+		"BITRISE_RBE_ENDPOINT":         "grpcs://rbe.example.com",
+		credhelper.EnvCredentialHosts:  "Registry.Example.com,",
+	})
+
+	assert.Equal(t, []string{"cache-1.example.com", "cache-2.example.com", "rbe.example.com", "registry.example.com"}, hosts)
+}
+
+func TestWriteEnv(t *testing.T) {
+	cfg, _ := patConfig()
+	out := &bytes.Buffer{}
+	require.NoError(t, credhelper.WriteEnv(out, cfg))
+	assert.Equal(t, "export BITRISE_BUILD_CACHE_AUTH_TOKEN='tok'\\''en'\nexport BITRISE_BUILD_CACHE_WORKSPACE_ID='ws-1'\n", out.String())
+
+	out.Reset()
+	require.NoError(t, credhelper.WriteEnv(out, configcommon.CacheAuthConfig{AuthToken: "jwt", WorkspaceID: "ws-1", IsJWT: true}))
+	assert.Equal(t, "export "+configcommon.EnvJWT+"='jwt'\n", out.String())
+}
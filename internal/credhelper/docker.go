@@ -0,0 +1,88 @@
+package credhelper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// Docker credential helper actions, see docker-credential-helpers.
+const (
+	DockerActionGet   = "get"
+	DockerActionStore = "store"
+	DockerActionErase = "erase"
+	DockerActionList  = "list"
+)
+
+// ErrUnknownAction is returned for an action the protocol does not define.
+var ErrUnknownAction = errors.New("unknown credential helper action")
+
+// ErrCredentialsNotFound is the `get` answer for a registry the helper has no
+// credentials for. Its message is the one Docker recognizes on stdout.
+var ErrCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// DockerCredentials is the `get` response of the Docker credential helper protocol.
+type DockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// RunDocker implements the Docker credential helper protocol. For `get` the
+// server URL arrives on stdin and the credentials are written as JSON. The
+// credentials are owned by the CLI's store, so `store` and `erase` only
+// consume their input and `list` reports no entries.
+//
+// Only a server URL on one of allowedHosts is answered; any other gets
+// ErrCredentialsNotFound. resolve is only called for answered `get` requests.
+func RunDocker(action string, in io.Reader, out io.Writer, allowedHosts []string, resolve func() (configcommon.CacheAuthConfig, error)) error {
+	switch action {
+	case DockerActionGet:
+		serverURL, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("read docker credential request: %w", err)
+		}
+
+		if !hostAllowed(allowedHosts, string(serverURL)) {
+			if _, err := fmt.Fprintln(out, ErrCredentialsNotFound.Error()); err != nil {
+				return fmt.Errorf("write docker credential response: %w", err)
+			}
+
+			return ErrCredentialsNotFound
+		}
+
+		cfg, err := resolve()
+		if err != nil {
+			return err
+		}
+
+		resp := DockerCredentials{
+			ServerURL: strings.TrimSpace(string(serverURL)),
+			Username:  basicAuthUsername(cfg),
+			Secret:    cfg.AuthToken,
+		}
+		if err := json.NewEncoder(out).Encode(resp); err != nil {
+			return fmt.Errorf("encode docker credentials: %w", err)
+		}
+
+		return nil
+	case DockerActionStore, DockerActionErase:
+		if _, err := io.Copy(io.Discard, in); err != nil {
+			return fmt.Errorf("read docker credential request: %w", err)
+		}
+
+		return nil
+	case DockerActionList:
+		if _, err := io.WriteString(out, "{}\n"); err != nil {
+			return fmt.Errorf("write docker credential list: %w", err)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+}
@@ -0,0 +1,39 @@
+package credhelper
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// WriteEnv writes the credentials as POSIX shell `export` lines, for
+// `eval "$(bitrise-build-cache credentials env)"`. A JWT is exported as
+// configcommon.EnvJWT, a PAT as configcommon.EnvAuthToken with the workspace.
+func WriteEnv(out io.Writer, cfg configcommon.CacheAuthConfig) error {
+	var lines []string
+	if cfg.IsJWT {
+		lines = append(lines, exportLine(configcommon.EnvJWT, cfg.AuthToken))
+	} else {
+		lines = append(lines,
+			exportLine(configcommon.EnvAuthToken, cfg.AuthToken),
+			exportLine(configcommon.EnvWorkspaceID, cfg.WorkspaceID),
+		)
+	}
+
+	if _, err := io.WriteString(out, strings.Join(lines, "\n")+"\n"); err != nil {
+		return fmt.Errorf("write env exports: %w", err)
+	}
+
+	return nil
+}
+
+func exportLine(key, value string) string {
+	return "export " + key + "=" + shellQuote(value)
+}
+
+// shellQuote single-quotes value for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
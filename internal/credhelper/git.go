@@ -0,0 +1,71 @@
+package credhelper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// Git credential helper actions, see gitcredentials(7).
+const (
+	GitActionGet   = "get"
+	GitActionStore = "store"
+	GitActionErase = "erase"
+)
+
+// RunGit implements the Git credential helper protocol. Git writes
+// `key=value` lines terminated by a blank line on stdin; for `get` the helper
+// answers with `username` and `password` lines. The credentials are owned by
+// the CLI's store, so `store` and `erase` only consume their input. Unknown
+// actions are ignored, as the protocol requires.
+//
+// Only a request for one of allowedHosts is answered; for any other host the
+// helper stays silent so Git moves on to its next helper. resolve is only
+// called for answered `get` requests.
+func RunGit(action string, in io.Reader, out io.Writer, allowedHosts []string, resolve func() (configcommon.CacheAuthConfig, error)) error {
+	attributes, err := readGitAttributes(in)
+	if err != nil {
+		return fmt.Errorf("read git credential request: %w", err)
+	}
+
+	if action != GitActionGet || !hostAllowed(allowedHosts, attributes["host"]) {
+		return nil
+	}
+
+	cfg, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(out, "username=%s\npassword=%s\n", basicAuthUsername(cfg), cfg.AuthToken); err != nil {
+		return fmt.Errorf("write git credentials: %w", err)
+	}
+
+	return nil
+}
+
+// readGitAttributes consumes the `key=value` lines up to the first blank line
+// or EOF.
+func readGitAttributes(in io.Reader) (map[string]string, error) {
+	attributes := make(map[string]string)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		attributes[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	return attributes, nil
+}
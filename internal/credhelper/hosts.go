@@ -0,0 +1,57 @@
+package credhelper
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// EnvCredentialHosts is a comma separated list of extra hosts the Git and
+// Docker helpers answer for, e.g. a Bitrise-fronted registry.
+const EnvCredentialHosts = "BITRISE_BUILD_CACHE_CREDENTIAL_HOSTS"
+
+// AllowedHosts returns the hosts the Git and Docker helpers hand the token to:
+// the configured build cache and RBE endpoints and EnvCredentialHosts. Any
+// other host gets no credentials, so a helper configured too broadly never
+// leaks the token to a third party.
+func AllowedHosts(envs map[string]string) []string {
+	candidates := configcommon.SelectCacheEndpointURLs("", envs)
+	candidates = append(candidates, configcommon.SelectRBEEndpointURL("", envs))
+	candidates = append(candidates, strings.Split(envs[EnvCredentialHosts], ",")...)
+
+	var hosts []string
+	for _, candidate := range candidates {
+		if host := hostname(candidate); host != "" && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+func hostAllowed(allowed []string, host string) bool {
+	host = hostname(host)
+
+	return host != "" && slices.Contains(allowed, host)
+}
+
+// hostname returns the lowercased host name of a URL or a bare
+// `host[:port]`, without the port.
+func hostname(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if !strings.Contains(s, "://") {
+		s = "//" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
@@ -17,8 +17,13 @@ type ControlStatus struct {
 type ControlResult struct {
 	BackendName string
 	Statuses    []ControlStatus
+	// NotInstalled lists the services Up skipped because their config is not
+	// on disk, e.g. services added after the daemon was installed.
+	NotInstalled []ControlStatus
 }
 
+// Up starts the installed services and skips the ones whose config is missing.
+// It only fails with ErrNotInstalled when none of them is installed.
 func Up(ctx context.Context, backend Backend, paths Paths, services []Service) (ControlResult, error) {
 	result := ControlResult{
 		BackendName: backend.Name(),
@@ -30,7 +35,9 @@ func Up(ctx context.Context, backend Backend, paths Paths, services []Service) (
 
 		if _, err := os.Stat(path); err != nil {
 			if os.IsNotExist(err) {
-				return result, fmt.Errorf("%w (missing %s)", ErrNotInstalled, path)
+				result.NotInstalled = append(result.NotInstalled, ControlStatus{Service: svc, ConfigPath: path})
+
+				continue
 			}
 
 			return result, fmt.Errorf("stat %s: %w", path, err)
@@ -43,6 +50,10 @@ func Up(ctx context.Context, backend Backend, paths Paths, services []Service) (
 		result.Statuses = append(result.Statuses, ControlStatus{Service: svc, ConfigPath: path})
 	}
 
+	if len(result.Statuses) == 0 && len(result.NotInstalled) > 0 {
+		return result, fmt.Errorf("%w (missing %s)", ErrNotInstalled, result.NotInstalled[0].ConfigPath)
+	}
+
 	return result, nil
 }
 
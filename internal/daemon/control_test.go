@@ -25,11 +25,11 @@ func TestUp_launchd_startsAllInstalledServices(t *testing.T) {
 	upRunner := &recordingRunner{}
 	result, err := Up(context.Background(), LaunchdBackend{Runner: upRunner}, paths, DefaultServices())
 	require.NoError(t, err)
-	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.Statuses, 4)
 	assert.Equal(t, "launchd", result.BackendName)
 
 	// Up runs enable-then-bootout-then-bootstrap-then-kickstart per service on launchd.
-	assert.Len(t, upRunner.calls, 8)
+	assert.Len(t, upRunner.calls, 16)
 	assert.Equal(t, "enable", upRunner.calls[0][1])
 	assert.Equal(t, "bootout", upRunner.calls[1][1])
 	assert.Equal(t, "bootstrap", upRunner.calls[2][1])
@@ -38,6 +38,14 @@ func TestUp_launchd_startsAllInstalledServices(t *testing.T) {
 	assert.Equal(t, "bootout", upRunner.calls[5][1])
 	assert.Equal(t, "bootstrap", upRunner.calls[6][1])
 	assert.Equal(t, "kickstart", upRunner.calls[7][1])
+	assert.Equal(t, "enable", upRunner.calls[8][1])
+	assert.Equal(t, "bootout", upRunner.calls[9][1])
+	assert.Equal(t, "bootstrap", upRunner.calls[10][1])
+	assert.Equal(t, "kickstart", upRunner.calls[11][1])
+	assert.Equal(t, "enable", upRunner.calls[12][1])
+	assert.Equal(t, "bootout", upRunner.calls[13][1])
+	assert.Equal(t, "bootstrap", upRunner.calls[14][1])
+	assert.Equal(t, "kickstart", upRunner.calls[15][1])
 }
 
 func TestUp_launchd_errorsWhenNotInstalled(t *testing.T) {
@@ -51,6 +59,39 @@ func TestUp_launchd_errorsWhenNotInstalled(t *testing.T) {
 	assert.Empty(t, runner.calls, "Backend.Start must not be called when config is missing")
 }
 
+// legacyServices are the services installs made before bazel-proxy and
+// gradle-cache-connector existed have on disk.
+func legacyServices() []Service {
+	return DefaultServices()[:2]
+}
+
+func TestUp_launchd_skipsServicesAddedAfterInstall(t *testing.T) {
+	home := t.TempDir()
+	paths := NewPathsFromHome(home)
+
+	_, err := Install(context.Background(), LaunchdBackend{Runner: &recordingRunner{}}, paths, legacyServices(), "/usr/local/bin/bitrise-build-cache")
+	require.NoError(t, err)
+
+	upRunner := &recordingRunner{}
+	result, err := Up(context.Background(), LaunchdBackend{Runner: upRunner}, paths, DefaultServices())
+	require.NoError(t, err)
+
+	require.Len(t, result.Statuses, 2)
+	assert.Equal(t, "xcelerate-proxy", result.Statuses[0].Service.Name)
+	assert.Equal(t, "ccache-helper", result.Statuses[1].Service.Name)
+	require.Len(t, result.NotInstalled, 2)
+	assert.Equal(t, "bazel-proxy", result.NotInstalled[0].Service.Name)
+	assert.Equal(t, "gradle-cache-connector", result.NotInstalled[1].Service.Name)
+
+	// only the installed services are bootstrapped
+	for _, call := range upRunner.calls {
+		for _, arg := range call {
+			assert.NotContains(t, arg, "bazel-proxy")
+			assert.NotContains(t, arg, "gradle-cache-connector")
+		}
+	}
+}
+
 func TestDown_launchd_stopsWithoutRemovingConfig(t *testing.T) {
 	home := t.TempDir()
 	paths := NewPathsFromHome(home)
@@ -64,9 +105,11 @@ func TestDown_launchd_stopsWithoutRemovingConfig(t *testing.T) {
 	_, err = Down(context.Background(), LaunchdBackend{Runner: downRunner}, paths, DefaultServices())
 	require.NoError(t, err)
 
-	require.Len(t, downRunner.calls, 2)
+	require.Len(t, downRunner.calls, 4)
 	assert.Equal(t, "bootout", downRunner.calls[0][1])
 	assert.Equal(t, "bootout", downRunner.calls[1][1])
+	assert.Equal(t, "bootout", downRunner.calls[2][1])
+	assert.Equal(t, "bootout", downRunner.calls[3][1])
 
 	// Plist files must remain on disk so Up can bring services back.
 	for _, svc := range DefaultServices() {
@@ -103,18 +146,28 @@ func TestRestart_launchd_callsDownThenUp(t *testing.T) {
 	_, err = Restart(context.Background(), LaunchdBackend{Runner: restartRunner}, paths, DefaultServices())
 	require.NoError(t, err)
 
-	// Restart is Down (2 boots-out) + Up (2 * enable+bootout+bootstrap+kickstart) = 10 calls.
-	require.Len(t, restartRunner.calls, 10)
+	// Restart is Down (4 boots-out) + Up (4 * enable+bootout+bootstrap+kickstart) = 20 calls.
+	require.Len(t, restartRunner.calls, 20)
 	assert.Equal(t, "bootout", restartRunner.calls[0][1])
 	assert.Equal(t, "bootout", restartRunner.calls[1][1])
-	assert.Equal(t, "enable", restartRunner.calls[2][1])
+	assert.Equal(t, "bootout", restartRunner.calls[2][1])
 	assert.Equal(t, "bootout", restartRunner.calls[3][1])
-	assert.Equal(t, "bootstrap", restartRunner.calls[4][1])
-	assert.Equal(t, "kickstart", restartRunner.calls[5][1])
-	assert.Equal(t, "enable", restartRunner.calls[6][1])
-	assert.Equal(t, "bootout", restartRunner.calls[7][1])
-	assert.Equal(t, "bootstrap", restartRunner.calls[8][1])
-	assert.Equal(t, "kickstart", restartRunner.calls[9][1])
+	assert.Equal(t, "enable", restartRunner.calls[4][1])
+	assert.Equal(t, "bootout", restartRunner.calls[5][1])
+	assert.Equal(t, "bootstrap", restartRunner.calls[6][1])
+	assert.Equal(t, "kickstart", restartRunner.calls[7][1])
+	assert.Equal(t, "enable", restartRunner.calls[8][1])
+	assert.Equal(t, "bootout", restartRunner.calls[9][1])
+	assert.Equal(t, "bootstrap", restartRunner.calls[10][1])
+	assert.Equal(t, "kickstart", restartRunner.calls[11][1])
+	assert.Equal(t, "enable", restartRunner.calls[12][1])
+	assert.Equal(t, "bootout", restartRunner.calls[13][1])
+	assert.Equal(t, "bootstrap", restartRunner.calls[14][1])
+	assert.Equal(t, "kickstart", restartRunner.calls[15][1])
+	assert.Equal(t, "enable", restartRunner.calls[16][1])
+	assert.Equal(t, "bootout", restartRunner.calls[17][1])
+	assert.Equal(t, "bootstrap", restartRunner.calls[18][1])
+	assert.Equal(t, "kickstart", restartRunner.calls[19][1])
 }
 
 func TestUp_systemd_startsAllInstalledServices(t *testing.T) {
@@ -128,10 +181,10 @@ func TestUp_systemd_startsAllInstalledServices(t *testing.T) {
 	upRunner := &recordingRunner{}
 	result, err := Up(context.Background(), SystemdBackend{Runner: upRunner}, paths, DefaultServices())
 	require.NoError(t, err)
-	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.Statuses, 4)
 	assert.Equal(t, "systemd", result.BackendName)
 
-	require.Len(t, upRunner.calls, 4)
+	require.Len(t, upRunner.calls, 8)
 	assert.Equal(t, "daemon-reload", upRunner.calls[0][2])
 	assert.Equal(t, "enable", upRunner.calls[1][2])
 }
@@ -147,6 +200,22 @@ func TestUp_systemd_errorsWhenNotInstalled(t *testing.T) {
 	assert.Empty(t, runner.calls)
 }
 
+func TestRestart_systemd_skipsServicesAddedAfterInstall(t *testing.T) {
+	home := t.TempDir()
+	paths := NewPathsFromHome(home)
+
+	_, err := Install(context.Background(), SystemdBackend{Runner: &recordingRunner{}}, paths, legacyServices(), "/usr/local/bin/bitrise-build-cache")
+	require.NoError(t, err)
+
+	// the updater and doctor restart with DefaultServices on old installs
+	result, err := Restart(context.Background(), SystemdBackend{Runner: &recordingRunner{}}, paths, DefaultServices())
+	require.NoError(t, err)
+
+	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.NotInstalled, 2)
+	assert.Equal(t, paths.UnitPath("bitrise-build-cache-bazel-proxy"), result.NotInstalled[0].ConfigPath)
+}
+
 func TestDown_systemd_stopsButKeepsUnitFile(t *testing.T) {
 	home := t.TempDir()
 	paths := NewPathsFromHome(home)
@@ -159,7 +228,7 @@ func TestDown_systemd_stopsButKeepsUnitFile(t *testing.T) {
 	_, err = Down(context.Background(), SystemdBackend{Runner: downRunner}, paths, DefaultServices())
 	require.NoError(t, err)
 
-	require.Len(t, downRunner.calls, 2)
+	require.Len(t, downRunner.calls, 4)
 	assert.Equal(t, "stop", downRunner.calls[0][2])
 
 	// Unit files must remain so Up can re-enable them.
@@ -196,13 +265,19 @@ func TestRestart_systemd_callsDownThenUp(t *testing.T) {
 	_, err = Restart(context.Background(), SystemdBackend{Runner: restartRunner}, paths, DefaultServices())
 	require.NoError(t, err)
 
-	require.Len(t, restartRunner.calls, 6)
+	require.Len(t, restartRunner.calls, 12)
 	assert.Equal(t, "stop", restartRunner.calls[0][2])
 	assert.Equal(t, "stop", restartRunner.calls[1][2])
-	assert.Equal(t, "daemon-reload", restartRunner.calls[2][2])
-	assert.Equal(t, "enable", restartRunner.calls[3][2])
+	assert.Equal(t, "stop", restartRunner.calls[2][2])
+	assert.Equal(t, "stop", restartRunner.calls[3][2])
 	assert.Equal(t, "daemon-reload", restartRunner.calls[4][2])
 	assert.Equal(t, "enable", restartRunner.calls[5][2])
+	assert.Equal(t, "daemon-reload", restartRunner.calls[6][2])
+	assert.Equal(t, "enable", restartRunner.calls[7][2])
+	assert.Equal(t, "daemon-reload", restartRunner.calls[8][2])
+	assert.Equal(t, "enable", restartRunner.calls[9][2])
+	assert.Equal(t, "daemon-reload", restartRunner.calls[10][2])
+	assert.Equal(t, "enable", restartRunner.calls[11][2])
 }
 
 // TestRestart_systemd_wrapsUpFailureWithStoppedHint locks the partial-
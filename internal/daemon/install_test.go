@@ -44,7 +44,7 @@ func TestInstall_launchd_writesPlistsAndBootstraps(t *testing.T) {
 
 	result, err := Install(context.Background(), backend, paths, DefaultServices(), "/usr/local/bin/bitrise-build-cache")
 	require.NoError(t, err)
-	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.Statuses, 4)
 	assert.Equal(t, "launchd", result.BackendName)
 
 	for _, st := range result.Statuses {
@@ -54,8 +54,8 @@ func TestInstall_launchd_writesPlistsAndBootstraps(t *testing.T) {
 		assert.True(t, filepath.IsAbs(st.ConfigPath))
 	}
 
-	// Every install runs enable-then-bootout-then-bootstrap-then-kickstart per service: 2 services * 4 calls.
-	assert.Len(t, runner.calls, 8)
+	// Every install runs enable-then-bootout-then-bootstrap-then-kickstart per service: 4 services * 4 calls.
+	assert.Len(t, runner.calls, 16)
 	assert.Equal(t, "enable", runner.calls[0][1])
 	assert.Equal(t, "bootout", runner.calls[1][1])
 	assert.Equal(t, "bootstrap", runner.calls[2][1])
@@ -66,6 +66,16 @@ func TestInstall_launchd_writesPlistsAndBootstraps(t *testing.T) {
 	assert.Equal(t, "bootstrap", runner.calls[6][1])
 	assert.Equal(t, "kickstart", runner.calls[7][1])
 	assert.Equal(t, "-k", runner.calls[7][2])
+	assert.Equal(t, "enable", runner.calls[8][1])
+	assert.Equal(t, "bootout", runner.calls[9][1])
+	assert.Equal(t, "bootstrap", runner.calls[10][1])
+	assert.Equal(t, "kickstart", runner.calls[11][1])
+	assert.Equal(t, "-k", runner.calls[11][2])
+	assert.Equal(t, "enable", runner.calls[12][1])
+	assert.Equal(t, "bootout", runner.calls[13][1])
+	assert.Equal(t, "bootstrap", runner.calls[14][1])
+	assert.Equal(t, "kickstart", runner.calls[15][1])
+	assert.Equal(t, "-k", runner.calls[15][2])
 }
 
 func TestInstall_launchd_idempotent_secondRunOverwritesPlist(t *testing.T) {
@@ -109,7 +119,7 @@ func TestUninstall_launchd_removesPlistsAndTolerantOfMissingService(t *testing.T
 
 	result, err := Uninstall(context.Background(), uninstallBackend, paths, DefaultServices())
 	require.NoError(t, err)
-	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.Statuses, 4)
 
 	for _, st := range result.Statuses {
 		assert.True(t, st.Removed, "service %s should be marked removed", st.Service.Name)
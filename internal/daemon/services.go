@@ -27,5 +27,13 @@ func DefaultServices() []Service {
 			Name: "ccache-helper",
 			Args: []string{"ccache", "storage-helper", "start"},
 		},
+		{
+			Name: "bazel-proxy",
+			Args: []string{"bazel", "start-proxy"},
+		},
+		{
+			Name: "gradle-cache-connector",
+			Args: []string{"gradle", "start-cache-connector"},
+		},
 	}
 }
@@ -22,7 +22,7 @@ func TestInstall_systemd_writesUnitsAndEnables(t *testing.T) {
 
 	result, err := Install(context.Background(), backend, paths, DefaultServices(), "/usr/local/bin/bitrise-build-cache")
 	require.NoError(t, err)
-	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.Statuses, 4)
 	assert.Equal(t, "systemd", result.BackendName)
 
 	for _, st := range result.Statuses {
@@ -33,8 +33,8 @@ func TestInstall_systemd_writesUnitsAndEnables(t *testing.T) {
 		assert.True(t, filepath.IsAbs(st.ConfigPath))
 	}
 
-	// Per service: daemon-reload + enable --now = 2 calls. 2 services = 4 total.
-	assert.Len(t, runner.calls, 4)
+	// Per service: daemon-reload + enable --now = 2 calls. 4 services = 8 total.
+	assert.Len(t, runner.calls, 8)
 
 	// Each call's first element is the systemctl binary; subsequent elements
 	// are --user + subcommand + ... — assert the subcommands cycle correctly.
@@ -43,6 +43,10 @@ func TestInstall_systemd_writesUnitsAndEnables(t *testing.T) {
 	assert.Equal(t, "--now", runner.calls[1][3])
 	assert.Equal(t, "daemon-reload", runner.calls[2][2])
 	assert.Equal(t, "enable", runner.calls[3][2])
+	assert.Equal(t, "daemon-reload", runner.calls[4][2])
+	assert.Equal(t, "enable", runner.calls[5][2])
+	assert.Equal(t, "daemon-reload", runner.calls[6][2])
+	assert.Equal(t, "enable", runner.calls[7][2])
 }
 
 func TestUninstall_systemd_disablesAndRemovesUnit(t *testing.T) {
@@ -57,7 +61,7 @@ func TestUninstall_systemd_disablesAndRemovesUnit(t *testing.T) {
 	uninstallRunner := &recordingRunner{}
 	result, err := Uninstall(context.Background(), SystemdBackend{Runner: uninstallRunner}, paths, DefaultServices())
 	require.NoError(t, err)
-	require.Len(t, result.Statuses, 2)
+	require.Len(t, result.Statuses, 4)
 
 	for _, st := range result.Statuses {
 		assert.True(t, st.Removed, "service %s should be marked removed", st.Service.Name)
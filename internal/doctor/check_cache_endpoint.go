@@ -0,0 +1,98 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// EndpointProbeFunc probes every configured cache endpoint, in configuration order.
+type EndpointProbeFunc func(ctx context.Context, cfg common.CacheAuthConfig, envs map[string]string) ([]kv.EndpointProbeResult, error)
+
+// cacheEndpointCheck reports the endpoint the kv client would select and the
+// health of the failover endpoints.
+func (d *Doctor) cacheEndpointCheck() Check {
+	return Check{
+		Name: "cache-endpoint",
+		Diagnose: func(ctx context.Context) Result {
+			cfg, _, err := common.ResolveAuthConfig(d.Envs)
+			if err != nil {
+				return Result{State: StateOK, Detail: "skipped (no credentials resolvable: " + err.Error() + ")"}
+			}
+
+			probe := d.EndpointProbe
+			if probe == nil {
+				probe = defaultEndpointProbe(d.Debug)
+			}
+
+			probeCtx, cancel := context.WithTimeout(ctx, backendProbeTimeout)
+			defer cancel()
+
+			results, err := probe(probeCtx, cfg, d.Envs)
+			if err != nil {
+				return Result{State: StateError, Detail: err.Error()}
+			}
+
+			best, ok := kv.FastestEndpoint(results)
+			if !ok {
+				return Result{State: StateError, Detail: "no endpoint responded: " + describeEndpoints(results)}
+			}
+
+			res := Result{
+				State:  StateOK,
+				Detail: fmt.Sprintf("selected %s (%dms)", results[best].Endpoint.Host, results[best].Latency.Milliseconds()),
+			}
+			if len(results) > 1 {
+				res.Detail += "; " + describeEndpoints(results)
+			}
+			for _, r := range results {
+				if r.Err != nil {
+					res.State = StateWarn
+				}
+			}
+
+			return res
+		},
+	}
+}
+
+func describeEndpoints(results []kv.EndpointProbeResult) string {
+	parts := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			parts = append(parts, fmt.Sprintf("%s unhealthy (%s)", r.Endpoint.Host, r.Err))
+
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %dms", r.Endpoint.Host, r.Latency.Milliseconds()))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func defaultEndpointProbe(debug bool) EndpointProbeFunc {
+	return func(ctx context.Context, cfg common.CacheAuthConfig, envs map[string]string) ([]kv.EndpointProbeResult, error) {
+		endpoints, err := kv.ParseEndpoints(common.SelectCacheEndpointURLs("", envs))
+		if err != nil {
+			return nil, fmt.Errorf("parse endpoints: %w", err)
+		}
+
+		client, err := kv.NewClient(kv.NewClientParams{
+			Endpoints:  endpoints,
+			ClientName: "doctor-endpoint-probe",
+			AuthConfig: cfg,
+			Logger:     log.NewLogger(log.WithDebugLog(debug)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("new kv client: %w", err)
+		}
+		defer func() { _ = client.Close() }()
+
+		return client.ProbeEndpoints(ctx), nil
+	}
+}
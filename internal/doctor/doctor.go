@@ -84,6 +84,7 @@ type Doctor struct {
 	LatestReleaseTag   func(ctx context.Context, c *http.Client) (string, error)
 	ActivatedTools     func() map[toolconfig.Tool]bool
 	BackendProbe       BackendProbeFunc
+	EndpointProbe      EndpointProbeFunc
 	Now                func() time.Time
 	Debug              bool
 }
@@ -168,7 +169,7 @@ func (d *Doctor) checks(opts Options) []Check {
 	}
 
 	if !opts.SkipBackendProbe {
-		checks = append(checks, d.authBackendCheck())
+		checks = append(checks, d.authBackendCheck(), d.cacheEndpointCheck())
 	}
 
 	checks = append(checks,
@@ -134,6 +134,9 @@ func newMinimalDoctor(t *testing.T) *Doctor {
 		BackendProbe: func(context.Context, common.CacheAuthConfig, map[string]string) (time.Duration, error) {
 			return time.Millisecond, nil
 		},
+		EndpointProbe: func(context.Context, common.CacheAuthConfig, map[string]string) ([]kv.EndpointProbeResult, error) {
+			return []kv.EndpointProbeResult{{Endpoint: kv.Endpoint{Host: "cache.example.com:443"}, Latency: time.Millisecond}}, nil
+		},
 	}
 }
 
@@ -473,6 +476,7 @@ func TestRun_skipBackendProbeOmitsItem(t *testing.T) {
 
 	for _, it := range report.Items {
 		assert.NotEqual(t, "auth-backend", it.Name, "auth-backend should be omitted when SkipBackendProbe=true")
+		assert.NotEqual(t, "cache-endpoint", it.Name, "cache-endpoint should be omitted when SkipBackendProbe=true")
 	}
 }
 
@@ -785,3 +789,54 @@ func TestRemoveFileFixer_missingFileIsError(t *testing.T) {
 	_, err := f.Fix()
 	require.Error(t, err)
 }
+
+func TestCacheEndpointCheck_reportsSelectedEndpoint(t *testing.T) {
+	r := &Doctor{
+		Envs: map[string]string{
+			common.EnvAuthToken:   "tok",
+			common.EnvWorkspaceID: "ws-1",
+		},
+		EndpointProbe: func(_ context.Context, _ common.CacheAuthConfig, _ map[string]string) ([]kv.EndpointProbeResult, error) {
+			return []kv.EndpointProbeResult{
+				{Endpoint: kv.Endpoint{Host: "a:443"}, Latency: 80 * time.Millisecond},
+				{Endpoint: kv.Endpoint{Host: "b:443"}, Latency: 20 * time.Millisecond},
+			}, nil
+		},
+	}
+
+	res := r.cacheEndpointCheck().Diagnose(context.Background())
+	assert.Equal(t, StateOK, res.State)
+	assert.Contains(t, res.Detail, "selected b:443 (20ms)")
+	assert.Contains(t, res.Detail, "a:443 80ms")
+}
+
+func TestCacheEndpointCheck_unhealthyEndpoints(t *testing.T) {
+	envs := map[string]string{
+		common.EnvAuthToken:   "tok",
+		common.EnvWorkspaceID: "ws-1",
+	}
+	unavailable := status.Error(codes.Unavailable, "down")
+
+	r := &Doctor{
+		Envs: envs,
+		EndpointProbe: func(_ context.Context, _ common.CacheAuthConfig, _ map[string]string) ([]kv.EndpointProbeResult, error) {
+			return []kv.EndpointProbeResult{
+				{Endpoint: kv.Endpoint{Host: "a:443"}, Err: unavailable},
+				{Endpoint: kv.Endpoint{Host: "b:443"}, Latency: 20 * time.Millisecond},
+			}, nil
+		},
+	}
+
+	res := r.cacheEndpointCheck().Diagnose(context.Background())
+	assert.Equal(t, StateWarn, res.State)
+	assert.Contains(t, res.Detail, "selected b:443")
+	assert.Contains(t, res.Detail, "a:443 unhealthy")
+
+	r.EndpointProbe = func(_ context.Context, _ common.CacheAuthConfig, _ map[string]string) ([]kv.EndpointProbeResult, error) {
+		return []kv.EndpointProbeResult{{Endpoint: kv.Endpoint{Host: "a:443"}, Err: unavailable}}, nil
+	}
+
+	res = r.cacheEndpointCheck().Diagnose(context.Background())
+	assert.Equal(t, StateError, res.State)
+	assert.Contains(t, res.Detail, "no endpoint responded")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bitrise-io/go-utils/v2/log"
 
@@ -14,7 +15,8 @@ import (
 )
 
 // EnvExporter exports environment variables to the current process and CI-specific mechanisms.
-// It sets os env vars, calls envman (Bitrise CI), and writes to GITHUB_ENV (GitHub Actions).
+// It sets os env vars, calls envman (Bitrise CI), writes to GITHUB_ENV (GitHub Actions)
+// and BASH_ENV (CircleCI).
 type EnvExporter struct {
 	envs   map[string]string
 	logger log.Logger
@@ -29,7 +31,7 @@ func New(envs map[string]string, logger log.Logger) *EnvExporter {
 }
 
 // Export sets the environment variable in the current process, calls envman for Bitrise CI,
-// and writes to the GITHUB_ENV file for GitHub Actions.
+// writes to the GITHUB_ENV file for GitHub Actions and to BASH_ENV for CircleCI.
 // All errors are logged as debug and do not fail the caller.
 func (e *EnvExporter) Export(key, value string) {
 	if err := os.Setenv(key, value); err != nil {
@@ -40,6 +42,7 @@ func (e *EnvExporter) Export(key, value string) {
 
 	e.exportViaEnvman(key, value)
 	e.exportViaGitHubEnv(key, value)
+	e.exportViaBashEnv(key, value)
 }
 
 func (e *EnvExporter) exportViaEnvman(key, value string) {
@@ -53,34 +56,53 @@ func (e *EnvExporter) exportViaEnvman(key, value string) {
 }
 
 func (e *EnvExporter) exportViaGitHubEnv(key, value string) {
-	filePath := e.envs["GITHUB_ENV"]
+	e.appendLine("GITHUB_ENV", e.envs["GITHUB_ENV"], key, fmt.Sprintf("%s=%s", key, value))
+}
+
+// exportViaBashEnv appends to the file CircleCI sources before every later
+// step. BASH_ENV is a generic bash variable, so it is only used on CircleCI.
+func (e *EnvExporter) exportViaBashEnv(key, value string) {
+	if e.envs["CIRCLECI"] == "" {
+		return
+	}
+
+	e.appendLine("BASH_ENV", e.envs["BASH_ENV"], key, fmt.Sprintf("export %s=%s", key, shellQuote(value)))
+}
+
+// appendLine appends the line exporting key to the CI-provided file at
+// filePath; name is the env var the path came from. An empty path is a no-op.
+func (e *EnvExporter) appendLine(name, filePath, key, line string) {
 	if filePath == "" {
 		return
 	}
 
-	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644) //nolint:mnd
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644) //nolint:mnd,gosec
 	if err != nil {
-		e.logger.Debugf("Failed to open GITHUB_ENV file: %v", err)
+		e.logger.Debugf("Failed to open %s file: %v", name, err)
 
 		return
 	}
 	defer f.Close()
 
 	writer := bufio.NewWriter(f)
-	if _, err := fmt.Fprintf(writer, "%s=%s\n", key, value); err != nil {
-		e.logger.Debugf("Failed to write to GITHUB_ENV file: %v", err)
+	if _, err := fmt.Fprintln(writer, line); err != nil {
+		e.logger.Debugf("Failed to write to %s file: %v", name, err)
 
 		return
 	}
 
 	if err := writer.Flush(); err != nil {
-		e.logger.Debugf("Failed to flush GITHUB_ENV file: %v", err)
+		e.logger.Debugf("Failed to flush %s file: %v", name, err)
 
 		return
 	}
 	e.logger.Infof("Appended %s to %s", key, filePath)
 }
 
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
 // ExportToShellRC writes an export statement to ~/.bashrc and ~/.zshrc using a marker block.
 // The blockName identifies the block in the file (e.g. "Bitrise Build Cache").
 // The content is the raw shell content to write (e.g. "export KEY=VALUE").
@@ -0,0 +1,89 @@
+package envexport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/exec"
+)
+
+const (
+	// DotenvPathEnvKey overrides where GitLab CI outputs are written. Point the
+	// job's artifacts:reports:dotenv at the same file to pass them on.
+	DotenvPathEnvKey = "BITRISE_BUILD_CACHE_DOTENV_PATH"
+	// DefaultDotenvFileName is the dotenv file written in CI_PROJECT_DIR when
+	// DotenvPathEnvKey is not set.
+	DefaultDotenvFileName = "bitrise-build-cache.env"
+
+	buildkiteAnnotationContext = "bitrise-build-cache"
+)
+
+// SetOutput publishes a step output in the format of the detected CI provider:
+// envman on Bitrise, GITHUB_OUTPUT on GitHub Actions, a dotenv artifact on
+// GitLab CI, BASH_ENV on CircleCI and build meta-data on Buildkite.
+// All errors are logged as debug and do not fail the caller.
+func (e *EnvExporter) SetOutput(key, value string) {
+	switch common.DetectCIProvider(e.envs) {
+	case common.CIProviderBitrise:
+		e.exportViaEnvman(key, value)
+	case common.CIProviderGitHubActions:
+		e.appendLine("GITHUB_OUTPUT", e.envs["GITHUB_OUTPUT"], key, fmt.Sprintf("%s=%s", key, value))
+	case common.CIProviderGitLabCI:
+		e.appendLine(DotenvPathEnvKey, e.dotenvPath(), key, fmt.Sprintf("%s=%s", key, value))
+	case common.CIProviderCircleCI:
+		e.exportViaBashEnv(key, value)
+	case common.CIProviderBuildkite:
+		e.runBuildkiteAgent("set meta-data "+key, "meta-data", "set", key, value)
+	}
+}
+
+// WriteJobSummary publishes markdown as the job summary on GitHub Actions
+// (GITHUB_STEP_SUMMARY) and as a build annotation on Buildkite. Providers
+// without a summary surface get it in the log.
+func (e *EnvExporter) WriteJobSummary(markdown string) {
+	switch common.DetectCIProvider(e.envs) {
+	case common.CIProviderGitHubActions:
+		if e.envs["GITHUB_STEP_SUMMARY"] != "" {
+			e.appendLine("GITHUB_STEP_SUMMARY", e.envs["GITHUB_STEP_SUMMARY"], "job summary", markdown)
+
+			return
+		}
+	case common.CIProviderBuildkite:
+		e.runBuildkiteAgent("annotate the build", "annotate", markdown,
+			"--context", buildkiteAnnotationContext, "--style", "info", "--append")
+
+		return
+	}
+
+	e.logger.Infof("%s", markdown)
+}
+
+func (e *EnvExporter) dotenvPath() string {
+	if path := e.envs[DotenvPathEnvKey]; path != "" {
+		return path
+	}
+
+	dir := e.envs["CI_PROJECT_DIR"]
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return ""
+		}
+	}
+
+	return filepath.Join(dir, DefaultDotenvFileName)
+}
+
+func (e *EnvExporter) runBuildkiteAgent(action string, args ...string) {
+	stdout, stderr, err := (exec.ExecRunner{}).RunCheck(context.Background(), "buildkite-agent", args...)
+	if err != nil {
+		e.logger.Debugf("Failed to %s via buildkite-agent: %s (%v)", action, strings.TrimSpace(stdout+stderr), err)
+
+		return
+	}
+	e.logger.Infof("Buildkite: %s", action)
+}
@@ -0,0 +1,144 @@
+//go:build unit
+
+package envexport
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOutput_GitHubActions(t *testing.T) {
+	t.Parallel()
+
+	outputFile := filepath.Join(t.TempDir(), "github_output")
+	exporter := New(map[string]string{
+		"GITHUB_ACTIONS": "true",
+		"GITHUB_OUTPUT":  outputFile,
+	}, log.NewLogger())
+
+	exporter.SetOutput("CACHE_HIT", "true")
+
+	content, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "CACHE_HIT=true\n", string(content))
+}
+
+func TestSetOutput_GitLabDotenv(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	exporter := New(map[string]string{
+		"GITLAB_CI":      "true",
+		"CI_PROJECT_DIR": projectDir,
+	}, log.NewLogger())
+
+	exporter.SetOutput("CACHE_KEY", "key-1")
+
+	content, err := os.ReadFile(filepath.Join(projectDir, DefaultDotenvFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "CACHE_KEY=key-1\n", string(content))
+}
+
+func TestSetOutput_CircleCIBashEnv(t *testing.T) {
+	t.Parallel()
+
+	bashEnv := filepath.Join(t.TempDir(), "bash_env")
+	exporter := New(map[string]string{
+		"CIRCLECI": "true",
+		"BASH_ENV": bashEnv,
+	}, log.NewLogger())
+
+	exporter.SetOutput("CACHE_KEY", "it's")
+
+	content, err := os.ReadFile(bashEnv)
+	require.NoError(t, err)
+	assert.Equal(t, `export CACHE_KEY='it'"'"'s'`+"\n", string(content))
+}
+
+func TestExport_skipsBashEnvOutsideCircleCI(t *testing.T) {
+	bashEnv := filepath.Join(t.TempDir(), "bash_env")
+	exporter := New(map[string]string{
+		"BASH_ENV": bashEnv,
+	}, log.NewLogger())
+
+	exporter.Export("TEST_ENVEXPORT_BASH_ENV", "value")
+	t.Cleanup(func() { os.Unsetenv("TEST_ENVEXPORT_BASH_ENV") })
+
+	assert.NoFileExists(t, bashEnv)
+}
+
+func TestPublishCacheReport_GitHubActions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	outputFile := filepath.Join(dir, "github_output")
+	summaryFile := filepath.Join(dir, "step_summary")
+	exporter := New(map[string]string{
+		"GITHUB_ACTIONS":      "true",
+		"GITHUB_OUTPUT":       outputFile,
+		"GITHUB_STEP_SUMMARY": summaryFile,
+	}, log.NewLogger())
+
+	exporter.PublishCacheReport(CacheReport{
+		Title:        "Xcode DerivedData restore",
+		OutputPrefix: "BITRISE_DERIVEDDATA_RESTORE",
+		CacheKey:     "xcode-cache-metadata-app-main-darwin",
+		CacheKeyType: "default",
+		IsRestore:    true,
+		CacheHit:     true,
+		HitRate:      0.875,
+	})
+
+	outputs, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "BITRISE_DERIVEDDATA_RESTORE_CACHE_HIT=true\n"+
+		"BITRISE_DERIVEDDATA_RESTORE_CACHE_KEY=xcode-cache-metadata-app-main-darwin\n"+
+		"BITRISE_DERIVEDDATA_RESTORE_CACHE_HIT_RATE=0.88\n", string(outputs))
+
+	summary, err := os.ReadFile(summaryFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "| Hit rate | 87.5% |")
+}
+
+func TestPublishCacheReport_noopOutsideCI(t *testing.T) {
+	t.Parallel()
+
+	outputFile := filepath.Join(t.TempDir(), "github_output")
+	exporter := New(map[string]string{"GITHUB_OUTPUT": outputFile}, log.NewLogger())
+
+	exporter.PublishCacheReport(CacheReport{OutputPrefix: "X", IsRestore: true})
+
+	assert.NoFileExists(t, outputFile)
+}
+
+func TestCacheReport_Markdown(t *testing.T) {
+	t.Parallel()
+
+	markdown := CacheReport{
+		Title:         "Gradle configuration cache restore",
+		CacheKey:      "key",
+		CacheKeyType:  "fallback",
+		IsRestore:     true,
+		HitRate:       -1,
+		TransferBytes: 2_000_000,
+		Duration:      1500 * time.Millisecond,
+		SkipReason:    "Gradle version differs",
+		Err:           errors.New("a|b\nc"),
+	}.Markdown()
+
+	assert.Equal(t, "### ❌ Bitrise Build Cache: Gradle configuration cache restore\n\n"+
+		"| | |\n|---|---|\n"+
+		"| Cache key | `key` (fallback) |\n"+
+		"| Cache hit | no |\n"+
+		"| Transferred | 2.0 MB |\n"+
+		"| Duration | 2s |\n"+
+		"| Skipped | Gradle version differs |\n"+
+		"| Error | a\\|b c |\n", markdown)
+}
@@ -0,0 +1,104 @@
+package envexport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// CacheReport summarizes a cache save or restore for the CI provider.
+type CacheReport struct {
+	// Title names the operation, e.g. "Xcode DerivedData restore".
+	Title string
+	// OutputPrefix prefixes the published outputs, e.g.
+	// BITRISE_DERIVEDDATA_RESTORE gives BITRISE_DERIVEDDATA_RESTORE_CACHE_HIT.
+	OutputPrefix string
+	CacheKey     string
+	CacheKeyType string
+	// IsRestore enables the cache hit output and row.
+	IsRestore bool
+	CacheHit  bool
+	// HitRate is the share of files served from the cache; negative when not applicable.
+	HitRate       float64
+	TransferBytes int64
+	Duration      time.Duration
+	// SkipReason explains why a restore was skipped without an error.
+	SkipReason string
+	Err        error
+}
+
+// PublishCacheReport sets the <prefix>_CACHE_KEY, <prefix>_CACHE_HIT (restores
+// only) and <prefix>_CACHE_HIT_RATE outputs and writes the job summary. It is a
+// no-op outside CI.
+func (e *EnvExporter) PublishCacheReport(r CacheReport) {
+	if common.DetectCIProvider(e.envs) == "" {
+		return
+	}
+
+	if r.IsRestore {
+		e.SetOutput(r.OutputPrefix+"_CACHE_HIT", strconv.FormatBool(r.CacheHit))
+	}
+	if r.CacheKey != "" {
+		e.SetOutput(r.OutputPrefix+"_CACHE_KEY", r.CacheKey)
+	}
+	if r.HitRate >= 0 {
+		e.SetOutput(r.OutputPrefix+"_CACHE_HIT_RATE", strconv.FormatFloat(r.HitRate, 'f', 2, 64))
+	}
+
+	e.WriteJobSummary(r.Markdown())
+}
+
+// Markdown renders the report as a job summary table.
+func (r CacheReport) Markdown() string {
+	status := "✅"
+	if r.Err != nil {
+		status = "❌"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s Bitrise Build Cache: %s\n\n", status, r.Title)
+	sb.WriteString("| | |\n|---|---|\n")
+
+	if r.CacheKey != "" {
+		key := "`" + r.CacheKey + "`"
+		if r.CacheKeyType != "" {
+			key += " (" + r.CacheKeyType + ")"
+		}
+		fmt.Fprintf(&sb, "| Cache key | %s |\n", key)
+	}
+
+	if r.IsRestore {
+		hit := "no"
+		if r.CacheHit {
+			hit = "yes"
+		}
+		fmt.Fprintf(&sb, "| Cache hit | %s |\n", hit)
+	}
+
+	if r.HitRate >= 0 {
+		fmt.Fprintf(&sb, "| Hit rate | %.1f%% |\n", r.HitRate*100)
+	}
+	if r.TransferBytes > 0 {
+		fmt.Fprintf(&sb, "| Transferred | %s |\n", humanize.Bytes(uint64(r.TransferBytes)))
+	}
+	if r.Duration > 0 {
+		fmt.Fprintf(&sb, "| Duration | %s |\n", r.Duration.Round(time.Second))
+	}
+	if r.SkipReason != "" {
+		fmt.Fprintf(&sb, "| Skipped | %s |\n", escapeTableCell(r.SkipReason))
+	}
+	if r.Err != nil {
+		fmt.Fprintf(&sb, "| Error | %s |\n", escapeTableCell(r.Err.Error()))
+	}
+
+	return sb.String()
+}
+
+func escapeTableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
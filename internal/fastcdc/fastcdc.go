@@ -0,0 +1,165 @@
+// Package fastcdc splits a stream into content-defined chunks using the
+// FastCDC algorithm (gear rolling hash with normalized chunking), so an edit in
+// the middle of a large file only changes the chunks around the edit.
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+const (
+	DefaultMinSize = 256 * 1024
+	DefaultAvgSize = 1024 * 1024
+	DefaultMaxSize = 4 * 1024 * 1024
+)
+
+var ErrInvalidOptions = errors.New("invalid chunker options")
+
+type Options struct {
+	MinSize int
+	AvgSize int // must be a power of two
+	MaxSize int
+}
+
+// DefaultOptions is tuned for build outputs: chunks average 1 MiB.
+func DefaultOptions() Options {
+	return Options{
+		MinSize: DefaultMinSize,
+		AvgSize: DefaultAvgSize,
+		MaxSize: DefaultMaxSize,
+	}
+}
+
+// Chunk is a slice of the input. Data is only valid until the next call to Next.
+type Chunk struct {
+	Offset int64
+	Data   []byte
+}
+
+type Chunker struct {
+	reader  io.Reader
+	opts    Options
+	maskS   uint64
+	maskL   uint64
+	buf     []byte
+	start   int
+	end     int
+	offset  int64
+	readErr error
+}
+
+func NewChunker(reader io.Reader, opts Options) (*Chunker, error) {
+	if opts.MinSize <= 0 || opts.AvgSize <= opts.MinSize || opts.MaxSize <= opts.AvgSize {
+		return nil, fmt.Errorf("%w: sizes must satisfy 0 < min < avg < max", ErrInvalidOptions)
+	}
+	if bits.OnesCount(uint(opts.AvgSize)) != 1 {
+		return nil, fmt.Errorf("%w: average size %d is not a power of two", ErrInvalidOptions, opts.AvgSize)
+	}
+
+	avgBits := bits.TrailingZeros(uint(opts.AvgSize))
+
+	//nolint:exhaustruct
+	return &Chunker{
+		reader: reader,
+		opts:   opts,
+		// The gear hash shifts left, so its high bits depend on the most input:
+		// the masks select high bits. The stricter mask is used below the
+		// average size, the looser one above it (normalized chunking).
+		maskS: ^uint64(0) << (64 - (avgBits + 2)),
+		maskL: ^uint64(0) << (64 - (avgBits - 2)),
+		buf:   make([]byte, opts.MaxSize),
+	}, nil
+}
+
+// Next returns the next chunk, or io.EOF once the input is exhausted.
+func (c *Chunker) Next() (Chunk, error) {
+	if err := c.fill(); err != nil {
+		return Chunk{}, err
+	}
+	if c.start == c.end {
+		return Chunk{}, io.EOF
+	}
+
+	data := c.buf[c.start:c.end]
+	n := c.cut(data)
+
+	chunk := Chunk{Offset: c.offset, Data: data[:n]}
+	c.start += n
+	c.offset += int64(n)
+
+	return chunk, nil
+}
+
+// fill tops the buffer up to MaxSize bytes unless the input ended.
+func (c *Chunker) fill() error {
+	if c.end-c.start >= c.opts.MaxSize || c.readErr != nil {
+		return nil
+	}
+
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+
+	for c.end < len(c.buf) {
+		n, err := c.reader.Read(c.buf[c.end:])
+		c.end += n
+		if errors.Is(err, io.EOF) {
+			c.readErr = err
+
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.opts.MinSize {
+		return n
+	}
+	n = min(n, c.opts.MaxSize)
+	normal := min(n, c.opts.AvgSize)
+
+	var fp uint64
+	i := c.opts.MinSize
+	for ; i < normal; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+
+	return n
+}
+
+// gear maps each byte to a random 64-bit value. Chunk boundaries, and so the
+// keys chunks are stored under, depend on it: it must never change.
+//
+//nolint:gochecknoglobals
+var gear = func() [256]uint64 {
+	var table [256]uint64
+
+	// splitmix64 with a fixed seed
+	state := uint64(0x6a09e667f3bcc908)
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+
+	return table
+}()
@@ -0,0 +1,112 @@
+//go:build unit
+
+package fastcdc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	return Options{MinSize: 2 * 1024, AvgSize: 8 * 1024, MaxSize: 32 * 1024}
+}
+
+func chunkAll(t *testing.T, data []byte, opts Options) []Chunk {
+	t.Helper()
+
+	chunker, err := NewChunker(bytes.NewReader(data), opts)
+	require.NoError(t, err)
+
+	var chunks []Chunk
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		require.NoError(t, err)
+
+		chunk.Data = bytes.Clone(chunk.Data)
+		chunks = append(chunks, chunk)
+	}
+}
+
+func randomData(size int, seed int64) []byte {
+	data := make([]byte, size)
+	//nolint:gosec
+	_, _ = rand.New(rand.NewSource(seed)).Read(data)
+
+	return data
+}
+
+func TestChunker_coversInputWithinBounds(t *testing.T) {
+	opts := testOptions()
+	data := randomData(1024*1024, 1)
+
+	chunks := chunkAll(t, data, opts)
+	require.Greater(t, len(chunks), 1)
+
+	var reassembled []byte
+	for i, chunk := range chunks {
+		assert.Equal(t, int64(len(reassembled)), chunk.Offset)
+		assert.LessOrEqual(t, len(chunk.Data), opts.MaxSize)
+		if i < len(chunks)-1 {
+			assert.GreaterOrEqual(t, len(chunk.Data), opts.MinSize)
+		}
+		reassembled = append(reassembled, chunk.Data...)
+	}
+	assert.Equal(t, data, reassembled)
+}
+
+func TestChunker_localEditOnlyChangesNearbyChunks(t *testing.T) {
+	opts := testOptions()
+	data := randomData(1024*1024, 2)
+
+	edited := bytes.Clone(data)
+	// insert a few bytes in the middle, shifting everything after it
+	edited = append(edited[:500_000], append([]byte("patched"), edited[500_000:]...)...)
+
+	digests := func(chunks []Chunk) map[[32]byte]bool {
+		m := make(map[[32]byte]bool)
+		for _, c := range chunks {
+			m[sha256.Sum256(c.Data)] = true
+		}
+
+		return m
+	}
+
+	before := digests(chunkAll(t, data, opts))
+	after := chunkAll(t, edited, opts)
+
+	changed := 0
+	for _, c := range after {
+		if !before[sha256.Sum256(c.Data)] {
+			changed++
+		}
+	}
+
+	assert.LessOrEqual(t, changed, 3)
+	assert.Greater(t, len(after), 20)
+}
+
+func TestChunker_smallAndEmptyInput(t *testing.T) {
+	assert.Empty(t, chunkAll(t, nil, testOptions()))
+
+	chunks := chunkAll(t, []byte("tiny"), testOptions())
+	require.Len(t, chunks, 1)
+	assert.Equal(t, []byte("tiny"), chunks[0].Data)
+}
+
+func TestNewChunker_invalidOptions(t *testing.T) {
+	_, err := NewChunker(bytes.NewReader(nil), Options{MinSize: 1024, AvgSize: 3000, MaxSize: 8192})
+	require.ErrorIs(t, err, ErrInvalidOptions)
+
+	_, err = NewChunker(bytes.NewReader(nil), Options{MinSize: 8192, AvgSize: 4096, MaxSize: 16384})
+	require.ErrorIs(t, err, ErrInvalidOptions)
+}
@@ -0,0 +1,94 @@
+package filegroup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/fastcdc"
+)
+
+// DefaultChunkingThreshold is the size above which ChunkLargeFiles splits files.
+const DefaultChunkingThreshold = 16 * 1024 * 1024
+
+// ChunkInfo is a content-defined slice of a file. Chunks are stored under their
+// hash and follow each other in order, so offsets are implicit.
+type ChunkInfo struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// ChunkLargeFiles records a content-defined chunk list for every file larger
+// than threshold, so uploads and downloads can skip chunks the cache already has.
+func ChunkLargeFiles(info *Info, threshold int64, logger log.Logger) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	semaphore := make(chan struct{}, 10) // Limit parallelization
+
+	for _, file := range info.Files {
+		if file.Size <= threshold {
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(file *FileInfo) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			chunks, err := ChunkFile(file.Path)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("chunk %s: %w", file.Path, err))
+				mu.Unlock()
+
+				return
+			}
+
+			file.Chunks = chunks
+			logger.Debugf("Split %s into %d chunks", file.Path, len(chunks))
+		}(file)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func ChunkFile(path string) ([]ChunkInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	chunker, err := fastcdc.NewChunker(file, fastcdc.DefaultOptions())
+	if err != nil {
+		return nil, fmt.Errorf("create chunker: %w", err)
+	}
+
+	var chunks []ChunkInfo
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			return chunks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("next chunk: %w", err)
+		}
+
+		sum := sha256.Sum256(chunk.Data)
+		chunks = append(chunks, ChunkInfo{
+			Hash: hex.EncodeToString(sum[:]),
+			Size: int64(len(chunk.Data)),
+		})
+	}
+}
@@ -44,6 +44,8 @@ type FileInfo struct {
 	ModTime    time.Time         `json:"modTime"`
 	Mode       os.FileMode       `json:"mode"`
 	Attributes map[string]string `json:"attributes,omitempty"`
+	// Chunks is set for files stored as content-defined chunks instead of a single blob.
+	Chunks []ChunkInfo `json:"chunks,omitempty"`
 }
 
 type fileGroupInfoCollector struct {
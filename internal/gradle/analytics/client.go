@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/analytics/multiplatform"
+)
+
+// Client sends Gradle task reports to the Bitrise backend.
+// It embeds multiplatform.Client for shared PutInvocation and PutInvocationRelation methods.
+type Client struct {
+	*multiplatform.Client
+}
+
+// NewClient creates an analytics Client.
+func NewClient(baseURL, accessToken string, logger log.Logger) (*Client, error) {
+	mp, err := multiplatform.NewClient(baseURL, accessToken, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create multiplatform client: %w", err)
+	}
+
+	return &Client{Client: mp}, nil
+}
+
+// PutGradleInvocation sends a GradleInvocation to the analytics backend via HTTP PUT.
+func (c *Client) PutGradleInvocation(inv GradleInvocation) error {
+	if err := c.Put(fmt.Sprintf("/v1/invocations/%s", inv.InvocationID), inv); err != nil {
+		return fmt.Errorf("put gradle invocation: %w", err)
+	}
+
+	return nil
+}
+
+// PutConfigCacheRestoreInvocation sends a ConfigCacheRestoreInvocation to the analytics backend via HTTP PUT.
+func (c *Client) PutConfigCacheRestoreInvocation(inv ConfigCacheRestoreInvocation) error {
+	if err := c.Put(fmt.Sprintf("/v1/invocations/%s", inv.InvocationID), inv); err != nil {
+		return fmt.Errorf("put config cache restore invocation: %w", err)
+	}
+
+	return nil
+}
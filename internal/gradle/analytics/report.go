@@ -0,0 +1,205 @@
+package analytics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/analytics/multiplatform"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+// ErrEmptyReport is returned when a task report has no task records.
+var ErrEmptyReport = errors.New("task report has no tasks")
+
+// maxLineSize bounds a single NDJSON line; execution reasons can list many files.
+const maxLineSize = 1024 * 1024
+
+// ReadTaskReport parses the NDJSON task report written by the init script.
+// Blank lines are skipped; a malformed line fails the whole report.
+func ReadTaskReport(r io.Reader) ([]TaskRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var records []TaskRecord
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record TaskRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("decode task report line %d: %w", lineNumber, err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read task report: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, ErrEmptyReport
+	}
+
+	return records, nil
+}
+
+// SummarizeTasks aggregates the task records of one build.
+func SummarizeTasks(records []TaskRecord) TaskStats {
+	var stats TaskStats
+	var nonCacheable, cacheMisses []TaskSummary
+
+	for _, record := range records {
+		stats.TotalTasks++
+		stats.TotalTaskDurationMs += record.DurationMs()
+
+		switch record.Outcome {
+		case OutcomeFromCache:
+			stats.FromCache++
+		case OutcomeUpToDate:
+			stats.UpToDate++
+		case OutcomeSkipped:
+			stats.Skipped++
+		case OutcomeNoSource:
+			stats.NoSource++
+		case OutcomeFailed:
+			stats.Failed++
+		case OutcomeExecuted:
+			stats.Executed++
+
+			summary := TaskSummary{
+				Path:             record.Path,
+				Type:             record.Type,
+				DurationMs:       record.DurationMs(),
+				Cacheable:        record.Cacheable,
+				ExecutionReasons: record.ExecutionReasons,
+			}
+
+			switch {
+			case record.Cacheable == nil:
+				stats.UnknownCacheabilityExecuted++
+			case *record.Cacheable:
+				stats.CacheableExecuted++
+				cacheMisses = append(cacheMisses, summary)
+			default:
+				stats.NonCacheableExecuted++
+				nonCacheable = append(nonCacheable, summary)
+			}
+		}
+	}
+
+	if cacheCandidates := stats.FromCache + stats.CacheableExecuted + stats.UnknownCacheabilityExecuted; cacheCandidates > 0 {
+		stats.CacheHitRate = float64(stats.FromCache) / float64(cacheCandidates)
+	}
+
+	if withActions := stats.FromCache + stats.UpToDate + stats.Executed + stats.Failed; withActions > 0 {
+		stats.AvoidanceRate = float64(stats.FromCache+stats.UpToDate) / float64(withActions)
+	}
+
+	stats.SlowestNonCacheable = slowest(nonCacheable)
+	stats.SlowestCacheMisses = slowest(cacheMisses)
+
+	return stats
+}
+
+// NewGradleInvocation assembles the analytics payload of a task report. The
+// invocation spans from the first task start to the last task end.
+func NewGradleInvocation(
+	records []TaskRecord,
+	parentInvocationID string,
+	authMetadata common.CacheAuthConfig,
+	commonMetadata common.CacheConfigMetadata,
+) *GradleInvocation {
+	stats := SummarizeTasks(records)
+
+	var start, end int64
+	for i, record := range records {
+		if i == 0 || record.StartTime < start {
+			start = record.StartTime
+		}
+		if record.EndTime > end {
+			end = record.EndTime
+		}
+	}
+
+	var runErr error
+	if stats.Failed > 0 {
+		runErr = fmt.Errorf("%d task(s) failed", stats.Failed)
+	}
+
+	base := multiplatform.NewInvocation(multiplatform.InvocationRunStats{
+		InvocationID:   records[0].InvocationID,
+		InvocationDate: time.UnixMilli(start),
+		Duration:       time.Duration(end-start) * time.Millisecond,
+		Command:        "gradle",
+		HitRate:        float32(stats.CacheHitRate),
+		Success:        stats.Failed == 0,
+		Error:          runErr,
+		BuildTool:      "gradle",
+	}, authMetadata, commonMetadata)
+
+	return &GradleInvocation{
+		Invocation:         *base,
+		ParentInvocationID: parentInvocationID,
+		BuildToolStats:     stats,
+	}
+}
+
+func slowest(tasks []TaskSummary) []TaskSummary {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].DurationMs > tasks[j].DurationMs
+	})
+
+	if len(tasks) > maxReportedTasks {
+		tasks = tasks[:maxReportedTasks]
+	}
+
+	if tasks == nil {
+		return []TaskSummary{}
+	}
+
+	return tasks
+}
+
+// NewConfigCacheRestoreInvocation assembles the analytics payload of a
+// configuration cache restore that started at startTime.
+func NewConfigCacheRestoreInvocation(
+	invocationID string,
+	startTime time.Time,
+	stats ConfigCacheRestoreStats,
+	runErr error,
+	authMetadata common.CacheAuthConfig,
+	commonMetadata common.CacheConfigMetadata,
+) *ConfigCacheRestoreInvocation {
+	var hitRate float32
+	if stats.Restored {
+		hitRate = 1
+	}
+
+	base := multiplatform.NewInvocation(multiplatform.InvocationRunStats{
+		InvocationID:   invocationID,
+		InvocationDate: startTime,
+		Duration:       time.Since(startTime),
+		Command:        "restore-gradle-configuration-cache",
+		HitRate:        hitRate,
+		Success:        runErr == nil,
+		Error:          runErr,
+		BuildTool:      "gradle",
+	}, authMetadata, commonMetadata)
+
+	return &ConfigCacheRestoreInvocation{
+		Invocation:     *base,
+		BuildToolStats: stats,
+	}
+}
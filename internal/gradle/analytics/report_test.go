@@ -0,0 +1,103 @@
+//go:build unit
+
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+)
+
+const sampleReport = `{"invocationId":"inv-1","path":":app:compileJava","type":"org.gradle.api.tasks.compile.JavaCompile","outcome":"FROM-CACHE","startTime":1000,"endTime":1200,"cacheable":true}
+{"invocationId":"inv-1","path":":app:processResources","type":"org.gradle.language.jvm.tasks.ProcessResources","outcome":"UP-TO-DATE","startTime":1000,"endTime":1010,"cacheable":false}
+
+{"invocationId":"inv-1","path":":app:generateSources","type":"com.example.GenerateTask","outcome":"EXECUTED","startTime":1200,"endTime":4200,"cacheable":false,"executionReasons":["No history is available."]}
+{"invocationId":"inv-1","path":":app:compileKotlin","type":"org.jetbrains.kotlin.gradle.tasks.KotlinCompile","outcome":"EXECUTED","startTime":1200,"endTime":2200,"incremental":true,"cacheable":true}
+{"invocationId":"inv-1","path":":app:lint","outcome":"EXECUTED","startTime":4200,"endTime":5000}
+{"invocationId":"inv-1","path":":app:test","type":"org.gradle.api.tasks.testing.Test","outcome":"NO-SOURCE","startTime":5000,"endTime":5000,"cacheable":true}
+`
+
+func TestReadTaskReport(t *testing.T) {
+	records, err := ReadTaskReport(strings.NewReader(sampleReport))
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+
+	assert.Equal(t, ":app:compileKotlin", records[3].Path)
+	assert.True(t, records[3].Incremental)
+	require.NotNil(t, records[3].Cacheable)
+	assert.True(t, *records[3].Cacheable)
+	assert.Nil(t, records[4].Cacheable)
+	assert.Equal(t, []string{"No history is available."}, records[2].ExecutionReasons)
+}
+
+func TestReadTaskReport_errors(t *testing.T) {
+	_, err := ReadTaskReport(strings.NewReader("\n\n"))
+	require.ErrorIs(t, err, ErrEmptyReport)
+
+	_, err = ReadTaskReport(strings.NewReader(`{"path":":a","outcome":"EXECUTED"}` + "\n{not json\n"))
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestSummarizeTasks(t *testing.T) {
+	records, err := ReadTaskReport(strings.NewReader(sampleReport))
+	require.NoError(t, err)
+
+	stats := SummarizeTasks(records)
+
+	assert.Equal(t, 6, stats.TotalTasks)
+	assert.Equal(t, 1, stats.FromCache)
+	assert.Equal(t, 1, stats.UpToDate)
+	assert.Equal(t, 3, stats.Executed)
+	assert.Equal(t, 1, stats.NoSource)
+	assert.Equal(t, 1, stats.CacheableExecuted)
+	assert.Equal(t, 1, stats.NonCacheableExecuted)
+	assert.Equal(t, 1, stats.UnknownCacheabilityExecuted)
+	assert.InDelta(t, 1.0/3.0, stats.CacheHitRate, 0.0001)
+	assert.InDelta(t, 2.0/5.0, stats.AvoidanceRate, 0.0001)
+	assert.Equal(t, int64(200+10+3000+1000+800), stats.TotalTaskDurationMs)
+
+	require.Len(t, stats.SlowestNonCacheable, 1)
+	assert.Equal(t, ":app:generateSources", stats.SlowestNonCacheable[0].Path)
+	require.Len(t, stats.SlowestCacheMisses, 1)
+	assert.Equal(t, ":app:compileKotlin", stats.SlowestCacheMisses[0].Path)
+}
+
+func TestNewGradleInvocation(t *testing.T) {
+	records, err := ReadTaskReport(strings.NewReader(sampleReport))
+	require.NoError(t, err)
+
+	inv := NewGradleInvocation(records, "parent-1",
+		common.CacheAuthConfig{WorkspaceID: "ws-1"},
+		common.CacheConfigMetadata{BitriseAppID: "app-1"},
+	)
+
+	assert.Equal(t, "inv-1", inv.InvocationID)
+	assert.Equal(t, "parent-1", inv.ParentInvocationID)
+	assert.Equal(t, "gradle", inv.BuildTool)
+	assert.Equal(t, "ws-1", inv.BitriseWorkspaceSlug)
+	assert.Equal(t, "app-1", inv.BitriseAppSlug)
+	assert.Equal(t, time.UnixMilli(1000), inv.InvocationDate)
+	assert.Equal(t, int64(4000), inv.DurationMs)
+	assert.True(t, inv.Success)
+	assert.InDelta(t, 1.0/3.0, inv.HitRate, 0.0001)
+}
+
+func TestNewConfigCacheRestoreInvocation(t *testing.T) {
+	inv := NewConfigCacheRestoreInvocation("op-1", time.Now(), ConfigCacheRestoreStats{
+		CacheKey:               "gradle-config-cache-metadata-app-main-linux",
+		CacheKeyType:           "default",
+		IncompatibilityReasons: []string{"Gradle version differs: saved 8.10, current 8.11"},
+	}, nil, common.CacheAuthConfig{WorkspaceID: "ws-1"}, common.CacheConfigMetadata{})
+
+	assert.Equal(t, "op-1", inv.InvocationID)
+	assert.Equal(t, "gradle", inv.BuildTool)
+	assert.Equal(t, "restore-gradle-configuration-cache", inv.Command)
+	assert.True(t, inv.Success)
+	assert.Zero(t, inv.HitRate)
+	assert.Len(t, inv.BuildToolStats.IncompatibilityReasons, 1)
+}
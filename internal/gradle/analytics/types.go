@@ -0,0 +1,121 @@
+package analytics
+
+import (
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/analytics/multiplatform"
+)
+
+// Task outcomes as written by the init script listener. They follow the
+// labels Gradle prints in the console.
+const (
+	OutcomeFromCache = "FROM-CACHE"
+	OutcomeUpToDate  = "UP-TO-DATE"
+	OutcomeExecuted  = "EXECUTED"
+	OutcomeSkipped   = "SKIPPED"
+	OutcomeNoSource  = "NO-SOURCE"
+	OutcomeFailed    = "FAILED"
+)
+
+// maxReportedTasks caps the executed tasks listed in TaskStats so a large
+// build does not turn into a multi-megabyte analytics payload.
+const maxReportedTasks = 50
+
+// TaskRecord is one NDJSON line of the task report: a single task of a single build.
+type TaskRecord struct {
+	InvocationID string `json:"invocationId"`
+	Path         string `json:"path"`
+	// Type is the task class; empty when the task graph was loaded from the
+	// configuration cache and the listener never saw the task instances.
+	Type string `json:"type,omitempty"`
+	// Outcome is one of the Outcome* constants.
+	Outcome string `json:"outcome"`
+	// StartTime and EndTime are epoch milliseconds.
+	StartTime   int64 `json:"startTime"`
+	EndTime     int64 `json:"endTime"`
+	Incremental bool  `json:"incremental,omitempty"`
+	// Cacheable reports whether the task type is annotated with @CacheableTask.
+	// Nil when unknown (configuration cache hit). Tasks enabling caching via
+	// outputs.cacheIf are not visible through Gradle's public API.
+	Cacheable *bool `json:"cacheable,omitempty"`
+	// ExecutionReasons are Gradle's reasons for running an EXECUTED task.
+	ExecutionReasons []string `json:"executionReasons,omitempty"`
+}
+
+// DurationMs is the wall-clock the task took.
+func (r TaskRecord) DurationMs() int64 {
+	if r.EndTime < r.StartTime {
+		return 0
+	}
+
+	return r.EndTime - r.StartTime
+}
+
+// TaskSummary is an executed task listed in TaskStats.
+type TaskSummary struct {
+	Path             string   `json:"path"`
+	Type             string   `json:"type,omitempty"`
+	DurationMs       int64    `json:"durationMs"`
+	Cacheable        *bool    `json:"cacheable,omitempty"`
+	ExecutionReasons []string `json:"executionReasons,omitempty"`
+}
+
+// TaskStats aggregates the task report of one build.
+type TaskStats struct {
+	TotalTasks int `json:"totalTasks"`
+	FromCache  int `json:"fromCache"`
+	UpToDate   int `json:"upToDate"`
+	Executed   int `json:"executed"`
+	Skipped    int `json:"skipped"`
+	NoSource   int `json:"noSource"`
+	Failed     int `json:"failed"`
+
+	// CacheableExecuted counts executed tasks of a @CacheableTask type —
+	// remote cache misses.
+	CacheableExecuted int `json:"cacheableExecuted"`
+	// NonCacheableExecuted counts executed tasks the cache could not have served.
+	NonCacheableExecuted int `json:"nonCacheableExecuted"`
+	// UnknownCacheabilityExecuted counts executed tasks whose type was not
+	// seen, which happens on configuration cache hits.
+	UnknownCacheabilityExecuted int `json:"unknownCacheabilityExecuted"`
+
+	// CacheHitRate is FROM-CACHE over the tasks that could have been served
+	// from the cache, counting tasks of unknown cacheability as misses.
+	CacheHitRate float64 `json:"cacheHitRate"`
+	// AvoidanceRate is FROM-CACHE plus UP-TO-DATE over all tasks with actions.
+	AvoidanceRate float64 `json:"avoidanceRate"`
+
+	TotalTaskDurationMs int64 `json:"totalTaskDurationMs"`
+
+	// SlowestNonCacheable lists the longest executed tasks that were not
+	// cacheable, the candidates for making cacheable.
+	SlowestNonCacheable []TaskSummary `json:"slowestNonCacheable"`
+	// SlowestCacheMisses lists the longest executed cacheable tasks.
+	SlowestCacheMisses []TaskSummary `json:"slowestCacheMisses"`
+}
+
+// GradleInvocation is the analytics payload for a Gradle task report. It
+// embeds multiplatform.Invocation so the BE receives the CI / host /
+// repository metadata at the top level, like the other build tools.
+type GradleInvocation struct {
+	multiplatform.Invocation
+
+	ParentInvocationID string    `json:"parentInvocationId,omitempty"`
+	BuildToolStats     TaskStats `json:"buildToolStats"`
+}
+
+// ConfigCacheRestoreStats describes a configuration cache restore. Restored is
+// false with IncompatibilityReasons set when the stored entry was produced by a
+// different Gradle, JDK or init-script setup and was skipped.
+type ConfigCacheRestoreStats struct {
+	CacheKey               string   `json:"cacheKey"`
+	CacheKeyType           string   `json:"cacheKeyType"`
+	Restored               bool     `json:"restored"`
+	IncompatibilityReasons []string `json:"incompatibilityReasons,omitempty"`
+}
+
+// ConfigCacheRestoreInvocation is the analytics payload of one
+// restore-gradle-configuration-cache run.
+type ConfigCacheRestoreInvocation struct {
+	multiplatform.Invocation
+
+	BuildToolStats ConfigCacheRestoreStats `json:"buildToolStats"`
+}
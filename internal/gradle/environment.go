@@ -0,0 +1,241 @@
+package gradle
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+)
+
+const (
+	gradleWrapperPropertiesPath = "gradle/wrapper/gradle-wrapper.properties"
+	gradlePropertiesFileName    = "gradle.properties"
+	javaHomeProperty            = "org.gradle.java.home"
+	javaHomeEnvKey              = "JAVA_HOME"
+)
+
+//nolint:gochecknoglobals
+var distributionVersionPattern = regexp.MustCompile(`gradle-([^/]+?)-(?:bin|all)\.zip`)
+
+// Environment is the part of the build environment that decides whether Gradle
+// can reuse a configuration cache entry. Empty fields could not be detected and
+// are not compared.
+type Environment struct {
+	GradleVersion               string            `json:"gradleVersion,omitempty"`
+	JavaVendor                  string            `json:"javaVendor,omitempty"`
+	JavaVersion                 string            `json:"javaVersion,omitempty"`
+	InitScripts                 map[string]string `json:"initScripts,omitempty"`
+	GradlePropertiesFingerprint string            `json:"gradlePropertiesFingerprint,omitempty"`
+}
+
+// CollectEnvironment detects the Gradle version from the wrapper of projectDir,
+// the JDK from org.gradle.java.home or JAVA_HOME, and fingerprints the init
+// scripts and gradle.properties files Gradle would pick up.
+func (g *Cache) CollectEnvironment(projectDir string) Environment {
+	var env Environment
+
+	env.GradleVersion = gradleWrapperVersion(filepath.Join(projectDir, gradleWrapperPropertiesPath))
+
+	projectProperties := filepath.Join(projectDir, gradlePropertiesFileName)
+	javaHome := readProperties(projectProperties)[javaHomeProperty]
+	if javaHome == "" {
+		javaHome = g.envProvider[javaHomeEnvKey]
+	}
+	if javaHome != "" {
+		release := readProperties(filepath.Join(javaHome, "release"))
+		env.JavaVendor = release["IMPLEMENTOR"]
+		env.JavaVersion = release["JAVA_VERSION"]
+	}
+
+	gradleHome := g.gradleUserHome()
+	if gradleHome == "" {
+		g.logger.Debugf("Could not resolve the Gradle user home, skipping init script and user gradle.properties detection")
+
+		env.GradlePropertiesFingerprint = fingerprintFiles(projectProperties)
+
+		return env
+	}
+
+	env.InitScripts = g.hashInitScripts(gradleHome)
+	env.GradlePropertiesFingerprint = fingerprintFiles(projectProperties, filepath.Join(gradleHome, gradlePropertiesFileName))
+
+	return env
+}
+
+// CompatibilityIssues lists why the entry described by md cannot be reused in
+// the current environment. Entries saved before the environment was recorded
+// are treated as compatible.
+func (md *Metadata) CompatibilityIssues(current Environment) []string {
+	if md.Environment == nil {
+		return nil
+	}
+	saved := md.Environment
+
+	var issues []string
+	compare := func(name, savedValue, currentValue string) {
+		if savedValue != "" && currentValue != "" && savedValue != currentValue {
+			issues = append(issues, fmt.Sprintf("%s differs: saved %s, current %s", name, savedValue, currentValue))
+		}
+	}
+
+	compare("Gradle version", saved.GradleVersion, current.GradleVersion)
+	compare("JDK vendor", saved.JavaVendor, current.JavaVendor)
+	compare("JDK version", saved.JavaVersion, current.JavaVersion)
+	compare("gradle.properties fingerprint", saved.GradlePropertiesFingerprint, current.GradlePropertiesFingerprint)
+
+	if saved.InitScripts != nil && current.InitScripts != nil {
+		issues = append(issues, initScriptIssues(saved.InitScripts, current.InitScripts)...)
+	}
+
+	return issues
+}
+
+func initScriptIssues(saved, current map[string]string) []string {
+	names := make(map[string]struct{}, len(saved)+len(current))
+	for name := range saved {
+		names[name] = struct{}{}
+	}
+	for name := range current {
+		names[name] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var issues []string
+	for _, name := range sorted {
+		savedHash, wasSaved := saved[name]
+		currentHash, isCurrent := current[name]
+
+		switch {
+		case !isCurrent:
+			issues = append(issues, fmt.Sprintf("init script %s is missing", name))
+		case !wasSaved:
+			issues = append(issues, fmt.Sprintf("init script %s was added", name))
+		case savedHash != currentHash:
+			issues = append(issues, fmt.Sprintf("init script %s changed", name))
+		}
+	}
+
+	return issues
+}
+
+func (g *Cache) gradleUserHome() string {
+	home := g.envProvider["HOME"]
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return g.envProvider[paths.GradleUserHomeEnvKey]
+		}
+	}
+
+	return paths.FromHome(home).GradleHome(g.envProvider[paths.GradleUserHomeEnvKey])
+}
+
+// hashInitScripts hashes init.gradle(.kts) and the scripts of init.d in the
+// Gradle user home, keyed by their path relative to it.
+func (g *Cache) hashInitScripts(gradleHome string) map[string]string {
+	candidates := []string{
+		filepath.Join(gradleHome, "init.gradle"),
+		filepath.Join(gradleHome, "init.gradle.kts"),
+	}
+
+	entries, err := os.ReadDir(filepath.Join(gradleHome, "init.d"))
+	if err != nil && !os.IsNotExist(err) {
+		g.logger.Debugf("Failed to list init scripts: %s", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".gradle") || strings.HasSuffix(name, ".gradle.kts")) {
+			continue
+		}
+		candidates = append(candidates, filepath.Join(gradleHome, "init.d", name))
+	}
+
+	scripts := map[string]string{}
+	for _, path := range candidates {
+		checksum, err := hash.ChecksumOfFile(path)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(gradleHome, path)
+		if err != nil {
+			rel = path
+		}
+		scripts[filepath.ToSlash(rel)] = checksum
+	}
+
+	return scripts
+}
+
+func gradleWrapperVersion(wrapperProperties string) string {
+	match := distributionVersionPattern.FindStringSubmatch(readProperties(wrapperProperties)["distributionUrl"])
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+// readProperties parses the key=value lines of a Java properties (or JDK
+// release) file. Missing files yield an empty map.
+func readProperties(path string) map[string]string {
+	properties := map[string]string{}
+
+	f, err := os.Open(path) //nolint:gosec // paths are derived from the project and JDK dirs
+	if err != nil {
+		return properties
+	}
+	defer f.Close() //nolint:errcheck
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		properties[strings.TrimSpace(key)] = strings.ReplaceAll(value, `\:`, ":")
+	}
+
+	return properties
+}
+
+// fingerprintFiles hashes the content of files in order, with missing files
+// hashed as absent, so adding, removing or editing any of them changes the
+// fingerprint.
+func fingerprintFiles(files ...string) string {
+	h := sha256.New()
+
+	for i, file := range files {
+		content, err := os.ReadFile(file) //nolint:gosec // gradle.properties locations
+		if err != nil {
+			_, _ = fmt.Fprintf(h, "%d:absent\n", i)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(h, "%d:%d\n", i, len(content))
+		_, _ = h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
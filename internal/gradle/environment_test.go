@@ -0,0 +1,127 @@
+//go:build unit
+
+package gradle
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestCollectEnvironment(t *testing.T) {
+	projectDir := t.TempDir()
+	gradleHome := t.TempDir()
+	javaHome := t.TempDir()
+
+	writeFile(t, filepath.Join(projectDir, "gradle/wrapper/gradle-wrapper.properties"),
+		"distributionUrl=https\\://services.gradle.org/distributions/gradle-8.10.2-bin.zip\n")
+	writeFile(t, filepath.Join(projectDir, "gradle.properties"), "org.gradle.caching=true\n")
+	writeFile(t, filepath.Join(javaHome, "release"), "IMPLEMENTOR=\"Eclipse Adoptium\"\nJAVA_VERSION=\"17.0.9\"\n")
+	writeFile(t, filepath.Join(gradleHome, "init.d", "bitrise-build-cache.init.gradle.kts"), "println(1)")
+	writeFile(t, filepath.Join(gradleHome, "init.d", "README.md"), "ignored")
+
+	c := NewCache(log.NewLogger(log.WithOutput(io.Discard)), map[string]string{
+		"JAVA_HOME":        javaHome,
+		"GRADLE_USER_HOME": gradleHome,
+	}, nil)
+
+	env := c.CollectEnvironment(projectDir)
+
+	assert.Equal(t, "8.10.2", env.GradleVersion)
+	assert.Equal(t, "Eclipse Adoptium", env.JavaVendor)
+	assert.Equal(t, "17.0.9", env.JavaVersion)
+	assert.Equal(t, []string{"init.d/bitrise-build-cache.init.gradle.kts"}, keys(env.InitScripts))
+	assert.NotEmpty(t, env.GradlePropertiesFingerprint)
+
+	writeFile(t, filepath.Join(gradleHome, "gradle.properties"), "org.gradle.jvmargs=-Xmx4g\n")
+	assert.NotEqual(t, env.GradlePropertiesFingerprint, c.CollectEnvironment(projectDir).GradlePropertiesFingerprint)
+}
+
+func TestCollectEnvironment_javaHomeFromGradleProperties(t *testing.T) {
+	projectDir := t.TempDir()
+	javaHome := t.TempDir()
+
+	writeFile(t, filepath.Join(projectDir, "gradle.properties"), "org.gradle.java.home="+javaHome+"\n")
+	writeFile(t, filepath.Join(javaHome, "release"), "IMPLEMENTOR=\"Azul Systems, Inc.\"\nJAVA_VERSION=\"21.0.1\"\n")
+
+	c := NewCache(log.NewLogger(log.WithOutput(io.Discard)), map[string]string{
+		"JAVA_HOME":        t.TempDir(),
+		"GRADLE_USER_HOME": t.TempDir(),
+	}, nil)
+
+	env := c.CollectEnvironment(projectDir)
+
+	assert.Empty(t, env.GradleVersion)
+	assert.Equal(t, "Azul Systems, Inc.", env.JavaVendor)
+	assert.Equal(t, "21.0.1", env.JavaVersion)
+}
+
+func TestMetadata_CompatibilityIssues(t *testing.T) {
+	saved := Environment{
+		GradleVersion:               "8.10.2",
+		JavaVendor:                  "Eclipse Adoptium",
+		JavaVersion:                 "17.0.9",
+		InitScripts:                 map[string]string{"init.d/a.gradle": "1", "init.d/b.gradle": "2"},
+		GradlePropertiesFingerprint: "fp",
+	}
+
+	t.Run("same environment", func(t *testing.T) {
+		md := Metadata{Environment: &saved}
+
+		assert.Empty(t, md.CompatibilityIssues(saved))
+	})
+
+	t.Run("metadata without environment", func(t *testing.T) {
+		md := Metadata{}
+
+		assert.Empty(t, md.CompatibilityIssues(Environment{GradleVersion: "9.0"}))
+	})
+
+	t.Run("undetected fields are not compared", func(t *testing.T) {
+		md := Metadata{Environment: &saved}
+
+		assert.Empty(t, md.CompatibilityIssues(Environment{GradlePropertiesFingerprint: "fp"}))
+	})
+
+	t.Run("differences", func(t *testing.T) {
+		md := Metadata{Environment: &saved}
+
+		issues := md.CompatibilityIssues(Environment{
+			GradleVersion:               "8.11",
+			JavaVendor:                  "Eclipse Adoptium",
+			JavaVersion:                 "21.0.1",
+			InitScripts:                 map[string]string{"init.d/a.gradle": "changed", "init.d/c.gradle": "3"},
+			GradlePropertiesFingerprint: "other",
+		})
+
+		assert.Equal(t, []string{
+			"Gradle version differs: saved 8.10.2, current 8.11",
+			"JDK version differs: saved 17.0.9, current 21.0.1",
+			"gradle.properties fingerprint differs: saved fp, current other",
+			"init script init.d/a.gradle changed",
+			"init script init.d/b.gradle is missing",
+			"init script init.d/c.gradle was added",
+		}, issues)
+	})
+}
+
+func keys(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+
+	return result
+}
@@ -1,35 +1,27 @@
 package gradle
 
 import (
-	"fmt"
-	"runtime"
-
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 )
 
+const (
+	DefaultCacheKeyTemplate         = "gradle-config-cache-metadata-{{ .App }}-{{ .Branch }}-{{ .OS }}"
+	DefaultFallbackCacheKeyTemplate = "gradle-config-cache-metadata-{{ .App }}-{{ .OS }}"
+)
+
+// CacheKeyParams configures the cache key templates. Empty templates fall back
+// to DefaultCacheKeyTemplate and DefaultFallbackCacheKeyTemplate.
 type CacheKeyParams struct {
-	IsFallback bool
+	Templates   cachekey.Templates
+	CommandFunc common.CommandFunc
 }
 
-func (g *Cache) GetCacheKey(keyParams CacheKeyParams) (string, error) {
-	branch := g.envProvider["BITRISE_GIT_BRANCH"]
-	if branch == "" && !keyParams.IsFallback {
-		return "", fmt.Errorf("cache key is required if BITRISE_GIT_BRANCH env var is not set")
-	}
-	// Branches can contain '/', which would split the kv/<key> resource name
-	// into extra segments and collapse the per-OS keys onto one another.
-	branch = common.SanitizeCacheKeyComponent(branch)
-
-	appSlug := g.envProvider["BITRISE_APP_SLUG"]
-	if appSlug == "" {
-		return "", fmt.Errorf("cache key is required if BITRISE_APP_SLUG env var is not set")
-	}
-
-	os := runtime.GOOS
-
-	if keyParams.IsFallback {
-		return fmt.Sprintf("gradle-config-cache-metadata-%s-%s", appSlug, os), nil
-	}
+func (g *Cache) GetCacheKeys(keyParams CacheKeyParams) (cachekey.Keys, error) {
+	templates := keyParams.Templates.WithDefaults(cachekey.Templates{
+		Primary:   DefaultCacheKeyTemplate,
+		Fallbacks: []string{DefaultFallbackCacheKeyTemplate},
+	})
 
-	return fmt.Sprintf("gradle-config-cache-metadata-%s-%s-%s", appSlug, branch, os), nil
+	return templates.Render(cachekey.NewVars(g.envProvider, keyParams.CommandFunc))
 }
@@ -14,7 +14,7 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
 )
 
-const metadataVersion = 1
+const metadataVersion = 2
 
 type Metadata struct {
 	ConfigCacheFiles     filegroup.Info `json:"configCacheFiles"`
@@ -27,10 +27,13 @@ type Metadata struct {
 	GitCommit            string         `json:"gitCommit,omitempty"`
 	GitBranch            string         `json:"gitBranch,omitempty"`
 	BuildCacheCLIVersion string         `json:"cliVersion,omitempty"`
+	Environment          *Environment   `json:"environment,omitempty"`
 	MetadataVersion      int            `json:"metadataVersion"`
 }
 
-func (g *Cache) CreateMetadata(cacheKey string, dir string) (*Metadata, error) {
+// CreateMetadata collects the configuration cache files of dir and records the
+// Gradle environment of projectDir they were produced with.
+func (g *Cache) CreateMetadata(cacheKey, dir, projectDir string) (*Metadata, error) {
 	fg, err := filegroup.CollectFileGroupInfo(dir,
 		true,
 		false,
@@ -53,6 +56,9 @@ func (g *Cache) CreateMetadata(cacheKey string, dir string) (*Metadata, error) {
 		MetadataVersion:      metadataVersion,
 	}
 
+	env := g.CollectEnvironment(projectDir)
+	m.Environment = &env
+
 	if m.GitCommit == "" {
 		m.GitCommit = g.envProvider["GIT_CLONE_COMMIT_HASH"]
 	}
@@ -110,5 +116,11 @@ func (md *Metadata) Print(logger log.Logger) {
 	logger.Infof("  Git branch: %s", md.GitBranch)
 	logger.Infof("  Config cache files: %d", len(md.ConfigCacheFiles.Files))
 	logger.Infof("  Build Cache CLI version: %s", md.BuildCacheCLIVersion)
+	if md.Environment != nil {
+		logger.Infof("  Gradle version: %s", md.Environment.GradleVersion)
+		logger.Infof("  JDK: %s %s", md.Environment.JavaVendor, md.Environment.JavaVersion)
+		logger.Infof("  Init scripts: %d", len(md.Environment.InitScripts))
+		logger.Infof("  gradle.properties fingerprint: %s", md.Environment.GradlePropertiesFingerprint)
+	}
 	logger.Infof("  Metadata version: %d", md.MetadataVersion)
 }
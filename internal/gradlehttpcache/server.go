@@ -0,0 +1,385 @@
+package gradlehttpcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/google/uuid"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+)
+
+// Client is the part of kv.Client the server forwards to.
+type Client interface {
+	ChangeSession(invocationID string, appSlug string, buildSlug string, stepSlug string)
+	DownloadStream(ctx context.Context, writer io.Writer, key string) error
+	UploadStreamToBuildCache(ctx context.Context, reader io.ReadSeeker, key string, size int64) error
+	Degraded() bool
+}
+
+const (
+	// CachePath is the URL prefix Gradle's HttpBuildCache appends the cache key to.
+	CachePath = "/cache/"
+
+	keyPrefix = "gradle-http-"
+
+	defaultInactivityTimeout = 2 * time.Minute
+	defaultMaxEntrySize      = 1024 * 1024 * 1024
+	readHeaderTimeout        = 30 * time.Second
+	maxKeyLength             = 256
+)
+
+// Server implements Gradle's HTTP build cache protocol (GET and PUT
+// /cache/<key>) on top of the Bitrise Build Cache.
+type Server struct {
+	kvClient    Client
+	pushEnabled bool
+	logger      log.Logger
+	emitter     InvocationEmitter
+	httpServer  *http.Server
+
+	sessionMutex    sync.Mutex
+	sessionState    *sessionState
+	currentSession  *SessionMeta
+	lastActivity    time.Time
+	inactivityTimer *time.Timer
+
+	// AppSlug and BuildSlug are forwarded to the cache with every session.
+	AppSlug   string
+	BuildSlug string
+	// InactivityTimeout is the idle window after which the current session
+	// is emitted. Zero falls back to defaultInactivityTimeout.
+	InactivityTimeout time.Duration
+	// MaxEntrySize is the largest entry accepted on PUT; larger ones are
+	// rejected with 413, which Gradle treats as "not stored" rather than as
+	// a cache failure. Zero falls back to defaultMaxEntrySize.
+	MaxEntrySize int64
+}
+
+func NewServer(kvClient Client, pushEnabled bool, logger log.Logger, emitter InvocationEmitter) *Server {
+	//nolint:exhaustruct
+	s := &Server{
+		kvClient:     kvClient,
+		pushEnabled:  pushEnabled,
+		logger:       logger,
+		emitter:      emitter,
+		sessionState: &sessionState{},
+	}
+
+	//nolint:exhaustruct
+	s.httpServer = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	return s
+}
+
+// Serve accepts Gradle's connections on l until Shutdown is called.
+func (s *Server) Serve(l net.Listener) error {
+	if err := s.httpServer.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve gradle http cache: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown stops the server after in-flight requests finish.
+func (s *Server) Shutdown(ctx context.Context) error {
+	//nolint:wrapcheck
+	return s.httpServer.Shutdown(ctx)
+}
+
+// FlushCurrentSession emits the currently-open session (if any). Caller is
+// responsible for invoking this on shutdown.
+func (s *Server) FlushCurrentSession(ctx context.Context) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	s.emitCurrentSessionLocked(ctx)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, CachePath)
+	if !ok || !isValidKey(key) {
+		http.Error(w, "invalid cache key", http.StatusBadRequest)
+
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.load(w, r, s.observe(r), key)
+	case http.MethodPut:
+		s.store(w, r, s.observe(r), key)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) load(w http.ResponseWriter, r *http.Request, state *sessionState, key string) {
+	s.logger.TDebugf("Load: %s", key)
+
+	response := &streamingResponse{w: w}
+	if err := s.kvClient.DownloadStream(r.Context(), response, keyPrefix+key); err != nil {
+		if response.started {
+			// The 200 is already out; abort the connection so Gradle sees a
+			// failed load instead of a truncated entry.
+			state.errors.Add(1)
+			s.logger.TErrorf("Load %s: failed after %d bytes: %s", key, response.written, err)
+			panic(http.ErrAbortHandler)
+		}
+		if errors.Is(err, kv.ErrCacheNotFound) {
+			state.misses.Add(1)
+			http.Error(w, "not found", http.StatusNotFound)
+
+			return
+		}
+
+		state.errors.Add(1)
+		s.logger.TErrorf("Load %s: %s", key, err)
+		http.Error(w, "load from remote cache failed", http.StatusBadGateway)
+
+		return
+	}
+
+	response.start()
+	state.hits.Add(1)
+	state.downloadBytes.Add(response.written)
+}
+
+// streamingResponse streams a download into the response, sending the 200
+// header with the first byte so a download that fails before producing any
+// content can still be answered with an error status.
+type streamingResponse struct {
+	w       http.ResponseWriter
+	started bool
+	written int64
+}
+
+func (r *streamingResponse) start() {
+	if r.started {
+		return
+	}
+
+	r.started = true
+	r.w.Header().Set("Content-Type", "application/octet-stream")
+	r.w.WriteHeader(http.StatusOK)
+}
+
+func (r *streamingResponse) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	r.start()
+	n, err := r.w.Write(p)
+	r.written += int64(n)
+
+	//nolint:wrapcheck
+	return n, err
+}
+
+func (s *Server) store(w http.ResponseWriter, r *http.Request, state *sessionState, key string) {
+	if !s.pushEnabled {
+		http.Error(w, "push is disabled", http.StatusForbidden)
+
+		return
+	}
+
+	maxSize := s.maxEntrySize()
+	if r.ContentLength > maxSize {
+		http.Error(w, "entry too large", http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	s.logger.TDebugf("Store: %s", key)
+
+	tmp, err := os.CreateTemp("", "gradle-http-cache-upload-*")
+	if err != nil {
+		s.logger.TErrorf("Store %s: create temp file: %s", key, err)
+		http.Error(w, "buffer upload failed", http.StatusInternalServerError)
+
+		return
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	size, err := io.Copy(tmp, io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		s.logger.TErrorf("Store %s: read request body: %s", key, err)
+		http.Error(w, "read request body failed", http.StatusBadRequest)
+
+		return
+	}
+	if size > maxSize {
+		http.Error(w, "entry too large", http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		s.logger.TErrorf("Store %s: rewind temp file: %s", key, err)
+		http.Error(w, "buffer upload failed", http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := s.kvClient.UploadStreamToBuildCache(r.Context(), tmp, keyPrefix+key, size); errors.Is(err, kv.ErrDegraded) {
+		// Gradle treats a failed store as a cache error: accept it as a no-op
+		state.skippedUploads.Add(1)
+		w.WriteHeader(http.StatusOK)
+
+		return
+	} else if err != nil {
+		state.errors.Add(1)
+		s.logger.TErrorf("Store %s: %s", key, err)
+		http.Error(w, "store to remote cache failed", http.StatusBadGateway)
+
+		return
+	}
+
+	state.uploads.Add(1)
+	state.uploadBytes.Add(size)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// observe attributes the request to the current session, starting a new one
+// when the server was idle, and returns the state it should be counted in.
+func (s *Server) observe(r *http.Request) *sessionState {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	if s.currentSession == nil {
+		s.startSessionLocked(gradleVersionFromUserAgent(r.UserAgent()))
+	}
+
+	s.lastActivity = time.Now()
+
+	return s.sessionState
+}
+
+func (s *Server) startSessionLocked(gradleVersion string) {
+	s.sessionState = &sessionState{}
+	s.currentSession = &SessionMeta{
+		InvocationID:  uuid.NewString(),
+		GradleVersion: gradleVersion,
+		StartTime:     time.Now(),
+	}
+
+	s.kvClient.ChangeSession(s.currentSession.InvocationID, s.AppSlug, s.BuildSlug, "")
+
+	target := s.currentSession
+	s.inactivityTimer = time.AfterFunc(s.inactivityDuration(), func() {
+		s.onInactivity(target)
+	})
+
+	s.logger.TInfof("New Gradle session: %s (Gradle %s)", target.InvocationID, target.GradleVersion)
+}
+
+// onInactivity fires when the timer elapses. Re-schedules itself if the
+// session saw activity in the meantime; emits otherwise.
+func (s *Server) onInactivity(target *SessionMeta) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	if s.currentSession != target {
+		return
+	}
+
+	if remaining := s.inactivityDuration() - time.Since(s.lastActivity); remaining > 0 {
+		s.inactivityTimer = time.AfterFunc(remaining, func() {
+			s.onInactivity(target)
+		})
+
+		return
+	}
+
+	s.emitCurrentSessionLocked(context.Background())
+}
+
+func (s *Server) emitCurrentSessionLocked(ctx context.Context) {
+	if s.currentSession == nil {
+		return
+	}
+
+	if s.inactivityTimer != nil {
+		s.inactivityTimer.Stop()
+		s.inactivityTimer = nil
+	}
+
+	meta := *s.currentSession
+	meta.EndTime = s.lastActivity
+	stats := s.sessionState.getStats()
+	stats.Degraded = s.kvClient.Degraded()
+
+	s.logger.TInfof("Gradle session %s finished: hit rate %.02f%% (%d hits, %d misses, %d errors), %d uploads, %d skipped while degraded",
+		meta.InvocationID, stats.HitRate()*100, stats.Hits, stats.Misses, stats.Errors, stats.Uploads, stats.SkippedUploads)
+
+	if s.emitter != nil {
+		s.emitter.EmitSession(ctx, meta, stats)
+	}
+
+	s.currentSession = nil
+}
+
+func (s *Server) inactivityDuration() time.Duration {
+	if s.InactivityTimeout > 0 {
+		return s.InactivityTimeout
+	}
+
+	return defaultInactivityTimeout
+}
+
+func (s *Server) maxEntrySize() int64 {
+	if s.MaxEntrySize > 0 {
+		return s.MaxEntrySize
+	}
+
+	return defaultMaxEntrySize
+}
+
+// isValidKey accepts the hex cache keys Gradle sends and rejects anything
+// that could address a different kv entry.
+func isValidKey(key string) bool {
+	if key == "" || len(key) > maxKeyLength {
+		return false
+	}
+
+	for _, c := range key {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// gradleVersionFromUserAgent extracts the version from Gradle's
+// "Gradle/8.5 (Mac OS X;14.2;aarch64) (...)" User-Agent.
+func gradleVersionFromUserAgent(userAgent string) string {
+	rest, ok := strings.CutPrefix(userAgent, "Gradle/")
+	if !ok {
+		return ""
+	}
+
+	version, _, _ := strings.Cut(rest, " ")
+
+	return version
+}
@@ -0,0 +1,226 @@
+package gradlehttpcache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/gradlehttpcache"
+)
+
+type fakeClient struct {
+	mutex       sync.Mutex
+	entries     map[string][]byte
+	sessions    []string
+	downloadErr error
+	// failAfterWrite is returned after the entry was written, like a
+	// connection that breaks mid-download.
+	failAfterWrite error
+	uploadErr      error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{entries: map[string][]byte{}}
+}
+
+func (f *fakeClient) ChangeSession(invocationID string, _ string, _ string, _ string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.sessions = append(f.sessions, invocationID)
+}
+
+func (f *fakeClient) DownloadStream(_ context.Context, writer io.Writer, key string) error {
+	f.mutex.Lock()
+	data, ok := f.entries[key]
+	downloadErr := f.downloadErr
+	failAfterWrite := f.failAfterWrite
+	f.mutex.Unlock()
+
+	if downloadErr != nil {
+		return downloadErr
+	}
+	if !ok {
+		return kv.ErrCacheNotFound
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+
+	return failAfterWrite
+}
+
+func (f *fakeClient) UploadStreamToBuildCache(_ context.Context, reader io.ReadSeeker, key string, _ int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.uploadErr != nil {
+		return f.uploadErr
+	}
+	f.entries[key] = data
+
+	return nil
+}
+
+func (f *fakeClient) Degraded() bool { return false }
+
+type recordingEmitter struct {
+	mutex sync.Mutex
+	metas []gradlehttpcache.SessionMeta
+	stats []gradlehttpcache.SessionStats
+}
+
+func (e *recordingEmitter) EmitSession(_ context.Context, meta gradlehttpcache.SessionMeta, stats gradlehttpcache.SessionStats) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.metas = append(e.metas, meta)
+	e.stats = append(e.stats, stats)
+}
+
+func newServer(client gradlehttpcache.Client, pushEnabled bool, emitter gradlehttpcache.InvocationEmitter) *gradlehttpcache.Server {
+	return gradlehttpcache.NewServer(client, pushEnabled, log.NewLogger(log.WithOutput(io.Discard)), emitter)
+}
+
+func do(t *testing.T, handler http.Handler, method, path string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("User-Agent", "Gradle/8.5 (Mac OS X;14.2;aarch64) (Eclipse Adoptium;17.0.9;17.0.9+9)")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestServer_StoreAndLoad(t *testing.T) {
+	client := newFakeClient()
+	emitter := &recordingEmitter{}
+	server := newServer(client, true, emitter)
+
+	rec := do(t, server, http.MethodGet, "/cache/0123abcd", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(t, server, http.MethodPut, "/cache/0123abcd", []byte("entry"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []byte("entry"), client.entries["gradle-http-0123abcd"])
+
+	rec = do(t, server, http.MethodGet, "/cache/0123abcd", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "entry", rec.Body.String())
+	assert.Equal(t, "application/octet-stream", rec.Header().Get("Content-Type"))
+
+	server.FlushCurrentSession(context.Background())
+
+	require.Len(t, emitter.metas, 1)
+	assert.Equal(t, "8.5", emitter.metas[0].GradleVersion)
+	assert.Equal(t, []string{emitter.metas[0].InvocationID}, client.sessions)
+	assert.Equal(t, gradlehttpcache.SessionStats{
+		Hits:          1,
+		Misses:        1,
+		Uploads:       1,
+		UploadBytes:   5,
+		DownloadBytes: 5,
+	}, emitter.stats[0])
+	assert.InDelta(t, 0.5, emitter.stats[0].HitRate(), 0.001)
+}
+
+func TestServer_PushDisabled(t *testing.T) {
+	client := newFakeClient()
+	server := newServer(client, false, nil)
+
+	rec := do(t, server, http.MethodPut, "/cache/0123abcd", []byte("entry"))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, client.entries)
+}
+
+func TestServer_StoreWhileDegradedIsNotAnUpload(t *testing.T) {
+	client := newFakeClient()
+	client.uploadErr = fmt.Errorf("upload stream: %w", kv.ErrDegraded)
+	emitter := &recordingEmitter{}
+	server := newServer(client, true, emitter)
+
+	rec := do(t, server, http.MethodPut, "/cache/0123abcd", []byte("entry"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	server.FlushCurrentSession(context.Background())
+
+	require.Len(t, emitter.stats, 1)
+	assert.Zero(t, emitter.stats[0].Uploads)
+	assert.Zero(t, emitter.stats[0].UploadBytes)
+	assert.Zero(t, emitter.stats[0].Errors)
+	assert.Equal(t, int64(1), emitter.stats[0].SkippedUploads)
+}
+
+func TestServer_EntryTooLarge(t *testing.T) {
+	client := newFakeClient()
+	server := newServer(client, true, nil)
+	server.MaxEntrySize = 4
+
+	rec := do(t, server, http.MethodPut, "/cache/0123abcd", []byte("entry"))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Empty(t, client.entries)
+}
+
+func TestServer_RemoteError(t *testing.T) {
+	client := newFakeClient()
+	client.downloadErr = errors.New("connection reset")
+	server := newServer(client, true, nil)
+
+	rec := do(t, server, http.MethodGet, "/cache/0123abcd", nil)
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestServer_RemoteErrorMidDownloadAborts(t *testing.T) {
+	client := newFakeClient()
+	client.entries["gradle-http-0123abcd"] = []byte("partial")
+	client.failAfterWrite = errors.New("connection reset")
+	server := newServer(client, true, nil)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		do(t, server, http.MethodGet, "/cache/0123abcd", nil)
+	})
+}
+
+func TestServer_RejectsInvalidRequests(t *testing.T) {
+	server := newServer(newFakeClient(), true, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "outside cache path", method: http.MethodGet, path: "/other/0123abcd", want: http.StatusBadRequest},
+		{name: "empty key", method: http.MethodGet, path: "/cache/", want: http.StatusBadRequest},
+		{name: "nested key", method: http.MethodGet, path: "/cache/a/b", want: http.StatusBadRequest},
+		{name: "unsupported method", method: http.MethodDelete, path: "/cache/0123abcd", want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, server, tt.method, tt.path, nil)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package gradlehttpcache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// SessionMeta is the identity of the Gradle build being emitted. Gradle's
+// HTTP build cache protocol carries no build id, so a session is a burst of
+// cache calls separated from the next one by the server's inactivity timeout.
+type SessionMeta struct {
+	// InvocationID is generated by the server when the session starts.
+	InvocationID string
+	// GradleVersion is parsed from the User-Agent Gradle sends.
+	GradleVersion string
+	StartTime     time.Time
+	// EndTime is the wall-clock of the last request of the session.
+	EndTime time.Time
+}
+
+// SessionStats is the counters snapshot at emit time.
+type SessionStats struct {
+	Hits    int64
+	Misses  int64
+	Errors  int64
+	Uploads int64
+	// SkippedUploads counts stores dropped because the remote was degraded.
+	SkippedUploads int64
+	UploadBytes    int64
+	DownloadBytes  int64
+	// Degraded is set when the remote was short-circuited during the session.
+	Degraded bool
+}
+
+// InvocationEmitter receives the stats of a finished Gradle session.
+type InvocationEmitter interface {
+	EmitSession(ctx context.Context, meta SessionMeta, stats SessionStats)
+}
+
+// HitRate is the share of cache loads that were served from the remote.
+func (s SessionStats) HitRate() float32 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float32(s.Hits) / float32(s.Hits+s.Misses)
+}
+
+type sessionState struct {
+	hits           atomic.Int64
+	misses         atomic.Int64
+	errors         atomic.Int64
+	uploads        atomic.Int64
+	skippedUploads atomic.Int64
+	uploadBytes    atomic.Int64
+	downloadBytes  atomic.Int64
+}
+
+func (s *sessionState) getStats() SessionStats {
+	return SessionStats{
+		Hits:           s.hits.Load(),
+		Misses:         s.misses.Load(),
+		Errors:         s.errors.Load(),
+		Uploads:        s.uploads.Load(),
+		SkippedUploads: s.skippedUploads.Load(),
+		UploadBytes:    s.uploadBytes.Load(),
+		DownloadBytes:  s.downloadBytes.Load(),
+	}
+}
@@ -0,0 +1,388 @@
+// Package localcache is a size-bounded, LRU-evicted blob store on local disk.
+// It is used as an L1 tier in front of the remote build cache so repeated
+// builds on the same machine don't re-download identical objects.
+package localcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tmpPrefix = ".tmp-"
+	dirPerm   = 0o755
+)
+
+// ErrNotFound is returned by Get when the key is not present in the store.
+var ErrNotFound = errors.New("local cache entry not found")
+
+type entry struct {
+	name string
+	size int64
+}
+
+// Store keeps blobs as individual files under dir, sharded by the first two
+// hex chars of the key's SHA-256. Recency is tracked in memory and seeded from
+// file mtimes on Open, so the LRU order survives restarts approximately.
+type Store struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	size    int64
+}
+
+// Open creates dir if needed, indexes the blobs already on disk and evicts
+// down to maxBytes.
+func Open(dir string, maxBytes int64) (*Store, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("invalid local cache size limit: %d", maxBytes)
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("create local cache dir (%s): %w", dir, err)
+	}
+
+	s := &Store{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Dir returns the root directory of the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Size returns the total bytes currently stored.
+func (s *Store) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size
+}
+
+// Len returns the number of blobs currently stored.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lru.Len()
+}
+
+// Has reports whether key is present without touching its recency.
+func (s *Store) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.entries[nameFor(key)]
+
+	return ok
+}
+
+// Get copies the blob stored under key into w and marks it as recently used.
+// Returns ErrNotFound on a miss. A blob that fails to read is dropped so the
+// caller can fall back to the remote tier next time.
+func (s *Store) Get(key string, w io.Writer) (int64, error) {
+	f, err := s.OpenEntry(key)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(w, f)
+	if err != nil {
+		s.drop(nameFor(key))
+
+		return n, fmt.Errorf("read local cache entry: %w", err)
+	}
+
+	return n, nil
+}
+
+// OpenEntry opens the blob stored under key for reading and marks it as
+// recently used. Returns ErrNotFound on a miss. The file stays readable even
+// if the entry is evicted while it is open.
+func (s *Store) OpenEntry(key string) (*os.File, error) {
+	name := nameFor(key)
+
+	s.mu.Lock()
+	elem, ok := s.entries[name]
+	if ok {
+		s.lru.MoveToFront(elem)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	path := s.pathFor(name)
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.forget(name)
+
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open local cache entry: %w", err)
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now) // best effort, only used to seed recency on the next Open
+
+	return f, nil
+}
+
+// Put stores the content of r under key, replacing any previous blob.
+func (s *Store) Put(key string, r io.Reader) (int64, error) {
+	pending, err := s.Create(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(pending, r)
+	if err != nil {
+		pending.Abort()
+
+		return n, fmt.Errorf("write local cache entry: %w", err)
+	}
+
+	if err := pending.Commit(); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// Remove deletes the blob stored under key. Missing keys are not an error.
+func (s *Store) Remove(key string) error {
+	name := nameFor(key)
+	s.forget(name)
+
+	if err := os.Remove(s.pathFor(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove local cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Create starts writing a new blob under key. Data is staged in a temp file
+// and only becomes visible to Get after Commit.
+func (s *Store) Create(key string) (*PendingEntry, error) {
+	name := nameFor(key)
+	shard := filepath.Dir(s.pathFor(name))
+
+	if err := os.MkdirAll(shard, dirPerm); err != nil {
+		return nil, fmt.Errorf("create local cache shard dir: %w", err)
+	}
+
+	f, err := os.CreateTemp(shard, tmpPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("create local cache temp file: %w", err)
+	}
+
+	return &PendingEntry{store: s, name: name, file: f}, nil
+}
+
+// PendingEntry is a blob being written into the store.
+type PendingEntry struct {
+	store   *Store
+	name    string
+	file    *os.File
+	written int64
+	err     error
+}
+
+// Write appends p to the staged blob. Write never fails towards the caller so
+// it can be used in an io.MultiWriter next to the real destination; the first
+// error is remembered and makes Commit fail instead.
+func (e *PendingEntry) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return len(p), nil
+	}
+
+	if e.written+int64(len(p)) > e.store.maxBytes {
+		e.err = fmt.Errorf("entry larger than local cache limit (%d bytes)", e.store.maxBytes)
+
+		return len(p), nil
+	}
+
+	n, err := e.file.Write(p)
+	e.written += int64(n)
+	if err != nil {
+		e.err = err
+	}
+
+	return len(p), nil
+}
+
+// Commit makes the staged blob visible and evicts older blobs if the store
+// went over its limit.
+func (e *PendingEntry) Commit() error {
+	tmpPath := e.file.Name()
+
+	if err := e.file.Close(); err != nil && e.err == nil {
+		e.err = err
+	}
+
+	if e.err != nil {
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("write local cache entry: %w", e.err)
+	}
+
+	if err := os.Rename(tmpPath, e.store.pathFor(e.name)); err != nil {
+		_ = os.Remove(tmpPath)
+
+		return fmt.Errorf("commit local cache entry: %w", err)
+	}
+
+	e.store.add(e.name, e.written)
+
+	return nil
+}
+
+// Abort discards the staged blob.
+func (e *PendingEntry) Abort() {
+	tmpPath := e.file.Name()
+	_ = e.file.Close()
+	_ = os.Remove(tmpPath)
+}
+
+func (s *Store) add(name string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[name]; ok {
+		//nolint:forcetypeassert
+		s.size -= elem.Value.(*entry).size
+		s.lru.Remove(elem)
+	}
+
+	s.entries[name] = s.lru.PushFront(&entry{name: name, size: size})
+	s.size += size
+
+	s.evictLocked()
+}
+
+func (s *Store) evictLocked() {
+	for s.size > s.maxBytes {
+		elem := s.lru.Back()
+		if elem == nil {
+			return
+		}
+
+		//nolint:forcetypeassert
+		e := elem.Value.(*entry)
+		s.lru.Remove(elem)
+		delete(s.entries, e.name)
+		s.size -= e.size
+
+		_ = os.Remove(s.pathFor(e.name))
+	}
+}
+
+func (s *Store) forget(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[name]; ok {
+		//nolint:forcetypeassert
+		s.size -= elem.Value.(*entry).size
+		s.lru.Remove(elem)
+		delete(s.entries, name)
+	}
+}
+
+func (s *Store) drop(name string) {
+	s.forget(name)
+	_ = os.Remove(s.pathFor(name))
+}
+
+type diskEntry struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (s *Store) load() error {
+	var found []diskEntry
+
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), tmpPrefix) {
+			// leftover from a crashed write
+			_ = os.Remove(path)
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil //nolint:nilerr // entry vanished while walking
+		}
+
+		found = append(found, diskEntry{name: d.Name(), size: info.Size(), modTime: info.ModTime()})
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("index local cache dir (%s): %w", s.dir, err)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].modTime.Before(found[j].modTime)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range found {
+		s.entries[f.name] = s.lru.PushFront(&entry{name: f.name, size: f.size})
+		s.size += f.size
+	}
+
+	s.evictLocked()
+
+	return nil
+}
+
+func (s *Store) pathFor(name string) string {
+	return filepath.Join(s.dir, name[:2], name)
+}
+
+func nameFor(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+//go:build unit
+
+package localcache_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
+)
+
+func TestStore_PutGet(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	n, err := store.Put("key-1", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	var buf bytes.Buffer
+	n, err = store.Get("key-1", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.Equal(t, "hello", buf.String())
+	assert.Equal(t, int64(5), store.Size())
+
+	_, err = store.Get("missing", &buf)
+	require.ErrorIs(t, err, localcache.ErrNotFound)
+}
+
+func TestStore_OpenEntry(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	_, err = store.Put("key-1", strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	f, err := store.OpenEntry("key-1")
+	require.NoError(t, err)
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	_, err = store.OpenEntry("missing")
+	require.ErrorIs(t, err, localcache.ErrNotFound)
+}
+
+func TestStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 10)
+	require.NoError(t, err)
+
+	_, err = store.Put("a", strings.NewReader("aaaa"))
+	require.NoError(t, err)
+	_, err = store.Put("b", strings.NewReader("bbbb"))
+	require.NoError(t, err)
+
+	// touch a so b becomes the eviction candidate
+	_, err = store.Get("a", &bytes.Buffer{})
+	require.NoError(t, err)
+
+	_, err = store.Put("c", strings.NewReader("cccc"))
+	require.NoError(t, err)
+
+	assert.True(t, store.Has("a"))
+	assert.False(t, store.Has("b"))
+	assert.True(t, store.Has("c"))
+	assert.Equal(t, int64(8), store.Size())
+}
+
+func TestStore_RejectsOversizedEntry(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 4)
+	require.NoError(t, err)
+
+	_, err = store.Put("big", strings.NewReader("too large"))
+	require.Error(t, err)
+	assert.False(t, store.Has("big"))
+	assert.Equal(t, int64(0), store.Size())
+}
+
+func TestStore_AbortDiscardsEntry(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	pending, err := store.Create("key")
+	require.NoError(t, err)
+	_, err = pending.Write([]byte("partial"))
+	require.NoError(t, err)
+	pending.Abort()
+
+	assert.False(t, store.Has("key"))
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestStore_ReopenIndexesExistingEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := localcache.Open(dir, 1024)
+	require.NoError(t, err)
+	_, err = store.Put("key", strings.NewReader("persisted"))
+	require.NoError(t, err)
+
+	reopened, err := localcache.Open(dir, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reopened.Len())
+	assert.Equal(t, int64(9), reopened.Size())
+
+	var buf bytes.Buffer
+	_, err = reopened.Get("key", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "persisted", buf.String())
+}
+
+func TestStore_Remove(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	_, err = store.Put("key", strings.NewReader("data"))
+	require.NoError(t, err)
+	require.NoError(t, store.Remove("key"))
+	require.NoError(t, store.Remove("key"))
+
+	_, err = store.Get("key", &bytes.Buffer{})
+	require.ErrorIs(t, err, localcache.ErrNotFound)
+}
@@ -0,0 +1,570 @@
+// Package mavenproxy serves Maven repositories over HTTP from a local disk
+// cache. Misses fall back to the Bitrise Build Cache when one is configured,
+// then to the upstream repository, and fetched artifacts are kept locally
+// (and optionally pushed to the build cache) for the next build. Only artifacts
+// matching the checksum the upstream repository publishes for them are pushed.
+package mavenproxy
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // Maven repositories publish SHA-1 checksums
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
+)
+
+// Client is the part of kv.Client artifacts are shared through.
+type Client interface {
+	DownloadStream(ctx context.Context, writer io.Writer, key string) error
+	UploadStreamToBuildCache(ctx context.Context, reader io.ReadSeeker, key string, size int64) error
+}
+
+// Repository is an upstream Maven repository served under
+// RepositoryPath + Segment.
+type Repository struct {
+	// Segment is the URL path segment the repository is served under, e.g. "central".
+	Segment string
+	// UpstreamURL is the root of the upstream repository, e.g. "https://repo1.maven.org/maven2".
+	UpstreamURL string
+}
+
+const (
+	// RepositoryPath is the URL prefix repositories are served under.
+	RepositoryPath = "/maven/"
+
+	keyPrefix = "maven-"
+
+	defaultUpstreamTimeout = 30 * time.Minute
+	readHeaderTimeout      = 30 * time.Second
+	maxChecksumFileSize    = 1024
+)
+
+// checksumFiles are the checksum files looked up upstream to verify an
+// artifact before it is shared, strongest first.
+//
+//nolint:gochecknoglobals
+var checksumFiles = []struct {
+	extension string
+	newHash   func() hash.Hash
+}{
+	{extension: ".sha256", newHash: sha256.New},
+	{extension: ".sha1", newHash: sha1.New},
+}
+
+// ServerParams configures a Server.
+type ServerParams struct {
+	Repositories []Repository
+	// Store is the local disk cache artifacts are served from.
+	Store *localcache.Store
+	// KVClient shares artifacts between machines through the Bitrise Build
+	// Cache. Nil keeps artifacts local only.
+	KVClient Client
+	// PushEnabled uploads artifacts fetched from upstream to KVClient.
+	PushEnabled bool
+	// HTTPClient fetches from upstream. Nil uses a client with defaultUpstreamTimeout.
+	HTTPClient *http.Client
+	Logger     log.Logger
+}
+
+// Server is the local Maven repository proxy.
+type Server struct {
+	repositories map[string]string
+	store        *localcache.Store
+	kvClient     Client
+	pushEnabled  bool
+	httpClient   *http.Client
+	logger       log.Logger
+	httpServer   *http.Server
+	stats        stats
+}
+
+func NewServer(params ServerParams) *Server {
+	repositories := make(map[string]string, len(params.Repositories))
+	for _, repo := range params.Repositories {
+		repositories[repo.Segment] = strings.TrimSuffix(repo.UpstreamURL, "/")
+	}
+
+	httpClient := params.HTTPClient
+	if httpClient == nil {
+		//nolint:exhaustruct
+		httpClient = &http.Client{Timeout: defaultUpstreamTimeout}
+	}
+
+	//nolint:exhaustruct
+	s := &Server{
+		repositories: repositories,
+		store:        params.Store,
+		kvClient:     params.KVClient,
+		pushEnabled:  params.PushEnabled,
+		httpClient:   httpClient,
+		logger:       params.Logger,
+	}
+
+	//nolint:exhaustruct
+	s.httpServer = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	return s
+}
+
+// Serve accepts connections on l until Shutdown is called.
+func (s *Server) Serve(l net.Listener) error {
+	if err := s.httpServer.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve maven proxy: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown stops the server after in-flight requests finish.
+func (s *Server) Shutdown(ctx context.Context) error {
+	//nolint:wrapcheck
+	return s.httpServer.Shutdown(ctx)
+}
+
+// Stats returns the counters since the server started.
+func (s *Server) Stats() Stats {
+	return s.stats.get()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	segment, artifactPath, ok := s.parsePath(r.URL.Path)
+	if !ok {
+		http.Error(w, "unknown repository", http.StatusNotFound)
+
+		return
+	}
+	upstreamURL := s.repositories[segment] + "/" + artifactPath
+
+	if !isImmutable(artifactPath) {
+		s.passThrough(w, r, upstreamURL)
+
+		return
+	}
+
+	key := artifactKey(segment, upstreamURL)
+
+	if s.serveLocal(w, r, key) {
+		s.stats.localHits.Add(1)
+
+		return
+	}
+
+	if s.fetchFromRemoteCache(r.Context(), key, upstreamURL) && s.serveLocal(w, r, key) {
+		s.stats.remoteHits.Add(1)
+
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		s.passThrough(w, r, upstreamURL)
+
+		return
+	}
+
+	if s.fetchFromUpstream(w, r, key, upstreamURL) && s.shouldPush() && s.verifyChecksum(r.Context(), key, upstreamURL) {
+		s.pushToRemoteCache(r.Context(), key)
+	}
+}
+
+// parsePath splits /maven/<segment>/<artifact path> and rejects paths that
+// could escape the repository root.
+func (s *Server) parsePath(urlPath string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(urlPath, RepositoryPath)
+	if !ok {
+		return "", "", false
+	}
+
+	segment, artifactPath, ok := strings.Cut(rest, "/")
+	if !ok || artifactPath == "" || strings.HasSuffix(artifactPath, "/") {
+		return "", "", false
+	}
+	if _, known := s.repositories[segment]; !known {
+		return "", "", false
+	}
+	if path.Clean("/"+artifactPath) != "/"+artifactPath {
+		return "", "", false
+	}
+
+	return segment, artifactPath, true
+}
+
+// serveLocal answers the request from the local cache. It returns false on a miss.
+func (s *Server) serveLocal(w http.ResponseWriter, r *http.Request, key string) bool {
+	f, err := s.store.OpenEntry(key)
+	if err != nil {
+		if !errors.Is(err, localcache.ErrNotFound) {
+			s.logger.TWarnf("Open local cache entry %s: %s", key, err)
+		}
+
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		s.logger.TWarnf("Stat local cache entry %s: %s", key, err)
+
+		return false
+	}
+
+	s.stats.servedBytes.Add(info.Size())
+	http.ServeContent(w, r, "", info.ModTime(), f)
+
+	return true
+}
+
+// fetchFromRemoteCache copies the artifact from the build cache into the
+// local cache. It returns false when there is no build cache, the artifact is
+// not in it, or it does not match the checksum upstream publishes: only
+// verified artifacts are pushed, so anything else was altered in the cache.
+func (s *Server) fetchFromRemoteCache(ctx context.Context, key, upstreamURL string) bool {
+	if s.kvClient == nil {
+		return false
+	}
+
+	checksum, ok := s.upstreamChecksum(ctx, upstreamURL)
+	if !ok {
+		s.logger.TDebugf("Not using the build cache for %s: upstream publishes no checksum for it", upstreamURL)
+
+		return false
+	}
+
+	pending, err := s.store.Create(key)
+	if err != nil {
+		s.logger.TWarnf("Create local cache entry %s: %s", key, err)
+
+		return false
+	}
+
+	hasher := checksum.newHash()
+	if err := s.kvClient.DownloadStream(ctx, io.MultiWriter(pending, hasher), keyPrefix+key); err != nil {
+		pending.Abort()
+		if !errors.Is(err, kv.ErrCacheNotFound) {
+			s.logger.TWarnf("Download %s from the build cache: %s", key, err)
+		}
+
+		return false
+	}
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != checksum.expected {
+		pending.Abort()
+		s.stats.checksumMismatches.Add(1)
+		s.logger.TWarnf("Dropping %s from the build cache: %s checksum mismatch, expected %s, got %s", key, checksum.extension, checksum.expected, actual)
+
+		return false
+	}
+
+	if err := pending.Commit(); err != nil {
+		s.logger.TWarnf("Commit local cache entry %s: %s", key, err)
+
+		return false
+	}
+
+	return true
+}
+
+// fetchFromUpstream streams the artifact from upstream to the client and
+// into the local cache. It returns true when the artifact was cached.
+func (s *Server) fetchFromUpstream(w http.ResponseWriter, r *http.Request, key, upstreamURL string) bool {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, upstreamURL, nil)
+	if err != nil {
+		http.Error(w, "create upstream request failed", http.StatusInternalServerError)
+
+		return false
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		s.stats.errors.Add(1)
+		s.logger.TErrorf("Fetch %s: %s", upstreamURL, err)
+		http.Error(w, "fetch from upstream failed", http.StatusBadGateway)
+
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		http.Error(w, http.StatusText(resp.StatusCode), resp.StatusCode)
+
+		return false
+	}
+
+	s.stats.upstreamFetches.Add(1)
+	copyHeaders(w.Header(), resp.Header)
+	w.WriteHeader(http.StatusOK)
+
+	pending, err := s.store.Create(key)
+	if err != nil {
+		s.logger.TWarnf("Create local cache entry %s: %s", key, err)
+
+		s.copyBody(w, resp.Body, upstreamURL)
+
+		return false
+	}
+
+	// the pending entry never fails a write, so the client gets the whole
+	// artifact even if it cannot be cached, e.g. because it is too large
+	if !s.copyBody(io.MultiWriter(w, pending), resp.Body, upstreamURL) {
+		pending.Abort()
+
+		return false
+	}
+
+	if err := pending.Commit(); err != nil {
+		s.logger.TDebugf("Not caching %s: %s", upstreamURL, err)
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) copyBody(w io.Writer, body io.Reader, upstreamURL string) bool {
+	n, err := io.Copy(w, body)
+	s.stats.servedBytes.Add(n)
+	if err != nil {
+		s.logger.TDebugf("Fetch %s: copy response: %s", upstreamURL, err)
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) shouldPush() bool {
+	return s.kvClient != nil && s.pushEnabled
+}
+
+// verifyChecksum checks the cached artifact against the checksum upstream
+// publishes next to it. Artifacts without a checksum, e.g. checksum files
+// themselves, are not verified and so not shared. On a mismatch the local
+// entry is dropped.
+func (s *Server) verifyChecksum(ctx context.Context, key, upstreamURL string) bool {
+	checksum, ok := s.upstreamChecksum(ctx, upstreamURL)
+	if !ok {
+		s.logger.TDebugf("Not sharing %s: upstream publishes no checksum for it", upstreamURL)
+
+		return false
+	}
+
+	actual, err := s.localDigest(key, checksum.newHash())
+	if err != nil {
+		s.logger.TWarnf("Hash local cache entry %s: %s", key, err)
+
+		return false
+	}
+	if actual != checksum.expected {
+		s.stats.checksumMismatches.Add(1)
+		s.logger.TWarnf("Not sharing %s: %s checksum mismatch, expected %s, got %s", upstreamURL, checksum.extension, checksum.expected, actual)
+		if err := s.store.Remove(key); err != nil {
+			s.logger.TWarnf("Remove local cache entry %s: %s", key, err)
+		}
+
+		return false
+	}
+
+	return true
+}
+
+type upstreamChecksum struct {
+	extension string
+	expected  string
+	newHash   func() hash.Hash
+}
+
+// upstreamChecksum returns the strongest checksum upstream publishes next to
+// the artifact.
+func (s *Server) upstreamChecksum(ctx context.Context, upstreamURL string) (upstreamChecksum, bool) {
+	for _, checksumFile := range checksumFiles {
+		expected, ok := s.fetchChecksum(ctx, upstreamURL+checksumFile.extension)
+		if ok {
+			return upstreamChecksum{extension: checksumFile.extension, expected: expected, newHash: checksumFile.newHash}, true
+		}
+	}
+
+	return upstreamChecksum{}, false
+}
+
+// fetchChecksum returns the hex digest in an upstream checksum file. Some
+// repositories append the file name after the digest.
+func (s *Server) fetchChecksum(ctx context.Context, checksumURL string) (string, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksumURL, nil)
+	if err != nil {
+		return "", false
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		s.logger.TDebugf("Fetch %s: %s", checksumURL, err)
+
+		return "", false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxChecksumFileSize))
+	if err != nil {
+		s.logger.TDebugf("Fetch %s: read response: %s", checksumURL, err)
+
+		return "", false
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(fields[0]); err != nil {
+		return "", false
+	}
+
+	return strings.ToLower(fields[0]), true
+}
+
+func (s *Server) localDigest(key string, hasher hash.Hash) (string, error) {
+	f, err := s.store.OpenEntry(key)
+	if err != nil {
+		return "", fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", fmt.Errorf("read: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// pushToRemoteCache uploads an artifact fetched from upstream to the build
+// cache. Failures only cost the sharing, so they are logged.
+func (s *Server) pushToRemoteCache(ctx context.Context, key string) {
+	f, err := s.store.OpenEntry(key)
+	if err != nil {
+		s.logger.TWarnf("Open local cache entry %s for upload: %s", key, err)
+
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		s.logger.TWarnf("Stat local cache entry %s for upload: %s", key, err)
+
+		return
+	}
+
+	if err := s.kvClient.UploadStreamToBuildCache(ctx, f, keyPrefix+key, info.Size()); errors.Is(err, kv.ErrDegraded) {
+		s.stats.skippedUploads.Add(1)
+
+		return
+	} else if err != nil {
+		s.logger.TWarnf("Upload %s to the build cache: %s", key, err)
+
+		return
+	}
+
+	s.stats.uploads.Add(1)
+}
+
+// passThrough forwards a request for a mutable file, e.g. maven-metadata.xml,
+// to upstream without caching it.
+func (s *Server) passThrough(w http.ResponseWriter, r *http.Request, upstreamURL string) {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, upstreamURL, nil)
+	if err != nil {
+		http.Error(w, "create upstream request failed", http.StatusInternalServerError)
+
+		return
+	}
+	for _, header := range []string{"If-Modified-Since", "If-None-Match"} {
+		if value := r.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		s.stats.errors.Add(1)
+		s.logger.TErrorf("Fetch %s: %s", upstreamURL, err)
+		http.Error(w, "fetch from upstream failed", http.StatusBadGateway)
+
+		return
+	}
+	defer resp.Body.Close()
+
+	s.stats.passThrough.Add(1)
+
+	copyHeaders(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		s.logger.TDebugf("Pass through %s: write response: %s", upstreamURL, err)
+	}
+}
+
+// copyHeaders copies the upstream headers relevant to Maven clients.
+func copyHeaders(dst, src http.Header) {
+	for _, header := range []string{"Content-Type", "Content-Length", "Last-Modified", "ETag"} {
+		if value := src.Get(header); value != "" {
+			dst.Set(header, value)
+		}
+	}
+}
+
+// isImmutable reports whether a repository file never changes once
+// published, so it is safe to cache. Metadata and snapshots are re-resolved.
+func isImmutable(artifactPath string) bool {
+	name := path.Base(artifactPath)
+	if strings.HasPrefix(name, "maven-metadata.xml") {
+		return false
+	}
+
+	return !strings.Contains(artifactPath, "-SNAPSHOT")
+}
+
+// artifactKey is the cache key of an artifact. The upstream URL is hashed so
+// keys stay short and free of path separators, and so repositories served
+// under the same segment but from different upstreams never share entries.
+func artifactKey(segment, upstreamURL string) string {
+	sum := sha256.Sum256([]byte(normalizeURL(upstreamURL)))
+
+	return segment + "-" + hex.EncodeToString(sum[:])
+}
+
+// normalizeURL lowercases the scheme and host, which are case-insensitive.
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	return u.String()
+}
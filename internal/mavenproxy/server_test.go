@@ -0,0 +1,326 @@
+package mavenproxy_test
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/mavenproxy"
+)
+
+const (
+	artifactPath = "/maven/central/com/example/lib/1.0/lib-1.0.jar"
+	upstreamPath = "/com/example/lib/1.0/lib-1.0.jar"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s)) //nolint:gosec
+
+	return hex.EncodeToString(sum[:])
+}
+
+type fakeClient struct {
+	mutex   sync.Mutex
+	entries map[string][]byte
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{entries: map[string][]byte{}}
+}
+
+func (f *fakeClient) DownloadStream(_ context.Context, writer io.Writer, key string) error {
+	f.mutex.Lock()
+	data, ok := f.entries[key]
+	f.mutex.Unlock()
+
+	if !ok {
+		return kv.ErrCacheNotFound
+	}
+
+	_, err := writer.Write(data)
+
+	return err
+}
+
+func (f *fakeClient) UploadStreamToBuildCache(_ context.Context, reader io.ReadSeeker, key string, _ int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.entries[key] = data
+
+	return nil
+}
+
+func (f *fakeClient) snapshot() map[string][]byte {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entries := make(map[string][]byte, len(f.entries))
+	for k, v := range f.entries {
+		entries[k] = v
+	}
+
+	return entries
+}
+
+type upstream struct {
+	*httptest.Server
+	requests atomic.Int64
+
+	mutex sync.Mutex
+	paths map[string]int
+}
+
+// requestsFor returns how many times path was requested.
+func (u *upstream) requestsFor(path string) int {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return u.paths[path]
+}
+
+// remoteKey is the build cache key of artifactPath fetched from upstreamURL.
+func remoteKey(upstreamURL string) string {
+	sum := sha256.Sum256([]byte(upstreamURL + upstreamPath))
+
+	return "maven-central-" + hex.EncodeToString(sum[:])
+}
+
+func newUpstream(t *testing.T, files map[string]string) *upstream {
+	t.Helper()
+
+	u := &upstream{paths: map[string]int{}}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.requests.Add(1)
+		u.mutex.Lock()
+		u.paths[r.URL.Path]++
+		u.mutex.Unlock()
+
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, content)
+	}))
+	t.Cleanup(u.Close)
+
+	return u
+}
+
+func newTestServer(t *testing.T, upstreamURL string, client mavenproxy.Client, push bool) *httptest.Server {
+	t.Helper()
+
+	store, err := localcache.Open(t.TempDir(), 1024*1024)
+	require.NoError(t, err)
+
+	server := mavenproxy.NewServer(mavenproxy.ServerParams{
+		Repositories: []mavenproxy.Repository{{Segment: "central", UpstreamURL: upstreamURL}},
+		Store:        store,
+		KVClient:     client,
+		PushEnabled:  push,
+		Logger:       log.NewLogger(),
+	})
+
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url) //nolint:noctx
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_CachesArtifactLocally(t *testing.T) {
+	up := newUpstream(t, map[string]string{upstreamPath: "jar-content"})
+	ts := newTestServer(t, up.URL, nil, false)
+
+	for range 2 {
+		status, body := get(t, ts.URL+artifactPath)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "jar-content", body)
+	}
+
+	assert.Equal(t, int64(1), up.requests.Load(), "second request must be served from the local cache")
+}
+
+func TestServer_SharesThroughBuildCache(t *testing.T) {
+	up := newUpstream(t, map[string]string{
+		upstreamPath:           "jar-content",
+		upstreamPath + ".sha1": sha1Hex("jar-content") + "  lib-1.0.jar\n",
+	})
+	client := newFakeClient()
+
+	first := newTestServer(t, up.URL, client, true)
+	status, _ := get(t, first.URL+artifactPath)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, client.snapshot(), 1, "verified artifact fetched from upstream must be pushed")
+	require.Contains(t, client.snapshot(), remoteKey(up.URL))
+	require.Equal(t, 1, up.requestsFor(upstreamPath))
+
+	// a second machine with an empty local cache
+	second := newTestServer(t, up.URL, client, false)
+	status, body := get(t, second.URL+artifactPath)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "jar-content", body)
+	assert.Equal(t, 1, up.requestsFor(upstreamPath), "second machine must be served from the build cache")
+}
+
+func TestServer_DropsRemoteEntriesFailingChecksum(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "checksum mismatch", files: map[string]string{
+			upstreamPath:           "jar-content",
+			upstreamPath + ".sha1": sha1Hex("jar-content"),
+		}},
+		{name: "no upstream checksum", files: map[string]string{upstreamPath: "jar-content"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := newUpstream(t, tt.files)
+			client := newFakeClient()
+			// an entry altered in the build cache
+			client.entries[remoteKey(up.URL)] = []byte("tampered-content")
+
+			ts := newTestServer(t, up.URL, client, false)
+			status, body := get(t, ts.URL+artifactPath)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, "jar-content", body, "the altered entry must not be served")
+			assert.Equal(t, 1, up.requestsFor(upstreamPath))
+
+			// nor cached locally
+			status, body = get(t, ts.URL+artifactPath)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, "jar-content", body)
+		})
+	}
+}
+
+func TestServer_DoesNotPushUnverifiedArtifacts(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "no upstream checksum", files: map[string]string{upstreamPath: "jar-content"}},
+		{name: "checksum mismatch", files: map[string]string{
+			upstreamPath:             "tampered-content",
+			upstreamPath + ".sha256": "0000000000000000000000000000000000000000000000000000000000000000",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := newUpstream(t, tt.files)
+			client := newFakeClient()
+			ts := newTestServer(t, up.URL, client, true)
+
+			status, _ := get(t, ts.URL+artifactPath)
+			require.Equal(t, http.StatusOK, status)
+			assert.Empty(t, client.snapshot())
+		})
+	}
+}
+
+func TestServer_KeysIncludeUpstream(t *testing.T) {
+	client := newFakeClient()
+	for _, content := range []string{"first-upstream", "second-upstream"} {
+		up := newUpstream(t, map[string]string{
+			upstreamPath:           content,
+			upstreamPath + ".sha1": sha1Hex(content),
+		})
+		ts := newTestServer(t, up.URL, client, true)
+
+		// both repositories are served as "central", but must not share entries
+		status, body := get(t, ts.URL+artifactPath)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, content, body)
+	}
+
+	assert.Len(t, client.snapshot(), 2)
+}
+
+func TestServer_DoesNotPushWhenDisabled(t *testing.T) {
+	up := newUpstream(t, map[string]string{upstreamPath: "jar-content", upstreamPath + ".sha1": sha1Hex("jar-content")})
+	client := newFakeClient()
+	ts := newTestServer(t, up.URL, client, false)
+
+	status, _ := get(t, ts.URL+artifactPath)
+	require.Equal(t, http.StatusOK, status)
+	assert.Empty(t, client.snapshot())
+}
+
+func TestServer_PassesMetadataThrough(t *testing.T) {
+	up := newUpstream(t, map[string]string{"/com/example/lib/maven-metadata.xml": "<metadata/>"})
+	ts := newTestServer(t, up.URL, nil, false)
+
+	for range 2 {
+		status, body := get(t, ts.URL+"/maven/central/com/example/lib/maven-metadata.xml")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "<metadata/>", body)
+	}
+
+	assert.Equal(t, int64(2), up.requests.Load(), "metadata must not be cached")
+}
+
+func TestServer_ForwardsUpstreamNotFound(t *testing.T) {
+	up := newUpstream(t, nil)
+	ts := newTestServer(t, up.URL, newFakeClient(), true)
+
+	status, _ := get(t, ts.URL+artifactPath)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestServer_RejectsInvalidPaths(t *testing.T) {
+	up := newUpstream(t, nil)
+	ts := newTestServer(t, up.URL, nil, false)
+
+	for _, path := range []string{
+		"/maven/unknown/com/example/lib-1.0.jar",
+		"/maven/central/",
+		"/cache/abc",
+	} {
+		status, _ := get(t, ts.URL+path)
+		assert.Equal(t, http.StatusNotFound, status, path)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+artifactPath, strings.NewReader("x")) //nolint:noctx
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	assert.Zero(t, up.requests.Load())
+}
@@ -0,0 +1,60 @@
+package mavenproxy
+
+import "sync/atomic"
+
+// Stats are the counters of a Server since it started.
+type Stats struct {
+	// LocalHits are artifacts served from the local disk cache.
+	LocalHits int64
+	// RemoteHits are artifacts served from the Bitrise Build Cache.
+	RemoteHits int64
+	// UpstreamFetches are artifacts downloaded from the upstream repository.
+	UpstreamFetches int64
+	// PassThrough are requests for mutable files forwarded to upstream uncached.
+	PassThrough int64
+	// Uploads are artifacts pushed to the Bitrise Build Cache.
+	Uploads int64
+	// SkippedUploads are artifacts not pushed because the remote was degraded.
+	SkippedUploads int64
+	// ChecksumMismatches are artifacts that did not match the checksum
+	// published upstream. They are neither shared nor kept locally.
+	ChecksumMismatches int64
+	Errors             int64
+	ServedBytes        int64
+}
+
+// HitRate is the share of artifacts served without going upstream.
+func (s Stats) HitRate() float32 {
+	total := s.LocalHits + s.RemoteHits + s.UpstreamFetches
+	if total == 0 {
+		return 0
+	}
+
+	return float32(s.LocalHits+s.RemoteHits) / float32(total)
+}
+
+type stats struct {
+	localHits          atomic.Int64
+	remoteHits         atomic.Int64
+	upstreamFetches    atomic.Int64
+	passThrough        atomic.Int64
+	uploads            atomic.Int64
+	skippedUploads     atomic.Int64
+	checksumMismatches atomic.Int64
+	errors             atomic.Int64
+	servedBytes        atomic.Int64
+}
+
+func (s *stats) get() Stats {
+	return Stats{
+		LocalHits:          s.localHits.Load(),
+		RemoteHits:         s.remoteHits.Load(),
+		UpstreamFetches:    s.upstreamFetches.Load(),
+		PassThrough:        s.passThrough.Load(),
+		Uploads:            s.uploads.Load(),
+		SkippedUploads:     s.skippedUploads.Load(),
+		ChecksumMismatches: s.checksumMismatches.Load(),
+		Errors:             s.errors.Load(),
+		ServedBytes:        s.servedBytes.Load(),
+	}
+}
@@ -145,11 +145,32 @@ func (c Config) EnsureFresh(ctx context.Context) (Credentials, error) {
 		// Exchange failed despite an unexpired JWT — fall through to a full refresh.
 	}
 
+	creds, err = c.refreshSession(ctx, creds)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	pat, expiry, err := c.exchangeJWTForPAT(ctx, creds.JWT)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("exchange refreshed token for a PAT: %w", err)
+	}
+	creds.PAT, creds.PATExpiry = pat, expiry
+	if err := save(creds); err != nil {
+		return Credentials{}, err
+	}
+	c.infof("Refreshed Bitrise access token.")
+
+	return creds, nil
+}
+
+// refreshSession runs the refresh-token grant and returns creds with the new
+// JWT (and the rotated refresh token, if any). The caller persists them.
+func (c Config) refreshSession(ctx context.Context, creds Credentials) (Credentials, error) {
 	if creds.RefreshToken == "" {
 		return Credentials{}, ErrLoginRequired
 	}
 	c.debugf("Refreshing the OAuth session")
-	now = time.Now() // re-anchor to just before the refresh exchange
+	now := time.Now() // anchor to just before the refresh exchange
 	refreshed, err := c.refreshJWT(ctx, creds.RefreshToken)
 	if err != nil {
 		return Credentials{}, fmt.Errorf("%w (refresh failed: %w)", ErrLoginRequired, err)
@@ -160,15 +181,5 @@ func (c Config) EnsureFresh(ctx context.Context) (Credentials, error) {
 		creds.RefreshToken = refreshed.RefreshToken
 	}
 
-	pat, expiry, err := c.exchangeJWTForPAT(ctx, creds.JWT)
-	if err != nil {
-		return Credentials{}, fmt.Errorf("exchange refreshed token for a PAT: %w", err)
-	}
-	creds.PAT, creds.PATExpiry = pat, expiry
-	if err := save(creds); err != nil {
-		return Credentials{}, err
-	}
-	c.infof("Refreshed Bitrise access token.")
-
 	return creds, nil
 }
@@ -22,8 +22,10 @@ type oauthMock struct {
 	server *httptest.Server
 
 	mu            sync.Mutex
-	tokenCalls    int // /oauth2/token (authorization_code + refresh)
-	exchangeCalls int // /oidc/token (JWT → PAT)
+	tokenCalls    int    // /oauth2/token (authorization_code + refresh)
+	exchangeCalls int    // /oidc/token (JWT → PAT or scoped token)
+	lastScope     string // scope of the last /oidc/token request
+	grantedScope  string // scope reported by /oidc/token; echoes the request when empty
 
 	jwt          string
 	refreshToken string
@@ -61,15 +63,25 @@ func newOAuthMock() *oauthMock {
 			"token_type":    "Bearer",
 		})
 	})
-	mux.HandleFunc("/oidc/token", func(w http.ResponseWriter, _ *http.Request) {
+	mux.HandleFunc("/oidc/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
 		m.mu.Lock()
 		m.exchangeCalls++
+		m.lastScope = r.FormValue("scope")
+		granted := m.grantedScope
 		m.mu.Unlock()
-		_ = json.NewEncoder(w).Encode(map[string]any{
+		if granted == "" {
+			granted = r.FormValue("scope")
+		}
+		resp := map[string]any{
 			"access_token": m.pat,
 			"token_type":   "bearer",
 			"expires_in":   m.patExpiresIn,
-		})
+		}
+		if granted != "" {
+			resp["scope"] = granted
+		}
+		_ = json.NewEncoder(w).Encode(resp)
 	})
 	m.server = httptest.NewServer(mux)
 
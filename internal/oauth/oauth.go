@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
 )
 
 // Identity defaults (production), each overridable per environment via the env
@@ -40,6 +42,7 @@ type Config struct {
 	Resource          string // audience/resource indicator pinned into the JWT
 	HTTPClient        *http.Client
 	Logger            log.Logger // optional; nil disables logging
+	ScopedTokenCache  string     // file caching minted scoped tokens; empty disables the cache
 }
 
 func (c Config) debugf(format string, args ...any) { //nolint:unparam // variadic for symmetry with infof/warnf and future callers
@@ -64,12 +67,17 @@ func (c Config) warnf(format string, args ...any) {
 // overridable via env (to target a non-prod environment):
 // BITRISE_OAUTH_ISSUER, BITRISE_OIDC_TOKEN_ENDPOINT, BITRISE_OAUTH_CLIENT_ID.
 func NewConfigFromEnv(envs map[string]string) Config {
-	return Config{
+	cfg := Config{
 		Issuer:            firstNonEmpty(envs["BITRISE_OAUTH_ISSUER"], DefaultIssuer),
 		OIDCTokenEndpoint: firstNonEmpty(envs["BITRISE_OIDC_TOKEN_ENDPOINT"], DefaultOIDCEndpoint),
 		ClientID:          firstNonEmpty(envs["BITRISE_OAUTH_CLIENT_ID"], DefaultClientID),
 		Resource:          DefaultResource,
 	}
+	if p, err := paths.Default(); err == nil {
+		cfg.ScopedTokenCache = p.ScopedTokenCacheFile()
+	}
+
+	return cfg
 }
 
 func firstNonEmpty(values ...string) string {
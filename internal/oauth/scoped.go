@@ -0,0 +1,172 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scope is the access a minted cache token grants.
+type Scope string
+
+const (
+	// ScopeRead can only read from the cache — for pull-request builds and
+	// developer machines.
+	ScopeRead Scope = "read"
+	// ScopeReadWrite can also push to the cache — for trusted pipelines.
+	ScopeReadWrite Scope = "read-write"
+)
+
+// scopedTokenLifetime is the fallback lifetime when the exchange omits
+// expires_in; kept short since a scoped token is minted per need.
+const scopedTokenLifetime = 15 * time.Minute
+
+// ErrInvalidScope is returned by ParseScope for an unknown scope.
+var ErrInvalidScope = errors.New("invalid token scope")
+
+// ErrScopeNotGranted is returned when the OIDC exchange grants a scope other
+// than the one requested, or does not say which scope it granted.
+var ErrScopeNotGranted = errors.New("scoped token exchange did not grant the requested scope")
+
+// ParseScope parses a --scope value.
+func ParseScope(raw string) (Scope, error) {
+	switch s := Scope(strings.TrimSpace(raw)); s {
+	case ScopeRead, ScopeReadWrite:
+		return s, nil
+	default:
+		return "", fmt.Errorf("%w %q: must be %q or %q", ErrInvalidScope, raw, ScopeRead, ScopeReadWrite)
+	}
+}
+
+// oauthScope is the space-separated scope requested from the OIDC exchange.
+// The scope names are not confirmed against the auth server yet. If it names
+// them differently, checkGrantedScope fails the exchange rather than
+// accepting a token of unknown reach.
+func (s Scope) oauthScope(workspaceID string) string {
+	scopes := []string{"build_cache:read"}
+	if s == ScopeReadWrite {
+		scopes = append(scopes, "build_cache:write")
+	}
+
+	return strings.Join(append(scopes, "workspace:"+workspaceID), " ")
+}
+
+// checkGrantedScope fails unless granted names exactly the scopes in
+// requested. An omitted scope is rejected too: the token must be known to be
+// narrowed, not assumed to be.
+func checkGrantedScope(requested, granted string) error {
+	want := strings.Fields(requested)
+	got := strings.Fields(granted)
+	slices.Sort(want)
+	slices.Sort(got)
+	got = slices.Compact(got)
+	if !slices.Equal(want, got) {
+		return fmt.Errorf("%w: requested %q, granted %q", ErrScopeNotGranted, requested, granted)
+	}
+
+	return nil
+}
+
+// ScopedToken is a short-lived cache token limited to one workspace and scope.
+type ScopedToken struct {
+	Token       string    `json:"token"`
+	WorkspaceID string    `json:"workspaceId"`
+	Scope       Scope     `json:"scope"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+func (t ScopedToken) validAt(now time.Time) bool {
+	return t.Token != "" && now.Add(refreshSkew).Before(t.Expiry)
+}
+
+// ScopedToken returns a short-lived token for workspaceID (the login's
+// workspace when empty) limited to scope, minted from the stored OAuth login:
+//
+//	unexpired token in the cache → return it
+//	JWT valid                    → exchange JWT → scoped token
+//	+ JWT expired                → refresh-token grant → new JWT → scoped token
+//
+// Unlike EnsureFresh it never touches the stored PAT. Returns ErrNotLoggedIn
+// when no OAuth credential is stored and ErrLoginRequired when the refresh
+// token is rejected.
+func (c Config) ScopedToken(ctx context.Context, workspaceID string, scope Scope) (ScopedToken, error) {
+	creds, src, err := LoadWithSource()
+	if err != nil {
+		return ScopedToken{}, err
+	}
+	if !creds.IsOAuthManaged() {
+		return ScopedToken{}, ErrNotLoggedIn
+	}
+	if workspaceID == "" {
+		workspaceID = creds.WorkspaceID
+	}
+	if workspaceID == "" {
+		return ScopedToken{}, errors.New("no workspace: pass one, or sign in again with 'bitrise-build-cache auth login --workspace <slug>'")
+	}
+
+	cache := tokenCache{path: c.ScopedTokenCache}
+	if cached, ok := cache.get(workspaceID, scope, time.Now()); ok {
+		c.debugf("Using cached %s token for workspace %s", scope, workspaceID)
+
+		return cached, nil
+	}
+
+	if creds.JWT == "" || !time.Now().Add(refreshSkew).Before(creds.JWTExpiry) {
+		creds, err = c.refreshSession(ctx, creds)
+		if err != nil {
+			return ScopedToken{}, err
+		}
+		if src != nil {
+			err = SaveTo(src, creds)
+		} else {
+			err = Save(creds)
+		}
+		if err != nil {
+			return ScopedToken{}, err
+		}
+	}
+
+	token, err := c.exchangeJWTForScopedToken(ctx, creds.JWT, workspaceID, scope)
+	if err != nil {
+		return ScopedToken{}, err
+	}
+	if err := cache.put(token, time.Now()); err != nil {
+		c.debugf("Cache scoped token: %s", err)
+	}
+	c.debugf("Minted %s token for workspace %s, valid until %s", scope, workspaceID, token.Expiry.Format(time.RFC3339))
+
+	return token, nil
+}
+
+// exchangeJWTForScopedToken is exchangeJWTForPAT narrowed with the RFC 8693
+// scope parameter to one workspace and the requested access. The scope the
+// response reports must match the request, so a broader token is never used.
+func (c Config) exchangeJWTForScopedToken(ctx context.Context, jwt, workspaceID string, scope Scope) (ScopedToken, error) {
+	requested := scope.oauthScope(workspaceID)
+	resp, err := c.postForm(ctx, c.OIDCTokenEndpoint, url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"subject_token":        {jwt},
+		"subject_token_type":   {"urn:ietf:params:oauth:token-type:access_token"},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"scope":                {requested},
+	})
+	if err != nil {
+		return ScopedToken{}, err
+	}
+	if resp.AccessToken == "" {
+		return ScopedToken{}, fmt.Errorf("OIDC exchange response missing access_token")
+	}
+	if err := checkGrantedScope(requested, resp.Scope); err != nil {
+		return ScopedToken{}, err
+	}
+	expiry := time.Now().Add(scopedTokenLifetime)
+	if resp.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	return ScopedToken{Token: resp.AccessToken, WorkspaceID: workspaceID, Scope: scope, Expiry: expiry}, nil
+}
@@ -0,0 +1,180 @@
+//go:build unit
+
+package oauth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseScope(t *testing.T) {
+	for _, raw := range []string{"read", "read-write"} {
+		if s, err := ParseScope(raw); err != nil || string(s) != raw {
+			t.Fatalf("ParseScope(%q) = %q, %v", raw, s, err)
+		}
+	}
+	if _, err := ParseScope("write"); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope, got %v", err)
+	}
+}
+
+func TestScopedToken_MintsAndCaches(t *testing.T) {
+	resetKeychain(t)
+	if err := Save(Credentials{
+		PAT: "stored-pat", PATExpiry: time.Now().Add(time.Hour),
+		JWT: "good-jwt", JWTExpiry: time.Now().Add(time.Hour),
+		RefreshToken: "r", WorkspaceID: "ws",
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	m := newOAuthMock()
+	defer m.close()
+	m.pat = "scoped-read"
+	cfg := m.config()
+	cfg.ScopedTokenCache = filepath.Join(t.TempDir(), "scoped-tokens.json")
+
+	got, err := cfg.ScopedToken(context.Background(), "", ScopeRead)
+	if err != nil {
+		t.Fatalf("ScopedToken: %v", err)
+	}
+	if got.Token != "scoped-read" || got.WorkspaceID != "ws" || got.Scope != ScopeRead {
+		t.Fatalf("got %+v", got)
+	}
+	if m.lastScope != "build_cache:read workspace:ws" {
+		t.Fatalf("scope = %q", m.lastScope)
+	}
+
+	// served from the cache: no second exchange
+	if again, err := cfg.ScopedToken(context.Background(), "ws", ScopeRead); err != nil || again.Token != "scoped-read" {
+		t.Fatalf("cached ScopedToken = %+v, %v", again, err)
+	}
+	if _, ec := m.counts(); ec != 1 {
+		t.Fatalf("expected 1 exchange, got %d", ec)
+	}
+
+	// another scope is minted separately
+	m.pat = "scoped-write"
+	if rw, err := cfg.ScopedToken(context.Background(), "ws", ScopeReadWrite); err != nil || rw.Token != "scoped-write" {
+		t.Fatalf("read-write ScopedToken = %+v, %v", rw, err)
+	}
+	if m.lastScope != "build_cache:read build_cache:write workspace:ws" {
+		t.Fatalf("scope = %q", m.lastScope)
+	}
+
+	info, err := os.Stat(cfg.ScopedTokenCache)
+	if err != nil {
+		t.Fatalf("stat cache: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("cache mode = %o, want 600", perm)
+	}
+	if saved, _ := Load(); saved.PAT != "stored-pat" {
+		t.Fatalf("stored PAT must be untouched, got %q", saved.PAT)
+	}
+
+	if err := cfg.ClearScopedTokens(); err != nil {
+		t.Fatalf("ClearScopedTokens: %v", err)
+	}
+	if _, err := os.Stat(cfg.ScopedTokenCache); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("cache not removed: %v", err)
+	}
+}
+
+func TestScopedToken_ExpiredJWT_RefreshesFirst(t *testing.T) {
+	resetKeychain(t)
+	if err := Save(Credentials{
+		PAT: "stored-pat", PATExpiry: time.Now().Add(time.Hour),
+		JWT: "old-jwt", JWTExpiry: time.Now().Add(-time.Minute),
+		RefreshToken: "refresh-old", WorkspaceID: "ws",
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	m := newOAuthMock()
+	defer m.close()
+	m.refreshToken = "refresh-rotated"
+
+	if _, err := m.config().ScopedToken(context.Background(), "", ScopeRead); err != nil {
+		t.Fatalf("ScopedToken: %v", err)
+	}
+	if tc, ec := m.counts(); tc != 1 || ec != 1 {
+		t.Fatalf("expected 1 refresh + 1 exchange; got token=%d exchange=%d", tc, ec)
+	}
+	saved, _ := Load()
+	if saved.RefreshToken != "refresh-rotated" || saved.PAT != "stored-pat" {
+		t.Fatalf("got %+v", saved)
+	}
+}
+
+func TestScopedToken_BroaderGrantIsRejected(t *testing.T) {
+	for name, granted := range map[string]string{
+		"write added":     "build_cache:read build_cache:write workspace:ws",
+		"other workspace": "build_cache:read workspace:other",
+		"no workspace":    "build_cache:read",
+	} {
+		t.Run(name, func(t *testing.T) {
+			resetKeychain(t)
+			if err := Save(Credentials{
+				PAT: "stored-pat", PATExpiry: time.Now().Add(time.Hour),
+				JWT: "good-jwt", JWTExpiry: time.Now().Add(time.Hour),
+				RefreshToken: "r", WorkspaceID: "ws",
+			}); err != nil {
+				t.Fatalf("seed: %v", err)
+			}
+			m := newOAuthMock()
+			defer m.close()
+			m.grantedScope = granted
+			cfg := m.config()
+			cfg.ScopedTokenCache = filepath.Join(t.TempDir(), "scoped-tokens.json")
+
+			if _, err := cfg.ScopedToken(context.Background(), "ws", ScopeRead); !errors.Is(err, ErrScopeNotGranted) {
+				t.Fatalf("expected ErrScopeNotGranted, got %v", err)
+			}
+			if _, err := os.Stat(cfg.ScopedTokenCache); !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("rejected token must not be cached: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckGrantedScope(t *testing.T) {
+	if err := checkGrantedScope("build_cache:read workspace:ws", "workspace:ws build_cache:read"); err != nil {
+		t.Fatalf("reordered grant: %v", err)
+	}
+	if err := checkGrantedScope("build_cache:read workspace:ws", ""); !errors.Is(err, ErrScopeNotGranted) {
+		t.Fatalf("omitted grant: expected ErrScopeNotGranted, got %v", err)
+	}
+}
+
+func TestScopedToken_NotLoggedIn(t *testing.T) {
+	resetKeychain(t)
+	m := newOAuthMock()
+	defer m.close()
+
+	if _, err := m.config().ScopedToken(context.Background(), "ws", ScopeRead); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("expected ErrNotLoggedIn, got %v", err)
+	}
+}
+
+func TestTokenCache_DropsExpired(t *testing.T) {
+	cache := tokenCache{path: filepath.Join(t.TempDir(), "scoped-tokens.json")}
+	now := time.Now()
+
+	expired := ScopedToken{Token: "old", WorkspaceID: "a", Scope: ScopeRead, Expiry: now.Add(-time.Minute)}
+	if err := cache.put(expired, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, ok := cache.get("a", ScopeRead, now); ok {
+		t.Fatal("expired token must not be served")
+	}
+
+	if err := cache.put(ScopedToken{Token: "new", WorkspaceID: "b", Scope: ScopeRead, Expiry: now.Add(time.Hour)}, now); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if got := cache.load(); len(got) != 1 || got[0].Token != "new" {
+		t.Fatalf("expired token not pruned: %+v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tokenCache keeps the unexpired scoped tokens in a 0600 JSON file so
+// repeated helper invocations don't mint a token each. An empty path
+// disables the cache.
+type tokenCache struct {
+	path string
+}
+
+type tokenCacheFile struct {
+	Tokens []ScopedToken `json:"tokens"`
+}
+
+func (tc tokenCache) get(workspaceID string, scope Scope, now time.Time) (ScopedToken, bool) {
+	for _, t := range tc.load() {
+		if t.WorkspaceID == workspaceID && t.Scope == scope && t.validAt(now) {
+			return t, true
+		}
+	}
+
+	return ScopedToken{}, false
+}
+
+// put stores token, replacing the one for the same workspace and scope and
+// dropping the expired ones.
+func (tc tokenCache) put(token ScopedToken, now time.Time) error {
+	if tc.path == "" {
+		return nil
+	}
+
+	kept := []ScopedToken{token}
+	for _, t := range tc.load() {
+		if (t.WorkspaceID != token.WorkspaceID || t.Scope != token.Scope) && t.validAt(now) {
+			kept = append(kept, t)
+		}
+	}
+
+	return tc.write(tokenCacheFile{Tokens: kept})
+}
+
+// load returns the cached tokens; an unreadable cache reads as empty.
+func (tc tokenCache) load() []ScopedToken {
+	if tc.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(tc.path)
+	if err != nil {
+		return nil
+	}
+
+	var file tokenCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil
+	}
+
+	return file.Tokens
+}
+
+func (tc tokenCache) write(file tokenCacheFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("encode token cache: %w", err)
+	}
+
+	dir := filepath.Dir(tc.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create token cache dir: %w", err)
+	}
+
+	// CreateTemp creates the file 0600, so the tokens are never world-readable.
+	tmp, err := os.CreateTemp(dir, ".scoped-tokens-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("write token cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close token cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, tc.path); err != nil {
+		return fmt.Errorf("replace token cache: %w", err)
+	}
+
+	return nil
+}
+
+// ClearScopedTokens removes the cached scoped tokens, e.g. on logout.
+func (c Config) ClearScopedTokens() error {
+	if c.ScopedTokenCache == "" {
+		return nil
+	}
+	if err := os.Remove(c.ScopedTokenCache); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove token cache: %w", err)
+	}
+
+	return nil
+}
@@ -36,12 +36,18 @@ const (
 	// CcacheSocketName is the ccache IPC unix-socket filename (lives under the OS temp dir).
 	CcacheSocketName = "ccache-ipc.sock"
 
+	// BazelProxySocketName is the Bazel remote-cache proxy unix-socket filename (lives under the OS temp dir).
+	BazelProxySocketName = "bazel-proxy.sock"
+
 	// xcelerateStateRelative is the per-user xcelerate state root.
 	xcelerateStateRelative = ".local/state/xcelerate"
 
 	// xcelerateLogsSubdir is the per-user xcelerate log dir under XcelerateStateDir.
 	xcelerateLogsSubdir = "logs"
 
+	// xcelerateJournalsSubdir holds the per-session key-level proxy journals read by `xcelerate report`.
+	xcelerateJournalsSubdir = "journals"
+
 	// xcelerateEnrichmentSubdir holds every persisted-state artefact the
 	// enrichment watcher, retry queue, and slim/handled-marker bookkeeping share.
 	xcelerateEnrichmentSubdir = "enrichment"
@@ -66,14 +72,26 @@ const (
 
 	pendingInvocationsFilename = "pending-invocations.ndjson"
 
+	// gradleTaskReportsSubdir holds the per-build NDJSON task reports written by the gradle init script.
+	gradleTaskReportsSubdir = "gradle-task-reports"
+
 	enrichmentHealthFilename = "health.json"
 
 	// bitriseBinSubdir holds the stable CLI binary copy used by the daemon supervisor.
 	bitriseBinSubdir = "bin"
 
+	// gradleMirrorsConfigFile declares the user-defined Gradle mirrors under BitriseRoot.
+	gradleMirrorsConfigFile = "gradle-mirrors.json"
+
+	// scopedTokenCacheFile caches the short-lived scoped tokens minted from the OAuth login under BitriseRoot.
+	scopedTokenCacheFile = "scoped-tokens.json"
+
 	// bitriseCacheSubdir is the per-tool cache/marker root used by activate, refresh, and child-stats.
 	bitriseCacheSubdir = "cache"
 
+	// localBlobsSubdir holds a tool's on-disk L1 blob cache under BitriseCacheDir(tool).
+	localBlobsSubdir = "local-blobs"
+
 	// xcelerateBinSubdir holds the xcelerate wrapper scripts (xcodebuild / xcrun) and CLI copy.
 	xcelerateBinSubdir = "bin"
 
@@ -150,6 +168,11 @@ func (p Paths) InvocationsFile(day string) string {
 	return filepath.Join(p.InvocationsDir(), day+".ndjson")
 }
 
+// GradleTaskReportsDir returns the directory the gradle init script writes task reports into.
+func (p Paths) GradleTaskReportsDir() string {
+	return filepath.Join(p.StateDir(), gradleTaskReportsSubdir)
+}
+
 func (p Paths) PendingInvocationsFile() string {
 	return filepath.Join(p.XcelerateEnrichmentDir(), pendingInvocationsFilename)
 }
@@ -193,6 +216,16 @@ func (p Paths) BitriseBinFile(name string) string {
 	return filepath.Join(p.BitriseBinDir(), name)
 }
 
+// GradleMirrorsConfigFile returns ~/.bitrise/gradle-mirrors.json.
+func (p Paths) GradleMirrorsConfigFile() string {
+	return filepath.Join(p.BitriseRoot(), gradleMirrorsConfigFile)
+}
+
+// ScopedTokenCacheFile returns ~/.bitrise/scoped-tokens.json.
+func (p Paths) ScopedTokenCacheFile() string {
+	return filepath.Join(p.BitriseRoot(), scopedTokenCacheFile)
+}
+
 // BitriseCacheDir is the per-tool cache/marker dir under ~/.bitrise/cache.
 func (p Paths) BitriseCacheDir(tool string) string {
 	return filepath.Join(p.BitriseRoot(), bitriseCacheSubdir, tool)
@@ -203,6 +236,11 @@ func (p Paths) BitriseCacheFile(tool, name string) string {
 	return filepath.Join(p.BitriseCacheDir(tool), name)
 }
 
+// LocalBlobCacheDir is the default on-disk L1 blob cache dir for a tool (~/.bitrise/cache/<tool>/local-blobs).
+func (p Paths) LocalBlobCacheDir(tool string) string {
+	return filepath.Join(p.BitriseCacheDir(tool), localBlobsSubdir)
+}
+
 // XcelerateRoot is the absolute path of ~/.bitrise-xcelerate.
 func (p Paths) XcelerateRoot() string {
 	return filepath.Join(p.Home, XcelerateRootRelative)
@@ -233,6 +271,11 @@ func (p Paths) CcacheSocketPath(tempDir string) string {
 	return filepath.Join(tempDir, CcacheSocketName)
 }
 
+// BazelProxySocketPath returns the Bazel remote-cache proxy unix-socket path under the supplied temp dir.
+func (p Paths) BazelProxySocketPath(tempDir string) string {
+	return filepath.Join(tempDir, BazelProxySocketName)
+}
+
 // XcelerateStateDir returns ~/.local/state/xcelerate.
 func (p Paths) XcelerateStateDir() string {
 	return filepath.Join(p.Home, xcelerateStateRelative)
@@ -243,6 +286,11 @@ func (p Paths) XcelerateLogDir() string {
 	return filepath.Join(p.XcelerateStateDir(), xcelerateLogsSubdir)
 }
 
+// XcelerateJournalDir returns ~/.local/state/xcelerate/journals.
+func (p Paths) XcelerateJournalDir() string {
+	return filepath.Join(p.XcelerateStateDir(), xcelerateJournalsSubdir)
+}
+
 // XcelerateHandledInvocationDir returns ~/.local/state/xcelerate/enrichment/handled-invocations.
 func (p Paths) XcelerateHandledInvocationDir() string {
 	return filepath.Join(p.XcelerateEnrichmentDir(), xcelerateHandledInvocationsSubdir)
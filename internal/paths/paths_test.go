@@ -38,8 +38,10 @@ func TestPaths_bitriseRoot(t *testing.T) {
 	assert.Equal(t, "/h/.bitrise", p.BitriseRoot())
 	assert.Equal(t, "/h/.bitrise/bin", p.BitriseBinDir())
 	assert.Equal(t, "/h/.bitrise/bin/bitrise-build-cache", p.BitriseBinFile("bitrise-build-cache"))
+	assert.Equal(t, "/h/.bitrise/gradle-mirrors.json", p.GradleMirrorsConfigFile())
 	assert.Equal(t, "/h/.bitrise/cache/ccache", p.BitriseCacheDir("ccache"))
 	assert.Equal(t, "/h/.bitrise/cache/reactnative/config.json", p.BitriseCacheFile("reactnative", "config.json"))
+	assert.Equal(t, "/h/.bitrise/cache/xcelerate/local-blobs", p.LocalBlobCacheDir("xcelerate"))
 }
 
 func TestPaths_xcelerate(t *testing.T) {
@@ -55,12 +57,14 @@ func TestPaths_proxySocket(t *testing.T) {
 	p := FromHome("/h")
 
 	assert.Equal(t, "/tmp/xcelerate-proxy.sock", p.ProxySocketPath("/tmp"))
+	assert.Equal(t, "/tmp/bazel-proxy.sock", p.BazelProxySocketPath("/tmp"))
 }
 
 func TestPaths_invocations(t *testing.T) {
 	p := FromHome("/h")
 
 	assert.Equal(t, "/h/.local/state/bitrise-build-cache/invocations", p.InvocationsDir())
+	assert.Equal(t, "/h/.local/state/bitrise-build-cache/gradle-task-reports", p.GradleTaskReportsDir())
 	assert.Equal(t, "/h/.local/state/bitrise-build-cache/invocations/2026-06-25.ndjson",
 		p.InvocationsFile("2026-06-25"))
 }
@@ -77,6 +81,7 @@ func TestPaths_xcelerateHandledInvocations(t *testing.T) {
 
 	assert.Equal(t, "/h/.local/state/xcelerate", p.XcelerateStateDir())
 	assert.Equal(t, "/h/.local/state/xcelerate/logs", p.XcelerateLogDir())
+	assert.Equal(t, "/h/.local/state/xcelerate/journals", p.XcelerateJournalDir())
 	assert.Equal(t, "/h/.local/state/xcelerate/enrichment/handled-invocations", p.XcelerateHandledInvocationDir())
 	assert.Equal(t, "/h/.local/state/xcelerate/enrichment/handled-invocations/abc-123", p.XcelerateHandledInvocationFile("abc-123"))
 }
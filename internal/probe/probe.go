@@ -0,0 +1,95 @@
+// Package probe checks whether the local helper services (xcelerate proxy,
+// ccache storage helper, Bazel proxy, Gradle cache connector) accept
+// connections.
+package probe
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/ccache"
+)
+
+// State is the outcome of a probe.
+type State string
+
+const (
+	// Running means the service accepted a connection.
+	Running State = "running"
+	// Stopped means nothing is listening: no socket file or a refused connection.
+	Stopped State = "stopped"
+	// Stuck means the socket file exists but the service does not respond.
+	Stuck State = "stuck"
+
+	// Timeout bounds every probe.
+	Timeout = 500 * time.Millisecond
+)
+
+// TCP probes a loopback listener. A refused connection means the service is
+// not running; there is no socket file to tell "stopped" and "stuck" apart.
+func TCP(address string) State {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Stopped
+	}
+	_ = conn.Close()
+
+	return Running
+}
+
+// Socket probes a unix socket by dialing it.
+func Socket(logger log.Logger, path string) State {
+	if !socketFileExists(logger, path) {
+		return Stopped
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path)
+	if err != nil {
+		return Stuck
+	}
+	_ = conn.Close()
+
+	return Running
+}
+
+// CcacheSocket uses the ccache protocol's health-check exchange so the
+// storage helper sees a clean handshake — a raw dial+close would surface as
+// "Capabilities check failed" in the helper's log, and CI asserts on those.
+func CcacheSocket(logger log.Logger, path string) State {
+	if !socketFileExists(logger, path) {
+		return Stopped
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	if err := ccache.SendHealthCheck(ctx, path); err != nil {
+		return Stuck
+	}
+
+	return Running
+}
+
+func socketFileExists(logger log.Logger, path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Debugf("probe: stat %s failed: %v", path, err)
+		}
+
+		return false
+	}
+
+	return true
+}
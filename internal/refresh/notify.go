@@ -20,6 +20,14 @@ func CurrentConfigVersions() map[toolconfig.Tool]string {
 	}
 }
 
+// IsOutdated reports whether a tool's stored config version is a major
+// version behind the CLI, i.e. whether Notify nudges for it.
+func IsOutdated(tool toolconfig.Tool, stored string) bool {
+	want, ok := CurrentConfigVersions()[tool]
+
+	return ok && needsNudge(stored, want)
+}
+
 func activateCommand(t toolconfig.Tool) string {
 	switch t {
 	case toolconfig.Gradle:
@@ -2,6 +2,7 @@ package analytics
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -13,6 +14,20 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 )
 
+// ErrorClassCorruptedBlob classifies failures caused by cache entries whose
+// content did not match their digest.
+const ErrorClassCorruptedBlob = "corrupted_blob"
+
+// ErrorClass returns the class of err reported to analytics, or "" when it has
+// no distinct class.
+func ErrorClass(err error) string {
+	if errors.Is(err, kv.ErrCorruptedBlob) {
+		return ErrorClassCorruptedBlob
+	}
+
+	return ""
+}
+
 func NewCacheOperation(startT time.Time, operationType string, metadata *common.CacheConfigMetadata) *CacheOperation {
 	op := &CacheOperation{
 		OperationID:   uuid.NewString(),
@@ -61,6 +76,7 @@ func (c *Client) PutCacheOperation(op *CacheOperation) error {
 
 func (op *CacheOperation) FillWithUploadStats(stats kv.UploadFilesStats) {
 	op.TransferSize = stats.UploadSize
+	op.setCacheEndpoint(stats.Endpoint)
 	op.FileStats = FileStats{
 		FilesToTransfer:  stats.FilesToUpload,
 		FilesTransferred: stats.FilesUploaded,
@@ -72,11 +88,29 @@ func (op *CacheOperation) FillWithUploadStats(stats kv.UploadFilesStats) {
 
 func (op *CacheOperation) FillWithDownloadStats(stats kv.DownloadFilesStats) {
 	op.TransferSize = stats.DownloadSize
+	op.setCacheEndpoint(stats.Endpoint)
 	op.FileStats = FileStats{
 		FilesToTransfer:  stats.FilesToBeDownloaded,
 		FilesTransferred: stats.FilesDownloaded,
 		FilesFailed:      stats.FilesFailedToDownload,
 		FilesMissing:     stats.FilesMissing,
 		TotalFiles:       stats.FilesToBeDownloaded,
+		FilesCorrupted:   stats.FilesCorrupted,
+	}
+}
+
+// FillWithError records err along with its class.
+func (op *CacheOperation) FillWithError(err error) {
+	errStr := err.Error()
+	op.Error = &errStr
+
+	if class := ErrorClass(err); class != "" {
+		op.ErrorClass = &class
+	}
+}
+
+func (op *CacheOperation) setCacheEndpoint(endpoint string) {
+	if endpoint != "" {
+		op.CacheEndpoint = &endpoint
 	}
 }
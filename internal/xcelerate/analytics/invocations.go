@@ -22,6 +22,8 @@ type InvocationRunStats struct {
 	Error            error
 	XcodeVersion     string
 	XcodeBuildNumber string
+	Degraded         bool
+	CorruptedBlobs   int64
 }
 
 func NewInvocation(runStats InvocationRunStats, authMetadata common.CacheAuthConfig, commonMetadata common.CacheConfigMetadata) *Invocation {
@@ -65,6 +67,8 @@ func NewInvocation(runStats InvocationRunStats, authMetadata common.CacheAuthCon
 		ExternalBuildID:      commonMetadata.ExternalBuildID,
 		ExternalWorkflowName: commonMetadata.ExternalWorkflowName,
 		BenchmarkPhase:       commonMetadata.BenchmarkPhase,
+		Degraded:             runStats.Degraded,
+		CorruptedBlobs:       runStats.CorruptedBlobs,
 	}
 }
 
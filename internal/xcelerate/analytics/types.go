@@ -13,6 +13,8 @@ type FileStats struct {
 	FilesFailed      int `json:"filesFailed"`
 	FilesMissing     int `json:"filesMissing"`
 	TotalFiles       int `json:"totalFiles"`
+	// FilesCorrupted counts files that did not match their digest, included in FilesMissing.
+	FilesCorrupted int `json:"filesCorrupted,omitempty"`
 }
 
 type CacheOperation struct {
@@ -24,6 +26,7 @@ type CacheOperation struct {
 	CacheKey             string    `json:"cacheKey"`
 	CacheKeyType         *string   `json:"cacheKeyType,omitempty"`
 	Error                *string   `json:"error,omitempty"`
+	ErrorClass           *string   `json:"errorClass,omitempty"`
 	CIProvider           string    `json:"ciProvider"`
 	ProjectID            *string   `json:"projectId,omitempty"`
 	BuildID              *string   `json:"buildId,omitempty"`
@@ -33,6 +36,7 @@ type CacheOperation struct {
 	WorkflowID           *string   `json:"workflowId,omitempty"`
 	WorkflowTitle        *string   `json:"workflowTitle,omitempty"`
 	CLIVersion           string    `json:"cliVersion"`
+	CacheEndpoint        *string   `json:"cacheEndpoint,omitempty"`
 	FileStats            FileStats `json:"fileStats"`
 }
 
@@ -71,4 +75,6 @@ type Invocation struct {
 	ExternalBuildID      string            `json:"externalBuildId,omitempty"`
 	ExternalWorkflowName string            `json:"externalWorkflowName,omitempty"`
 	BenchmarkPhase       string            `json:"benchmarkPhase,omitempty"`
+	Degraded             bool              `json:"degraded,omitempty"`
+	CorruptedBlobs       int64             `json:"corruptedBlobs,omitempty"`
 }
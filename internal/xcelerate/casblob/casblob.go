@@ -0,0 +1,186 @@
+// Package casblob implements the container format the xcelerate proxy stores
+// LLVM CAS objects in: a versioned header carrying the reference list,
+// followed by the raw payload. See docs/xcelerate-cas-blob-format.md.
+package casblob
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Version is the format version written by NewReader.
+const Version = 1
+
+const (
+	// MaxReferences bounds the reference count accepted by Decode.
+	MaxReferences = 1 << 20
+	// MaxReferenceSize bounds the length of a single reference accepted by Decode.
+	MaxReferenceSize = 4096
+)
+
+// Magic starts every entry. 0x89 can never begin a gob stream, which is how
+// Decode tells entries apart from the legacy gob encoding.
+//
+//nolint:gochecknoglobals
+var Magic = [4]byte{0x89, 'B', 'C', 'B'}
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported cas blob format version")
+	ErrMalformed          = errors.New("malformed cas blob")
+)
+
+// legacyBlob is the gob-encoded layout written before the container format.
+type legacyBlob struct {
+	Data       []byte
+	References [][]byte
+}
+
+// NewReader returns the encoded form of an entry. The payload is read through
+// ReadAt on demand, so large objects (e.g. an *os.File) are never buffered in
+// memory. The returned reader is seekable and reports the encoded size.
+func NewReader(references [][]byte, payload io.ReaderAt, payloadSize int64) *io.SectionReader {
+	header := encodeHeader(references, payloadSize)
+
+	return io.NewSectionReader(
+		concatReaderAt{header: header, payload: payload},
+		0,
+		int64(len(header))+payloadSize,
+	)
+}
+
+// Decode reads a single entry from r, streams its payload into payload and
+// returns its references. Legacy gob entries are decoded transparently.
+func Decode(r io.Reader, payload io.Writer) ([][]byte, error) {
+	br := bufio.NewReader(r)
+
+	first, err := br.Peek(1)
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if first[0] != Magic[0] {
+		return decodeLegacy(br, payload)
+	}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return nil, fmt.Errorf("read magic: %w", err)
+	}
+	if magic != Magic {
+		return nil, fmt.Errorf("%w: bad magic %x", ErrMalformed, magic)
+	}
+
+	version, err := br.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("read version: %w", err)
+	}
+	if version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	references, err := readReferences(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var payloadSize uint64
+	if err := binary.Read(br, binary.BigEndian, &payloadSize); err != nil {
+		return nil, fmt.Errorf("read payload size: %w", err)
+	}
+
+	//nolint:gosec
+	n, err := io.CopyN(payload, br, int64(payloadSize))
+	if err != nil {
+		return nil, fmt.Errorf("read payload (%d of %d bytes): %w", n, payloadSize, err)
+	}
+
+	return references, nil
+}
+
+func encodeHeader(references [][]byte, payloadSize int64) []byte {
+	size := len(Magic) + 1 + 4 + 8
+	for _, ref := range references {
+		size += 4 + len(ref)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	buf.Write(Magic[:])
+	buf.WriteByte(Version)
+	//nolint:gosec
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(references)))
+	for _, ref := range references {
+		//nolint:gosec
+		_ = binary.Write(buf, binary.BigEndian, uint32(len(ref)))
+		buf.Write(ref)
+	}
+	//nolint:gosec
+	_ = binary.Write(buf, binary.BigEndian, uint64(payloadSize))
+
+	return buf.Bytes()
+}
+
+func readReferences(r io.Reader) ([][]byte, error) {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, fmt.Errorf("read reference count: %w", err)
+	}
+	if count > MaxReferences {
+		return nil, fmt.Errorf("%w: %d references", ErrMalformed, count)
+	}
+
+	references := make([][]byte, 0, count)
+	for i := range count {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return nil, fmt.Errorf("read reference %d size: %w", i, err)
+		}
+		if size > MaxReferenceSize {
+			return nil, fmt.Errorf("%w: reference %d is %d bytes", ErrMalformed, i, size)
+		}
+
+		ref := make([]byte, size)
+		if _, err := io.ReadFull(r, ref); err != nil {
+			return nil, fmt.Errorf("read reference %d: %w", i, err)
+		}
+		references = append(references, ref)
+	}
+
+	return references, nil
+}
+
+func decodeLegacy(r io.Reader, payload io.Writer) ([][]byte, error) {
+	var data legacyBlob
+	if err := gob.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode legacy gob entry: %w", err)
+	}
+
+	if _, err := payload.Write(data.Data); err != nil {
+		return nil, fmt.Errorf("write payload: %w", err)
+	}
+
+	return data.References, nil
+}
+
+// concatReaderAt serves the in-memory header followed by the payload.
+type concatReaderAt struct {
+	header  []byte
+	payload io.ReaderAt
+}
+
+func (c concatReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	if off < int64(len(c.header)) {
+		n = copy(p, c.header[off:])
+		if n == len(p) {
+			return n, nil
+		}
+	}
+
+	m, err := c.payload.ReadAt(p[n:], off+int64(n)-int64(len(c.header)))
+
+	return n + m, err //nolint:wrapcheck
+}
@@ -0,0 +1,113 @@
+//go:build unit
+
+package casblob_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/casblob"
+)
+
+func TestRoundTrip(t *testing.T) {
+	refs := [][]byte{[]byte("ref-1"), {}, []byte("ref-3")}
+	payload := []byte("payload bytes")
+
+	encoded := casblob.NewReader(refs, bytes.NewReader(payload), int64(len(payload)))
+	raw, err := io.ReadAll(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, encoded.Size(), int64(len(raw)))
+	assert.Equal(t, casblob.Magic[:], raw[:4])
+	assert.Equal(t, byte(casblob.Version), raw[4])
+
+	var out bytes.Buffer
+	gotRefs, err := casblob.Decode(bytes.NewReader(raw), &out)
+	require.NoError(t, err)
+	assert.Equal(t, refs, gotRefs)
+	assert.Equal(t, payload, out.Bytes())
+}
+
+func TestNewReader_StreamsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payload")
+	payload := bytes.Repeat([]byte("0123456789"), 10_000)
+	require.NoError(t, os.WriteFile(path, payload, 0o600))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	encoded := casblob.NewReader(nil, file, int64(len(payload)))
+
+	// the reader can be consumed twice (hash, then upload)
+	first, err := io.ReadAll(encoded)
+	require.NoError(t, err)
+	_, err = encoded.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	second, err := io.ReadAll(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	var out bytes.Buffer
+	refs, err := casblob.Decode(bytes.NewReader(first), &out)
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+	assert.Equal(t, payload, out.Bytes())
+}
+
+func TestDecode_LegacyGob(t *testing.T) {
+	// mirrors the struct the proxy gob-encoded before the container format
+	type blob struct {
+		Data       []byte
+		References [][]byte
+	}
+
+	var raw bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&raw).Encode(&blob{
+		Data:       []byte("legacy payload"),
+		References: [][]byte{[]byte("legacy-ref")},
+	}))
+
+	var out bytes.Buffer
+	refs, err := casblob.Decode(&raw, &out)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("legacy-ref")}, refs)
+	assert.Equal(t, "legacy payload", out.String())
+}
+
+func TestDecode_Errors(t *testing.T) {
+	valid, err := io.ReadAll(casblob.NewReader([][]byte{[]byte("ref")}, bytes.NewReader([]byte("data")), 4))
+	require.NoError(t, err)
+
+	t.Run("unsupported version", func(t *testing.T) {
+		raw := bytes.Clone(valid)
+		raw[4] = 99
+
+		_, err := casblob.Decode(bytes.NewReader(raw), io.Discard)
+		require.ErrorIs(t, err, casblob.ErrUnsupportedVersion)
+	})
+
+	t.Run("bad magic", func(t *testing.T) {
+		raw := bytes.Clone(valid)
+		raw[1] = 'X'
+
+		_, err := casblob.Decode(bytes.NewReader(raw), io.Discard)
+		require.ErrorIs(t, err, casblob.ErrMalformed)
+	})
+
+	t.Run("truncated payload", func(t *testing.T) {
+		_, err := casblob.Decode(bytes.NewReader(valid[:len(valid)-2]), io.Discard)
+		require.Error(t, err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := casblob.Decode(bytes.NewReader(nil), io.Discard)
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
@@ -1,35 +1,27 @@
 package deriveddata
 
 import (
-	"fmt"
-	"runtime"
-
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/cachekey"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 )
 
+const (
+	DefaultCacheKeyTemplate         = "xcode-cache-metadata-{{ .App }}-{{ .Branch }}-{{ .OS }}"
+	DefaultFallbackCacheKeyTemplate = "xcode-cache-metadata-{{ .App }}-{{ .OS }}"
+)
+
+// CacheKeyParams configures the cache key templates. Empty templates fall back
+// to DefaultCacheKeyTemplate and DefaultFallbackCacheKeyTemplate.
 type CacheKeyParams struct {
-	IsFallback bool
+	Templates   cachekey.Templates
+	CommandFunc common.CommandFunc
 }
 
-func GetCacheKey(envs map[string]string, keyParams CacheKeyParams) (string, error) {
-	branch := envs["BITRISE_GIT_BRANCH"]
-	if branch == "" && !keyParams.IsFallback {
-		return "", fmt.Errorf("cache key is required if BITRISE_GIT_BRANCH env var is not set")
-	}
-	// Branches can contain '/', which would split the kv/<key> resource name
-	// into extra segments and collapse the per-OS keys onto one another.
-	branch = common.SanitizeCacheKeyComponent(branch)
-
-	appSlug := envs["BITRISE_APP_SLUG"]
-	if appSlug == "" {
-		return "", fmt.Errorf("cache key is required if BITRISE_APP_SLUG env var is not set")
-	}
-
-	os := runtime.GOOS
-
-	if keyParams.IsFallback {
-		return fmt.Sprintf("xcode-cache-metadata-%s-%s", appSlug, os), nil
-	}
+func GetCacheKeys(envs map[string]string, keyParams CacheKeyParams) (cachekey.Keys, error) {
+	templates := keyParams.Templates.WithDefaults(cachekey.Templates{
+		Primary:   DefaultCacheKeyTemplate,
+		Fallbacks: []string{DefaultFallbackCacheKeyTemplate},
+	})
 
-	return fmt.Sprintf("xcode-cache-metadata-%s-%s-%s", appSlug, branch, os), nil
+	return templates.Render(cachekey.NewVars(envs, keyParams.CommandFunc))
 }
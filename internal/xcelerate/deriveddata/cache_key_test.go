@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetCacheKeySanitizesBranchSlash(t *testing.T) {
@@ -15,7 +16,8 @@ func TestGetCacheKeySanitizesBranchSlash(t *testing.T) {
 		"BITRISE_GIT_BRANCH": "renovate/all-non-major-updates",
 	}
 
-	key, err := GetCacheKey(envs, CacheKeyParams{})
+	keys, err := GetCacheKeys(envs, CacheKeyParams{})
+	key := keys.Primary
 
 	assert.NoError(t, err)
 	assert.NotContains(t, key, "/", "cache key must not contain '/'")
@@ -28,7 +30,9 @@ func TestGetCacheKeyFallbackHasNoBranch(t *testing.T) {
 		"BITRISE_GIT_BRANCH": "renovate/all-non-major-updates",
 	}
 
-	key, err := GetCacheKey(envs, CacheKeyParams{IsFallback: true})
+	keys, err := GetCacheKeys(envs, CacheKeyParams{})
+	require.Len(t, keys.Fallbacks, 1)
+	key := keys.Fallbacks[0]
 
 	assert.NoError(t, err)
 	assert.Equal(t, "xcode-cache-metadata-app-slug-"+runtime.GOOS, key)
@@ -0,0 +1,143 @@
+package deriveddata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+)
+
+// IncrementalRestoreStats summarizes an incremental restore: Restored files were
+// downloaded, Skipped ones were already up to date on disk and Removed ones were
+// stale files not listed in the metadata.
+type IncrementalRestoreStats struct {
+	Restored int
+	Skipped  int
+	Removed  int
+}
+
+// PlanIncrementalRestore compares the files of fgi with what is already on disk
+// and returns the file group of the files that have to be downloaded, along with
+// the number of up to date files. A file is up to date when its size and
+// modification time match; when only the modification time differs the digest
+// is compared and, if it matches, the modification time is restored instead.
+func PlanIncrementalRestore(fgi filegroup.Info, logger log.Logger) (filegroup.Info, int) {
+	toDownload := filegroup.Info{
+		Directories: fgi.Directories,
+		Symlinks:    fgi.Symlinks,
+	}
+	skipped := 0
+
+	for _, fi := range fgi.Files {
+		if isUpToDate(fi, logger) {
+			skipped++
+
+			continue
+		}
+		toDownload.Files = append(toDownload.Files, fi)
+	}
+
+	logger.Infof("(i) %d files are up to date, %d files to download", skipped, len(toDownload.Files))
+
+	return toDownload, skipped
+}
+
+func isUpToDate(fi *filegroup.FileInfo, logger log.Logger) bool {
+	stat, err := os.Lstat(fi.Path)
+	if err != nil || !stat.Mode().IsRegular() || stat.Size() != fi.Size {
+		return false
+	}
+
+	if !stat.ModTime().Equal(fi.ModTime) {
+		checksum, err := hash.ChecksumOfFile(fi.Path)
+		if err != nil {
+			logger.Debugf("Error hashing file %s: %v", fi.Path, err)
+
+			return false
+		}
+		if checksum != fi.Hash {
+			return false
+		}
+
+		if err := os.Chtimes(fi.Path, fi.ModTime, fi.ModTime); err != nil {
+			logger.Debugf("Error setting modification time for %s: %v", fi.Path, err)
+
+			return false
+		}
+	}
+
+	if fi.Mode != 0 && stat.Mode().Perm() != fi.Mode.Perm() {
+		if err := os.Chmod(fi.Path, fi.Mode); err != nil {
+			logger.Debugf("Error setting file mode for %s: %v", fi.Path, err)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// RemoveStaleFiles deletes the regular files in the directories of fgi that are
+// not listed in it, e.g. outputs of a build that the cached one did not produce.
+// Unlisted subdirectories and SourcePackages (possibly skipped on save) are left
+// untouched.
+func RemoveStaleFiles(fgi filegroup.Info, logger log.Logger) int {
+	known := make(map[string]bool, len(fgi.Files)+len(fgi.Symlinks))
+	for _, fi := range fgi.Files {
+		known[filepath.Clean(fi.Path)] = true
+	}
+	for _, si := range fgi.Symlinks {
+		known[filepath.Clean(si.Path)] = true
+	}
+
+	removed := 0
+	for _, dir := range fgi.Directories {
+		if isInSourcePackages(dir.Path) {
+			continue
+		}
+
+		entries, err := os.ReadDir(dir.Path)
+		if err != nil {
+			logger.Debugf("Failed to list directory %s: %s", dir.Path, err)
+
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+
+			path := filepath.Join(dir.Path, entry.Name())
+			if known[path] {
+				continue
+			}
+
+			if err := os.Remove(path); err != nil {
+				logger.Infof("Failed to remove stale file %s: %s", path, err)
+
+				continue
+			}
+			logger.Debugf("Removed stale file %s", path)
+			removed++
+		}
+	}
+
+	logger.Infof("(i) Removed %d stale files", removed)
+
+	return removed
+}
+
+func isInSourcePackages(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == "SourcePackages" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package deriveddata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+)
+
+func writeFileInfo(t *testing.T, path, content string, modTime time.Time) *filegroup.FileInfo {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	checksum, err := hash.ChecksumOfFile(path)
+	require.NoError(t, err)
+
+	return &filegroup.FileInfo{Path: path, Size: int64(len(content)), Hash: checksum, ModTime: modTime, Mode: 0o600}
+}
+
+func TestPlanIncrementalRestore(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	unchanged := writeFileInfo(t, filepath.Join(dir, "unchanged.o"), "same", modTime)
+
+	touched := writeFileInfo(t, filepath.Join(dir, "touched.o"), "same content", modTime)
+	require.NoError(t, os.Chtimes(touched.Path, time.Now(), time.Now()))
+
+	modified := writeFileInfo(t, filepath.Join(dir, "modified.o"), "old!", modTime)
+	require.NoError(t, os.WriteFile(modified.Path, []byte("new!"), 0o600))
+
+	resized := writeFileInfo(t, filepath.Join(dir, "resized.o"), "short", modTime)
+	require.NoError(t, os.WriteFile(resized.Path, []byte("much longer"), 0o600))
+
+	missing := &filegroup.FileInfo{Path: filepath.Join(dir, "missing.o"), Size: 1, Hash: "abc", ModTime: modTime}
+
+	toDownload, skipped := PlanIncrementalRestore(filegroup.Info{
+		Files:       []*filegroup.FileInfo{unchanged, touched, modified, resized, missing},
+		Directories: []*filegroup.DirectoryInfo{{Path: dir, ModTime: modTime}},
+	}, setupTests())
+
+	assert.Equal(t, 2, skipped)
+	assert.Equal(t, []*filegroup.FileInfo{modified, resized, missing}, toDownload.Files)
+	assert.Len(t, toDownload.Directories, 1)
+
+	stat, err := os.Stat(touched.Path)
+	require.NoError(t, err)
+	assert.True(t, stat.ModTime().Equal(modTime), "modification time of a file with matching digest is restored")
+}
+
+func TestRemoveStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	kept := writeFileInfo(t, filepath.Join(dir, "Build", "kept.o"), "kept", modTime)
+	stale := writeFileInfo(t, filepath.Join(dir, "Build", "stale.o"), "stale", modTime)
+	spm := writeFileInfo(t, filepath.Join(dir, "SourcePackages", "checkouts", "Package.swift"), "spm", modTime)
+	unlisted := writeFileInfo(t, filepath.Join(dir, "Build", "Unlisted", "other.o"), "other", modTime)
+
+	removed := RemoveStaleFiles(filegroup.Info{
+		Files: []*filegroup.FileInfo{kept},
+		Directories: []*filegroup.DirectoryInfo{
+			{Path: dir},
+			{Path: filepath.Join(dir, "Build")},
+			{Path: filepath.Join(dir, "SourcePackages")},
+			{Path: filepath.Join(dir, "SourcePackages", "checkouts")},
+		},
+	}, setupTests())
+
+	assert.Equal(t, 1, removed)
+	assert.FileExists(t, kept.Path)
+	assert.NoFileExists(t, stale.Path)
+	assert.FileExists(t, spm.Path)
+	assert.FileExists(t, unlisted.Path)
+}
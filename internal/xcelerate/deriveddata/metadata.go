@@ -15,7 +15,12 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/filegroup"
 )
 
-const metadataVersion = 1
+const (
+	metadataVersion = 1
+	// chunkedMetadataVersion marks metadata with chunked files, which older CLIs
+	// cannot restore (they report those files as missing).
+	chunkedMetadataVersion = 2
+)
 
 type Metadata struct {
 	ProjectFiles         filegroup.Info `json:"projectFiles"`
@@ -39,6 +44,9 @@ type CreateMetadataParams struct {
 	CacheKey           string
 	FollowSymlinks     bool
 	SkipSPM            bool
+	// ChunkLargeFiles stores large DerivedData and Xcode cache files as
+	// content-defined chunks, so a small change does not re-upload the whole file.
+	ChunkLargeFiles bool
 }
 
 func CreateMetadata(params CreateMetadataParams, envs map[string]string, logger log.Logger) (*Metadata, error) {
@@ -79,22 +87,30 @@ func CreateMetadata(params CreateMetadataParams, envs map[string]string, logger
 		}
 	}
 
+	version := metadataVersion
+	if params.ChunkLargeFiles {
+		if err := filegroup.ChunkLargeFiles(&derivedData, filegroup.DefaultChunkingThreshold, logger); err != nil {
+			return nil, fmt.Errorf("chunk derived data files: %w", err)
+		}
+		if err := filegroup.ChunkLargeFiles(&xcodeCacheDir, filegroup.DefaultChunkingThreshold, logger); err != nil {
+			return nil, fmt.Errorf("chunk xcode cache dir files: %w", err)
+		}
+		version = chunkedMetadataVersion
+	}
+
+	ciKeys := common.CIProviderEnvKeys(common.DetectCIProvider(envs))
 	m := Metadata{
 		ProjectFiles:         projectFiles,
 		DerivedData:          derivedData,
 		XcodeCacheDir:        xcodeCacheDir,
 		CacheKey:             params.CacheKey,
 		CreatedAt:            time.Now(),
-		AppID:                envs["BITRISE_APP_SLUG"],
-		BuildID:              envs["BITRISE_BUILD_SLUG"],
-		GitCommit:            envs["BITRISE_GIT_COMMIT"],
-		GitBranch:            envs["BITRISE_GIT_BRANCH"],
+		AppID:                common.LookupEnv(envs, ciKeys.App),
+		BuildID:              common.LookupEnv(envs, ciKeys.Build),
+		GitCommit:            common.LookupEnv(envs, ciKeys.Commit),
+		GitBranch:            common.LookupEnv(envs, ciKeys.Branch),
 		BuildCacheCLIVersion: common.GetCLIVersion(logger),
-		MetadataVersion:      metadataVersion,
-	}
-
-	if m.GitCommit == "" {
-		m.GitCommit = envs["GIT_CLONE_COMMIT_HASH"]
+		MetadataVersion:      version,
 	}
 
 	return &m, nil
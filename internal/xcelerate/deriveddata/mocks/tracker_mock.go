@@ -26,6 +26,9 @@ var _ deriveddata.StepAnalyticsTracker = &StepAnalyticsTrackerMock{}
 //			LogDerivedDataUploadedFunc: func(duration time.Duration, stats kv.UploadFilesStats)  {
 //				panic("mock out the LogDerivedDataUploaded method")
 //			},
+//			LogIncrementalRestoreFunc: func(duration time.Duration, stats deriveddata.IncrementalRestoreStats)  {
+//				panic("mock out the LogIncrementalRestore method")
+//			},
 //			LogMetadataLoadedFunc: func(duration time.Duration, cacheKeyType string, totalFileCount int, restoredFileCount int, size int64)  {
 //				panic("mock out the LogMetadataLoaded method")
 //			},
@@ -54,6 +57,9 @@ type StepAnalyticsTrackerMock struct {
 	// LogDerivedDataUploadedFunc mocks the LogDerivedDataUploaded method.
 	LogDerivedDataUploadedFunc func(duration time.Duration, stats kv.UploadFilesStats)
 
+	// LogIncrementalRestoreFunc mocks the LogIncrementalRestore method.
+	LogIncrementalRestoreFunc func(duration time.Duration, stats deriveddata.IncrementalRestoreStats)
+
 	// LogMetadataLoadedFunc mocks the LogMetadataLoaded method.
 	LogMetadataLoadedFunc func(duration time.Duration, cacheKeyType string, totalFileCount int, restoredFileCount int, size int64)
 
@@ -85,6 +91,13 @@ type StepAnalyticsTrackerMock struct {
 			// Stats is the stats argument value.
 			Stats kv.UploadFilesStats
 		}
+		// LogIncrementalRestore holds details about calls to the LogIncrementalRestore method.
+		LogIncrementalRestore []struct {
+			// Duration is the duration argument value.
+			Duration time.Duration
+			// Stats is the stats argument value.
+			Stats deriveddata.IncrementalRestoreStats
+		}
 		// LogMetadataLoaded holds details about calls to the LogMetadataLoaded method.
 		LogMetadataLoaded []struct {
 			// Duration is the duration argument value.
@@ -127,6 +140,7 @@ type StepAnalyticsTrackerMock struct {
 	}
 	lockLogDerivedDataDownloaded sync.RWMutex
 	lockLogDerivedDataUploaded   sync.RWMutex
+	lockLogIncrementalRestore    sync.RWMutex
 	lockLogMetadataLoaded        sync.RWMutex
 	lockLogMetadataSaved         sync.RWMutex
 	lockLogRestoreFinished       sync.RWMutex
@@ -206,6 +220,42 @@ func (mock *StepAnalyticsTrackerMock) LogDerivedDataUploadedCalls() []struct {
 	return calls
 }
 
+// LogIncrementalRestore calls LogIncrementalRestoreFunc.
+func (mock *StepAnalyticsTrackerMock) LogIncrementalRestore(duration time.Duration, stats deriveddata.IncrementalRestoreStats) {
+	if mock.LogIncrementalRestoreFunc == nil {
+		panic("StepAnalyticsTrackerMock.LogIncrementalRestoreFunc: method is nil but StepAnalyticsTracker.LogIncrementalRestore was just called")
+	}
+	callInfo := struct {
+		Duration time.Duration
+		Stats    deriveddata.IncrementalRestoreStats
+	}{
+		Duration: duration,
+		Stats:    stats,
+	}
+	mock.lockLogIncrementalRestore.Lock()
+	mock.calls.LogIncrementalRestore = append(mock.calls.LogIncrementalRestore, callInfo)
+	mock.lockLogIncrementalRestore.Unlock()
+	mock.LogIncrementalRestoreFunc(duration, stats)
+}
+
+// LogIncrementalRestoreCalls gets all the calls that were made to LogIncrementalRestore.
+// Check the length with:
+//
+//	len(mockedStepAnalyticsTracker.LogIncrementalRestoreCalls())
+func (mock *StepAnalyticsTrackerMock) LogIncrementalRestoreCalls() []struct {
+	Duration time.Duration
+	Stats    deriveddata.IncrementalRestoreStats
+} {
+	var calls []struct {
+		Duration time.Duration
+		Stats    deriveddata.IncrementalRestoreStats
+	}
+	mock.lockLogIncrementalRestore.RLock()
+	calls = mock.calls.LogIncrementalRestore
+	mock.lockLogIncrementalRestore.RUnlock()
+	return calls
+}
+
 // LogMetadataLoaded calls LogMetadataLoadedFunc.
 func (mock *StepAnalyticsTrackerMock) LogMetadataLoaded(duration time.Duration, cacheKeyType string, totalFileCount int, restoredFileCount int, size int64) {
 	if mock.LogMetadataLoadedFunc == nil {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	xa "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/analytics"
 )
 
 //go:generate moq -out mocks/tracker_mock.go -pkg mocks . StepAnalyticsTracker
@@ -18,6 +19,7 @@ type StepAnalyticsTracker interface {
 	LogSaveFinished(totalDuration time.Duration, err error)
 	LogMetadataLoaded(duration time.Duration, cacheKeyType string, totalFileCount int, restoredFileCount int, size int64)
 	LogDerivedDataDownloaded(duration time.Duration, stats kv.DownloadFilesStats)
+	LogIncrementalRestore(duration time.Duration, stats IncrementalRestoreStats)
 	LogRestoreFinished(totalDuration time.Duration, err error)
 
 	Wait()
@@ -63,6 +65,14 @@ func (t *DefaultStepAnalyticsTracker) LogDerivedDataUploaded(duration time.Durat
 		"total_files":             stats.TotalFiles,
 		"upload_size_bytes":       stats.UploadSize,
 		"largest_file_size_bytes": stats.LargestFileSize,
+		"retry_attempts":          stats.RetryAttempts,
+		"resumed_bytes":           stats.ResumedBytes,
+		"cache_endpoint":          stats.Endpoint,
+		"concurrency":             stats.Concurrency,
+		"min_concurrency":         stats.MinConcurrency,
+		"concurrency_backoffs":    stats.ConcurrencyBackoffs,
+		"bandwidth_limit_bps":     stats.BandwidthLimit,
+		"bandwidth_wait_ms":       stats.BandwidthWait.Milliseconds(),
 	})
 	t.tracker.Enqueue("step_save_xcode_build_cache_derived_data_uploaded", properties)
 }
@@ -95,18 +105,40 @@ func (t *DefaultStepAnalyticsTracker) LogDerivedDataDownloaded(duration time.Dur
 		"files_downloaded":        stats.FilesDownloaded,
 		"files_missing":           stats.FilesMissing,
 		"files_failed":            stats.FilesFailedToDownload,
+		"files_corrupted":         stats.FilesCorrupted,
 		"download_size_bytes":     stats.DownloadSize,
 		"largest_file_size_bytes": stats.LargestFileSize,
+		"retry_attempts":          stats.RetryAttempts,
+		"resumed_bytes":           stats.ResumedBytes,
+		"cache_endpoint":          stats.Endpoint,
+		"concurrency":             stats.Concurrency,
+		"min_concurrency":         stats.MinConcurrency,
+		"concurrency_backoffs":    stats.ConcurrencyBackoffs,
+		"bandwidth_limit_bps":     stats.BandwidthLimit,
+		"bandwidth_wait_ms":       stats.BandwidthWait.Milliseconds(),
 	})
 	t.tracker.Enqueue("step_restore_xcode_build_cache_derived_data_downloaded", properties)
 }
 
+func (t *DefaultStepAnalyticsTracker) LogIncrementalRestore(duration time.Duration, stats IncrementalRestoreStats) {
+	properties := t.propertiesWithCLIVersion().Merge(analytics.Properties{
+		"duration_ms":    duration.Milliseconds(),
+		"files_restored": stats.Restored,
+		"files_skipped":  stats.Skipped,
+		"files_removed":  stats.Removed,
+	})
+	t.tracker.Enqueue("step_restore_xcode_build_cache_incremental_restore", properties)
+}
+
 func (t *DefaultStepAnalyticsTracker) LogRestoreFinished(totalDuration time.Duration, err error) {
 	properties := t.propertiesWithCLIVersion().Merge(analytics.Properties{
 		"total_duration_ms": totalDuration.Milliseconds(),
 	})
 	if err != nil {
 		properties["error"] = err.Error()
+		if class := xa.ErrorClass(err); class != "" {
+			properties["error_class"] = class
+		}
 	}
 	t.tracker.Enqueue("step_restore_xcode_build_cache_finished", properties)
 }
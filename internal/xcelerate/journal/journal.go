@@ -0,0 +1,275 @@
+// Package journal records the key-level calls of an xcelerate proxy session, so
+// `xcelerate report` can explain which compilation keys missed and why a build
+// was slow. Each session is one NDJSON file named after its invocation ID.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxEvents bounds the events recorded per session, later ones are dropped.
+	DefaultMaxEvents = 100_000
+	// DefaultMaxJournals is the number of session journals kept on disk.
+	DefaultMaxJournals = 50
+
+	fileExtension = ".ndjson"
+)
+
+// Outcome is the result of a journaled call.
+type Outcome string
+
+const (
+	OutcomeHit    Outcome = "hit"
+	OutcomeMiss   Outcome = "miss"
+	OutcomeUpload Outcome = "upload"
+	// OutcomeError is a read that failed for another reason than a missing or
+	// corrupted entry, e.g. a network error.
+	OutcomeError Outcome = "error"
+)
+
+// MissReason is why a read missed.
+type MissReason string
+
+const (
+	ReasonNotFound MissReason = "not_found"
+	// ReasonCorrupted is an entry whose content did not match its digest.
+	ReasonCorrupted MissReason = "corrupted"
+)
+
+// Event is a single proxy call.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Key     string    `json:"key"`
+	Outcome Outcome   `json:"outcome"`
+	// Reason is why a miss missed, empty for other outcomes.
+	Reason MissReason `json:"reason,omitempty"`
+	// Error is the failure of an OutcomeError call.
+	Error string `json:"error,omitempty"`
+	// Size is the number of bytes downloaded or uploaded, 0 for misses.
+	Size int64 `json:"size,omitempty"`
+	// LatencyUS is the duration of the call in microseconds.
+	LatencyUS int64 `json:"latency_us"`
+}
+
+// Latency returns the duration of the call.
+func (e Event) Latency() time.Duration {
+	return time.Duration(e.LatencyUS) * time.Microsecond
+}
+
+// Writer appends events to the journal of a session. It is safe for concurrent
+// use, and a nil *Writer is valid and records nothing.
+type Writer struct {
+	mutex     sync.Mutex
+	file      *os.File
+	buffer    *bufio.Writer
+	maxEvents int
+	events    int
+	dropped   int
+}
+
+// Path returns the journal file of invocationID in dir.
+func Path(dir, invocationID string) string {
+	return filepath.Join(dir, invocationID+fileExtension)
+}
+
+// Create starts the journal of invocationID in dir, keeping at most maxEvents
+// events (DefaultMaxEvents when not positive). Journals beyond
+// DefaultMaxJournals are pruned, oldest first.
+func Create(dir, invocationID string, maxEvents int) (*Writer, error) {
+	if invocationID == "" || strings.ContainsAny(invocationID, `/\`) {
+		return nil, fmt.Errorf("invalid invocation ID %q", invocationID)
+	}
+	if maxEvents <= 0 {
+		maxEvents = DefaultMaxEvents
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create journal dir: %w", err)
+	}
+
+	// the new journal is not created yet, so keep one less
+	if _, err := Prune(dir, DefaultMaxJournals-1); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(Path(dir, invocationID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+
+	//nolint:exhaustruct
+	return &Writer{
+		file:      file,
+		buffer:    bufio.NewWriter(file),
+		maxEvents: maxEvents,
+	}, nil
+}
+
+// Record appends e to the journal. Events beyond the limit are counted but not
+// written.
+func (w *Writer) Record(e Event) error {
+	if w == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal journal event: %w", err)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	if w.events >= w.maxEvents {
+		w.dropped++
+
+		return nil
+	}
+	w.events++
+
+	if _, err := w.buffer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write journal event: %w", err)
+	}
+
+	return nil
+}
+
+// Dropped returns the number of events not written because of the limit.
+func (w *Writer) Dropped() int {
+	if w == nil {
+		return 0
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.dropped
+}
+
+// Close flushes and closes the journal. Later events are ignored.
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	flushErr := w.buffer.Flush()
+	closeErr := w.file.Close()
+	w.file = nil
+
+	if flushErr != nil {
+		return fmt.Errorf("flush journal: %w", flushErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close journal: %w", closeErr)
+	}
+
+	return nil
+}
+
+// Read returns the events of a journal file. Malformed lines, e.g. one cut
+// short by a crash, are skipped.
+func Read(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+
+	return events, nil
+}
+
+// Entry is a journal on disk.
+type Entry struct {
+	InvocationID string
+	Path         string
+	ModTime      time.Time
+}
+
+// List returns the journals in dir, oldest first.
+func List(dir string) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read journal dir: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasSuffix(name, fileExtension) {
+			continue
+		}
+
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, Entry{
+			InvocationID: strings.TrimSuffix(name, fileExtension),
+			Path:         filepath.Join(dir, name),
+			ModTime:      info.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ModTime.Before(entries[j].ModTime) })
+
+	return entries, nil
+}
+
+// Prune removes the oldest journals in dir so at most keep remain. It returns
+// the number of removed journals.
+func Prune(dir string, keep int) (int, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for len(entries)-removed > max(keep, 0) {
+		if err := os.Remove(entries[removed].Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, fmt.Errorf("remove journal: %w", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,88 @@
+//go:build unit
+
+package journal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/journal"
+)
+
+func TestWriter_RecordAndRead(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := journal.Create(dir, "inv-1", 2)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, w.Record(journal.Event{Time: now, Method: "Get", Key: "a", Outcome: journal.OutcomeHit, Size: 10, LatencyUS: 5}))
+	require.NoError(t, w.Record(journal.Event{Time: now, Method: "Get", Key: "b", Outcome: journal.OutcomeMiss, LatencyUS: 7}))
+	require.NoError(t, w.Record(journal.Event{Time: now, Method: "Get", Key: "c", Outcome: journal.OutcomeMiss}))
+	assert.Equal(t, 1, w.Dropped())
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Record(journal.Event{Key: "after-close"}))
+
+	events, err := journal.Read(journal.Path(dir, "inv-1"))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "a", events[0].Key)
+	assert.Equal(t, int64(10), events[0].Size)
+	assert.Equal(t, 5*time.Microsecond, events[0].Latency())
+	assert.Equal(t, journal.OutcomeMiss, events[1].Outcome)
+}
+
+func TestWriter_NilIsNoOp(t *testing.T) {
+	var w *journal.Writer
+
+	require.NoError(t, w.Record(journal.Event{Key: "a"}))
+	assert.Zero(t, w.Dropped())
+	require.NoError(t, w.Close())
+}
+
+func TestCreate_RejectsInvalidInvocationID(t *testing.T) {
+	_, err := journal.Create(t.TempDir(), "../escape", 0)
+	require.Error(t, err)
+}
+
+func TestRead_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inv.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte(`{"key":"a","outcome":"hit"}`+"\n"+`{"key":"trunc`), 0o600))
+
+	events, err := journal.Read(path)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "a", events[0].Key)
+}
+
+func TestPrune_KeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"old", "mid", "new"} {
+		path := journal.Path(dir, id)
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	removed, err := journal.Prune(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	entries, err := journal.List(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "mid", entries[0].InvocationID)
+	assert.Equal(t, "new", entries[1].InvocationID)
+}
+
+func TestList_MissingDir(t *testing.T) {
+	entries, err := journal.List(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
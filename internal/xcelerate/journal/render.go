@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+)
+
+// WriteJSON writes the report as a single JSON document.
+func WriteJSON(out io.Writer, report Report) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("encode report JSON: %w", err)
+	}
+
+	return nil
+}
+
+// WriteText pretty-prints the report as tables.
+func WriteText(out io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	p := &printer{out: tw}
+
+	p.line("Invocation: %s", report.InvocationID)
+	p.totals(report.Totals)
+
+	p.line("")
+	p.line("Top missed keys:")
+	p.line("MISSES\tCORRUPTED\tMETHOD\tKEY")
+	for _, km := range report.TopMisses {
+		p.line("%d\t%d\t%s\t%s", km.Misses, km.Corrupted, km.Method, km.Key)
+	}
+
+	p.line("")
+	p.line("Largest downloads:")
+	p.line("SIZE\tMETHOD\tKEY")
+	for _, e := range report.LargestDownloads {
+		p.line("%s\t%s\t%s", humanizeSize(e.Size), e.Method, e.Key)
+	}
+
+	p.line("")
+	p.line("Slowest calls:")
+	p.line("LATENCY\tOUTCOME\tMETHOD\tKEY")
+	for _, e := range report.SlowestCalls {
+		p.line("%s\t%s\t%s\t%s", e.Latency(), e.Outcome, e.Method, e.Key)
+	}
+
+	if len(report.Errors) > 0 {
+		p.line("")
+		p.line("Errors:")
+		p.line("METHOD\tKEY\tERROR")
+		for _, e := range report.Errors {
+			p.line("%s\t%s\t%s", e.Method, e.Key, e.Error)
+		}
+	}
+
+	p.line("")
+	if prev := report.Previous; prev != nil {
+		p.line("Previous invocation: %s", prev.InvocationID)
+		p.totals(prev.Totals)
+		p.line("Hit rate change: %+.1f pp", (report.Totals.HitRate()-prev.Totals.HitRate())*100)
+		p.line("Keys hit previously but missed now: %d", prev.NewMissesTotal)
+		for _, key := range prev.NewMisses {
+			p.line("  %s", key)
+		}
+	} else {
+		p.line("Previous invocation: none found")
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flush report: %w", err)
+	}
+
+	return nil
+}
+
+type printer struct {
+	out io.Writer
+	err error
+}
+
+func (p *printer) line(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+
+	if _, err := fmt.Fprintf(p.out, format+"\n", args...); err != nil {
+		p.err = fmt.Errorf("write report: %w", err)
+	}
+}
+
+func (p *printer) totals(t Totals) {
+	p.line("Calls: %d (%d hits, %d misses of which %d corrupted, %d uploads, %d errors)",
+		t.Calls, t.Hits, t.Misses, t.Corrupted, t.Uploads, t.Errors)
+	p.line("Hit rate: %.1f%%", t.HitRate()*100)
+	p.line("Downloaded: %s, uploaded: %s", humanizeSize(t.DownloadBytes), humanizeSize(t.UploadBytes))
+}
+
+func humanizeSize(n int64) string {
+	return humanize.Bytes(uint64(max(n, 0))) //nolint:gosec
+}
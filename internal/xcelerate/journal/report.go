@@ -0,0 +1,177 @@
+package journal
+
+import (
+	"sort"
+)
+
+// KeyMisses is a key with the number of times it missed.
+type KeyMisses struct {
+	Key    string `json:"key"`
+	Method string `json:"method"`
+	Misses int    `json:"misses"`
+	// Corrupted counts the misses caused by a corrupted entry.
+	Corrupted int `json:"corrupted"`
+}
+
+// Totals are the aggregate counters of a journal.
+type Totals struct {
+	Calls         int   `json:"calls"`
+	Hits          int   `json:"hits"`
+	Misses        int   `json:"misses"`
+	Corrupted     int   `json:"corrupted"`
+	Uploads       int   `json:"uploads"`
+	Errors        int   `json:"errors"`
+	DownloadBytes int64 `json:"download_bytes"`
+	UploadBytes   int64 `json:"upload_bytes"`
+}
+
+// HitRate returns hits over reads, 0 without reads.
+func (t Totals) HitRate() float64 {
+	if t.Hits+t.Misses == 0 {
+		return 0
+	}
+
+	return float64(t.Hits) / float64(t.Hits+t.Misses)
+}
+
+// Comparison relates a journal to the one of a previous invocation.
+type Comparison struct {
+	InvocationID string `json:"invocation_id"`
+	Totals       Totals `json:"totals"`
+	// NewMisses are keys that hit in the previous invocation but missed now.
+	NewMisses      []string `json:"new_misses"`
+	NewMissesTotal int      `json:"new_misses_total"`
+}
+
+// Report explains the cache behaviour of an invocation.
+type Report struct {
+	InvocationID     string      `json:"invocation_id"`
+	Totals           Totals      `json:"totals"`
+	TopMisses        []KeyMisses `json:"top_misses"`
+	LargestDownloads []Event     `json:"largest_downloads"`
+	SlowestCalls     []Event     `json:"slowest_calls"`
+	Errors           []Event     `json:"errors"`
+	Previous         *Comparison `json:"previous,omitempty"`
+}
+
+// NewReport summarizes events, listing at most top entries per ranking. A
+// negative top lists none.
+func NewReport(invocationID string, events []Event, top int) Report {
+	report := Report{
+		InvocationID:     invocationID,
+		Totals:           totalsOf(events),
+		TopMisses:        topMisses(events, top),
+		LargestDownloads: topEvents(events, top, func(e Event) bool { return e.Outcome == OutcomeHit }, func(a, b Event) bool { return a.Size > b.Size }),
+		SlowestCalls:     topEvents(events, top, func(Event) bool { return true }, func(a, b Event) bool { return a.LatencyUS > b.LatencyUS }),
+		Errors:           topEvents(events, top, func(e Event) bool { return e.Outcome == OutcomeError }, func(a, b Event) bool { return a.Time.Before(b.Time) }),
+		Previous:         nil,
+	}
+
+	return report
+}
+
+// Compare relates the events of an invocation to the ones of a previous
+// invocation, listing at most top newly missed keys.
+func Compare(events []Event, previousInvocationID string, previous []Event, top int) Comparison {
+	hitBefore := make(map[string]bool)
+	for _, e := range previous {
+		if e.Outcome == OutcomeHit {
+			hitBefore[e.Key] = true
+		}
+	}
+
+	comparison := Comparison{
+		InvocationID:   previousInvocationID,
+		Totals:         totalsOf(previous),
+		NewMisses:      []string{},
+		NewMissesTotal: 0,
+	}
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if e.Outcome != OutcomeMiss || !hitBefore[e.Key] || seen[e.Key] {
+			continue
+		}
+		seen[e.Key] = true
+
+		comparison.NewMissesTotal++
+		if len(comparison.NewMisses) < max(top, 0) {
+			comparison.NewMisses = append(comparison.NewMisses, e.Key)
+		}
+	}
+
+	return comparison
+}
+
+func totalsOf(events []Event) Totals {
+	var totals Totals
+	for _, e := range events {
+		totals.Calls++
+		switch e.Outcome {
+		case OutcomeHit:
+			totals.Hits++
+			totals.DownloadBytes += e.Size
+		case OutcomeMiss:
+			totals.Misses++
+			if e.Reason == ReasonCorrupted {
+				totals.Corrupted++
+			}
+		case OutcomeUpload:
+			totals.Uploads++
+			totals.UploadBytes += e.Size
+		case OutcomeError:
+			totals.Errors++
+		}
+	}
+
+	return totals
+}
+
+func topMisses(events []Event, top int) []KeyMisses {
+	byKey := make(map[string]*KeyMisses)
+	for _, e := range events {
+		if e.Outcome != OutcomeMiss {
+			continue
+		}
+
+		km, ok := byKey[e.Key]
+		if !ok {
+			km = &KeyMisses{Key: e.Key, Method: e.Method, Misses: 0, Corrupted: 0}
+			byKey[e.Key] = km
+		}
+		km.Misses++
+		if e.Reason == ReasonCorrupted {
+			km.Corrupted++
+		}
+	}
+
+	misses := make([]KeyMisses, 0, len(byKey))
+	for _, km := range byKey {
+		misses = append(misses, *km)
+	}
+	sort.Slice(misses, func(i, j int) bool {
+		if misses[i].Misses != misses[j].Misses {
+			return misses[i].Misses > misses[j].Misses
+		}
+
+		return misses[i].Key < misses[j].Key
+	})
+
+	return misses[:clamp(top, len(misses))]
+}
+
+func topEvents(events []Event, top int, include func(Event) bool, less func(a, b Event) bool) []Event {
+	selected := make([]Event, 0, len(events))
+	for _, e := range events {
+		if include(e) {
+			selected = append(selected, e)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return less(selected[i], selected[j]) })
+
+	return selected[:clamp(top, len(selected))]
+}
+
+// clamp limits top to [0, n].
+func clamp(top, n int) int {
+	return max(min(top, n), 0)
+}
@@ -0,0 +1,100 @@
+//go:build unit
+
+package journal_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/journal"
+)
+
+func testEvents() []journal.Event {
+	return []journal.Event{
+		{Method: "GetValue", Key: "k1", Outcome: journal.OutcomeMiss, LatencyUS: 100},
+		{Method: "GetValue", Key: "k1", Outcome: journal.OutcomeMiss, LatencyUS: 50},
+		{Method: "GetValue", Key: "k2", Outcome: journal.OutcomeMiss, LatencyUS: 10},
+		{Method: "Load", Key: "c1", Outcome: journal.OutcomeHit, Size: 300, LatencyUS: 900},
+		{Method: "Load", Key: "c2", Outcome: journal.OutcomeHit, Size: 700, LatencyUS: 20},
+		{Method: "Save", Key: "c3", Outcome: journal.OutcomeUpload, Size: 40, LatencyUS: 5000},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	report := journal.NewReport("inv", testEvents(), 2)
+
+	assert.Equal(t, journal.Totals{
+		Calls: 6, Hits: 2, Misses: 3, Uploads: 1, DownloadBytes: 1000, UploadBytes: 40,
+	}, report.Totals)
+	assert.InDelta(t, 0.4, report.Totals.HitRate(), 0.0001)
+
+	assert.Equal(t, []journal.KeyMisses{
+		{Key: "k1", Method: "GetValue", Misses: 2},
+		{Key: "k2", Method: "GetValue", Misses: 1},
+	}, report.TopMisses)
+
+	require.Len(t, report.LargestDownloads, 2)
+	assert.Equal(t, "c2", report.LargestDownloads[0].Key)
+	assert.Equal(t, "c1", report.LargestDownloads[1].Key)
+
+	require.Len(t, report.SlowestCalls, 2)
+	assert.Equal(t, "c3", report.SlowestCalls[0].Key)
+	assert.Equal(t, "c1", report.SlowestCalls[1].Key)
+}
+
+func TestNewReport_missReasonsAndErrors(t *testing.T) {
+	events := []journal.Event{
+		{Method: "Load", Key: "c1", Outcome: journal.OutcomeMiss, Reason: journal.ReasonNotFound},
+		{Method: "Load", Key: "c1", Outcome: journal.OutcomeMiss, Reason: journal.ReasonCorrupted},
+		{Method: "Load", Key: "c2", Outcome: journal.OutcomeError, Error: "connection reset"},
+	}
+
+	report := journal.NewReport("inv", events, 5)
+
+	assert.Equal(t, journal.Totals{Calls: 3, Misses: 2, Corrupted: 1, Errors: 1}, report.Totals)
+	assert.Equal(t, []journal.KeyMisses{{Key: "c1", Method: "Load", Misses: 2, Corrupted: 1}}, report.TopMisses)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, "connection reset", report.Errors[0].Error)
+}
+
+func TestNewReport_negativeTop(t *testing.T) {
+	report := journal.NewReport("inv", testEvents(), -1)
+
+	assert.Empty(t, report.TopMisses)
+	assert.Empty(t, report.LargestDownloads)
+	assert.Empty(t, report.SlowestCalls)
+	assert.Empty(t, journal.Compare(testEvents(), "prev", []journal.Event{{Key: "k1", Outcome: journal.OutcomeHit}}, -1).NewMisses)
+}
+
+func TestCompare(t *testing.T) {
+	previous := []journal.Event{
+		{Key: "k1", Outcome: journal.OutcomeHit},
+		{Key: "k3", Outcome: journal.OutcomeHit},
+		{Key: "k2", Outcome: journal.OutcomeMiss},
+	}
+
+	comparison := journal.Compare(testEvents(), "prev", previous, 10)
+
+	assert.Equal(t, "prev", comparison.InvocationID)
+	assert.Equal(t, []string{"k1"}, comparison.NewMisses)
+	assert.Equal(t, 1, comparison.NewMissesTotal)
+	assert.Equal(t, 2, comparison.Totals.Hits)
+}
+
+func TestWriteText(t *testing.T) {
+	report := journal.NewReport("inv", testEvents(), 5)
+	comparison := journal.Compare(testEvents(), "prev", []journal.Event{{Key: "k1", Outcome: journal.OutcomeHit}}, 5)
+	report.Previous = &comparison
+
+	var out bytes.Buffer
+	require.NoError(t, journal.WriteText(&out, report))
+
+	text := out.String()
+	assert.Contains(t, text, "Invocation: inv")
+	assert.Contains(t, text, "Top missed keys:")
+	assert.Contains(t, text, "Previous invocation: prev")
+	assert.Contains(t, text, "Keys hit previously but missed now: 1")
+}
@@ -1,8 +1,17 @@
 package proxy
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
 
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	blobhash "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/casblob"
 	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
 	llvmcas "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/cas"
 )
@@ -17,7 +26,70 @@ func createLLVMKVKey(key []byte) string {
 	return "xcelerate-kv-" + hex.EncodeToString(key)
 }
 
-type blob struct {
-	Data       []byte
-	References [][]byte
+// verifyCASObject checks that the stored bytes of a CAS object, written to
+// hasher, hash to the ID it was requested by. A mismatch is kv.ErrCorruptedBlob.
+func verifyCASObject(key string, id []byte, hasher hash.Hash) error {
+	if got := hasher.Sum(nil); !bytes.Equal(got, id) {
+		return fmt.Errorf("%s: %w: got %s", key, kv.ErrCorruptedBlob, hex.EncodeToString(got))
+	}
+
+	return nil
+}
+
+// downloadBlob streams a CAS entry from the cache through the casblob decoder,
+// writing its payload to payload. It returns the encoded size and references,
+// after verifying the encoded entry against id.
+func (p *Proxy) downloadBlob(ctx context.Context, key string, id []byte, payload io.Writer) (int64, [][]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	hasher := blobhash.NewBlobHasher(digestFunction)
+	counter := &countingWriter{w: io.MultiWriter(pw, hasher)}
+
+	downloadErrCh := make(chan error, 1)
+	go func() {
+		err := p.kvClient.DownloadStream(ctx, counter, key)
+		pw.CloseWithError(err)
+		downloadErrCh <- err
+	}()
+
+	references, decodeErr := casblob.Decode(pr, payload)
+	if decodeErr == nil {
+		// Drain so the download can finish and verify the checksum.
+		if n, err := io.Copy(io.Discard, pr); err == nil && n > 0 {
+			decodeErr = fmt.Errorf("%d bytes of trailing data", n)
+		}
+	}
+	if decodeErr != nil {
+		// Stop the download instead of letting it retry into a closed pipe.
+		cancel()
+	}
+	pr.CloseWithError(errors.New("decoding finished"))
+
+	// A failed download also fails decoding with the same error; report it as a download error.
+	downloadErr := <-downloadErrCh
+	if downloadErr != nil && (decodeErr == nil || errors.Is(decodeErr, downloadErr)) {
+		return 0, nil, fmt.Errorf("%s: failed to download data: %w", key, downloadErr)
+	}
+	if decodeErr != nil {
+		return 0, nil, fmt.Errorf("%s: failed to decode data: %w", key, decodeErr)
+	}
+	if err := verifyCASObject(key, id, hasher); err != nil {
+		return 0, nil, err
+	}
+
+	return counter.n, references, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err //nolint:wrapcheck
 }
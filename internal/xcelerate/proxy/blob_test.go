@@ -0,0 +1,172 @@
+//go:build unit
+
+package proxy_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy/mocks"
+	remoteexecution "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/build/bazel/remote/execution/v2"
+	llvmcas "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/cas"
+)
+
+func casIDOf(data []byte) *llvmcas.CASDataID {
+	hasher := hash.NewBlobHasher(remoteexecution.DigestFunction_BLAKE3)
+	hasher.Write(data)
+
+	return &llvmcas.CASDataID{Id: hasher.Sum(nil)}
+}
+
+func newInMemoryKVClient() (*mocks.ClientMock, map[string][]byte) {
+	var mu sync.Mutex
+	store := map[string][]byte{}
+
+	return &mocks.ClientMock{
+		DownloadStreamFunc: func(_ context.Context, writer io.Writer, key string) error {
+			mu.Lock()
+			data, ok := store[key]
+			mu.Unlock()
+			if !ok {
+				return kv.ErrCacheNotFound
+			}
+
+			_, err := writer.Write(data)
+
+			return err
+		},
+		UploadStreamToBuildCacheFunc: func(_ context.Context, reader io.ReadSeeker, key string, _ int64) error {
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			store[key] = data
+			mu.Unlock()
+
+			return nil
+		},
+	}, store
+}
+
+func TestProxy_PutGet_RoundTrip(t *testing.T) {
+	kvClient, _ := newInMemoryKVClient()
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+	p := proxy.NewProxy(kvClient, true, mockLogger, loggerFactory, nil)
+
+	putResp, err := p.Put(context.Background(), &llvmcas.CASPutRequest{
+		Data: &llvmcas.CASObject{
+			Blob:       &llvmcas.CASBytes{Contents: &llvmcas.CASBytes_Data{Data: []byte("object")}},
+			References: []*llvmcas.CASDataID{{Id: []byte("ref-a")}, {Id: []byte("ref-b")}},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, putResp.GetCasId())
+
+	getResp, err := p.Get(context.Background(), &llvmcas.CASGetRequest{CasId: putResp.GetCasId()})
+	require.NoError(t, err)
+	require.Equal(t, llvmcas.CASGetResponse_SUCCESS, getResp.GetOutcome())
+	assert.Equal(t, []byte("object"), getResp.GetData().GetBlob().GetData())
+	require.Len(t, getResp.GetData().GetReferences(), 2)
+	assert.Equal(t, []byte("ref-b"), getResp.GetData().GetReferences()[1].GetId())
+}
+
+func TestProxy_Get_LegacyGobEntry(t *testing.T) {
+	kvClient, store := newInMemoryKVClient()
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+	p := proxy.NewProxy(kvClient, true, mockLogger, loggerFactory, nil)
+
+	type blob struct {
+		Data       []byte
+		References [][]byte
+	}
+	var raw bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&raw).Encode(&blob{Data: []byte("old"), References: [][]byte{[]byte("r")}}))
+
+	// legacy entries were stored under the ID of their gob encoding too
+	casID := casIDOf(raw.Bytes())
+	store["xcelerate-cas-"+hex.EncodeToString(casID.GetId())] = raw.Bytes()
+
+	getResp, err := p.Get(context.Background(), &llvmcas.CASGetRequest{CasId: casID})
+	require.NoError(t, err)
+	require.Equal(t, llvmcas.CASGetResponse_SUCCESS, getResp.GetOutcome())
+	assert.Equal(t, []byte("old"), getResp.GetData().GetBlob().GetData())
+	assert.Equal(t, []byte("r"), getResp.GetData().GetReferences()[0].GetId())
+}
+
+func TestProxy_Get_CorruptEntry(t *testing.T) {
+	kvClient, store := newInMemoryKVClient()
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+	p := proxy.NewProxy(kvClient, true, mockLogger, loggerFactory, nil)
+
+	store["xcelerate-cas-03"] = []byte{0x89, 'B', 'C', 'B', 1, 0xff}
+
+	getResp, err := p.Get(context.Background(), &llvmcas.CASGetRequest{CasId: &llvmcas.CASDataID{Id: []byte{0x03}}})
+	require.NoError(t, err)
+	assert.Equal(t, llvmcas.CASGetResponse_ERROR, getResp.GetOutcome())
+	assert.Contains(t, getResp.GetError().GetDescription(), "failed to decode data")
+
+	missing, err := p.Get(context.Background(), &llvmcas.CASGetRequest{CasId: &llvmcas.CASDataID{Id: []byte{0x04}}})
+	require.NoError(t, err)
+	assert.Equal(t, llvmcas.CASGetResponse_OBJECT_NOT_FOUND, missing.GetOutcome())
+}
+
+func TestProxy_CorruptedObjectIsAMiss(t *testing.T) {
+	kvClient, store := newInMemoryKVClient()
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+	p := proxy.NewProxy(kvClient, true, mockLogger, loggerFactory, nil)
+
+	saveResp, err := p.Save(context.Background(), &llvmcas.CASSaveRequest{
+		Data: &llvmcas.CASBlob{Blob: &llvmcas.CASBytes{Contents: &llvmcas.CASBytes_Data{Data: []byte("object")}}},
+	})
+	require.NoError(t, err)
+	key := "xcelerate-cas-" + hex.EncodeToString(saveResp.GetCasId().GetId())
+	store[key] = []byte("bit rot")
+
+	loadResp, err := p.Load(context.Background(), &llvmcas.CASLoadRequest{CasId: saveResp.GetCasId()})
+	require.NoError(t, err)
+	assert.Equal(t, llvmcas.CASLoadResponse_OBJECT_NOT_FOUND, loadResp.GetOutcome())
+
+	putResp, err := p.Put(context.Background(), &llvmcas.CASPutRequest{
+		Data: &llvmcas.CASObject{Blob: &llvmcas.CASBytes{Contents: &llvmcas.CASBytes_Data{Data: []byte("object")}}},
+	})
+	require.NoError(t, err)
+	putKey := "xcelerate-cas-" + hex.EncodeToString(putResp.GetCasId().GetId())
+	// a flipped payload byte still decodes, only the digest catches it
+	corrupted := bytes.Clone(store[putKey])
+	corrupted[len(corrupted)-1] ^= 0xff
+	store[putKey] = corrupted
+
+	getResp, err := p.Get(context.Background(), &llvmcas.CASGetRequest{CasId: putResp.GetCasId()})
+	require.NoError(t, err)
+	assert.Equal(t, llvmcas.CASGetResponse_OBJECT_NOT_FOUND, getResp.GetOutcome())
+
+	quarantined := kvClient.QuarantineBlobCalls()
+	require.Len(t, quarantined, 2)
+	assert.Equal(t, key, quarantined[0].Key)
+	assert.Equal(t, putKey, quarantined[1].Key)
+
+	stats, err := p.GetSessionStats(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.GetMisses())
+
+	// corrupted objects can be saved again in the same session
+	_, err = p.Save(context.Background(), &llvmcas.CASSaveRequest{
+		Data: &llvmcas.CASBlob{Blob: &llvmcas.CASBytes{Contents: &llvmcas.CASBytes_Data{Data: []byte("object")}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("object"), store[key])
+}
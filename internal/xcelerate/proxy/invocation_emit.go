@@ -28,6 +28,17 @@ type SessionStats struct {
 	UploadBytes   int64
 	DownloadBytes int64
 	KVUploadBytes int64
+	// LocalHits / RemoteHits split Hits by the tier that served them.
+	LocalHits  int64
+	RemoteHits int64
+	// Degraded is set when the remote was short-circuited during the session.
+	Degraded bool
+	// CorruptedBlobs counts CAS objects that did not match their ID. They are
+	// also counted as misses.
+	CorruptedBlobs int64
+	// SkippedUploads counts uploads dropped while the remote was degraded.
+	// They are not counted in Uploads.
+	SkippedUploads int64
 }
 
 // InvocationEmitter emits a slim analytics invocation for a closed proxy session.
@@ -51,13 +62,15 @@ func (s SessionStats) HitRate() float32 {
 
 func (s stats) toPublic() SessionStats {
 	return SessionStats{
-		Hits:          s.hits,
-		Misses:        s.misses,
-		KVHits:        s.kvHits,
-		KVMisses:      s.kvMisses,
-		Uploads:       s.uploads,
-		UploadBytes:   s.uploadBytes,
-		DownloadBytes: s.downloadBytes,
-		KVUploadBytes: s.kvUploadBytes,
+		Hits:           s.hits,
+		Misses:         s.misses,
+		KVHits:         s.kvHits,
+		KVMisses:       s.kvMisses,
+		Uploads:        s.uploads,
+		UploadBytes:    s.uploadBytes,
+		DownloadBytes:  s.downloadBytes,
+		KVUploadBytes:  s.kvUploadBytes,
+		CorruptedBlobs: s.corrupted,
+		SkippedUploads: s.skippedUploads,
 	}
 }
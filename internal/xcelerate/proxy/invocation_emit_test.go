@@ -2,6 +2,8 @@ package proxy_test
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"sync"
 	"testing"
 	"time"
@@ -11,8 +13,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy/mocks"
+	llvmcas "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/cas"
 	sessionproto "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/session"
 )
 
@@ -154,3 +158,61 @@ func TestSetSession_startTimeMonotonicish(t *testing.T) {
 }
 
 var _ = emptypb.Empty{}
+
+func TestFlushCurrentSession_reportsDegraded(t *testing.T) {
+	emitter := &capturingEmitter{}
+	kvClient := &mocks.ClientMock{DegradedFunc: func() bool { return true }}
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+	p := proxy.NewProxy(kvClient, false, mockLogger, loggerFactory, emitter)
+
+	_, err := p.SetSession(context.Background(), &sessionproto.SetSessionRequest{
+		InvocationId: "inv-4", AppSlug: "app", BuildSlug: "b4", StepSlug: "s4",
+	})
+	require.NoError(t, err)
+
+	stats, err := p.GetSessionStats(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.True(t, stats.GetDegraded())
+
+	p.FlushCurrentSession(context.Background())
+
+	calls := emitter.captured()
+	require.Len(t, calls, 1)
+	assert.True(t, calls[0].stats.Degraded)
+}
+
+func TestProxy_putWhileDegradedIsNotAnUpload(t *testing.T) {
+	emitter := &capturingEmitter{}
+	kvClient := &mocks.ClientMock{
+		DegradedFunc: func() bool { return true },
+		UploadStreamToBuildCacheFunc: func(context.Context, io.ReadSeeker, string, int64) error {
+			return fmt.Errorf("upload stream: %w", kv.ErrDegraded)
+		},
+	}
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+	p := proxy.NewProxy(kvClient, true, mockLogger, loggerFactory, emitter)
+
+	_, err := p.SetSession(context.Background(), &sessionproto.SetSessionRequest{
+		InvocationId: "inv-5", AppSlug: "app", BuildSlug: "b5", StepSlug: "s5",
+	})
+	require.NoError(t, err)
+
+	resp, err := p.Put(context.Background(), &llvmcas.CASPutRequest{
+		Data: &llvmcas.CASObject{Blob: &llvmcas.CASBytes{Contents: &llvmcas.CASBytes_Data{Data: []byte("object")}}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetCasId(), "a skipped upload still answers with the CAS ID")
+	assert.Nil(t, resp.GetError())
+
+	stats, err := p.GetSessionStats(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Zero(t, stats.GetUploads())
+	assert.Zero(t, stats.GetUploadedBytes())
+
+	p.FlushCurrentSession(context.Background())
+
+	calls := emitter.captured()
+	require.Len(t, calls, 1)
+	assert.Equal(t, int64(1), calls[0].stats.SkippedUploads)
+	assert.Zero(t, calls[0].stats.Uploads)
+}
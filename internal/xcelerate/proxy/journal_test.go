@@ -0,0 +1,92 @@
+//go:build unit
+
+package proxy_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/journal"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy/mocks"
+	llvmkv "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/kv"
+	sessionproto "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/session"
+)
+
+func TestProxy_journalsKeyLevelCalls(t *testing.T) {
+	var value bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&value).Encode(map[string][]byte{"k": []byte("v")}))
+
+	kvClient := &mocks.ClientMock{
+		DownloadStreamFunc: func(_ context.Context, writer io.Writer, key string) error {
+			if strings.HasSuffix(key, hex.EncodeToString([]byte("boom"))) {
+				return errors.New("connection reset")
+			}
+			if !strings.HasSuffix(key, hex.EncodeToString([]byte("hit"))) {
+				return kv.ErrCacheNotFound
+			}
+			_, err := writer.Write(value.Bytes())
+
+			return err //nolint:wrapcheck
+		},
+		UploadStreamToBuildCacheFunc: func(context.Context, io.ReadSeeker, string, int64) error {
+			return nil
+		},
+	}
+	loggerFactory := func(string) (log.Logger, error) { return mockLogger, nil }
+
+	p := proxy.NewProxy(kvClient, true, mockLogger, loggerFactory, nil)
+	p.JournalDir = t.TempDir()
+
+	_, err := p.SetSession(context.Background(), &sessionproto.SetSessionRequest{InvocationId: "inv-journal"})
+	require.NoError(t, err)
+
+	_, err = p.GetValue(context.Background(), &llvmkv.GetValueRequest{Key: []byte("hit")})
+	require.NoError(t, err)
+	_, err = p.GetValue(context.Background(), &llvmkv.GetValueRequest{Key: []byte("miss")})
+	require.NoError(t, err)
+	_, err = p.GetValue(context.Background(), &llvmkv.GetValueRequest{Key: []byte("boom")})
+	require.NoError(t, err)
+	_, err = p.PutValue(context.Background(), &llvmkv.PutValueRequest{
+		Key:   []byte("put"),
+		Value: &llvmkv.Value{Entries: map[string][]byte{"k": []byte("v")}},
+	})
+	require.NoError(t, err)
+
+	p.FlushCurrentSession(context.Background())
+
+	events, err := journal.Read(journal.Path(p.JournalDir, "inv-journal"))
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	assert.Equal(t, "GetValue", events[0].Method)
+	assert.Equal(t, journal.OutcomeHit, events[0].Outcome)
+	assert.Equal(t, int64(value.Len()), events[0].Size)
+	assert.Equal(t, journal.OutcomeMiss, events[1].Outcome)
+	assert.Equal(t, journal.ReasonNotFound, events[1].Reason)
+	assert.Equal(t, journal.OutcomeError, events[2].Outcome)
+	assert.Contains(t, events[2].Error, "connection reset")
+	assert.Equal(t, "PutValue", events[3].Method)
+	assert.Equal(t, journal.OutcomeUpload, events[3].Outcome)
+	assert.Positive(t, events[3].Size)
+}
+
+func TestProxy_noJournalWithoutDir(t *testing.T) {
+	p := newProxyForEmit(t, nil)
+
+	_, err := p.SetSession(context.Background(), &sessionproto.SetSessionRequest{InvocationId: "inv-no-journal"})
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() { p.FlushCurrentSession(context.Background()) })
+}
@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
+)
+
+var _ Client = (*LocalCacheClient)(nil)
+
+// tierStatsReporter is implemented by clients serving reads from more than one tier.
+type tierStatsReporter interface {
+	TierStats() (localHits int64, remoteHits int64)
+}
+
+// LocalCacheClient serves reads from an on-disk store before falling back to
+// the wrapped remote client. Remote downloads and uploads are written through,
+// so the next build on the same machine hits locally.
+type LocalCacheClient struct {
+	Client
+
+	store *localcache.Store
+
+	loggerMu sync.RWMutex
+	logger   log.Logger
+
+	localHits  atomic.Int64
+	remoteHits atomic.Int64
+}
+
+func NewLocalCacheClient(remote Client, store *localcache.Store, logger log.Logger) *LocalCacheClient {
+	//nolint:exhaustruct
+	return &LocalCacheClient{
+		Client: remote,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// ChangeSession resets the tier counters along with the remote session.
+func (c *LocalCacheClient) ChangeSession(invocationID string, appSlug string, buildSlug string, stepSlug string) {
+	c.localHits.Store(0)
+	c.remoteHits.Store(0)
+
+	c.Client.ChangeSession(invocationID, appSlug, buildSlug, stepSlug)
+}
+
+func (c *LocalCacheClient) SetLogger(logger log.Logger) {
+	c.loggerMu.Lock()
+	c.logger = logger
+	c.loggerMu.Unlock()
+
+	c.Client.SetLogger(logger)
+}
+
+func (c *LocalCacheClient) DownloadStream(ctx context.Context, writer io.Writer, key string) error {
+	_, err := c.store.Get(key, writer)
+	switch {
+	case err == nil:
+		c.localHits.Add(1)
+
+		return nil
+	case errors.Is(err, localcache.ErrNotFound):
+		// fall back to remote
+	default:
+		// Get drops unreadable entries, but the writer may already hold a partial blob.
+		return fmt.Errorf("local cache: %w", err)
+	}
+
+	pending, err := c.store.Create(key)
+	if err != nil {
+		c.getLogger().TDebugf("Local cache: skipping write-through for %s: %s", key, err)
+
+		//nolint:wrapcheck
+		return c.Client.DownloadStream(ctx, writer, key)
+	}
+
+	if err := c.Client.DownloadStream(ctx, io.MultiWriter(writer, pending), key); err != nil {
+		pending.Abort()
+
+		//nolint:wrapcheck
+		return err
+	}
+
+	c.remoteHits.Add(1)
+
+	if err := pending.Commit(); err != nil {
+		c.getLogger().TDebugf("Local cache: failed to store %s: %s", key, err)
+	}
+
+	return nil
+}
+
+func (c *LocalCacheClient) UploadStreamToBuildCache(ctx context.Context, reader io.ReadSeeker, key string, size int64) error {
+	if !c.store.Has(key) {
+		if _, err := c.store.Put(key, reader); err != nil {
+			c.getLogger().TDebugf("Local cache: failed to store %s: %s", key, err)
+		}
+
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("rewind after local cache write: %w", err)
+		}
+	}
+
+	//nolint:wrapcheck
+	return c.Client.UploadStreamToBuildCache(ctx, reader, key, size)
+}
+
+// QuarantineBlob drops the local copy of a corrupted entry, as either tier may
+// have served it, then quarantines it remotely.
+func (c *LocalCacheClient) QuarantineBlob(ctx context.Context, key string) {
+	if err := c.store.Remove(key); err != nil {
+		c.getLogger().TDebugf("Local cache: failed to remove %s: %s", key, err)
+	}
+
+	c.Client.QuarantineBlob(ctx, key)
+}
+
+// TierStats returns the local and remote hit counts since the last ChangeSession.
+func (c *LocalCacheClient) TierStats() (int64, int64) {
+	return c.localHits.Load(), c.remoteHits.Load()
+}
+
+func (c *LocalCacheClient) getLogger() log.Logger {
+	c.loggerMu.RLock()
+	defer c.loggerMu.RUnlock()
+
+	return c.logger
+}
@@ -0,0 +1,90 @@
+//go:build unit
+
+package proxy_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/bitrise-io/go-utils/v2/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/localcache"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/proxy/mocks"
+)
+
+func TestLocalCacheClient_DownloadStream(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	remote := &mocks.ClientMock{
+		DownloadStreamFunc: func(_ context.Context, writer io.Writer, key string) error {
+			if key == "missing" {
+				return kv.ErrCacheNotFound
+			}
+
+			_, err := writer.Write([]byte("remote-" + key))
+
+			return err
+		},
+	}
+
+	client := proxy.NewLocalCacheClient(remote, store, mockLogger)
+
+	// first read goes to the remote and is written through
+	var first bytes.Buffer
+	require.NoError(t, client.DownloadStream(context.Background(), &first, "key"))
+	assert.Equal(t, "remote-key", first.String())
+	assert.True(t, store.Has("key"))
+
+	// second read is served locally
+	var second bytes.Buffer
+	require.NoError(t, client.DownloadStream(context.Background(), &second, "key"))
+	assert.Equal(t, "remote-key", second.String())
+	assert.Len(t, remote.DownloadStreamCalls(), 1)
+
+	// misses are not cached
+	err = client.DownloadStream(context.Background(), &bytes.Buffer{}, "missing")
+	require.ErrorIs(t, err, kv.ErrCacheNotFound)
+	assert.False(t, store.Has("missing"))
+
+	localHits, remoteHits := client.TierStats()
+	assert.Equal(t, int64(1), localHits)
+	assert.Equal(t, int64(1), remoteHits)
+
+	client.ChangeSession("inv", "app", "build", "step")
+	localHits, remoteHits = client.TierStats()
+	assert.Zero(t, localHits)
+	assert.Zero(t, remoteHits)
+}
+
+func TestLocalCacheClient_UploadWritesThrough(t *testing.T) {
+	store, err := localcache.Open(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	var uploaded []byte
+	remote := &mocks.ClientMock{
+		UploadStreamToBuildCacheFunc: func(_ context.Context, reader io.ReadSeeker, _ string, _ int64) error {
+			uploaded, err = io.ReadAll(reader)
+
+			return err
+		},
+		SetLoggerFunc: func(log.Logger) {},
+	}
+
+	client := proxy.NewLocalCacheClient(remote, store, mockLogger)
+	client.SetLogger(mockLogger)
+
+	require.NoError(t, client.UploadStreamToBuildCache(context.Background(), bytes.NewReader([]byte("payload")), "key", 7))
+	assert.Equal(t, "payload", string(uploaded))
+
+	var local bytes.Buffer
+	_, err = store.Get("key", &local)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", local.String())
+}
@@ -24,12 +24,18 @@ var _ proxy.Client = &ClientMock{}
 //			ChangeSessionFunc: func(invocationID string, appSlug string, buildSlug string, stepSlug string)  {
 //				panic("mock out the ChangeSession method")
 //			},
+//			DegradedFunc: func() bool {
+//				panic("mock out the Degraded method")
+//			},
 //			DownloadStreamFunc: func(ctx context.Context, writer io.Writer, key string) error {
 //				panic("mock out the DownloadStream method")
 //			},
 //			GetCapabilitiesWithRetryFunc: func(ctx context.Context) error {
 //				panic("mock out the GetCapabilitiesWithRetry method")
 //			},
+//			QuarantineBlobFunc: func(ctx context.Context, key string)  {
+//				panic("mock out the QuarantineBlob method")
+//			},
 //			SetLoggerFunc: func(logger log.Logger)  {
 //				panic("mock out the SetLogger method")
 //			},
@@ -46,12 +52,18 @@ type ClientMock struct {
 	// ChangeSessionFunc mocks the ChangeSession method.
 	ChangeSessionFunc func(invocationID string, appSlug string, buildSlug string, stepSlug string)
 
+	// DegradedFunc mocks the Degraded method.
+	DegradedFunc func() bool
+
 	// DownloadStreamFunc mocks the DownloadStream method.
 	DownloadStreamFunc func(ctx context.Context, writer io.Writer, key string) error
 
 	// GetCapabilitiesWithRetryFunc mocks the GetCapabilitiesWithRetry method.
 	GetCapabilitiesWithRetryFunc func(ctx context.Context) error
 
+	// QuarantineBlobFunc mocks the QuarantineBlob method.
+	QuarantineBlobFunc func(ctx context.Context, key string)
+
 	// SetLoggerFunc mocks the SetLogger method.
 	SetLoggerFunc func(logger log.Logger)
 
@@ -71,6 +83,9 @@ type ClientMock struct {
 			// StepSlug is the stepSlug argument value.
 			StepSlug string
 		}
+		// Degraded holds details about calls to the Degraded method.
+		Degraded []struct {
+		}
 		// DownloadStream holds details about calls to the DownloadStream method.
 		DownloadStream []struct {
 			// Ctx is the ctx argument value.
@@ -85,6 +100,13 @@ type ClientMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// QuarantineBlob holds details about calls to the QuarantineBlob method.
+		QuarantineBlob []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
 		// SetLogger holds details about calls to the SetLogger method.
 		SetLogger []struct {
 			// Logger is the logger argument value.
@@ -103,8 +125,10 @@ type ClientMock struct {
 		}
 	}
 	lockChangeSession            sync.RWMutex
+	lockDegraded                 sync.RWMutex
 	lockDownloadStream           sync.RWMutex
 	lockGetCapabilitiesWithRetry sync.RWMutex
+	lockQuarantineBlob           sync.RWMutex
 	lockSetLogger                sync.RWMutex
 	lockUploadStreamToBuildCache sync.RWMutex
 }
@@ -153,6 +177,36 @@ func (mock *ClientMock) ChangeSessionCalls() []struct {
 	return calls
 }
 
+// Degraded calls DegradedFunc.
+func (mock *ClientMock) Degraded() bool {
+	callInfo := struct {
+	}{}
+	mock.lockDegraded.Lock()
+	mock.calls.Degraded = append(mock.calls.Degraded, callInfo)
+	mock.lockDegraded.Unlock()
+	if mock.DegradedFunc == nil {
+		var (
+			bOut bool
+		)
+		return bOut
+	}
+	return mock.DegradedFunc()
+}
+
+// DegradedCalls gets all the calls that were made to Degraded.
+// Check the length with:
+//
+//	len(mockedClient.DegradedCalls())
+func (mock *ClientMock) DegradedCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockDegraded.RLock()
+	calls = mock.calls.Degraded
+	mock.lockDegraded.RUnlock()
+	return calls
+}
+
 // DownloadStream calls DownloadStreamFunc.
 func (mock *ClientMock) DownloadStream(ctx context.Context, writer io.Writer, key string) error {
 	callInfo := struct {
@@ -231,6 +285,42 @@ func (mock *ClientMock) GetCapabilitiesWithRetryCalls() []struct {
 	return calls
 }
 
+// QuarantineBlob calls QuarantineBlobFunc.
+func (mock *ClientMock) QuarantineBlob(ctx context.Context, key string) {
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockQuarantineBlob.Lock()
+	mock.calls.QuarantineBlob = append(mock.calls.QuarantineBlob, callInfo)
+	mock.lockQuarantineBlob.Unlock()
+	if mock.QuarantineBlobFunc == nil {
+		return
+	}
+	mock.QuarantineBlobFunc(ctx, key)
+}
+
+// QuarantineBlobCalls gets all the calls that were made to QuarantineBlob.
+// Check the length with:
+//
+//	len(mockedClient.QuarantineBlobCalls())
+func (mock *ClientMock) QuarantineBlobCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockQuarantineBlob.RLock()
+	calls = mock.calls.QuarantineBlob
+	mock.lockQuarantineBlob.RUnlock()
+	return calls
+}
+
 // SetLogger calls SetLoggerFunc.
 func (mock *ClientMock) SetLogger(logger log.Logger) {
 	callInfo := struct {
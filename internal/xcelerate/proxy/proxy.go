@@ -21,6 +21,8 @@ import (
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/build_cache/kv"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/hash"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/slicebuf"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/casblob"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/journal"
 	llvmcas "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/cas"
 	llvmkv "github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/kv"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/proto/llvm/session"
@@ -39,6 +41,8 @@ type Client interface {
 	DownloadStream(ctx context.Context, writer io.Writer, key string) error
 	UploadStreamToBuildCache(ctx context.Context, reader io.ReadSeeker, key string, size int64) error
 	GetCapabilitiesWithRetry(ctx context.Context) error
+	Degraded() bool
+	QuarantineBlob(ctx context.Context, key string)
 }
 
 type LoggerFactory func(invocationID string) (log.Logger, error)
@@ -67,6 +71,10 @@ type Proxy struct {
 	InactivityTimeout time.Duration
 	inactivityTimer   *time.Timer
 	lastActivity      time.Time
+
+	// JournalDir is where per-session key-level journals are written for
+	// `xcelerate report`. Journaling is off when empty.
+	JournalDir string
 }
 
 const defaultInactivityTimeout = 5 * time.Minute
@@ -142,6 +150,7 @@ func (p *Proxy) FlushCurrentSession(ctx context.Context) {
 	defer p.sessionMutex.Unlock()
 
 	p.emitCurrentSessionLocked(ctx)
+	p.closeJournalLocked()
 }
 
 // emitCurrentSessionLocked snapshots + emits under the caller-held sessionMutex.
@@ -158,6 +167,8 @@ func (p *Proxy) emitCurrentSessionLocked(ctx context.Context) {
 	meta := *p.currentSession
 	meta.EndTime = p.lastActivity
 	stats := p.sessionState.getStats().toPublic()
+	stats.LocalHits, stats.RemoteHits = p.tierStats()
+	stats.Degraded = p.kvClient.Degraded()
 
 	p.emitter.EmitSlim(ctx, meta, stats)
 
@@ -257,12 +268,13 @@ func (p *Proxy) SetSession(ctx context.Context, request *session.SetSessionReque
 	defer p.sessionMutex.Unlock()
 
 	p.emitCurrentSessionLocked(ctx)
+	p.closeJournalLocked()
 
 	p.capabilitiesCalled = false
 
 	p.kvClient.ChangeSession(request.GetInvocationId(), request.GetAppSlug(), request.GetBuildSlug(), request.GetStepSlug())
 
-	p.sessionState = newSessionState()
+	p.sessionState = newJournaledSessionState(p.openJournal(request.GetInvocationId()))
 	p.currentSession = &SessionMeta{
 		InvocationID: request.GetInvocationId(),
 		AppSlug:      request.GetAppSlug(),
@@ -292,6 +304,7 @@ func (p *Proxy) SetSession(ctx context.Context, request *session.SetSessionReque
 
 func (p *Proxy) GetSessionStats(_ context.Context, _ *emptypb.Empty) (*session.GetSessionStatsResponse, error) {
 	collectedStats := p.sessionState.getStats()
+	localHits, remoteHits := p.tierStats()
 
 	return &session.GetSessionStatsResponse{
 		UploadedBytes:   collectedStats.uploadBytes,
@@ -302,9 +315,22 @@ func (p *Proxy) GetSessionStats(_ context.Context, _ *emptypb.Empty) (*session.G
 		KvHits:          collectedStats.kvHits,
 		KvMisses:        collectedStats.kvMisses,
 		KvUploadedBytes: collectedStats.kvUploadBytes,
+		LocalHits:       localHits,
+		RemoteHits:      remoteHits,
+		Degraded:        p.kvClient.Degraded(),
 	}, nil
 }
 
+// tierStats reports local vs remote hits when the client has a local tier.
+// Without one every hit is a remote hit.
+func (p *Proxy) tierStats() (int64, int64) {
+	if reporter, ok := p.kvClient.(tierStatsReporter); ok {
+		return reporter.TierStats()
+	}
+
+	return 0, p.sessionState.hits.Load()
+}
+
 func (p *Proxy) Get(ctx context.Context, request *llvmcas.CASGetRequest) (*llvmcas.CASGetResponse, error) {
 	p.ccSemaphore <- struct{}{}
 	defer func() { <-p.ccSemaphore }()
@@ -321,8 +347,13 @@ func (p *Proxy) Get(ctx context.Context, request *llvmcas.CASGetRequest) (*llvmc
 	}()
 
 	errorHandler := func(err error) *llvmcas.CASGetResponse {
-		if errors.Is(err, kv.ErrCacheNotFound) {
+		if errors.Is(err, kv.ErrCorruptedBlob) {
+			p.logger.TWarnf("Get: %s, treating it as a miss", err)
+			p.quarantine(ctx, key)
+		}
+		if errors.Is(err, kv.ErrCacheNotFound) || errors.Is(err, kv.ErrCorruptedBlob) {
 			p.sessionState.incrementMisses()
+			p.journalFailure("Get", key, start, err)
 
 			//nolint:exhaustruct
 			return &llvmcas.CASGetResponse{
@@ -331,6 +362,7 @@ func (p *Proxy) Get(ctx context.Context, request *llvmcas.CASGetRequest) (*llvmc
 		}
 
 		p.logger.TErrorf("Get error: %s", err)
+		p.journalFailure("Get", key, start, err)
 
 		return &llvmcas.CASGetResponse{
 			Outcome: llvmcas.CASGetResponse_ERROR,
@@ -342,21 +374,16 @@ func (p *Proxy) Get(ctx context.Context, request *llvmcas.CASGetRequest) (*llvmc
 		}
 	}
 
-	buffer := bytes.NewBuffer(nil)
-	err := p.kvClient.DownloadStream(ctx, buffer, key)
+	data := bytes.NewBuffer(nil)
+	size, refs, err := p.downloadBlob(ctx, key, request.GetCasId().GetId(), data)
 	if err != nil {
-		return errorHandler(fmt.Errorf("%s: failed to download data: %w", key, err)), nil
+		return errorHandler(err), nil
 	}
 
 	p.sessionState.saveKeyOnce(key)
-	size := int64(buffer.Len())
 
-	data := blob{} //nolint:exhaustruct
-	if err := gob.NewDecoder(buffer).Decode(&data); err != nil {
-		return errorHandler(fmt.Errorf("%s: failed to decode data: %w", key, err)), nil
-	}
-	references := make([]*llvmcas.CASDataID, 0, len(data.References))
-	for _, ref := range data.References {
+	references := make([]*llvmcas.CASDataID, 0, len(refs))
+	for _, ref := range refs {
 		references = append(references, &llvmcas.CASDataID{
 			Id: ref,
 		})
@@ -365,6 +392,7 @@ func (p *Proxy) Get(ctx context.Context, request *llvmcas.CASGetRequest) (*llvmc
 	hit = true
 	p.sessionState.addDownloadBytes(size)
 	p.sessionState.incrementHits()
+	p.journalCall("Get", key, start, journal.OutcomeHit, size)
 
 	return &llvmcas.CASGetResponse{
 		Outcome: llvmcas.CASGetResponse_SUCCESS,
@@ -372,7 +400,7 @@ func (p *Proxy) Get(ctx context.Context, request *llvmcas.CASGetRequest) (*llvmc
 			Data: &llvmcas.CASObject{
 				Blob: &llvmcas.CASBytes{
 					Contents: &llvmcas.CASBytes_Data{
-						Data: data.Data,
+						Data: data.Bytes(),
 					},
 				},
 				References: references,
@@ -408,30 +436,36 @@ func (p *Proxy) Put(ctx context.Context, request *llvmcas.CASPutRequest) (*llvmc
 		p.logWriteCallStats("Save", key, start)
 	}()
 
-	var data []byte
-	if request.GetData().GetBlob().GetFilePath() != "" {
-		var err error
-		data, err = os.ReadFile(request.GetData().GetBlob().GetFilePath())
+	var payload io.ReaderAt
+	var payloadSize int64
+	if filePath := request.GetData().GetBlob().GetFilePath(); filePath != "" {
+		file, err := os.Open(filePath)
 		if err != nil {
-			return errorHandler(fmt.Errorf("failed to read file %s: %w", request.GetData().GetBlob().GetFilePath(), err)), nil
+			return errorHandler(fmt.Errorf("failed to read file %s: %w", filePath, err)), nil
 		}
-	} else {
-		data = request.GetData().GetBlob().GetData()
-	}
+		defer file.Close()
 
-	rawData := &blob{
-		Data:       data,
-		References: make([][]byte, 0, len(request.GetData().GetReferences())),
+		stat, err := file.Stat()
+		if err != nil {
+			return errorHandler(fmt.Errorf("failed to read file %s: %w", filePath, err)), nil
+		}
+
+		payload = file
+		payloadSize = stat.Size()
+	} else {
+		payload = bytes.NewReader(request.GetData().GetBlob().GetData())
+		payloadSize = int64(len(request.GetData().GetBlob().GetData()))
 	}
 
+	references := make([][]byte, 0, len(request.GetData().GetReferences()))
 	for _, ref := range request.GetData().GetReferences() {
-		rawData.References = append(rawData.References, ref.GetId())
+		references = append(references, ref.GetId())
 	}
 
-	hasher := hash.NewBlobHasher(digestFunction)
-	buffer := slicebuf.NewBuffer()
+	encoded := casblob.NewReader(references, payload, payloadSize)
 
-	if err := gob.NewEncoder(io.MultiWriter(hasher, buffer)).Encode(rawData); err != nil {
+	hasher := hash.NewBlobHasher(digestFunction)
+	if _, err := io.Copy(hasher, encoded); err != nil {
 		return errorHandler(fmt.Errorf("failed to encode data: %w", err)), nil
 	}
 
@@ -462,15 +496,26 @@ func (p *Proxy) Put(ctx context.Context, request *llvmcas.CASPutRequest) (*llvmc
 		}, nil
 	}
 
-	size := int64(buffer.Len())
+	size := encoded.Size()
+	if _, err := encoded.Seek(0, io.SeekStart); err != nil {
+		return errorHandler(fmt.Errorf("failed to seek encoded data: %w", err)), nil
+	}
 
-	err := p.kvClient.UploadStreamToBuildCache(ctx, buffer, key, size)
+	err := p.kvClient.UploadStreamToBuildCache(ctx, encoded, key, size)
+	if p.skippedUpload("Put", key, err) {
+		return &llvmcas.CASPutResponse{
+			Contents: &llvmcas.CASPutResponse_CasId{
+				CasId: casId,
+			},
+		}, nil
+	}
 	if err != nil {
 		return errorHandler(fmt.Errorf("failed to upload data: %w", err)), nil
 	}
 
 	p.sessionState.addUploadBytes(size)
 	p.sessionState.incrementUploads()
+	p.journalCall("Put", key, start, journal.OutcomeUpload, size)
 
 	return &llvmcas.CASPutResponse{
 		Contents: &llvmcas.CASPutResponse_CasId{
@@ -495,8 +540,13 @@ func (p *Proxy) Load(ctx context.Context, request *llvmcas.CASLoadRequest) (*llv
 	}()
 
 	errorHandler := func(err error) *llvmcas.CASLoadResponse {
-		if errors.Is(err, kv.ErrCacheNotFound) {
+		if errors.Is(err, kv.ErrCorruptedBlob) {
+			p.logger.TWarnf("Load: %s, treating it as a miss", err)
+			p.quarantine(ctx, key)
+		}
+		if errors.Is(err, kv.ErrCacheNotFound) || errors.Is(err, kv.ErrCorruptedBlob) {
 			p.sessionState.incrementMisses()
+			p.journalFailure("Load", key, start, err)
 
 			//nolint:exhaustruct
 			return &llvmcas.CASLoadResponse{
@@ -505,6 +555,7 @@ func (p *Proxy) Load(ctx context.Context, request *llvmcas.CASLoadRequest) (*llv
 		}
 
 		p.logger.TErrorf("Load error: %s", err)
+		p.journalFailure("Load", key, start, err)
 
 		return &llvmcas.CASLoadResponse{
 			Outcome: llvmcas.CASLoadResponse_ERROR,
@@ -517,10 +568,14 @@ func (p *Proxy) Load(ctx context.Context, request *llvmcas.CASLoadRequest) (*llv
 	}
 
 	buffer := bytes.NewBuffer(nil)
-	err := p.kvClient.DownloadStream(ctx, buffer, key)
+	hasher := hash.NewBlobHasher(digestFunction)
+	err := p.kvClient.DownloadStream(ctx, io.MultiWriter(buffer, hasher), key)
 	if err != nil {
 		return errorHandler(fmt.Errorf("%s: failed to download data: %w", key, err)), nil
 	}
+	if err := verifyCASObject(key, request.GetCasId().GetId(), hasher); err != nil {
+		return errorHandler(err), nil
+	}
 
 	p.sessionState.saveKeyOnce(key)
 	size := int64(buffer.Len())
@@ -533,6 +588,7 @@ func (p *Proxy) Load(ctx context.Context, request *llvmcas.CASLoadRequest) (*llv
 	hit = true
 	p.sessionState.addDownloadBytes(size)
 	p.sessionState.incrementHits()
+	p.journalCall("Load", key, start, journal.OutcomeHit, size)
 
 	return &llvmcas.CASLoadResponse{
 		Outcome: llvmcas.CASLoadResponse_SUCCESS,
@@ -641,12 +697,20 @@ func (p *Proxy) Save(ctx context.Context, request *llvmcas.CASSaveRequest) (*llv
 	}
 
 	err := p.kvClient.UploadStreamToBuildCache(ctx, reader, key, size)
+	if p.skippedUpload("Save", key, err) {
+		return &llvmcas.CASSaveResponse{
+			Contents: &llvmcas.CASSaveResponse_CasId{
+				CasId: casId,
+			},
+		}, nil
+	}
 	if err != nil {
 		return errorHandler(fmt.Errorf("%s: failed to upload data: %w", key, err)), nil
 	}
 
 	p.sessionState.addUploadBytes(size)
 	p.sessionState.incrementUploads()
+	p.journalCall("Save", key, start, journal.OutcomeUpload, size)
 
 	return &llvmcas.CASSaveResponse{
 		Contents: &llvmcas.CASSaveResponse_CasId{
@@ -674,6 +738,7 @@ func (p *Proxy) GetValue(ctx context.Context, request *llvmkv.GetValueRequest) (
 		if errors.Is(err, kv.ErrCacheNotFound) {
 			p.sessionState.incrementMisses()
 			p.sessionState.incrementKVMisses()
+			p.journalFailure("GetValue", key, start, err)
 
 			//nolint:exhaustruct
 			return &llvmkv.GetValueResponse{
@@ -682,6 +747,7 @@ func (p *Proxy) GetValue(ctx context.Context, request *llvmkv.GetValueRequest) (
 		}
 
 		p.logger.TErrorf("GetValue error: %s", err)
+		p.journalFailure("GetValue", key, start, err)
 
 		return &llvmkv.GetValueResponse{
 			Outcome: llvmkv.GetValueResponse_ERROR,
@@ -710,6 +776,7 @@ func (p *Proxy) GetValue(ctx context.Context, request *llvmkv.GetValueRequest) (
 	p.sessionState.addDownloadBytes(size)
 	p.sessionState.incrementHits()
 	p.sessionState.incrementKVHits()
+	p.journalCall("GetValue", key, start, journal.OutcomeHit, size)
 
 	return &llvmkv.GetValueResponse{
 		Outcome: llvmkv.GetValueResponse_SUCCESS,
@@ -758,6 +825,10 @@ func (p *Proxy) PutValue(ctx context.Context, request *llvmkv.PutValueRequest) (
 	size := int64(buffer.Len())
 
 	err := p.kvClient.UploadStreamToBuildCache(ctx, buffer, key, size)
+	if p.skippedUpload("PutValue", key, err) {
+		//nolint:exhaustruct
+		return &llvmkv.PutValueResponse{}, nil
+	}
 	if err != nil {
 		return errorHandler(fmt.Errorf("%s: failed to upload value: %w", key, err)), nil
 	}
@@ -765,11 +836,101 @@ func (p *Proxy) PutValue(ctx context.Context, request *llvmkv.PutValueRequest) (
 	p.sessionState.addUploadBytes(size)
 	p.sessionState.addKVUploadBytes(size)
 	p.sessionState.incrementUploads()
+	p.journalCall("PutValue", key, start, journal.OutcomeUpload, size)
 
 	//nolint:exhaustruct
 	return &llvmkv.PutValueResponse{}, nil
 }
 
+// openJournal starts the journal of invocationID, nil when journaling is off
+// or the journal cannot be created.
+func (p *Proxy) openJournal(invocationID string) *journal.Writer {
+	if p.JournalDir == "" {
+		return nil
+	}
+
+	writer, err := journal.Create(p.JournalDir, invocationID, journal.DefaultMaxEvents)
+	if err != nil {
+		p.logger.TWarnf("Key journal disabled for %s: %s", invocationID, err)
+
+		return nil
+	}
+
+	return writer
+}
+
+// closeJournalLocked closes the journal of the current session under the
+// caller-held sessionMutex.
+func (p *Proxy) closeJournalLocked() {
+	writer := p.sessionState.journal
+	if writer == nil {
+		return
+	}
+
+	if dropped := writer.Dropped(); dropped > 0 {
+		p.logger.TWarnf("Key journal full, %d events were not recorded", dropped)
+	}
+	if err := writer.Close(); err != nil {
+		p.logger.TWarnf("Failed to close key journal: %s", err)
+	}
+}
+
+// journalCall records a key-level call in the journal of the current session.
+func (p *Proxy) journalCall(method string, key string, start time.Time, outcome journal.Outcome, size int64) {
+	//nolint:exhaustruct
+	p.journalEvent(start, journal.Event{Method: method, Key: key, Outcome: outcome, Size: size})
+}
+
+// journalFailure records a failed read: a miss with its reason when the entry
+// is missing or corrupted, an error otherwise.
+func (p *Proxy) journalFailure(method string, key string, start time.Time, err error) {
+	//nolint:exhaustruct
+	e := journal.Event{Method: method, Key: key, Outcome: journal.OutcomeMiss}
+	switch {
+	case errors.Is(err, kv.ErrCorruptedBlob):
+		e.Reason = journal.ReasonCorrupted
+	case errors.Is(err, kv.ErrCacheNotFound):
+		e.Reason = journal.ReasonNotFound
+	default:
+		e.Outcome = journal.OutcomeError
+		e.Error = err.Error()
+	}
+
+	p.journalEvent(start, e)
+}
+
+func (p *Proxy) journalEvent(start time.Time, e journal.Event) {
+	e.Time = start
+	e.LatencyUS = time.Since(start).Microseconds()
+
+	if err := p.sessionState.journal.Record(e); err != nil {
+		p.logger.TDebugf("Failed to journal %s %s: %s", e.Method, e.Key, err)
+	}
+}
+
+// skippedUpload reports whether err is an upload the client skipped because
+// the remote is degraded. Such an upload is a no-op for the caller: it is
+// counted apart from uploads and the key may be saved again later.
+func (p *Proxy) skippedUpload(method, key string, err error) bool {
+	if !errors.Is(err, kv.ErrDegraded) {
+		return false
+	}
+
+	p.logger.TDebugf("%s: remote cache is degraded, not uploading %s", method, key)
+	p.sessionState.markKeyUnsaved(key)
+	p.sessionState.incrementSkippedUploads()
+
+	return true
+}
+
+// quarantine records a CAS object that failed verification: it is counted as
+// corrupted, may be saved again in this session and is quarantined by the client.
+func (p *Proxy) quarantine(ctx context.Context, key string) {
+	p.sessionState.incrementCorrupted()
+	p.sessionState.markKeyUnsaved(key)
+	p.kvClient.QuarantineBlob(ctx, key)
+}
+
 func (p *Proxy) logReadCallStats(method string, key string, start time.Time, hit bool) {
 	p.logger.TDebugf("%s with key %s took %s and was a hit: %t",
 		method,
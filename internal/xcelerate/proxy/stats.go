@@ -3,6 +3,8 @@ package proxy
 import (
 	"sync"
 	"sync/atomic"
+
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/xcelerate/journal"
 )
 
 type sessionState struct {
@@ -14,24 +16,35 @@ type sessionState struct {
 	kvHits        atomic.Int64
 	kvMisses      atomic.Int64
 	kvUploadBytes atomic.Int64
-	savedKeys     sync.Map
+	corrupted     atomic.Int64
+	// skippedUploads counts uploads dropped because the remote was degraded.
+	skippedUploads atomic.Int64
+	savedKeys      sync.Map
+	// journal records the key-level calls of the session, nil when journaling is off.
+	journal *journal.Writer
 }
 
 type stats struct {
-	downloadBytes int64
-	uploadBytes   int64
-	uploads       int64
-	misses        int64
-	hits          int64
-	kvHits        int64
-	kvMisses      int64
-	kvUploadBytes int64
+	downloadBytes  int64
+	uploadBytes    int64
+	uploads        int64
+	misses         int64
+	hits           int64
+	kvHits         int64
+	kvMisses       int64
+	kvUploadBytes  int64
+	corrupted      int64
+	skippedUploads int64
 }
 
 func newSessionState() *sessionState {
 	return &sessionState{}
 }
 
+func newJournaledSessionState(journal *journal.Writer) *sessionState {
+	return &sessionState{journal: journal}
+}
+
 func (s *sessionState) addDownloadBytes(n int64) {
 	s.downloadBytes.Add(n)
 }
@@ -46,14 +59,16 @@ func (s *sessionState) addKVUploadBytes(n int64) {
 
 func (s *sessionState) getStats() stats {
 	return stats{
-		downloadBytes: s.downloadBytes.Load(),
-		uploadBytes:   s.uploadBytes.Load(),
-		uploads:       s.uploads.Load(),
-		hits:          s.hits.Load(),
-		misses:        s.misses.Load(),
-		kvHits:        s.kvHits.Load(),
-		kvMisses:      s.kvMisses.Load(),
-		kvUploadBytes: s.kvUploadBytes.Load(),
+		downloadBytes:  s.downloadBytes.Load(),
+		uploadBytes:    s.uploadBytes.Load(),
+		uploads:        s.uploads.Load(),
+		hits:           s.hits.Load(),
+		misses:         s.misses.Load(),
+		kvHits:         s.kvHits.Load(),
+		kvMisses:       s.kvMisses.Load(),
+		kvUploadBytes:  s.kvUploadBytes.Load(),
+		corrupted:      s.corrupted.Load(),
+		skippedUploads: s.skippedUploads.Load(),
 	}
 }
 
@@ -73,6 +88,14 @@ func (s *sessionState) incrementKVHits() {
 	s.kvHits.Add(1)
 }
 
+func (s *sessionState) incrementCorrupted() {
+	s.corrupted.Add(1)
+}
+
+func (s *sessionState) incrementSkippedUploads() {
+	s.skippedUploads.Add(1)
+}
+
 func (s *sessionState) incrementUploads() {
 	s.uploads.Add(1)
 }
@@ -4,8 +4,10 @@ import (
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/auth"
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/bazel"
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/browse"
+	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/cache"
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/ccache"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/common"
+	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/credentials"
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/daemon"
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/doctor"
 	_ "github.com/bitrise-io/bitrise-build-cache-cli/v3/cmd/file"
@@ -26,6 +26,11 @@ type ActivatorParams struct {
 	DebugLogging          bool
 	Envs                  map[string]string
 
+	// LocalCacheMaxBytes enables the storage helper's on-disk cache tier when
+	// non-zero. LocalCacheDir defaults to ~/.bitrise/cache/ccache/local-blobs.
+	LocalCacheDir      string
+	LocalCacheMaxBytes int64
+
 	// Logger overrides the default logger. If nil, a default logger is created.
 	Logger log.Logger
 	// OsProxy overrides the default OS proxy. If nil, utils.DefaultOsProxy{} is used.
@@ -49,6 +54,8 @@ type Activator struct {
 	baseDirOverride       string
 	debugLogging          bool
 	envs                  map[string]string
+	localCacheDir         string
+	localCacheMaxBytes    int64
 }
 
 // NewActivator creates an Activator with production defaults.
@@ -90,6 +97,8 @@ func NewActivator(params ActivatorParams) *Activator {
 		baseDirOverride:       params.BaseDirOverride,
 		debugLogging:          params.DebugLogging,
 		envs:                  envs,
+		localCacheDir:         params.LocalCacheDir,
+		localCacheMaxBytes:    params.LocalCacheMaxBytes,
 	}
 }
 
@@ -104,6 +113,8 @@ func (a *Activator) Activate(ctx context.Context) error {
 		PushEnabled:           a.pushEnabled,
 		IPCSocketPathOverride: a.ipcSocketPathOverride,
 		BaseDirOverride:       a.baseDirOverride,
+		LocalCacheDir:         a.localCacheDir,
+		LocalCacheMaxBytes:    a.localCacheMaxBytes,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create ccache config: %w", err)
@@ -5,6 +5,7 @@ package ccache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -22,7 +23,9 @@ import (
 	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
 	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/consts"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/credhelper"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/exec"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/oauth"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 	pkgcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/pkg/common"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/pkg/common/childstats"
@@ -81,6 +84,9 @@ type StorageHelper struct {
 	parentID     string
 	downloaded   int64
 	uploaded     int64
+	localHits    int64
+	remoteHits   int64
+	degraded     bool
 }
 
 // NewStorageHelper reads the ccache configuration from the default config path
@@ -235,10 +241,19 @@ func (h *StorageHelper) CollectAndSendStats(ctx context.Context, invocationIDOve
 
 	h.sessionMu.RLock()
 	dl, ul := h.downloaded, h.uploaded
+	localHits, remoteHits := h.localHits, h.remoteHits
+	degraded := h.degraded
 	invocationID := h.invocationID
 	parentID := h.parentID
 	h.sessionMu.RUnlock()
 
+	if localHits > 0 {
+		h.logger.TInfof("Storage helper hits: local: %d, remote: %d", localHits, remoteHits)
+	}
+	if degraded {
+		h.logger.TWarnf("Remote cache was unreachable during this session, the storage helper ran in degraded mode")
+	}
+
 	hasActivity := stats.HasActivity() || dl > 0 || ul > 0
 	if !hasActivity {
 		h.logger.TInfof("No ccache activity detected, skipping analytics")
@@ -267,6 +282,8 @@ func (h *StorageHelper) CollectAndSendStats(ctx context.Context, invocationIDOve
 	metadata := configcommon.NewMetadata(h.params.Envs, newCommandFunc(ctx), h.logger)
 
 	inv := ccacheanalytics.NewCcacheInvocation(invocationID, parentID, time.Now(), stats, dl, ul, h.config.AuthConfig, metadata)
+	inv.Degraded = degraded
+	inv.LocalHits, inv.RemoteHits = localHits, remoteHits
 	if err := client.PutCcacheInvocation(*inv); err != nil {
 		h.logger.TWarnf("Failed to send ccache invocation: %v", err)
 	}
@@ -411,6 +428,26 @@ func (h *StorageHelper) loadSessionInfo(ctx context.Context, invocationIDOverrid
 		return iccache.SessionStats{}, fmt.Errorf("failed to get session stats: %w", err)
 	}
 
+	localHits, remoteHits, err := iccache.SendGetTierHits(ctx, socketPath)
+	switch {
+	case errors.Is(err, iccache.ErrRequestNotSupported):
+		h.logger.TDebugf("Storage helper does not report tier hits: %v", err)
+	case err != nil:
+		h.logger.TWarnf("Failed to get tier hits from storage helper: %v", err)
+	default:
+		stats.LocalHits, stats.RemoteHits = localHits, remoteHits
+	}
+
+	degraded, err := iccache.SendGetDegraded(ctx, socketPath)
+	switch {
+	case errors.Is(err, iccache.ErrRequestNotSupported):
+		h.logger.TDebugf("Storage helper does not report degraded mode: %v", err)
+	case err != nil:
+		h.logger.TWarnf("Failed to get degraded mode from storage helper: %v", err)
+	default:
+		stats.Degraded = degraded
+	}
+
 	// Update internal state with loaded session info, allowing overrides from params.
 	// This ensures the caller can correlate the session info with the correct invocation IDs
 	// even if the helper was running with different IDs or the caller wants to override them for analytics purposes.
@@ -431,6 +468,9 @@ func (h *StorageHelper) loadSessionInfo(ctx context.Context, invocationIDOverrid
 
 	h.uploaded = stats.UploadedBytes
 	h.downloaded = stats.DownloadedBytes
+	h.localHits = stats.LocalHits
+	h.remoteHits = stats.RemoteHits
+	h.degraded = stats.Degraded
 	h.sessionMu.Unlock()
 
 	return stats, nil
@@ -518,26 +558,33 @@ func createKVClient(
 	envs map[string]string,
 	invocationID string,
 ) (*kv.Client, error) {
-	endpointURL := configcommon.SelectCacheEndpointURL(config.BuildCacheEndpoint, envs)
-
-	buildCacheHost, insecureGRPC, err := kv.ParseURLGRPC(endpointURL)
+	endpointURLs := config.BuildCacheEndpoints
+	if len(endpointURLs) == 0 {
+		endpointURLs = configcommon.SelectCacheEndpointURLs(config.BuildCacheEndpoint, envs)
+	}
+	endpoints, err := kv.ParseEndpoints(endpointURLs)
 	if err != nil {
-		return nil, fmt.Errorf("parse endpoint URL %q: %w", endpointURL, err)
+		return nil, fmt.Errorf("parse endpoint URL: %w", err)
 	}
 
 	logger := log.NewLogger(log.WithDebugLog(config.DebugLogging))
 	commandFunc := newCommandFunc(ctx)
+	oauthCfg := oauth.NewConfigFromEnv(envs)
+	oauthCfg.Logger = logger
 
 	client, err := kv.NewClient(kv.NewClientParams{
-		UseInsecure:         insecureGRPC,
-		Host:                buildCacheHost,
+		Endpoints:           endpoints,
 		DialTimeout:         5 * time.Second,
 		ClientName:          "ccache",
 		AuthConfig:          config.AuthConfig,
+		AuthSource:          credhelper.NewAuthSource(context.WithoutCancel(ctx), envs, oauthCfg, logger),
 		Logger:              logger,
 		CacheConfigMetadata: configcommon.NewMetadata(envs, commandFunc, logger),
 		CacheOperationID:    uuid.NewString(),
 		InvocationID:        invocationID,
+		CircuitBreaker:      kv.NewCircuitBreaker(kv.CircuitBreakerParams{Logger: logger}),
+		CompressUploads:     configcommon.CompressionEnabled(envs),
+		DeleteCorrupted:     configcommon.DeleteCorruptedEnabled(envs),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("new KV client: %w", err)
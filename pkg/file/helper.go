@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/bitrise-io/go-utils/v2/log"
@@ -157,23 +158,24 @@ func (h *Helper) newKVClient(ctx context.Context) (*kv.Client, error) {
 		return nil, fmt.Errorf("resolve auth config: %w", err)
 	}
 
-	endpointURL := configcommon.SelectCacheEndpointURL(h.endpointURL, h.envs)
-	h.logger.Debugf("Build Cache Endpoint URL: %s", endpointURL)
+	endpointURLs := configcommon.SelectCacheEndpointURLs(h.endpointURL, h.envs)
+	h.logger.Debugf("Build Cache Endpoint URL: %s", strings.Join(endpointURLs, ", "))
 
-	host, insecureGRPC, err := kv.ParseURLGRPC(endpointURL)
+	endpoints, err := kv.ParseEndpoints(endpointURLs)
 	if err != nil {
-		return nil, fmt.Errorf("parse endpoint URL %q: %w", endpointURL, err)
+		return nil, fmt.Errorf("parse endpoint URL: %w", err)
 	}
 
 	client, err := kv.NewClient(kv.NewClientParams{
-		UseInsecure:         insecureGRPC,
-		Host:                host,
+		Endpoints:           endpoints,
 		DialTimeout:         5 * time.Second,
 		ClientName:          ClientName,
 		AuthConfig:          authConfig,
 		Logger:              h.logger,
 		CacheConfigMetadata: configcommon.NewMetadata(h.envs, h.commandFunc, h.logger),
 		CacheOperationID:    uuid.NewString(),
+		CompressUploads:     configcommon.CompressionEnabled(h.envs),
+		DeleteCorrupted:     configcommon.DeleteCorruptedEnabled(h.envs),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("new kv client: %w", err)
@@ -31,6 +31,15 @@ type ActivatorParams struct {
 	// "google"). Empty means all mirrors in mirrorsconfig.KnownMirrors.
 	SelectedFlags []string
 
+	// CustomMirrorSpecs declares mirrors in addition to the known ones, in the
+	// `--mirror` format: "<name>=<url>,match=url:<prefix>,match=name:<repo>[,plugins]".
+	CustomMirrorSpecs []string
+
+	// MirrorsConfigFile is the JSON file declaring custom mirrors. Empty falls
+	// back to ~/.bitrise/gradle-mirrors.json; "-" disables the file. A missing
+	// file declares no mirrors.
+	MirrorsConfigFile string
+
 	// Datacenter overrides the BITRISE_DEN_VM_DATACENTER env var. Empty falls
 	// back to the env var.
 	Datacenter string
@@ -39,6 +48,11 @@ type ActivatorParams struct {
 	// falls back to the env var (true iff the value equals "true").
 	Enabled *bool
 
+	// LocalProxyPort points the mirrors at the local dependency proxy
+	// (`gradle start-dependency-proxy`) on this port instead of the datacenter
+	// mirrors. Zero keeps the datacenter mirrors.
+	LocalProxyPort int
+
 	// Envs is the env var source consulted when Datacenter or Enabled need
 	// fallback. Nil means utils.AllEnvs().
 	Envs map[string]string
@@ -64,12 +78,15 @@ type Activator struct {
 	osProxy      utils.OsProxy
 	pathModifier pathutil.PathModifier
 
-	gradleHome    string
-	projectRoot   string
-	selectedFlags []string
-	datacenter    string
-	enabled       *bool
-	envs          map[string]string
+	gradleHome     string
+	projectRoot    string
+	selectedFlags  []string
+	customSpecs    []string
+	mirrorsConfig  string
+	datacenter     string
+	enabled        *bool
+	localProxyPort int
+	envs           map[string]string
 }
 
 // NewActivator creates an Activator with production defaults.
@@ -99,19 +116,22 @@ func NewActivator(params ActivatorParams) *Activator {
 		osProxy:      osProxy,
 		pathModifier: pathModifier,
 
-		gradleHome:    params.GradleHome,
-		projectRoot:   params.ProjectRoot,
-		selectedFlags: params.SelectedFlags,
-		datacenter:    params.Datacenter,
-		enabled:       params.Enabled,
-		envs:          envs,
+		gradleHome:     params.GradleHome,
+		projectRoot:    params.ProjectRoot,
+		selectedFlags:  params.SelectedFlags,
+		customSpecs:    params.CustomMirrorSpecs,
+		mirrorsConfig:  params.MirrorsConfigFile,
+		datacenter:     params.Datacenter,
+		enabled:        params.Enabled,
+		localProxyPort: params.LocalProxyPort,
+		envs:           envs,
 	}
 }
 
 // Activate installs the Gradle mirrors init script when activation is enabled.
 // When disabled (via the Enabled param or the BITRISE_MAVENCENTRAL_PROXY_ENABLED
-// env var), or when no datacenter is available, Activate logs the reason and
-// returns nil.
+// env var), or when no datacenter is available, only custom mirrors are
+// installed; without any, Activate logs the reason and returns nil.
 //
 // The installed init script also re-reads BITRISE_MAVENCENTRAL_PROXY_ENABLED at
 // Gradle build time: an explicit "false" disables the mirrors at runtime, so a
@@ -137,16 +157,20 @@ func (a *Activator) Activate(_ context.Context) error {
 
 	enabled := a.resolveEnabled()
 	datacenter := a.resolveDatacenter()
-	selected := mirrorsconfig.FilterByFlagNames(a.selectedFlags)
+	selected, err := a.resolveMirrors()
+	if err != nil {
+		return err
+	}
 	projectRoot := a.resolveProjectRoot()
 
 	if err := mirrorsconfig.Activate(a.logger, a.osProxy, mirrorsconfig.Params{
-		GradleHome:  gradleHome,
-		Mirrors:     selected,
-		Datacenter:  datacenter,
-		Enabled:     enabled,
-		ProjectRoot: projectRoot,
-		Exporter:    envexport.New(a.envs, a.logger),
+		GradleHome:     gradleHome,
+		Mirrors:        selected,
+		Datacenter:     datacenter,
+		Enabled:        enabled,
+		ProjectRoot:    projectRoot,
+		Exporter:       envexport.New(a.envs, a.logger),
+		LocalProxyPort: a.localProxyPort,
 	}); err != nil {
 		return fmt.Errorf("activate gradle mirrors: %w", err)
 	}
@@ -176,6 +200,34 @@ func (a *Activator) resolveGradleHome() (string, error) {
 	return paths.FromHome(home).GradleHome(a.envs[paths.GradleUserHomeEnvKey]), nil
 }
 
+// resolveMirrors returns the selected known mirrors followed by the custom
+// ones, which are validated before anything is rendered.
+func (a *Activator) resolveMirrors() ([]mirrorsconfig.RepoMirror, error) {
+	configFile := a.mirrorsConfig
+	switch configFile {
+	case "-":
+		configFile = ""
+	case "":
+		home, err := a.osProxy.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("resolve home dir: %w", err)
+		}
+		configFile = paths.FromHome(home).GradleMirrorsConfigFile()
+	}
+
+	custom, err := mirrorsconfig.LoadCustomMirrors(a.osProxy, configFile, a.customSpecs)
+	if err != nil {
+		return nil, fmt.Errorf("load custom mirrors: %w", err)
+	}
+
+	mirrors, err := mirrorsconfig.WithCustomMirrors(mirrorsconfig.FilterByFlagNames(a.selectedFlags), custom)
+	if err != nil {
+		return nil, fmt.Errorf("load custom mirrors: %w", err)
+	}
+
+	return mirrors, nil
+}
+
 func (a *Activator) resolveEnabled() bool {
 	if a.enabled != nil {
 		return *a.enabled
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	mirrorsconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle/mirrors"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/pkg/gradle/mirrors"
 )
 
@@ -97,3 +98,48 @@ func TestActivator_Activate_EnabledButNoDatacenter(t *testing.T) {
 	_, err := os.Stat(filepath.Join(tmpDir, "init.d", mirrorsconfig.InitFileName))
 	assert.True(t, os.IsNotExist(err))
 }
+
+func TestActivator_Activate_CustomMirrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "gradle-mirrors.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(`{"mirrors": [
+		{"name": "jitpack", "url": "https://artifactory.example.com/jitpack", "urlPrefixes": ["https://jitpack.io"]}
+	]}`), 0o600))
+
+	a := mirrors.NewActivator(mirrors.ActivatorParams{
+		GradleHome:        tmpDir,
+		SelectedFlags:     []string{"mavencentral"},
+		CustomMirrorSpecs: []string{"sonatype-snapshots=http://nexus.internal/snapshots,match=name:SonatypeSnapshots"},
+		MirrorsConfigFile: configFile,
+		Datacenter:        "AMS1",
+		Enabled:           boolPtr(true),
+		Logger:            log.NewLogger(),
+	})
+
+	require.NoError(t, a.Activate(context.Background()))
+
+	installed, exists, err := mirrorsconfig.ReadInstalledMirrors(utils.DefaultOsProxy{}, tmpDir)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, []mirrorsconfig.InstalledMirror{
+		{Name: "mavencentral", URL: "https://repository-manager.services.bitrise.io:8090/maven/central"},
+		{Name: "jitpack", URL: "https://artifactory.example.com/jitpack", Custom: true},
+		{Name: "sonatype-snapshots", URL: "http://nexus.internal/snapshots", Custom: true},
+	}, installed)
+}
+
+func TestActivator_Activate_InvalidCustomMirror(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	a := mirrors.NewActivator(mirrors.ActivatorParams{
+		GradleHome:        tmpDir,
+		CustomMirrorSpecs: []string{"google=https://example.com/google,match=name:Google"},
+		MirrorsConfigFile: "-",
+		Datacenter:        "AMS1",
+		Enabled:           boolPtr(true),
+		Logger:            log.NewLogger(),
+	})
+
+	require.ErrorIs(t, a.Activate(context.Background()), mirrorsconfig.ErrInvalidCustomMirror)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "init.d", mirrorsconfig.InitFileName))
+}
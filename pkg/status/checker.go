@@ -10,7 +10,9 @@ import (
 
 	"github.com/bitrise-io/go-utils/v2/log"
 
+	bazelconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/bazel"
 	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle/mirrors"
 	rnconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/reactnative"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
 	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
@@ -18,14 +20,12 @@ import (
 )
 
 // Feature names accepted by IsEnabled and the `--feature` flag.
-// Bazel activation has no reliable on-disk marker yet, so it's intentionally
-// absent from this set — `status --feature=bazel` returns ErrUnknownFeature
-// (exit 2) rather than silently lying about disabled state.
 const (
 	FeatureGradle      = "gradle"
 	FeatureXcode       = "xcode"
 	FeatureCpp         = "cpp"
 	FeatureReactNative = "react-native"
+	FeatureBazel       = "bazel"
 )
 
 // ErrUnknownFeature is returned by IsEnabled when the feature name is not
@@ -39,6 +39,14 @@ type Status struct {
 	Xcode       bool `json:"xcode"`
 	Cpp         bool `json:"cpp"`
 	ReactNative bool `json:"reactNative"`
+	Bazel       bool `json:"bazel"`
+}
+
+// GradleMirror is a repository mirror installed by `activate gradle-mirrors`.
+type GradleMirror struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Custom bool   `json:"custom,omitempty"`
 }
 
 // CheckerParams holds the dependencies for a Checker.
@@ -98,6 +106,7 @@ func (c *Checker) Status() Status {
 		Xcode:       c.xcodeEnabled(),
 		Cpp:         c.cppEnabled(),
 		ReactNative: c.reactNativeEnabled(),
+		Bazel:       c.bazelEnabled(),
 	}
 }
 
@@ -113,11 +122,38 @@ func (c *Checker) IsEnabled(feature string) (bool, error) {
 		return c.cppEnabled(), nil
 	case FeatureReactNative:
 		return c.reactNativeEnabled(), nil
+	case FeatureBazel:
+		return c.bazelEnabled(), nil
 	default:
 		return false, fmt.Errorf("%w: %q", ErrUnknownFeature, feature)
 	}
 }
 
+// GradleMirrors lists the mirrors recorded in the installed Gradle mirrors
+// init script, custom ones included. It returns nil when no script is
+// installed or it cannot be read.
+func (c *Checker) GradleMirrors() []GradleMirror {
+	home, err := c.osProxy.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	gradleHome := paths.FromHome(home).GradleHome(c.envs[paths.GradleUserHomeEnvKey])
+	installed, _, err := mirrors.ReadInstalledMirrors(c.osProxy, gradleHome)
+	if err != nil {
+		c.logger.Debugf("Read Gradle mirrors: %s", err)
+
+		return nil
+	}
+
+	result := make([]GradleMirror, 0, len(installed))
+	for _, m := range installed {
+		result = append(result, GradleMirror{Name: m.Name, URL: m.URL, Custom: m.Custom})
+	}
+
+	return result
+}
+
 // ---------------------------------------------------------------------------
 // Private — per-feature detection
 // ---------------------------------------------------------------------------
@@ -162,3 +198,24 @@ func (c *Checker) reactNativeEnabled() bool {
 
 	return cfg.Enabled
 }
+
+// bazelEnabled reports an activation whose sidecar is present and whose
+// bazelrc has not been removed since.
+func (c *Checker) bazelEnabled() bool {
+	home, err := c.osProxy.UserHomeDir()
+	if err != nil {
+		return false
+	}
+
+	sidecar, ok, err := bazelconfig.ReadSidecar(home)
+	if err != nil || !ok {
+		return false
+	}
+
+	if sidecar.BazelrcPath == "" {
+		return true
+	}
+	_, err = c.osProxy.Stat(sidecar.BazelrcPath)
+
+	return err == nil
+}
@@ -193,9 +193,9 @@ func TestChecker_IsEnabled(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, gradle)
 
-	_, err = c.IsEnabled("bazel")
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, status.ErrUnknownFeature))
+	bazel, err := c.IsEnabled(status.FeatureBazel)
+	require.NoError(t, err)
+	assert.False(t, bazel)
 
 	_, err = c.IsEnabled("nonsense")
 	require.Error(t, err)
@@ -0,0 +1,247 @@
+package status
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+
+	bazelconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/bazel"
+	ccacheconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/ccache"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	gradleconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle"
+	rnconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/reactnative"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/paths"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/probe"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/refresh"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/toolconfig"
+)
+
+// DetailsSchemaVersion is the version of the Details shape. It is bumped when
+// a field is removed or changes meaning; new fields keep the version.
+const DetailsSchemaVersion = 1
+
+// Details is the per-tool activation report behind `status --json`.
+type Details struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Tools         ToolDetails `json:"tools"`
+}
+
+// ToolDetails holds the activation details of every known tool.
+type ToolDetails struct {
+	Gradle      Tool `json:"gradle"`
+	Xcode       Tool `json:"xcode"`
+	Cpp         Tool `json:"cpp"`
+	ReactNative Tool `json:"reactNative"`
+	Bazel       Tool `json:"bazel"`
+}
+
+// Tool is the activation state of one tool, read from the files its activate
+// command wrote.
+type Tool struct {
+	Enabled bool `json:"enabled"`
+	// ConfigFiles are the files written by activate that exist on disk.
+	ConfigFiles []string `json:"configFiles"`
+	// ConfigVersion is the schema version of the stored config; empty for
+	// configs written before versioning or tools without a versioned config.
+	ConfigVersion string `json:"configVersion,omitempty"`
+	// CurrentConfigVersion is the schema version this CLI writes.
+	CurrentConfigVersion string `json:"currentConfigVersion,omitempty"`
+	// ConfigOutdated is set when ConfigVersion is a major version behind
+	// CurrentConfigVersion and activate has to be re-run.
+	ConfigOutdated bool      `json:"configOutdated"`
+	ConfiguredAt   time.Time `json:"configuredAt,omitzero"`
+	// Endpoint is the build cache endpoint; empty means the default.
+	Endpoint       string `json:"endpoint,omitempty"`
+	PushEnabled    bool   `json:"pushEnabled"`
+	BenchmarkPhase string `json:"benchmarkPhase,omitempty"`
+	// Helper is the local service the tool talks to, when it uses one.
+	Helper *Helper `json:"helper,omitempty"`
+}
+
+// Helper is a local service (proxy, storage helper, cache connector) and
+// whether it accepts connections.
+type Helper struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// State is "running", "stopped" or "stuck" (socket present, not responding).
+	State     string `json:"state"`
+	Reachable bool   `json:"reachable"`
+}
+
+// Details reports the activation details of every known tool. Like Status it
+// never fails: unreadable files leave the affected fields empty. Helpers are
+// probed with a short timeout.
+func (c *Checker) Details() Details {
+	home, _ := c.osProxy.UserHomeDir()
+
+	return Details{
+		SchemaVersion: DetailsSchemaVersion,
+		Tools: ToolDetails{
+			Gradle:      c.gradleDetails(home),
+			Xcode:       c.xcodeDetails(home),
+			Cpp:         c.cppDetails(),
+			ReactNative: c.reactNativeDetails(),
+			Bazel:       c.bazelDetails(home),
+		},
+	}
+}
+
+func (c *Checker) gradleDetails(home string) Tool {
+	details := Tool{Enabled: c.gradleEnabled(), ConfigFiles: []string{}}
+	if home == "" {
+		return details
+	}
+
+	gradleHome := paths.FromHome(home).GradleHome(c.envs[paths.GradleUserHomeEnvKey])
+	c.addExistingFiles(&details, paths.GradleInitScript(gradleHome))
+
+	sidecar, ok, err := gradleconfig.ReadSidecar(home)
+	if err != nil || !ok {
+		return details
+	}
+
+	c.addExistingFiles(&details, gradleconfig.SidecarFilePath(home))
+	setConfigVersion(&details, toolconfig.Gradle, sidecar.ConfigVersion, sidecar.WrittenAt)
+	details.Endpoint = sidecar.CacheEndpoint
+	details.PushEnabled = sidecar.CachePushEnabled
+	details.BenchmarkPhase = c.benchmarkPhase(home, configcommon.BuildToolGradle)
+
+	if sidecar.CacheLocalHTTPPort != 0 {
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(sidecar.CacheLocalHTTPPort))
+		details.Helper = newHelper("gradle-cache-connector", address, probe.TCP(address))
+	}
+
+	return details
+}
+
+func (c *Checker) xcodeDetails(home string) Tool {
+	details := Tool{ConfigFiles: []string{}}
+
+	cfg, err := xcelerate.ReadConfig(c.osProxy, c.decoderFactory)
+	if err != nil {
+		return details
+	}
+
+	c.addExistingFiles(&details, xcelerate.PathFor(c.osProxy, "config.json"))
+	details.Enabled = cfg.BuildCacheEnabled
+	setConfigVersion(&details, toolconfig.Xcelerate, cfg.ConfigVersion, cfg.WrittenAt)
+	details.Endpoint = cfg.BuildCacheEndpoint
+	details.PushEnabled = cfg.PushEnabled
+	if home != "" {
+		details.BenchmarkPhase = c.benchmarkPhase(home, configcommon.BuildToolXcode)
+	}
+
+	if cfg.ProxySocketPath != "" {
+		details.Helper = newHelper("xcelerate-proxy", cfg.ProxySocketPath, probe.Socket(c.logger, cfg.ProxySocketPath))
+	}
+
+	return details
+}
+
+func (c *Checker) cppDetails() Tool {
+	details := Tool{ConfigFiles: []string{}}
+
+	cfg, err := ccacheconfig.ReadConfig(c.osProxy, c.decoderFactory)
+	if err != nil {
+		return details
+	}
+
+	c.addExistingFiles(&details, ccacheconfig.PathFor(c.osProxy, "config.json"))
+	details.Enabled = cfg.Enabled
+	setConfigVersion(&details, toolconfig.Ccache, cfg.ConfigVersion, cfg.WrittenAt)
+	details.Endpoint = cfg.BuildCacheEndpoint
+	details.PushEnabled = cfg.PushEnabled
+
+	if cfg.IPCEndpoint != "" {
+		details.Helper = newHelper("ccache-helper", cfg.IPCEndpoint, probe.CcacheSocket(c.logger, cfg.IPCEndpoint))
+	}
+
+	return details
+}
+
+func (c *Checker) reactNativeDetails() Tool {
+	details := Tool{ConfigFiles: []string{}}
+
+	cfg, err := rnconfig.ReadConfig(c.osProxy, c.decoderFactory)
+	if err != nil {
+		return details
+	}
+
+	c.addExistingFiles(&details, rnconfig.PathFor(c.osProxy, rnconfig.ConfigFileName))
+	details.Enabled = cfg.Enabled
+
+	return details
+}
+
+func (c *Checker) bazelDetails(home string) Tool {
+	details := Tool{ConfigFiles: []string{}}
+	if home == "" {
+		return details
+	}
+
+	sidecar, ok, err := bazelconfig.ReadSidecar(home)
+	if err != nil || !ok {
+		return details
+	}
+
+	c.addExistingFiles(&details, bazelconfig.SidecarFilePath(home), sidecar.BazelrcPath)
+	details.Enabled = c.bazelEnabled()
+	setConfigVersion(&details, toolconfig.Bazel, sidecar.ConfigVersion, sidecar.WrittenAt)
+	details.Endpoint = sidecar.CacheEndpoint
+	details.PushEnabled = sidecar.CachePushEnabled
+	details.BenchmarkPhase = c.benchmarkPhase(home, configcommon.BuildToolBazel)
+
+	if sidecar.CacheProxySocketPath != "" {
+		details.Helper = newHelper("bazel-proxy", sidecar.CacheProxySocketPath, probe.Socket(c.logger, sidecar.CacheProxySocketPath))
+	}
+
+	return details
+}
+
+// addExistingFiles appends the non-empty files that exist to details.ConfigFiles.
+func (c *Checker) addExistingFiles(details *Tool, files ...string) {
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		if _, err := c.osProxy.Stat(file); err == nil {
+			details.ConfigFiles = append(details.ConfigFiles, file)
+		}
+	}
+}
+
+// benchmarkPhase reads the phase activate stored for buildTool. Empty means
+// the build is not part of a benchmark.
+func (c *Checker) benchmarkPhase(home, buildTool string) string {
+	content, exists, err := c.osProxy.ReadFileIfExists(configcommon.BenchmarkPhaseFileIn(home, buildTool))
+	if err != nil || !exists {
+		return ""
+	}
+
+	var phase configcommon.BenchmarkPhaseFile
+	if err := json.Unmarshal([]byte(content), &phase); err != nil {
+		c.logger.Debugf("Decode %s benchmark phase: %s", buildTool, err)
+
+		return ""
+	}
+
+	return phase.Phase
+}
+
+func setConfigVersion(details *Tool, tool toolconfig.Tool, stored string, writtenAt time.Time) {
+	details.ConfigVersion = stored
+	details.CurrentConfigVersion = refresh.CurrentConfigVersions()[tool]
+	details.ConfigOutdated = refresh.IsOutdated(tool, stored)
+	details.ConfiguredAt = writtenAt
+}
+
+func newHelper(name, address string, state probe.State) *Helper {
+	return &Helper{
+		Name:      name,
+		Address:   address,
+		State:     string(state),
+		Reachable: state == probe.Running,
+	}
+}
@@ -0,0 +1,139 @@
+//go:build unit
+
+package status_test
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bazelconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/bazel"
+	configcommon "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/common"
+	gradleconfig "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/gradle"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/config/xcelerate"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils"
+	utilsMocks "github.com/bitrise-io/bitrise-build-cache-cli/v3/internal/utils/mocks"
+	"github.com/bitrise-io/bitrise-build-cache-cli/v3/pkg/status"
+)
+
+func newDetailsCheckerForHome(home string) *status.Checker {
+	osProxy := &utilsMocks.OsProxyMock{
+		UserHomeDirFunc:      func() (string, error) { return home, nil },
+		OpenFileFunc:         os.OpenFile,
+		StatFunc:             os.Stat,
+		ReadFileIfExistsFunc: utils.DefaultOsProxy{}.ReadFileIfExists,
+	}
+	decoderFactory := &utilsMocks.DecoderFactoryMock{
+		DecoderFunc: func(r io.Reader) utils.Decoder { return json.NewDecoder(r) },
+	}
+
+	return status.NewChecker(status.CheckerParams{
+		Logger:         mockLogger,
+		OsProxy:        osProxy,
+		DecoderFactory: decoderFactory,
+		Envs:           map[string]string{},
+	})
+}
+
+func TestChecker_Details_NothingActivated(t *testing.T) {
+	d := newDetailsCheckerForHome(t.TempDir()).Details()
+
+	assert.Equal(t, status.DetailsSchemaVersion, d.SchemaVersion)
+	for _, tool := range []status.Tool{d.Tools.Gradle, d.Tools.Xcode, d.Tools.Cpp, d.Tools.ReactNative, d.Tools.Bazel} {
+		assert.False(t, tool.Enabled)
+		assert.Empty(t, tool.ConfigFiles)
+		assert.NotNil(t, tool.ConfigFiles, "configFiles must encode as [] rather than null")
+		assert.Nil(t, tool.Helper)
+	}
+}
+
+func TestChecker_Details_Xcode(t *testing.T) {
+	home := t.TempDir()
+
+	// unix socket paths are length-limited, so keep it short
+	socketDir, err := os.MkdirTemp("/tmp", "status-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "proxy.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	dir := filepath.Join(home, ".bitrise-xcelerate")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	payload, err := json.Marshal(xcelerate.Config{
+		BuildCacheEnabled:  true,
+		BuildCacheEndpoint: "grpcs://cache.example.com",
+		PushEnabled:        true,
+		ProxySocketPath:    socketPath,
+		ConfigVersion:      "1.0.0",
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), payload, 0o600))
+
+	phaseFile := configcommon.BenchmarkPhaseFileIn(home, configcommon.BuildToolXcode)
+	require.NoError(t, os.MkdirAll(filepath.Dir(phaseFile), 0o755))
+	require.NoError(t, os.WriteFile(phaseFile, []byte(`{"phase":"warmup"}`), 0o600))
+
+	xcode := newDetailsCheckerForHome(home).Details().Tools.Xcode
+
+	assert.True(t, xcode.Enabled)
+	assert.Equal(t, []string{filepath.Join(dir, "config.json")}, xcode.ConfigFiles)
+	assert.Equal(t, "1.0.0", xcode.ConfigVersion)
+	assert.NotEmpty(t, xcode.CurrentConfigVersion)
+	assert.False(t, xcode.ConfigOutdated)
+	assert.Equal(t, "grpcs://cache.example.com", xcode.Endpoint)
+	assert.True(t, xcode.PushEnabled)
+	assert.Equal(t, "warmup", xcode.BenchmarkPhase)
+	require.NotNil(t, xcode.Helper)
+	assert.Equal(t, status.Helper{Name: "xcelerate-proxy", Address: socketPath, State: "running", Reachable: true}, *xcode.Helper)
+}
+
+func TestChecker_Details_GradleAndBazel(t *testing.T) {
+	home := t.TempDir()
+	writeFixture(t, home, featureBits{gradle: true})
+
+	// grab a free port, then close it so the connector reads as stopped
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	require.NoError(t, gradleconfig.WriteSidecar(home, gradleconfig.Sidecar{
+		CacheEnabled:       true,
+		CachePushEnabled:   true,
+		CacheLocalHTTPPort: port,
+	}))
+
+	bazelrc := filepath.Join(home, ".bazelrc")
+	require.NoError(t, os.WriteFile(bazelrc, []byte("build --remote_cache=grpcs://example.com\n"), 0o600))
+	require.NoError(t, bazelconfig.WriteSidecar(home, bazelconfig.Sidecar{BazelrcPath: bazelrc, CacheEnabled: true}))
+
+	c := newDetailsCheckerForHome(home)
+	d := c.Details()
+
+	gradle := d.Tools.Gradle
+	assert.True(t, gradle.Enabled)
+	assert.Len(t, gradle.ConfigFiles, 2)
+	assert.True(t, gradle.PushEnabled)
+	require.NotNil(t, gradle.Helper)
+	assert.Equal(t, "gradle-cache-connector", gradle.Helper.Name)
+	assert.Equal(t, "stopped", gradle.Helper.State)
+	assert.False(t, gradle.Helper.Reachable)
+
+	bazel := d.Tools.Bazel
+	assert.True(t, bazel.Enabled)
+	assert.Equal(t, []string{bazelconfig.SidecarFilePath(home), bazelrc}, bazel.ConfigFiles)
+	assert.False(t, bazel.PushEnabled)
+	assert.Nil(t, bazel.Helper)
+	assert.True(t, c.Status().Bazel)
+
+	require.NoError(t, os.Remove(bazelrc))
+	assert.False(t, c.Status().Bazel, "a removed bazelrc disables bazel")
+}
@@ -152,6 +152,9 @@ type GetSessionStatsResponse struct {
 	KvHits          int64                  `protobuf:"varint,6,opt,name=kv_hits,json=kvHits,proto3" json:"kv_hits,omitempty"`
 	KvMisses        int64                  `protobuf:"varint,7,opt,name=kv_misses,json=kvMisses,proto3" json:"kv_misses,omitempty"`
 	KvUploadedBytes int64                  `protobuf:"varint,8,opt,name=kv_uploaded_bytes,json=kvUploadedBytes,proto3" json:"kv_uploaded_bytes,omitempty"`
+	LocalHits       int64                  `protobuf:"varint,9,opt,name=local_hits,json=localHits,proto3" json:"local_hits,omitempty"`
+	RemoteHits      int64                  `protobuf:"varint,10,opt,name=remote_hits,json=remoteHits,proto3" json:"remote_hits,omitempty"`
+	Degraded        bool                   `protobuf:"varint,11,opt,name=degraded,proto3" json:"degraded,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -242,6 +245,27 @@ func (x *GetSessionStatsResponse) GetKvUploadedBytes() int64 {
 	return 0
 }
 
+func (x *GetSessionStatsResponse) GetLocalHits() int64 {
+	if x != nil {
+		return x.LocalHits
+	}
+	return 0
+}
+
+func (x *GetSessionStatsResponse) GetRemoteHits() int64 {
+	if x != nil {
+		return x.RemoteHits
+	}
+	return 0
+}
+
+func (x *GetSessionStatsResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
 var File_llvm_session_session_proto protoreflect.FileDescriptor
 
 const file_llvm_session_session_proto_rawDesc = "" +
@@ -255,7 +279,7 @@ const file_llvm_session_session_proto_rawDesc = "" +
 	"\tstep_slug\x18\x04 \x01(\tR\bstepSlug\"a\n" +
 	"\x11EndSessionRequest\x12#\n" +
 	"\rinvocation_id\x18\x01 \x01(\tR\finvocationId\x12'\n" +
-	"\x10end_time_unix_ms\x18\x02 \x01(\x03R\rendTimeUnixMs\"\x93\x02\n" +
+	"\x10end_time_unix_ms\x18\x02 \x01(\x03R\rendTimeUnixMs\"\xef\x02\n" +
 	"\x17GetSessionStatsResponse\x12%\n" +
 	"\x0euploaded_bytes\x18\x01 \x01(\x03R\ruploadedBytes\x12)\n" +
 	"\x10downloaded_bytes\x18\x02 \x01(\x03R\x0fdownloadedBytes\x12\x12\n" +
@@ -264,7 +288,13 @@ const file_llvm_session_session_proto_rawDesc = "" +
 	"\auploads\x18\x05 \x01(\x03R\auploads\x12\x17\n" +
 	"\akv_hits\x18\x06 \x01(\x03R\x06kvHits\x12\x1b\n" +
 	"\tkv_misses\x18\a \x01(\x03R\bkvMisses\x12*\n" +
-	"\x11kv_uploaded_bytes\x18\b \x01(\x03R\x0fkvUploadedBytes2\xe0\x01\n" +
+	"\x11kv_uploaded_bytes\x18\b \x01(\x03R\x0fkvUploadedBytes\x12\x1d\n" +
+	"\n" +
+	"local_hits\x18\t \x01(\x03R\tlocalHits\x12\x1f\n" +
+	"\vremote_hits\x18\n" +
+	" \x01(\x03R\n" +
+	"remoteHits\x12\x1a\n" +
+	"\bdegraded\x18\v \x01(\bR\bdegraded2\xe0\x01\n" +
 	"\aSession\x12B\n" +
 	"\n" +
 	"SetSession\x12\x1a.session.SetSessionRequest\x1a\x16.google.protobuf.Empty\"\x00\x12B\n" +